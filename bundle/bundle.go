@@ -0,0 +1,151 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The bundle package defines bundles, which describe a set of services
+// and the relations between them, so that they can be deployed
+// together.
+//
+// A bundle is written in YAML; for example:
+//
+//     services:
+//       wordpress:
+//         charm: cs:precise/wordpress
+//         num_units: 2
+//         options:
+//           blog-title: My Blog
+//         constraints: mem=2G
+//         expose: true
+//       mysql:
+//         charm: cs:precise/mysql-27
+//         num_units: 1
+//     relations:
+//       - [wordpress, mysql]
+package bundle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/state"
+)
+
+// Bundle describes a set of services and the relations between them.
+type Bundle struct {
+	// Services holds the services in the bundle, keyed by service
+	// name.
+	Services map[string]*Service `yaml:"services"`
+
+	// Relations holds the relations between the services. Each
+	// relation holds two endpoints of the form
+	// <service>[:<relation>], as accepted by "juju add-relation".
+	Relations [][]string `yaml:"relations,omitempty"`
+}
+
+// Service describes a single service in a bundle.
+type Service struct {
+	// Charm holds the URL of the service's charm. The series and
+	// schema may be omitted, as for "juju deploy"; if the revision
+	// is omitted, the latest revision is used.
+	Charm string `yaml:"charm"`
+
+	// NumUnits holds the number of units of the service.
+	NumUnits int `yaml:"num_units,omitempty"`
+
+	// Options holds the service's charm configuration settings.
+	Options map[string]interface{} `yaml:"options,omitempty"`
+
+	// Constraints holds the service's constraints, in the form
+	// accepted by "juju set-constraints".
+	Constraints string `yaml:"constraints,omitempty"`
+
+	// Expose holds whether the service is exposed.
+	Expose bool `yaml:"expose,omitempty"`
+
+	// Annotations holds the service's annotations.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Parse parses a bundle from YAML data and verifies it.
+func Parse(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := goyaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("cannot parse bundle: %v", err)
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Verify checks that the bundle is well formed. It does not check the
+// bundle against the charms of its services, or against any
+// environment.
+func (b *Bundle) Verify() error {
+	if len(b.Services) == 0 {
+		return fmt.Errorf("bundle has no services")
+	}
+	for _, name := range b.ServiceNames() {
+		svc := b.Services[name]
+		if !state.IsServiceName(name) {
+			return fmt.Errorf("invalid service name %q", name)
+		}
+		if svc == nil || svc.Charm == "" {
+			return fmt.Errorf("service %q has no charm", name)
+		}
+		if svc.NumUnits < 0 {
+			return fmt.Errorf("service %q has negative number of units", name)
+		}
+		if _, err := constraints.Parse(svc.Constraints); err != nil {
+			return fmt.Errorf("service %q has invalid constraints: %v", name, err)
+		}
+	}
+	for _, rel := range b.Relations {
+		if len(rel) != 2 {
+			return fmt.Errorf("relation %q must have two endpoints", rel)
+		}
+	}
+	return nil
+}
+
+// ServiceNames returns the names of the services in the bundle, in
+// alphabetical order.
+func (b *Bundle) ServiceNames() []string {
+	names := make([]string, 0, len(b.Services))
+	for name := range b.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// secretWords holds the words that mark a charm option as holding a
+// secret, such as a password or a private key.
+var secretWords = map[string]bool{
+	"password":    true,
+	"passwd":      true,
+	"secret":      true,
+	"key":         true,
+	"token":       true,
+	"credentials": true,
+}
+
+// IsSecretOption reports whether the charm option with the given name
+// is likely to hold a secret. Charms do not declare which of their
+// options are secret, so the decision is made from the words in the
+// option name; for example "admin-password" and "ssh_key" are secret,
+// but "keyboard" is not.
+func IsSecretOption(name string) bool {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for _, word := range words {
+		if secretWords[word] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package bundle_test
+
+import (
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/bundle"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type bundleSuite struct{}
+
+var _ = Suite(&bundleSuite{})
+
+func (*bundleSuite) TestParse(c *C) {
+	b, err := bundle.Parse([]byte(`
+services:
+  wordpress:
+    charm: cs:precise/wordpress
+    num_units: 2
+    options:
+      blog-title: My Blog
+      skill-level: 3
+    constraints: mem=2G
+    expose: true
+    annotations:
+      gui-x: "10"
+  mysql:
+    charm: mysql
+relations:
+  - [wordpress, "mysql:server"]
+`))
+	c.Assert(err, IsNil)
+	c.Assert(b, DeepEquals, &bundle.Bundle{
+		Services: map[string]*bundle.Service{
+			"wordpress": {
+				Charm:    "cs:precise/wordpress",
+				NumUnits: 2,
+				Options: map[string]interface{}{
+					"blog-title":  "My Blog",
+					"skill-level": 3,
+				},
+				Constraints: "mem=2G",
+				Expose:      true,
+				Annotations: map[string]string{"gui-x": "10"},
+			},
+			"mysql": {
+				Charm: "mysql",
+			},
+		},
+		Relations: [][]string{{"wordpress", "mysql:server"}},
+	})
+	c.Assert(b.ServiceNames(), DeepEquals, []string{"mysql", "wordpress"})
+}
+
+var verifyTests = []struct {
+	about string
+	yaml  string
+	err   string
+}{{
+	about: "not yaml",
+	yaml:  "services: [",
+	err:   "cannot parse bundle: .*",
+}, {
+	about: "no services",
+	yaml:  "relations: [[a, b]]",
+	err:   "bundle has no services",
+}, {
+	about: "bad service name",
+	yaml:  "services: {Bad_Name: {charm: wordpress}}",
+	err:   `invalid service name "Bad_Name"`,
+}, {
+	about: "no charm",
+	yaml:  "services: {wordpress: {num_units: 1}}",
+	err:   `service "wordpress" has no charm`,
+}, {
+	about: "empty service",
+	yaml:  "services: {wordpress: }",
+	err:   `service "wordpress" has no charm`,
+}, {
+	about: "negative units",
+	yaml:  "services: {wordpress: {charm: wordpress, num_units: -1}}",
+	err:   `service "wordpress" has negative number of units`,
+}, {
+	about: "bad constraints",
+	yaml:  "services: {wordpress: {charm: wordpress, constraints: 'foo=bar'}}",
+	err:   `service "wordpress" has invalid constraints: unknown constraint "foo"`,
+}, {
+	about: "relation with one endpoint",
+	yaml:  "services: {wordpress: {charm: wordpress}}\nrelations: [[wordpress]]",
+	err:   `relation \["wordpress"\] must have two endpoints`,
+}}
+
+func (*bundleSuite) TestVerifyErrors(c *C) {
+	for i, test := range verifyTests {
+		c.Logf("test %d: %s", i, test.about)
+		_, err := bundle.Parse([]byte(test.yaml))
+		c.Check(err, ErrorMatches, test.err)
+	}
+}
+
+func (*bundleSuite) TestIsSecretOption(c *C) {
+	for name, secret := range map[string]bool{
+		"password":       true,
+		"admin-password": true,
+		"DB_PASSWD":      true,
+		"ssh_key":        true,
+		"api-key":        true,
+		"oauth-token":    true,
+		"secret":         true,
+		"blog-title":     false,
+		"keyboard":       false,
+		"passwords-file": false,
+		"skill-level":    false,
+	} {
+		c.Check(bundle.IsSecretOption(name), Equals, secret, Commentf("option %q", name))
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package bundle
+
+// Diff describes the differences between a bundle and the services and
+// relations in an environment.
+type Diff struct {
+	// MissingServices holds the names of the services in the bundle
+	// that are not in the environment.
+	MissingServices []string `yaml:"missing-services,omitempty" json:"missing-services,omitempty"`
+
+	// ExtraServices holds the names of the services in the
+	// environment that are not in the bundle.
+	ExtraServices []string `yaml:"extra-services,omitempty" json:"extra-services,omitempty"`
+
+	// Services holds the differences found in the services that are
+	// both in the bundle and in the environment, keyed by service
+	// name. Services without differences are omitted.
+	Services map[string]*ServiceDiff `yaml:"services,omitempty" json:"services,omitempty"`
+
+	// MissingRelations holds the relations in the bundle that are not
+	// in the environment.
+	MissingRelations [][]string `yaml:"missing-relations,omitempty" json:"missing-relations,omitempty"`
+
+	// ExtraRelations holds the relations in the environment that are
+	// not in the bundle.
+	ExtraRelations [][]string `yaml:"extra-relations,omitempty" json:"extra-relations,omitempty"`
+}
+
+// Empty reports whether the diff holds no differences.
+func (d *Diff) Empty() bool {
+	return len(d.MissingServices) == 0 &&
+		len(d.ExtraServices) == 0 &&
+		len(d.Services) == 0 &&
+		len(d.MissingRelations) == 0 &&
+		len(d.ExtraRelations) == 0
+}
+
+// ServiceDiff describes the differences between a service in a bundle
+// and the service of the same name in an environment.
+type ServiceDiff struct {
+	// Charm holds the charm URLs, if they differ.
+	Charm *ValueDiff `yaml:"charm,omitempty" json:"charm,omitempty"`
+
+	// Options holds the configuration settings that differ, keyed
+	// by option name.
+	Options map[string]*ValueDiff `yaml:"options,omitempty" json:"options,omitempty"`
+
+	// Constraints holds the constraints, if they differ.
+	Constraints *ValueDiff `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+}
+
+// ValueDiff holds a value as given in a bundle and as found in an
+// environment.
+type ValueDiff struct {
+	Bundle      interface{} `yaml:"bundle" json:"bundle"`
+	Environment interface{} `yaml:"environment" json:"environment"`
+}
@@ -0,0 +1,149 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/schema"
+)
+
+// Actions represents the actions that may be run on units of a charm,
+// as declared in its actions.yaml file. Each action is run by executing
+// the file of the same name in the charm's actions directory.
+type Actions struct {
+	ActionSpecs map[string]ActionSpec `yaml:"actions"`
+}
+
+// ActionSpec describes a single action and the parameters it accepts.
+// Parameters have the same types as config options: string, int, float
+// and boolean. A parameter with a default value always has a value when
+// the action runs; other parameters need not be given.
+type ActionSpec struct {
+	Description string
+	Params      map[string]Option
+}
+
+// NewActions returns a new Actions without any actions.
+func NewActions() *Actions {
+	return &Actions{map[string]ActionSpec{}}
+}
+
+var validActionName = regexp.MustCompile("^[a-z][a-z0-9]*(-[a-z0-9]+)*$")
+
+// IsValidActionName reports whether name is a valid action name.
+func IsValidActionName(name string) bool {
+	return validActionName.MatchString(name)
+}
+
+// ReadActions reads an Actions in YAML format.
+func ReadActions(r io.Reader) (*Actions, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var actions *Actions
+	if err := goyaml.Unmarshal(data, &actions); err != nil {
+		return nil, err
+	}
+	if actions == nil {
+		return nil, fmt.Errorf("invalid actions: empty actions definition")
+	}
+	if actions.ActionSpecs == nil {
+		actions.ActionSpecs = map[string]ActionSpec{}
+	}
+	for name, spec := range actions.ActionSpecs {
+		if !IsValidActionName(name) {
+			return nil, fmt.Errorf("invalid actions: bad action name %q", name)
+		}
+		for pname, param := range spec.Params {
+			switch param.Type {
+			case "string", "int", "float", "boolean":
+			case "":
+				param.Type = "string"
+			default:
+				return nil, fmt.Errorf("invalid actions: action %q parameter %q has unknown type %q", name, pname, param.Type)
+			}
+			if def := param.Default; def != nil {
+				if param.Default, err = optionTypeCheckers[param.Type].Coerce(def, nil); err != nil {
+					return nil, fmt.Errorf("invalid actions: action %q parameter %q expected %s default, got %#v", name, pname, param.Type, def)
+				}
+			}
+			spec.Params[pname] = param
+		}
+		actions.ActionSpecs[name] = spec
+	}
+	return actions, nil
+}
+
+// spec returns the named action, or an error if none such exists.
+func (a *Actions) spec(name string) (ActionSpec, error) {
+	if spec, ok := a.ActionSpecs[name]; ok {
+		return spec, nil
+	}
+	return ActionSpec{}, fmt.Errorf("unknown action %q", name)
+}
+
+// ValidateParams returns a copy of the supplied parameters for the named
+// action, with a consistent type for each value and with default values
+// added for parameters that are not given. It returns an error if the
+// action is unknown, or if the parameters contain unknown keys or
+// invalid values.
+func (a *Actions) ValidateParams(name string, params map[string]interface{}) (map[string]interface{}, error) {
+	spec, err := a.spec(name)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(schema.Fields)
+	defaults := make(schema.Defaults)
+	for pname, param := range spec.Params {
+		fields[pname] = optionTypeCheckers[param.Type]
+		if param.Default != nil {
+			defaults[pname] = param.Default
+		} else {
+			defaults[pname] = schema.Omit
+		}
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	out, err := schema.StrictFieldMap(fields, defaults).Coerce(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for action %q: %v", name, err)
+	}
+	return out.(map[string]interface{}), nil
+}
+
+// ParseParamsStrings returns parameters for the named action derived
+// from the supplied map, as given on a command line. Every value must be
+// parseable to the correct type for the parameter identified by its key.
+// The result is validated as by ValidateParams.
+func (a *Actions) ParseParamsStrings(name string, values map[string]string) (map[string]interface{}, error) {
+	spec, err := a.spec(name)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]interface{})
+	for pname, str := range values {
+		param, ok := spec.Params[pname]
+		if !ok {
+			return nil, fmt.Errorf("action %q has no parameter %q", name, pname)
+		}
+		if str == "" && param.Type == "string" {
+			params[pname] = str
+			continue
+		}
+		value, err := param.parse(pname, str)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q of action %q expected %s, got %q", pname, name, param.Type, str)
+		}
+		params[pname] = value
+	}
+	return a.ValidateParams(name, params)
+}
@@ -0,0 +1,159 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+)
+
+type ActionsSuite struct {
+	actions *charm.Actions
+}
+
+var _ = Suite(&ActionsSuite{})
+
+func (s *ActionsSuite) SetUpSuite(c *C) {
+	var err error
+	s.actions, err = charm.ReadActions(bytes.NewBuffer([]byte(`
+actions:
+  snapshot:
+    description: Take a snapshot of the database.
+    params:
+      outfile:
+        description: The file to write out to.
+        type: string
+        default: foo.bz2
+      compression:
+        description: The compression level.
+        type: int
+        default: 9
+      incremental:
+        description: Whether to take an incremental snapshot.
+        type: boolean
+  restart:
+    description: Restart the service.
+`)))
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionsSuite) TestReadSample(c *C) {
+	c.Assert(s.actions.ActionSpecs, DeepEquals, map[string]charm.ActionSpec{
+		"snapshot": {
+			Description: "Take a snapshot of the database.",
+			Params: map[string]charm.Option{
+				"outfile": {
+					Type:        "string",
+					Description: "The file to write out to.",
+					Default:     "foo.bz2",
+				},
+				"compression": {
+					Type:        "int",
+					Description: "The compression level.",
+					Default:     int64(9),
+				},
+				"incremental": {
+					Type:        "boolean",
+					Description: "Whether to take an incremental snapshot.",
+				},
+			},
+		},
+		"restart": {
+			Description: "Restart the service.",
+		},
+	})
+}
+
+var readActionsErrorTests = []struct {
+	yaml string
+	err  string
+}{{
+	yaml: "",
+	err:  "invalid actions: empty actions definition",
+}, {
+	yaml: "actions:\n  Snap_shot:\n    description: x\n",
+	err:  `invalid actions: bad action name "Snap_shot"`,
+}, {
+	yaml: "actions:\n  snapshot:\n    params:\n      outfile:\n        type: blob\n",
+	err:  `invalid actions: action "snapshot" parameter "outfile" has unknown type "blob"`,
+}, {
+	yaml: "actions:\n  snapshot:\n    params:\n      level:\n        type: int\n        default: high\n",
+	err:  `invalid actions: action "snapshot" parameter "level" expected int default, got "high"`,
+}}
+
+func (s *ActionsSuite) TestReadActionsErrors(c *C) {
+	for i, t := range readActionsErrorTests {
+		c.Logf("test %d: %q", i, t.yaml)
+		_, err := charm.ReadActions(bytes.NewBufferString(t.yaml))
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *ActionsSuite) TestReadActionsDefaultType(c *C) {
+	actions, err := charm.ReadActions(bytes.NewBufferString("actions:\n  snapshot:\n    params:\n      outfile: {}\n"))
+	c.Assert(err, IsNil)
+	c.Assert(actions.ActionSpecs["snapshot"].Params["outfile"].Type, Equals, "string")
+}
+
+func (s *ActionsSuite) TestValidateParams(c *C) {
+	params, err := s.actions.ValidateParams("snapshot", nil)
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]interface{}{
+		"outfile":     "foo.bz2",
+		"compression": int64(9),
+	})
+
+	params, err = s.actions.ValidateParams("snapshot", map[string]interface{}{
+		"outfile":     "bar.gz",
+		"compression": 3,
+		"incremental": true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]interface{}{
+		"outfile":     "bar.gz",
+		"compression": int64(3),
+		"incremental": true,
+	})
+
+	params, err = s.actions.ValidateParams("restart", nil)
+	c.Assert(err, IsNil)
+	c.Assert(params, HasLen, 0)
+}
+
+func (s *ActionsSuite) TestValidateParamsErrors(c *C) {
+	_, err := s.actions.ValidateParams("explode", nil)
+	c.Assert(err, ErrorMatches, `unknown action "explode"`)
+
+	_, err = s.actions.ValidateParams("snapshot", map[string]interface{}{"compression": "high"})
+	c.Assert(err, ErrorMatches, `invalid parameters for action "snapshot": compression: expected int, got "high"`)
+
+	_, err = s.actions.ValidateParams("restart", map[string]interface{}{"now": true})
+	c.Assert(err, ErrorMatches, `invalid parameters for action "restart": now: expected nothing, got true`)
+}
+
+func (s *ActionsSuite) TestParseParamsStrings(c *C) {
+	params, err := s.actions.ParseParamsStrings("snapshot", map[string]string{
+		"outfile":     "",
+		"compression": "1",
+		"incremental": "true",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]interface{}{
+		"outfile":     "",
+		"compression": int64(1),
+		"incremental": true,
+	})
+
+	_, err = s.actions.ParseParamsStrings("snapshot", map[string]string{"compression": "high"})
+	c.Assert(err, ErrorMatches, `parameter "compression" of action "snapshot" expected int, got "high"`)
+
+	_, err = s.actions.ParseParamsStrings("snapshot", map[string]string{"speed": "1"})
+	c.Assert(err, ErrorMatches, `action "snapshot" has no parameter "speed"`)
+
+	_, err = s.actions.ParseParamsStrings("explode", nil)
+	c.Assert(err, ErrorMatches, `unknown action "explode"`)
+}
@@ -21,6 +21,7 @@ type Bundle struct {
 	Path     string // May be empty if Bundle wasn't read from a file
 	meta     *Meta
 	config   *Config
+	actions  *Actions
 	revision int
 	r        io.ReaderAt
 	size     int64
@@ -83,6 +84,19 @@ func readBundle(r io.ReaderAt, size int64) (bundle *Bundle, err error) {
 		}
 	}
 
+	reader, err = zipOpen(zipr, "actions.yaml")
+	if _, ok := err.(*noBundleFile); ok {
+		b.actions = NewActions()
+	} else if err != nil {
+		return nil, err
+	} else {
+		b.actions, err = ReadActions(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	reader, err = zipOpen(zipr, "revision")
 	if err != nil {
 		if _, ok := err.(*noBundleFile); !ok {
@@ -140,6 +154,12 @@ func (b *Bundle) Config() *Config {
 	return b.config
 }
 
+// Actions returns the Actions representing the actions.yaml file
+// for the charm bundle.
+func (b *Bundle) Actions() *Actions {
+	return b.actions
+}
+
 // ExpandTo expands the charm bundle into dir, creating it if necessary.
 // If any errors occur during the expansion procedure, the process will
 // continue. Only the last error found is returned.
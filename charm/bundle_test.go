@@ -46,6 +46,9 @@ func (s *BundleSuite) TestReadBundleWithoutConfig(c *C) {
 	// A lacking config.yaml file still causes a proper
 	// Config value to be returned.
 	c.Assert(bundle.Config().Options, HasLen, 0)
+
+	// Likewise for a lacking actions.yaml file.
+	c.Assert(bundle.Actions().ActionSpecs, HasLen, 0)
 }
 
 func (s *BundleSuite) TestReadBundleBytes(c *C) {
@@ -40,6 +40,9 @@ func (s *DirSuite) TestReadDirWithoutConfig(c *C) {
 	// A lacking config.yaml file still causes a proper
 	// Config value to be returned.
 	c.Assert(dir.Config().Options, HasLen, 0)
+
+	// Likewise for a lacking actions.yaml file.
+	c.Assert(dir.Actions().ActionSpecs, HasLen, 0)
 }
 
 func (s *DirSuite) TestBundleTo(c *C) {
@@ -17,6 +17,17 @@ const (
 	UpgradeCharm  Kind = "upgrade-charm"
 	Stop          Kind = "stop"
 
+	// The update-status hook runs periodically while the unit is idle,
+	// so that the charm can refresh its workload status.
+	UpdateStatus Kind = "update-status"
+
+	// These hooks concern the leadership of the unit's service. The
+	// leader-elected hook runs when the unit becomes the leader, and
+	// the leader-settings-changed hook runs on the other units when
+	// the leader changes the settings it shares with them.
+	LeaderElected         Kind = "leader-elected"
+	LeaderSettingsChanged Kind = "leader-settings-changed"
+
 	// These hooks require an associated relation, and the name of the relation
 	// unit whose change triggered the hook. The hook file names that these
 	// kinds represent will be prefixed by the relation name; for example,
@@ -37,6 +48,9 @@ var unitHooks = []Kind{
 	ConfigChanged,
 	UpgradeCharm,
 	Stop,
+	LeaderElected,
+	LeaderSettingsChanged,
+	UpdateStatus,
 }
 
 // UnitHooks returns all known unit hook kinds.
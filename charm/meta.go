@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"launchpad.net/goyaml"
 
@@ -61,6 +62,7 @@ type Meta struct {
 	Format      int                 `bson:",omitempty"`
 	OldRevision int                 `bson:",omitempty"` // Obsolete
 	Categories  []string            `bson:",omitempty"`
+	HookTimeout time.Duration       `bson:",omitempty"`
 }
 
 func generateRelationHooks(relName string, allHooks map[string]bool) {
@@ -138,6 +140,12 @@ func ReadMeta(r io.Reader) (meta *Meta, err error) {
 		// Obsolete
 		meta.OldRevision = int(m["revision"].(int64))
 	}
+	if timeout := m["hook-timeout"]; timeout != nil {
+		meta.HookTimeout, err = time.ParseDuration(timeout.(string))
+		if err != nil || meta.HookTimeout < 0 {
+			return nil, fmt.Errorf("metadata: invalid hook-timeout %q", timeout)
+		}
+	}
 	if err := meta.Check(); err != nil {
 		return nil, err
 	}
@@ -306,24 +314,26 @@ var ifaceSchema = schema.FieldMap(
 
 var charmSchema = schema.FieldMap(
 	schema.Fields{
-		"name":        schema.String(),
-		"summary":     schema.String(),
-		"description": schema.String(),
-		"peers":       schema.StringMap(ifaceExpander(int64(1))),
-		"provides":    schema.StringMap(ifaceExpander(nil)),
-		"requires":    schema.StringMap(ifaceExpander(int64(1))),
-		"revision":    schema.Int(), // Obsolete
-		"format":      schema.Int(),
-		"subordinate": schema.Bool(),
-		"categories":  schema.List(schema.String()),
+		"name":         schema.String(),
+		"summary":      schema.String(),
+		"description":  schema.String(),
+		"peers":        schema.StringMap(ifaceExpander(int64(1))),
+		"provides":     schema.StringMap(ifaceExpander(nil)),
+		"requires":     schema.StringMap(ifaceExpander(int64(1))),
+		"revision":     schema.Int(), // Obsolete
+		"format":       schema.Int(),
+		"subordinate":  schema.Bool(),
+		"categories":   schema.List(schema.String()),
+		"hook-timeout": schema.String(),
 	},
 	schema.Defaults{
-		"provides":    schema.Omit,
-		"requires":    schema.Omit,
-		"peers":       schema.Omit,
-		"revision":    schema.Omit,
-		"format":      1,
-		"subordinate": schema.Omit,
-		"categories":  schema.Omit,
+		"provides":     schema.Omit,
+		"requires":     schema.Omit,
+		"peers":        schema.Omit,
+		"revision":     schema.Omit,
+		"format":       1,
+		"subordinate":  schema.Omit,
+		"categories":   schema.Omit,
+		"hook-timeout": schema.Omit,
 	},
 )
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	. "launchpad.net/gocheck"
 
@@ -67,6 +68,24 @@ func (s *MetaSuite) TestSubordinate(c *C) {
 	c.Assert(meta.Subordinate, Equals, true)
 }
 
+func (s *MetaSuite) TestHookTimeout(c *C) {
+	meta, err := charm.ReadMeta(repoMeta("dummy"))
+	c.Assert(err, IsNil)
+	c.Assert(meta.HookTimeout, Equals, time.Duration(0))
+
+	hackYaml := ReadYaml(repoMeta("dummy"))
+	hackYaml["hook-timeout"] = "90s"
+	meta, err = charm.ReadMeta(hackYaml.Reader())
+	c.Assert(err, IsNil)
+	c.Assert(meta.HookTimeout, Equals, 90*time.Second)
+
+	for _, timeout := range []string{"forever", "-1m"} {
+		hackYaml["hook-timeout"] = timeout
+		_, err = charm.ReadMeta(hackYaml.Reader())
+		c.Assert(err, ErrorMatches, `metadata: invalid hook-timeout "`+timeout+`"`)
+	}
+}
+
 func (s *MetaSuite) TestSubordinateWithoutContainerRelation(c *C) {
 	r := repoMeta("dummy")
 	hackYaml := ReadYaml(r)
@@ -336,6 +355,9 @@ func (s *MetaSuite) TestMetaHooks(c *C) {
 		"config-changed":                true,
 		"upgrade-charm":                 true,
 		"stop":                          true,
+		"leader-elected":                true,
+		"leader-settings-changed":       true,
+		"update-status":                 true,
 		"cache-relation-joined":         true,
 		"cache-relation-changed":        true,
 		"cache-relation-departed":       true,
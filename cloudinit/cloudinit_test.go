@@ -216,6 +216,26 @@ func (S) TestOutput(c *C) {
 	}
 }
 
+func (S) TestPackages(c *C) {
+	cfg := cloudinit.New()
+	c.Assert(cfg.Packages(), HasLen, 0)
+	cfg.AddPackage("juju")
+	cfg.AddPackage("ubuntu")
+	c.Assert(cfg.Packages(), DeepEquals, []string{"juju", "ubuntu"})
+}
+
+func (S) TestRunCmds(c *C) {
+	cfg := cloudinit.New()
+	c.Assert(cfg.RunCmds(), HasLen, 0)
+	cfg.AddRunCmd("ls > /dev")
+	cfg.AddRunCmdArgs("ls", ">with space", "it's")
+	cfg.AddBootCmd("not a run command")
+	c.Assert(cfg.RunCmds(), DeepEquals, []string{
+		"ls > /dev",
+		`'ls' '>with space' 'it'"'"'s'`,
+	})
+}
+
 //#cloud-config
 //packages:
 //- juju
@@ -231,3 +251,70 @@ func ExampleConfig() {
 	}
 	fmt.Printf("%s", data)
 }
+
+func (S) TestRenderScript(c *C) {
+	cfg := cloudinit.New()
+	script, err := cfg.RenderScript()
+	c.Assert(err, IsNil)
+	c.Assert(script, Equals, "#!/bin/bash\nset -e\n")
+
+	cfg.SetOutput(cloudinit.OutAll, "| tee -a /var/log/cloud-init-output.log", "")
+	cfg.AddSSHAuthorizedKeys("ssh-rsa one\n# comment\nssh-rsa two it's")
+	cfg.AddAptSource("ppa:juju/experimental", "1024R/C8068B11")
+	cfg.AddAptSourceWithKeyId("deb http://example.com precise main", "ABCD", "keyserver.example.com")
+	cfg.SetAptUpdate(true)
+	cfg.SetAptUpgrade(true)
+	cfg.AddPackage("git")
+	cfg.AddRunCmd("mkdir -p /var/lib/juju")
+	cfg.AddRunCmdArgs("echo", "it's")
+	script, err = cfg.RenderScript()
+	c.Assert(err, IsNil)
+	c.Assert(script, Equals, `#!/bin/bash
+set -e
+exec > >(tee -a /var/log/cloud-init-output.log) 2>&1
+user='ubuntu'
+home=$(getent passwd "$user" | cut -d: -f6)
+if [ -z "$home" ]; then echo "user $user does not exist" >&2; exit 1; fi
+mkdir -p "$home/.ssh"
+printf '%s\n' 'ssh-rsa one' 'ssh-rsa two it'"'"'s' >> "$home/.ssh/authorized_keys"
+chmod 700 "$home/.ssh"
+chmod 600 "$home/.ssh/authorized_keys"
+chown -R "$user:" "$home/.ssh"
+export DEBIAN_FRONTEND=noninteractive
+add-apt-repository -y 'ppa:juju/experimental'
+apt-key adv --keyserver 'keyserver.example.com' --recv-keys 'ABCD'
+printf '%s\n' 'deb http://example.com precise main' >> /etc/apt/sources.list.d/cloud_config_sources.list
+apt-get update
+apt-get -y upgrade
+apt-get -y install 'git'
+mkdir -p /var/lib/juju
+'echo' 'it'"'"'s'
+`)
+}
+
+func (S) TestRenderScriptUser(c *C) {
+	cfg := cloudinit.New()
+	cfg.SetUser("me")
+	cfg.AddSSHAuthorizedKeys("ssh-rsa one")
+	cfg.SetOutput(cloudinit.OutAll, ">>/var/log/out.log", ">/var/log/err.log")
+	script, err := cfg.RenderScript()
+	c.Assert(err, IsNil)
+	c.Assert(script, Matches, `(?s)#!/bin/bash
+set -e
+exec >>/var/log/out.log 2>/var/log/err.log
+user='me'
+.*`)
+}
+
+func (S) TestRenderScriptUnsupported(c *C) {
+	cfg := cloudinit.New()
+	cfg.AddBootCmd("echo hello")
+	cfg.SetLocale("fr_FR.UTF-8")
+	_, err := cfg.RenderScript()
+	c.Assert(err, ErrorMatches, "cannot render cloud-init attributes as a script: bootcmd, locale")
+
+	cfg = cloudinit.New()
+	cfg.SetOutput(cloudinit.OutInit, ">>/var/log/init.log", "")
+	_, err = cfg.RenderScript()
+	c.Assert(err, ErrorMatches, `cannot render cloud-init output for "init" as a script`)
+}
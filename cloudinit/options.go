@@ -5,6 +5,8 @@ package cloudinit
 
 import (
 	"strings"
+
+	"launchpad.net/juju-core/utils"
 )
 
 // SetAttr sets an arbitrary attribute in the cloudinit config.
@@ -87,6 +89,12 @@ func (cfg *Config) AddPackage(name string) {
 	cfg.attrs["packages"] = append(pkgs, name)
 }
 
+// Packages returns the packages that will be installed on first boot.
+func (cfg *Config) Packages() []string {
+	pkgs, _ := cfg.attrs["packages"].([]string)
+	return pkgs
+}
+
 func (cfg *Config) addCmd(kind string, c *command) {
 	cmds, _ := cfg.attrs[kind].([]*command)
 	cfg.attrs[kind] = append(cmds, c)
@@ -106,6 +114,25 @@ func (cfg *Config) AddRunCmdArgs(args ...string) {
 	cfg.addCmd("runcmd", &command{args: args})
 }
 
+// RunCmds returns the commands that will be executed at first
+// boot, as shell command lines.
+func (cfg *Config) RunCmds() []string {
+	cmds, _ := cfg.attrs["runcmd"].([]*command)
+	lines := make([]string, len(cmds))
+	for i, c := range cmds {
+		if c.args == nil {
+			lines[i] = c.literal
+			continue
+		}
+		args := make([]string, len(c.args))
+		for j, arg := range c.args {
+			args[j] = utils.ShQuote(arg)
+		}
+		lines[i] = strings.Join(args, " ")
+	}
+	return lines
+}
+
 // AddBootCmd is like AddRunCmd except that the
 // command will run very early in the boot process,
 // and it will run on every boot, not just the first time.
@@ -0,0 +1,158 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudinit
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"launchpad.net/juju-core/utils"
+)
+
+// scriptAttrs holds the attributes that RenderScript knows how to
+// render.
+var scriptAttrs = map[string]bool{
+	"user":                true,
+	"output":              true,
+	"ssh_authorized_keys": true,
+	"apt_sources":         true,
+	"apt_update":          true,
+	"apt_upgrade":         true,
+	"packages":            true,
+	"runcmd":              true,
+}
+
+// RenderScript returns a bash script that, when run as root, has the
+// same effect as cloud-init given the configuration. It is used to
+// set up machines that were not started with cloud-init. Only some
+// attributes can be rendered; if others are set, an error is returned.
+//
+// The script redirects its output, adds the authorized keys of the
+// configured user, adds the apt sources, updates and upgrades
+// packages, installs the packages and finally runs the run commands,
+// in the order cloud-init does.
+func (cfg *Config) RenderScript() (string, error) {
+	var unsupported []string
+	for name := range cfg.attrs {
+		if !scriptAttrs[name] {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return "", fmt.Errorf("cannot render cloud-init attributes as a script: %s", strings.Join(unsupported, ", "))
+	}
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/bash\nset -e\n")
+	if err := cfg.renderOutput(&buf); err != nil {
+		return "", err
+	}
+	cfg.renderAuthorizedKeys(&buf)
+	cfg.renderApt(&buf)
+	for _, cmd := range cfg.RunCmds() {
+		fmt.Fprintln(&buf, cmd)
+	}
+	return buf.String(), nil
+}
+
+// renderOutput writes commands that redirect the output of the rest
+// of the script as specified by SetOutput. Only OutAll is supported,
+// because the script is not run in stages.
+func (cfg *Config) renderOutput(buf *bytes.Buffer) error {
+	out, _ := cfg.attrs["output"].(map[string]interface{})
+	var stdout, stderr string
+	for kind, dest := range out {
+		if kind != string(OutAll) {
+			return fmt.Errorf("cannot render cloud-init output for %q as a script", kind)
+		}
+		switch dest := dest.(type) {
+		case string:
+			stdout = dest
+		case []string:
+			stdout, stderr = dest[0], dest[1]
+		}
+	}
+	if stdout == "" {
+		return nil
+	}
+	if stderr == "" {
+		fmt.Fprintf(buf, "exec %s 2>&1\n", redirection(stdout))
+	} else {
+		fmt.Fprintf(buf, "exec %s 2%s\n", redirection(stdout), redirection(stderr))
+	}
+	return nil
+}
+
+// redirection returns the bash redirection of output to the
+// destination given to SetOutput.
+func redirection(dest string) string {
+	dest = strings.TrimSpace(dest)
+	if strings.HasPrefix(dest, "|") {
+		return "> >(" + strings.TrimSpace(dest[1:]) + ")"
+	}
+	return dest
+}
+
+// renderAuthorizedKeys writes commands that add the keys given to
+// AddSSHAuthorizedKeys to the configured user's authorized keys.
+func (cfg *Config) renderAuthorizedKeys(buf *bytes.Buffer) {
+	keys, _ := cfg.attrs["ssh_authorized_keys"].([]string)
+	if len(keys) == 0 {
+		return
+	}
+	user, _ := cfg.attrs["user"].(string)
+	if user == "" {
+		user = "ubuntu"
+	}
+	fmt.Fprintf(buf, "user=%s\n", utils.ShQuote(user))
+	buf.WriteString(`home=$(getent passwd "$user" | cut -d: -f6)
+if [ -z "$home" ]; then echo "user $user does not exist" >&2; exit 1; fi
+mkdir -p "$home/.ssh"
+`)
+	buf.WriteString("printf '%s\\n'")
+	for _, key := range keys {
+		buf.WriteString(" " + utils.ShQuote(key))
+	}
+	buf.WriteString(` >> "$home/.ssh/authorized_keys"
+chmod 700 "$home/.ssh"
+chmod 600 "$home/.ssh/authorized_keys"
+chown -R "$user:" "$home/.ssh"
+`)
+}
+
+// renderApt writes commands that add the apt sources, update and
+// upgrade packages, and install the packages. As with cloud-init,
+// the package lists are updated whenever packages are installed.
+func (cfg *Config) renderApt(buf *bytes.Buffer) {
+	sources, _ := cfg.attrs["apt_sources"].([]*source)
+	update, _ := cfg.attrs["apt_update"].(bool)
+	upgrade, _ := cfg.attrs["apt_upgrade"].(bool)
+	pkgs := cfg.Packages()
+	if len(sources) == 0 && !update && !upgrade && len(pkgs) == 0 {
+		return
+	}
+	buf.WriteString("export DEBIAN_FRONTEND=noninteractive\n")
+	for _, src := range sources {
+		if strings.HasPrefix(src.Source, "ppa:") {
+			// The key of a PPA is fetched by add-apt-repository.
+			fmt.Fprintf(buf, "add-apt-repository -y %s\n", utils.ShQuote(src.Source))
+			continue
+		}
+		if src.Key != "" {
+			fmt.Fprintf(buf, "printf '%%s\\n' %s | apt-key add -\n", utils.ShQuote(src.Key))
+		} else if src.KeyId != "" {
+			fmt.Fprintf(buf, "apt-key adv --keyserver %s --recv-keys %s\n", utils.ShQuote(src.KeyServer), utils.ShQuote(src.KeyId))
+		}
+		fmt.Fprintf(buf, "printf '%%s\\n' %s >> /etc/apt/sources.list.d/cloud_config_sources.list\n", utils.ShQuote(src.Source))
+	}
+	buf.WriteString("apt-get update\n")
+	if upgrade {
+		buf.WriteString("apt-get -y upgrade\n")
+	}
+	for _, pkg := range pkgs {
+		fmt.Fprintf(buf, "apt-get -y install %s\n", utils.ShQuote(pkg))
+	}
+}
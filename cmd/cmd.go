@@ -20,6 +20,28 @@ import (
 // code 1 without producing error output.
 var ErrSilent = errors.New("cmd: error out silently")
 
+// RcPassthroughError can be returned from Run to signal that Main should
+// exit with the given code without producing error output.
+type RcPassthroughError struct {
+	Code int
+}
+
+func (e *RcPassthroughError) Error() string {
+	return fmt.Sprintf("subprocess encountered error code %v", e.Code)
+}
+
+// IsRcPassthroughError returns whether err is an *RcPassthroughError.
+func IsRcPassthroughError(err error) bool {
+	_, ok := err.(*RcPassthroughError)
+	return ok
+}
+
+// NewRcPassthroughError returns an error that causes Main to exit with
+// the given code without producing error output.
+func NewRcPassthroughError(code int) error {
+	return &RcPassthroughError{code}
+}
+
 // Command is implemented by types that interpret command-line arguments.
 type Command interface {
 	// Info returns information about the Command.
@@ -160,6 +182,9 @@ func Main(c Command, ctx *Context, args []string) int {
 		return rc
 	}
 	if err := c.Run(ctx); err != nil {
+		if rcErr, ok := err.(*RcPassthroughError); ok {
+			return rcErr.Code
+		}
 		if err != ErrSilent {
 			fmt.Fprintf(ctx.Stderr, "error: %v\n", err)
 		}
@@ -79,6 +79,14 @@ func (s *CmdSuite) TestMainRunSilentError(c *C) {
 	c.Assert(bufferString(ctx.Stderr), Equals, "")
 }
 
+func (s *CmdSuite) TestMainRunRcPassthroughError(c *C) {
+	ctx := testing.Context(c)
+	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "rc-passthrough"})
+	c.Assert(result, Equals, 42)
+	c.Assert(bufferString(ctx.Stdout), Equals, "")
+	c.Assert(bufferString(ctx.Stderr), Equals, "")
+}
+
 func (s *CmdSuite) TestMainSuccess(c *C) {
 	ctx := testing.Context(c)
 	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "success!"})
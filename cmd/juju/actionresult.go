@@ -0,0 +1,83 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// ActionResultCommand shows the progress and results of an action
+// queued with DoCommand.
+type ActionResultCommand struct {
+	EnvCommandBase
+	ActionId string
+	out      cmd.Output
+}
+
+const actionResultDoc = `
+Show the progress and results of an action queued with "juju do". The
+status of the action is one of "pending", "completed" and "failed"; the
+results are the values set by the action with action-set, and a failed
+action reports why it failed.
+`
+
+func (c *ActionResultCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "action-result",
+		Args:    "<id>",
+		Purpose: "show the results of an action",
+		Doc:     actionResultDoc,
+	}
+}
+
+func (c *ActionResultCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *ActionResultCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no action id specified")
+	}
+	c.ActionId, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ActionResultCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	result, err := conn.State.Client().ActionResult(c.ActionId)
+	if err != nil {
+		return err
+	}
+	out := map[string]interface{}{
+		"id":       c.ActionId,
+		"unit":     result.UnitName,
+		"action":   result.Name,
+		"enqueued": result.Enqueued.Format(time.RFC3339),
+		"status":   result.Status,
+	}
+	if len(result.Params) > 0 {
+		out["params"] = result.Params
+	}
+	if len(result.Results) > 0 {
+		out["results"] = result.Results
+	}
+	if result.Message != "" {
+		out["message"] = result.Message
+	}
+	return c.out.Write(ctx, out)
+}
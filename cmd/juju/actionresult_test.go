@@ -0,0 +1,60 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type ActionResultSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ActionResultSuite{})
+
+func (s *ActionResultSuite) TestInit(c *C) {
+	err := testing.InitCommand(&ActionResultCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no action id specified")
+	err = testing.InitCommand(&ActionResultCommand{}, []string{"1", "2"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["2"\]`)
+}
+
+func (s *ActionResultSuite) TestActionResult(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	a, err := s.State.EnqueueAction(unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	err = a.Fail(map[string]interface{}{"size": "0"}, "disk full")
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &ActionResultCommand{}, []string{a.Id()})
+	c.Assert(err, IsNil)
+	var out map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &out)
+	c.Assert(err, IsNil)
+	enqueued, err := time.Parse(time.RFC3339, out["enqueued"].(string))
+	c.Assert(err, IsNil)
+	c.Assert(enqueued.Unix(), Equals, a.Enqueued().Unix())
+	delete(out, "enqueued")
+	c.Assert(out, DeepEquals, map[string]interface{}{
+		"id":      a.Id(),
+		"unit":    "dummy/0",
+		"action":  "snapshot",
+		"params":  map[interface{}]interface{}{"outfile": "foo.bz2"},
+		"status":  "failed",
+		"results": map[interface{}]interface{}{"size": "0"},
+		"message": "disk full",
+	})
+
+	_, err = testing.RunCommand(c, &ActionResultCommand{}, []string{"999"})
+	c.Assert(err, ErrorMatches, "action 999 not found")
+}
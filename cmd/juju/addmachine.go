@@ -8,6 +8,7 @@ import (
 	"launchpad.net/gnuflag"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs/manual"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/log"
@@ -24,14 +25,29 @@ type AddMachineCommand struct {
 	Constraints   constraints.Value
 	MachineId     string
 	ContainerType instance.ContainerType
+	// If specified, provision this [user@]host over SSH instead of
+	// starting a new machine.
+	SSHHost string
 }
 
+const addMachineDoc = `
+Machines are created in a clean state and ready to have units deployed.
+
+An existing machine may be added to the environment by giving it as
+ssh:[user@]host. The machine is connected to over SSH, its series and
+hardware are detected, and the juju tools and machine agent are installed
+on it. The user must be able to run sudo on the host without a password.
+The host's key is checked against your known hosts; the key of a host you
+have not connected to before is trusted and added to them. When such a
+machine is destroyed, its machine agent removes itself.
+`
+
 func (c *AddMachineCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "add-machine",
-		Args:    "[<container>:machine | <container>]",
+		Args:    "[<container>:machine | <container> | ssh:[user@]host]",
 		Purpose: "start a new, empty machine and optionally a container, or add a container to a machine",
-		Doc:     "Machines are created in a clean state and ready to have units deployed.",
+		Doc:     addMachineDoc,
 	}
 }
 
@@ -52,6 +68,16 @@ func (c *AddMachineCommand) Init(args []string) error {
 	if containerSpec == "" {
 		return nil
 	}
+	if strings.HasPrefix(containerSpec, "ssh:") {
+		c.SSHHost = containerSpec[len("ssh:"):]
+		if c.SSHHost == "" {
+			return fmt.Errorf("missing host in %q", containerSpec)
+		}
+		if c.Series != "" {
+			return fmt.Errorf("cannot specify series when adding an existing machine")
+		}
+		return nil
+	}
 	// container arg can either be 'type:machine' or 'type'
 	if c.ContainerType, err = instance.ParseSupportedContainerType(containerSpec); err != nil {
 		if !state.IsMachineOrNewContainer(containerSpec) {
@@ -70,6 +96,22 @@ func (c *AddMachineCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
+
+	if c.SSHHost != "" {
+		m, err := manual.ProvisionMachine(manual.ProvisionMachineArgs{
+			Host:        c.SSHHost,
+			State:       conn.State,
+			Environ:     conn.Environ,
+			Constraints: c.Constraints,
+		})
+		if err == nil {
+			log.Infof("created machine %v", m)
+		}
+		return err
+	}
 
 	series := c.Series
 	if series == "" {
@@ -99,3 +99,16 @@ func (s *AddMachineSuite) TestAddMachineErrors(c *C) {
 	err = runAddMachine(c, "lxc", "--constraints", "container=lxc")
 	c.Assert(err, ErrorMatches, `container constraint "lxc" not allowed when adding a machine`)
 }
+
+func (s *AddMachineSuite) TestInitSSH(c *C) {
+	addCmd := &AddMachineCommand{}
+	err := testing.InitCommand(addCmd, []string{"ssh:ubuntu@10.0.0.1"})
+	c.Assert(err, IsNil)
+	c.Assert(addCmd.SSHHost, Equals, "ubuntu@10.0.0.1")
+	c.Assert(addCmd.ContainerType, Equals, instance.ContainerType(""))
+
+	err = testing.InitCommand(&AddMachineCommand{}, []string{"ssh:"})
+	c.Assert(err, ErrorMatches, `missing host in "ssh:"`)
+	err = testing.InitCommand(&AddMachineCommand{}, []string{"--series", "precise", "ssh:10.0.0.1"})
+	c.Assert(err, ErrorMatches, `cannot specify series when adding an existing machine`)
+}
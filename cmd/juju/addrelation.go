@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/statecmd"
 )
@@ -39,6 +40,9 @@ func (c *AddRelationCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	params := params.AddRelation{
 		Endpoints: c.Endpoints,
 	}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"launchpad.net/gnuflag"
+	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/state"
@@ -15,15 +16,22 @@ import (
 )
 
 // UnitCommandBase provides support for commands which deploy units. It handles the parsing
-// and validation of --to and --num-units arguments.
+// and validation of --to and --num-units arguments, and the reporting of --dry-run results.
 type UnitCommandBase struct {
 	ToMachineSpec string
 	NumUnits      int
+	DryRun        bool
+	out           cmd.Output
 }
 
 func (c *UnitCommandBase) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumUnits, "num-units", 1, "")
 	f.StringVar(&c.ToMachineSpec, "to", "", "the machine or container to deploy the unit in, bypasses constraints")
+	f.BoolVar(&c.DryRun, "dry-run", false, "show where units would be placed, without changing anything")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
 }
 
 func (c *UnitCommandBase) Init(args []string) error {
@@ -41,6 +49,39 @@ func (c *UnitCommandBase) Init(args []string) error {
 	return nil
 }
 
+// dryRunResult holds what a command run with --dry-run would do.
+type dryRunResult struct {
+	Charm         string   `yaml:"charm" json:"charm"`
+	Service       string   `yaml:"service" json:"service"`
+	Units         int      `yaml:"units" json:"units"`
+	Constraints   string   `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+	Machines      []string `yaml:"machines,omitempty" json:"machines,omitempty"`
+	Hosts         []string `yaml:"container-hosts,omitempty" json:"container-hosts,omitempty"`
+	NewMachines   int      `yaml:"new-machines,omitempty" json:"new-machines,omitempty"`
+	ContainerType string   `yaml:"container-type,omitempty" json:"container-type,omitempty"`
+	InstanceType  string   `yaml:"instance-type,omitempty" json:"instance-type,omitempty"`
+}
+
+// writeDryRun writes out the charm and placement of the units that
+// would be added to the service. The placement is nil when no units
+// would be added.
+func (c *UnitCommandBase) writeDryRun(ctx *cmd.Context, curl *charm.URL, serviceName string, numUnits int, p *juju.UnitPlacement) error {
+	result := dryRunResult{
+		Charm:   curl.String(),
+		Service: serviceName,
+		Units:   numUnits,
+	}
+	if p != nil {
+		result.Constraints = p.Constraints.String()
+		result.Machines = p.Machines
+		result.Hosts = p.Hosts
+		result.NewMachines = p.NewMachines
+		result.ContainerType = string(p.ContainerType)
+		result.InstanceType = p.InstanceType
+	}
+	return c.out.Write(ctx, result)
+}
+
 // AddUnitCommand is responsible adding additional units to a service.
 type AddUnitCommand struct {
 	EnvCommandBase
@@ -54,6 +95,10 @@ Examples:
  juju add-unit mysql --to 23       (Add unit to machine 23)
  juju add-unit mysql --to 24/lxc/3 (Add unit to lxc container 3 on host machine 24)
  juju add-unit mysql --to lxc:25   (Add unit to a new lxc container on host machine 25)
+
+With --dry-run, nothing is changed; the constraints of the new units,
+the existing machines they would be placed on, and the number and
+instance type of the new machines that would be started are shown.
 `
 
 func (c *AddUnitCommand) Info() *cmd.Info {
@@ -86,12 +131,18 @@ func (c *AddUnitCommand) Init(args []string) error {
 
 // Run connects to the environment specified on the command line
 // and calls conn.AddUnits.
-func (c *AddUnitCommand) Run(_ *cmd.Context) error {
+func (c *AddUnitCommand) Run(ctx *cmd.Context) error {
 	conn, err := juju.NewConnFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
+	if c.DryRun {
+		return c.dryRun(ctx, conn)
+	}
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 
 	params := params.AddServiceUnits{
 		ServiceName:   c.ServiceName,
@@ -101,3 +152,24 @@ func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 	_, err = statecmd.AddServiceUnits(conn.State, params)
 	return err
 }
+
+// dryRun shows where the units would be placed.
+func (c *AddUnitCommand) dryRun(ctx *cmd.Context, conn *juju.Conn) error {
+	service, err := conn.State.Service(c.ServiceName)
+	if err != nil {
+		return err
+	}
+	if !service.IsPrincipal() {
+		return fmt.Errorf("cannot add units to subordinate service %q", c.ServiceName)
+	}
+	scons, err := service.Constraints()
+	if err != nil {
+		return err
+	}
+	curl, _ := service.CharmURL()
+	p, err := conn.PlanUnits(curl.Series, scons, c.NumUnits, c.ToMachineSpec)
+	if err != nil {
+		return err
+	}
+	return c.writeDryRun(ctx, curl, c.ServiceName, c.NumUnits, p)
+}
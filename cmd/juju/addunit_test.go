@@ -130,3 +130,30 @@ func (s *AddUnitSuite) TestForceMachineNewContainer(c *C) {
 	s.assertForceMachine(c, svc, 3, 1, machine.Id()+"/lxc/0")
 	s.assertForceMachine(c, svc, 3, 2, machine.Id())
 }
+
+func (s *AddUnitSuite) TestDryRun(c *C) {
+	curl := s.setupService(c)
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &AddUnitCommand{}, []string{"some-service-name", "-n", "2", "--dry-run"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, ""+
+		"charm: local:precise/dummy-1\n"+
+		"service: some-service-name\n"+
+		"units: 2\n"+
+		"machines:\n"+
+		"- \""+machine.Id()+"\"\n"+
+		"new-machines: 1\n")
+	s.AssertService(c, "some-service-name", curl, 1, 0)
+	err = machine.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(machine.Clean(), Equals, true)
+
+	ctx, err = testing.RunCommand(c, &AddUnitCommand{}, []string{"some-service-name", "--to", "lxc:" + machine.Id(), "--dry-run", "--format", "json"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `{"charm":"local:precise/dummy-1","service":"some-service-name","units":1,"container-hosts":["`+machine.Id()+`"],"container-type":"lxc"}`+"\n")
+	containers, err := machine.Containers()
+	c.Assert(err, IsNil)
+	c.Assert(containers, HasLen, 0)
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/utils"
+)
+
+// AddUserCommand adds a user to the environment.
+type AddUserCommand struct {
+	EnvCommandBase
+	User     string
+	Password string
+	Role     string
+}
+
+const addUserDoc = `
+Add a user to the environment. Users with the "admin" role may make any
+change to the environment; users with the "read-only" role may only
+inspect it.
+
+If no password is given, a random one is generated and printed.
+`
+
+func (c *AddUserCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add-user",
+		Args:    "<username> [<password>]",
+		Purpose: "add a user to the environment",
+		Doc:     addUserDoc,
+	}
+}
+
+func (c *AddUserCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Role, "role", string(state.ReadOnlyRole), `the user's role, either "admin" or "read-only"`)
+}
+
+func (c *AddUserCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("no username specified")
+	case 1:
+		c.User = args[0]
+	default:
+		c.User, c.Password = args[0], args[1]
+		if err := cmd.CheckEmpty(args[2:]); err != nil {
+			return err
+		}
+	}
+	if !state.UserRole(c.Role).IsValid() {
+		return fmt.Errorf(`invalid role %q; must be "admin" or "read-only"`, c.Role)
+	}
+	return nil
+}
+
+func (c *AddUserCommand) Run(ctx *cmd.Context) error {
+	password := c.Password
+	if password == "" {
+		var err error
+		if password, err = utils.RandomPassword(); err != nil {
+			return err
+		}
+	}
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.State.Client().AddUser(c.User, password, c.Role); err != nil {
+		return err
+	}
+	if c.Password == "" {
+		fmt.Fprintf(ctx.Stdout, "password: %s\n", password)
+	}
+	return nil
+}
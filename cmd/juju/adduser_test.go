@@ -0,0 +1,84 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type AddUserSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&AddUserSuite{})
+
+func (s *AddUserSuite) TestInit(c *C) {
+	for i, test := range []struct {
+		args     []string
+		user     string
+		password string
+		role     string
+		err      string
+	}{{
+		err: "no username specified",
+	}, {
+		args: []string{"foo"},
+		user: "foo",
+		role: "read-only",
+	}, {
+		args:     []string{"--role", "admin", "foo", "secret"},
+		user:     "foo",
+		password: "secret",
+		role:     "admin",
+	}, {
+		args: []string{"--role", "superuser", "foo"},
+		err:  `invalid role "superuser"; must be "admin" or "read-only"`,
+	}, {
+		args: []string{"foo", "secret", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		addUserCmd := &AddUserCommand{}
+		err := testing.InitCommand(addUserCmd, test.args)
+		if test.err != "" {
+			c.Check(err, ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(addUserCmd.User, Equals, test.user)
+		c.Check(addUserCmd.Password, Equals, test.password)
+		c.Check(addUserCmd.Role, Equals, test.role)
+	}
+}
+
+func (s *AddUserSuite) TestAddUser(c *C) {
+	ctx, err := testing.RunCommand(c, &AddUserCommand{}, []string{"--role", "admin", "foo", "secret"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, "")
+	u, err := s.State.User("foo")
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.AdminRole)
+	c.Assert(u.PasswordValid("secret"), Equals, true)
+
+	_, err = testing.RunCommand(c, &AddUserCommand{}, []string{"foo", "secret"})
+	c.Assert(err, ErrorMatches, "user already exists")
+}
+
+func (s *AddUserSuite) TestAddUserRandomPassword(c *C) {
+	ctx, err := testing.RunCommand(c, &AddUserCommand{}, []string{"foo"})
+	c.Assert(err, IsNil)
+	out := testing.Stdout(ctx)
+	c.Assert(out, Matches, "password: .+\n")
+	u, err := s.State.User("foo")
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.ReadOnlyRole)
+	password := strings.TrimSpace(strings.TrimPrefix(out, "password: "))
+	c.Assert(u.PasswordValid(password), Equals, true)
+}
@@ -0,0 +1,131 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/backup"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/utils"
+)
+
+// BackupCommand makes a backup of the state server.
+type BackupCommand struct {
+	SSHCommon
+	Filename string
+}
+
+const backupDoc = `
+Back up the state of the environment to a file that may later be given
+to "juju restore".
+
+The backup holds a dump of the state database, the state server's
+configuration and the environment's CA certificate and key. The state
+server's agent and database are stopped while the backup is made, so
+the environment cannot be changed during that time.
+
+The files in the environment's provider storage (tools and charms) are
+not copied, but a list of them is recorded so that restore can warn
+about any that have since gone missing.
+`
+
+func (c *BackupCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "backup",
+		Purpose: "back up the state server",
+		Doc:     backupDoc,
+	}
+}
+
+func (c *BackupCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Filename, "o", "", "write the backup to the named file")
+	f.StringVar(&c.Filename, "output", "", "")
+}
+
+func (c *BackupCommand) Init(args []string) error {
+	if c.Filename == "" {
+		c.Filename = time.Now().UTC().Format("juju-backup-20060102-150405.tgz")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupCommand) Run(ctx *cmd.Context) (err error) {
+	c.Conn, err = juju.NewConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	host, err := c.machinePublicAddress("0")
+	if err != nil {
+		return err
+	}
+	// The archive holds the environment's secrets, such as the CA
+	// private key, so only the user may read it.
+	filename := ctx.AbsPath(c.Filename)
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	// An existing file keeps its mode when truncated.
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(filename)
+		}
+	}()
+
+	log.Infof("backing up state server on %s...", host)
+	ssh := sshCommand(host, backup.BackupScript(environs.DataDir))
+	ssh.Stderr = ctx.Stderr
+	stdout, err := ssh.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := ssh.Start(); err != nil {
+		return err
+	}
+	writeErr := backup.WriteArchive(f, stdout, c.Environ.Config(), c.Environ.Storage())
+	if writeErr != nil {
+		// Drain the remote output so that ssh is not left blocked.
+		io.Copy(ioutil.Discard, stdout)
+	}
+	if err := ssh.Wait(); err != nil {
+		return fmt.Errorf("cannot back up state server: %v", err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	fmt.Fprintf(ctx.Stdout, "backup written to %s\n", c.Filename)
+	return nil
+}
+
+// sshCommand returns a command that runs the given script as root
+// on the given host.
+func sshCommand(host, script string) *exec.Cmd {
+	return exec.Command("ssh",
+		"-l", "ubuntu",
+		"-o", "StrictHostKeyChecking no",
+		"-o", "PasswordAuthentication no",
+		host,
+		"sudo bash -c "+utils.ShQuote(script),
+	)
+}
@@ -0,0 +1,172 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cert"
+	"launchpad.net/juju-core/environs/backup"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+// stateServerFiles holds the names of the files in the archive
+// written on the state server by the backup script.
+var stateServerFiles = []string{
+	"juju-backup/dump/juju/machines.bson",
+	"juju-backup/agents/machine-0/agent.conf",
+	"juju-backup/server.pem",
+}
+
+type BackupSuite struct {
+	SSHCommonSuite
+}
+
+var _ = Suite(&BackupSuite{})
+
+// fakeBackupSSH replaces ssh with a command that writes a tar archive
+// of the state server's files, as the backup script would.
+func (s *BackupSuite) fakeBackupSSH(c *C) {
+	dir := c.MkDir()
+	tarFile := filepath.Join(dir, "remote.tar")
+	f, err := os.Create(tarFile)
+	c.Assert(err, IsNil)
+	tw := tar.NewWriter(f)
+	for _, name := range stateServerFiles {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))})
+		c.Assert(err, IsNil)
+		_, err = tw.Write([]byte(name))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	script := fmt.Sprintf("#!/bin/bash\ncat %s\n", tarFile)
+	err = ioutil.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0777)
+	c.Assert(err, IsNil)
+	os.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+}
+
+func (s *BackupSuite) TestBackup(c *C) {
+	s.makeMachines(1, c)
+	s.fakeBackupSSH(c)
+
+	ctx := coretesting.Context(c)
+	_, err := coretesting.RunCommandInDir(c, &BackupCommand{}, []string{"-o", "test.tgz"}, ctx.Dir)
+	c.Assert(err, IsNil)
+
+	f, err := os.Open(filepath.Join(ctx.Dir, "test.tgz"))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	archive, err := backup.ReadArchive(f)
+	c.Assert(err, IsNil)
+	caCert, _ := s.Conn.Environ.Config().CACert()
+	c.Assert(archive.CACert, DeepEquals, caCert)
+	c.Assert(archive.Manifest, Not(HasLen), 0)
+}
+
+func (s *BackupSuite) TestBackupFileMode(c *C) {
+	s.makeMachines(1, c)
+	s.fakeBackupSSH(c)
+
+	// The archive is readable only by the user, even if the file
+	// already exists.
+	ctx := coretesting.Context(c)
+	filename := filepath.Join(ctx.Dir, "test.tgz")
+	err := ioutil.WriteFile(filename, nil, 0644)
+	c.Assert(err, IsNil)
+	_, err = coretesting.RunCommandInDir(c, &BackupCommand{}, []string{"-o", "test.tgz"}, ctx.Dir)
+	c.Assert(err, IsNil)
+	info, err := os.Stat(filename)
+	c.Assert(err, IsNil)
+	c.Assert(info.Mode().Perm(), Equals, os.FileMode(0600))
+}
+
+func (s *BackupSuite) TestBackupFailure(c *C) {
+	s.makeMachines(1, c)
+	// The ssh from SSHCommonSuite does not write an archive.
+	ctx := coretesting.Context(c)
+	_, err := coretesting.RunCommandInDir(c, &BackupCommand{}, []string{"-o", "test.tgz"}, ctx.Dir)
+	c.Assert(err, ErrorMatches, "cannot write backup archive: cannot read state server backup: .*")
+	_, err = os.Stat(filepath.Join(ctx.Dir, "test.tgz"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *BackupSuite) TestBackupInit(c *C) {
+	backupCmd := &BackupCommand{}
+	err := coretesting.InitCommand(backupCmd, nil)
+	c.Assert(err, IsNil)
+	c.Assert(backupCmd.Filename, Matches, `juju-backup-\d{8}-\d{6}\.tgz`)
+
+	err = coretesting.InitCommand(&BackupCommand{}, []string{"foo"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+type RestoreSuite struct {
+	SSHCommonSuite
+}
+
+var _ = Suite(&RestoreSuite{})
+
+func (s *RestoreSuite) TestRestoreInit(c *C) {
+	err := coretesting.InitCommand(&RestoreCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no backup file specified")
+
+	err = coretesting.InitCommand(&RestoreCommand{}, []string{"foo.tgz", "bar"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["bar"\]`)
+
+	restoreCmd := &RestoreCommand{}
+	err = coretesting.InitCommand(restoreCmd, []string{"--constraints", "mem=4G", "foo.tgz"})
+	c.Assert(err, IsNil)
+	c.Assert(restoreCmd.Filename, Equals, "foo.tgz")
+	c.Assert(restoreCmd.Constraints.String(), Equals, "mem=4096M")
+}
+
+// writeBackup writes a backup archive of the environment, with the
+// given CA certificate, to a file and returns its name.
+func (s *RestoreSuite) writeBackup(c *C, caCert, caKey []byte) string {
+	cfg, err := s.Conn.Environ.Config().Apply(map[string]interface{}{
+		"ca-cert":        string(caCert),
+		"ca-private-key": string(caKey),
+	})
+	c.Assert(err, IsNil)
+	var remote bytes.Buffer
+	tw := tar.NewWriter(&remote)
+	for _, name := range stateServerFiles {
+		c.Assert(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644}), IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	filename := filepath.Join(c.MkDir(), "backup.tgz")
+	f, err := os.Create(filename)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	err = backup.WriteArchive(f, &remote, cfg, s.Conn.Environ.Storage())
+	c.Assert(err, IsNil)
+	return filename
+}
+
+func (s *RestoreSuite) TestRestoreDifferentEnvironment(c *C) {
+	caCert, caKey, err := cert.NewCA("other", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, IsNil)
+	filename := s.writeBackup(c, caCert, caKey)
+	_, err = coretesting.RunCommand(c, &RestoreCommand{}, []string{filename})
+	c.Assert(err, ErrorMatches, `backup archive was made from a different environment \(CA certificates differ\)`)
+}
+
+func (s *RestoreSuite) TestRestoreStateServerRunning(c *C) {
+	cfg := s.Conn.Environ.Config()
+	caCert, _ := cfg.CACert()
+	caKey, _ := cfg.CAPrivateKey()
+	filename := s.writeBackup(c, caCert, caKey)
+	_, err := coretesting.RunCommand(c, &RestoreCommand{}, []string{filename})
+	c.Assert(err, ErrorMatches, "the old state server is still running; destroy it before restoring")
+}
@@ -0,0 +1,127 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// BlockCommand switches on a block that protects the environment
+// against accidental changes, or lists the blocks that are on.
+type BlockCommand struct {
+	EnvCommandBase
+	Type    string
+	Message string
+	out     cmd.Output
+}
+
+const blockDoc = `
+Switch on a block that protects the environment against accidents.
+The block types are:
+
+ destroy-environment  prevent the environment from being destroyed
+ remove-object        also prevent machines, services, units and
+                      relations from being removed
+ all-changes          prevent all changes to the environment
+
+The message, if given, is shown when an operation is blocked. Without
+arguments, the blocks that are on are listed. Blocks are switched off
+with "juju unblock".
+
+Examples:
+ juju block destroy-environment
+ juju block all-changes "production freeze until Monday"
+`
+
+func (c *BlockCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "block",
+		Args:    "[<type> [<message>]]",
+		Purpose: "protect the environment against accidental changes",
+		Doc:     blockDoc,
+	}
+}
+
+func (c *BlockCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *BlockCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if _, err := state.ParseBlockType(args[0]); err != nil {
+		return err
+	}
+	c.Type = args[0]
+	c.Message = strings.Join(args[1:], " ")
+	return nil
+}
+
+func (c *BlockCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := conn.State.Client()
+	if c.Type != "" {
+		return client.SwitchBlockOn(c.Type, c.Message)
+	}
+	blocks, err := client.ListBlocks()
+	if err != nil {
+		return err
+	}
+	result := make(map[string]string)
+	for _, block := range blocks {
+		result[block.Type] = block.Message
+	}
+	return c.out.Write(ctx, result)
+}
+
+// UnblockCommand switches off a block.
+type UnblockCommand struct {
+	EnvCommandBase
+	Type string
+}
+
+func (c *UnblockCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "unblock",
+		Args:    "<type>",
+		Purpose: "switch off a block switched on with juju block",
+		Doc:     `The block types are destroy-environment, remove-object and all-changes.`,
+	}
+}
+
+func (c *UnblockCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no block type specified")
+	}
+	if _, err := state.ParseBlockType(args[0]); err != nil {
+		return err
+	}
+	c.Type = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *UnblockCommand) Run(_ *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().SwitchBlockOff(c.Type)
+}
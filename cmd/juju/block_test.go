@@ -0,0 +1,97 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type BlockSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&BlockSuite{})
+
+var blockInitTests = []struct {
+	args      []string
+	blockType string
+	message   string
+	err       string
+}{{
+	args: nil,
+}, {
+	args:      []string{"destroy-environment"},
+	blockType: "destroy-environment",
+}, {
+	args:      []string{"all-changes", "production", "freeze"},
+	blockType: "all-changes",
+	message:   "production freeze",
+}, {
+	args: []string{"everything"},
+	err:  `unknown block type "everything"`,
+}}
+
+func (s *BlockSuite) TestBlockInit(c *C) {
+	for i, t := range blockInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		blockCmd := &BlockCommand{}
+		err := testing.InitCommand(blockCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(blockCmd.Type, Equals, t.blockType)
+		c.Check(blockCmd.Message, Equals, t.message)
+	}
+}
+
+func (s *BlockSuite) TestUnblockInit(c *C) {
+	err := testing.InitCommand(&UnblockCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no block type specified")
+	err = testing.InitCommand(&UnblockCommand{}, []string{"everything"})
+	c.Assert(err, ErrorMatches, `unknown block type "everything"`)
+	err = testing.InitCommand(&UnblockCommand{}, []string{"all-changes", "now"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["now"\]`)
+}
+
+func (s *BlockSuite) TestBlockUnblock(c *C) {
+	_, err := testing.RunCommand(c, &BlockCommand{}, []string{"remove-object", "careful"})
+	c.Assert(err, IsNil)
+	ctx, err := testing.RunCommand(c, &BlockCommand{}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, "remove-object: careful\n")
+
+	_, err = testing.RunCommand(c, &UnblockCommand{}, []string{"remove-object"})
+	c.Assert(err, IsNil)
+	blocks, err := s.State.AllBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, HasLen, 0)
+}
+
+func (s *BlockSuite) TestBlockedCommands(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy")
+	c.Assert(err, IsNil)
+
+	err = s.State.SwitchBlockOn(state.RemoveBlock, "careful")
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &DestroyServiceCommand{}, []string{"dummy"})
+	c.Assert(err, ErrorMatches, "operation blocked by the remove-object block: careful")
+	_, err = testing.RunCommand(c, &DestroyEnvironmentCommand{}, nil)
+	c.Assert(err, ErrorMatches, "operation blocked by the remove-object block: careful")
+	_, err = testing.RunCommand(c, &AddUnitCommand{}, []string{"dummy"})
+	c.Assert(err, IsNil)
+
+	err = s.State.SwitchBlockOn(state.ChangeBlock, "")
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &AddUnitCommand{}, []string{"dummy"})
+	c.Assert(err, ErrorMatches, "operation blocked by the all-changes block")
+	_, err = testing.RunCommand(c, &DestroyEnvironmentCommand{}, nil)
+	c.Assert(err, ErrorMatches, "operation blocked by the all-changes block")
+}
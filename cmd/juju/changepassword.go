@@ -0,0 +1,61 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// ChangePasswordCommand changes the password of a user.
+type ChangePasswordCommand struct {
+	EnvCommandBase
+	User     string
+	Password string
+}
+
+const changePasswordDoc = `
+Change the password of a user. Admin users may change the password of
+any user; read-only users may change only their own.
+
+The juju client connects to the environment as the "admin" user, using
+the admin-secret in environments.yaml. If the admin user's password is
+changed, admin-secret must be changed to match.
+`
+
+func (c *ChangePasswordCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "change-password",
+		Args:    "<password>",
+		Purpose: "change the password of a user",
+		Doc:     changePasswordDoc,
+	}
+}
+
+func (c *ChangePasswordCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.User, "user", state.AdminUser, "the user whose password is changed")
+}
+
+func (c *ChangePasswordCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no password specified")
+	}
+	c.Password = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ChangePasswordCommand) Run(_ *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().SetUserPassword(c.User, c.Password)
+}
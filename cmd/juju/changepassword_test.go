@@ -0,0 +1,45 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type ChangePasswordSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ChangePasswordSuite{})
+
+func (s *ChangePasswordSuite) TestChangePassword(c *C) {
+	_, err := s.State.AddUserWithRole("foo", "secret", state.ReadOnlyRole)
+	c.Assert(err, IsNil)
+
+	_, err = testing.RunCommand(c, &ChangePasswordCommand{}, []string{"--user", "foo", "new secret"})
+	c.Assert(err, IsNil)
+	u, err := s.State.User("foo")
+	c.Assert(err, IsNil)
+	c.Assert(u.PasswordValid("new secret"), Equals, true)
+
+	_, err = testing.RunCommand(c, &ChangePasswordCommand{}, []string{"--user", "nosuch", "secret"})
+	c.Assert(err, ErrorMatches, `user "nosuch" not found`)
+}
+
+func (s *ChangePasswordSuite) TestInit(c *C) {
+	changeCmd := &ChangePasswordCommand{}
+	err := testing.InitCommand(changeCmd, []string{"secret"})
+	c.Assert(err, IsNil)
+	c.Assert(changeCmd.User, Equals, "admin")
+	c.Assert(changeCmd.Password, Equals, "secret")
+
+	err = testing.InitCommand(&ChangePasswordCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no password specified")
+	err = testing.InitCommand(&ChangePasswordCommand{}, []string{"a", "b"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["b"\]`)
+}
@@ -161,3 +161,62 @@ func setupConfigfile(c *C, dir string) string {
 	c.Assert(err, IsNil)
 	return path
 }
+
+func (s *ConfigSuite) TestSetInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--revert", "1", "dummy-service", "username=hello"},
+		err:  "cannot specify --revert with --config or key=value arguments",
+	}, {
+		args: []string{"--revert", "1", "--config", "testconfig.yaml", "dummy-service"},
+		err:  "cannot specify --revert with --config or key=value arguments",
+	}, {
+		args: []string{"--revert", "-1", "dummy-service"},
+		err:  "invalid revision -1",
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		err := coretesting.InitCommand(&SetCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *ConfigSuite) TestConfigHistoryAndRevert(c *C) {
+	sch := s.AddTestingCharm(c, "dummy")
+	svc, err := s.State.AddService("dummy-service", sch)
+	c.Assert(err, IsNil)
+	for _, args := range [][]string{
+		{"dummy-service", "username=hello", "outlook=good"},
+		{"dummy-service", "username=bad", "outlook="},
+	} {
+		_, err := coretesting.RunCommand(c, &SetCommand{}, args)
+		c.Assert(err, IsNil)
+	}
+
+	ctx, err := coretesting.RunCommand(c, &GetCommand{}, []string{"--history", "dummy-service"})
+	c.Assert(err, IsNil)
+	var history []map[string]interface{}
+	err = goyaml.Unmarshal(ctx.Stdout.(*bytes.Buffer).Bytes(), &history)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	for i, change := range history {
+		c.Assert(change["time"], Not(Equals), "")
+		c.Assert(change["revision"], Equals, i+1)
+		c.Assert(change["user"], Equals, "user-admin")
+		c.Assert(change["charm"], Equals, "local:series/dummy-1")
+	}
+	c.Assert(history[1]["changes"], DeepEquals, map[interface{}]interface{}{
+		"username": map[interface{}]interface{}{"old": "hello", "new": "bad"},
+		"outlook":  map[interface{}]interface{}{"old": "good"},
+	})
+
+	_, err = coretesting.RunCommand(c, &SetCommand{}, []string{"--revert", "1", "dummy-service"})
+	c.Assert(err, IsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"username": "hello", "outlook": "good"})
+
+	_, err = coretesting.RunCommand(c, &SetCommand{}, []string{"--revert", "99", "dummy-service"})
+	c.Assert(err, ErrorMatches, `config revision 99 of service "dummy-service" not found`)
+}
@@ -111,6 +111,9 @@ func (c *SetConstraintsCommand) Run(_ *cmd.Context) (err error) {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	if c.ServiceName == "" {
 		return conn.State.SetEnvironConstraints(c.Constraints)
 	}
@@ -0,0 +1,101 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/worker/uniter/debug"
+)
+
+// DebugHooksCommand is responsible for launching a ssh shell on a given unit,
+// in which the unit's hooks are run by the operator as they fire.
+type DebugHooksCommand struct {
+	SSHCommand
+	hooks []string
+}
+
+const debugHooksDoc = `
+Interactively debug a hook remotely on a service unit.
+
+A tmux session is started on the unit's machine. When one of the named
+hooks, or any hook if none are named, is about to run, a new window is
+opened in the session instead, with the hook's environment set, and the
+unit agent waits for the window's shell to exit. Run the hook with
+"./hooks/$JUJU_HOOK_NAME", or investigate as required; exiting the shell
+with a non-zero status marks the hook as failed, so that it may be
+retried with "juju resolved --retry".
+`
+
+func (c *DebugHooksCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "debug-hooks",
+		Args:    "<unit name> [hook names]",
+		Purpose: "launch a tmux session to debug a hook",
+		Doc:     debugHooksDoc,
+	}
+}
+
+func (c *DebugHooksCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit name specified")
+	}
+	c.Target = args[0]
+	if !state.IsUnitName(c.Target) {
+		return fmt.Errorf("%q is not a valid unit name", c.Target)
+	}
+	c.hooks = args[1:]
+	return nil
+}
+
+// validateHooks returns an error if any of the hooks to be debugged
+// is not a valid hook for the unit's charm.
+func (c *DebugHooksCommand) validateHooks() error {
+	if len(c.hooks) == 0 {
+		return nil
+	}
+	conn, err := juju.NewConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	unit, err := conn.State.Unit(c.Target)
+	if err != nil {
+		return err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return err
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return err
+	}
+	validHooks := ch.Meta().Hooks()
+	for _, hook := range c.hooks {
+		if !validHooks[hook] {
+			return fmt.Errorf("unit %q does not contain hook %q", c.Target, hook)
+		}
+	}
+	return nil
+}
+
+// Run ensures c.Target is a unit, and resolves its address,
+// and connects to it via SSH to execute the debug-hooks
+// script.
+func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
+	if err := c.validateHooks(); err != nil {
+		return err
+	}
+	debugctx := debug.NewHooksContext(c.Target)
+	script := base64.StdEncoding.EncodeToString([]byte(debug.ClientScript(debugctx, c.hooks)))
+	innercmd := fmt.Sprintf(`F=$(mktemp); echo %s | base64 -d > $F; . $F`, script)
+	c.Args = []string{fmt.Sprintf("sudo /bin/bash -c '%s'", innercmd)}
+	return c.SSHCommand.Run(ctx)
+}
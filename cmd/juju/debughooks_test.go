@@ -0,0 +1,80 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/cmd"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/debug"
+)
+
+var _ = Suite(&DebugHooksSuite{})
+
+type DebugHooksSuite struct {
+	SSHCommonSuite
+}
+
+func debugHooksArgs(unit string, hooks ...string) string {
+	script := debug.ClientScript(debug.NewHooksContext(unit), hooks)
+	innercmd := fmt.Sprintf(`F=$(mktemp); echo %s | base64 -d > $F; . $F`,
+		base64.StdEncoding.EncodeToString([]byte(script)))
+	return fmt.Sprintf("%sdummyenv-0.dns sudo /bin/bash -c '%s'\n", sshArgs, innercmd)
+}
+
+var debugHooksTests = []struct {
+	args   []string
+	code   int
+	result string
+	stderr string
+}{{
+	args:   []string{"mysql/0"},
+	result: debugHooksArgs("mysql/0"),
+}, {
+	args:   []string{"mysql/0", "install", "config-changed"},
+	result: debugHooksArgs("mysql/0", "install", "config-changed"),
+}, {
+	args:   []string{"mysql/0", "db-relation-changed"},
+	code:   1,
+	stderr: `error: unit "mysql/0" does not contain hook "db-relation-changed"` + "\n",
+}, {
+	args:   []string{"mysql"},
+	code:   2,
+	stderr: `error: "mysql" is not a valid unit name` + "\n",
+}, {
+	args:   []string{},
+	code:   2,
+	stderr: "error: no unit name specified\n",
+}}
+
+func (s *DebugHooksSuite) TestDebugHooksCommand(c *C) {
+	m := s.makeMachines(1, c)
+	ch := coretesting.Charms.Dir("dummy")
+	curl := charm.MustParseURL(
+		fmt.Sprintf("local:series/%s-%d", ch.Meta().Name, ch.Revision()),
+	)
+	bundleURL, err := url.Parse("http://bundles.testing.invalid/dummy-1")
+	c.Assert(err, IsNil)
+	dummy, err := s.State.AddCharm(ch, curl, bundleURL, "dummy-1-sha256")
+	c.Assert(err, IsNil)
+	srv, err := s.State.AddService("mysql", dummy)
+	c.Assert(err, IsNil)
+	s.addUnit(srv, m[0], c)
+
+	for i, t := range debugHooksTests {
+		c.Logf("test %d: juju debug-hooks %s", i, t.args)
+		ctx := coretesting.Context(c)
+		code := cmd.Main(&DebugHooksCommand{}, ctx, t.args)
+		c.Check(code, Equals, t.code)
+		c.Check(ctx.Stderr.(*bytes.Buffer).String(), Equals, t.stderr)
+		c.Check(ctx.Stdout.(*bytes.Buffer).String(), Equals, t.result)
+	}
+}
@@ -4,42 +4,115 @@
 package main
 
 import (
+	"fmt"
+
 	"launchpad.net/gnuflag"
+
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log/tailer"
+	"launchpad.net/juju-core/state/api/params"
 )
 
+// DebugLogCommand shows the consolidated log of the environment.
 type DebugLogCommand struct {
-	// The debug log command simply invokes juju ssh with the required arguments.
-	sshCmd cmd.Command
+	EnvCommandBase
+	Entities []string
+	Level    string
+	Modules  []string
+	Lines    int
+	Replay   bool
+	NoTail   bool
 }
 
 const debuglogDoc = `
-Launch an ssh shell on the state server machine and tail the consolidated log file.
-The consolidated log file contains log messages from all nodes in the environment.
+Show the consolidated log file, which contains log messages from all
+nodes in the environment, as read by the API server. By default the
+last 10 lines of the log are shown, and new lines are shown as they
+are written until the command is interrupted.
+
+The lines shown may be restricted to those logged by entities, given
+as machine ids, service or unit names, or tags; to those logged at a
+level or above; and to those logged by modules and their submodules.
+
+Examples:
+ juju debug-log --include unit-mysql-0 --level WARNING
+ juju debug-log --include 0,wordpress/1 --include-module juju.worker
+ juju debug-log --replay --no-tail     (Show the whole log and exit)
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "debug-log",
-		Args:    "[<ssh args>...]",
 		Purpose: "display the consolidated log file",
 		Doc:     debuglogDoc,
 	}
 }
 
 func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
-	c.sshCmd.SetFlags(f)
+	c.EnvCommandBase.SetFlags(f)
+	f.Var(newStringsValue(&c.Entities), "i", "only show log lines of these entities")
+	f.Var(newStringsValue(&c.Entities), "include", "")
+	f.StringVar(&c.Level, "l", "", "only show log lines at this level or above")
+	f.StringVar(&c.Level, "level", "", "")
+	f.Var(newStringsValue(&c.Modules), "include-module", "only show log lines of these modules")
+	f.IntVar(&c.Lines, "n", 10, "show this many lines of the existing log")
+	f.IntVar(&c.Lines, "lines", 10, "")
+	f.BoolVar(&c.Replay, "replay", false, "show all the existing log")
+	f.BoolVar(&c.NoTail, "no-tail", false, "exit once the existing log is shown")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
-	args = append([]string{"0"}, args...)
-	args = append(args, "tail -f /var/log/juju/all-machines.log")
-	return c.sshCmd.Init(args)
+	for i, entity := range c.Entities {
+		tag, err := entityTag(entity)
+		if err != nil {
+			return err
+		}
+		c.Entities[i] = tag
+	}
+	if c.Level != "" {
+		if _, ok := tailer.ParseLevel(c.Level); !ok {
+			return fmt.Errorf("invalid log level %q", c.Level)
+		}
+	}
+	if c.Lines < 0 {
+		return fmt.Errorf("invalid number of lines %d", c.Lines)
+	}
+	return cmd.CheckEmpty(args)
 }
 
-// Run uses "juju ssh" to log into the state server node
-// and tails the consolidated log file which captures log
-// messages from all nodes.
+// Run asks the API server for the lines of the consolidated log and
+// writes them to the standard output as they arrive.
 func (c *DebugLogCommand) Run(ctx *cmd.Context) error {
-	return c.sshCmd.Run(ctx)
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	args := params.DebugLog{
+		Entities: c.Entities,
+		Level:    c.Level,
+		Modules:  c.Modules,
+		Replay:   c.Lines,
+		Follow:   !c.NoTail,
+	}
+	if c.Replay {
+		args.Replay = -1
+	}
+	watcher, err := conn.State.Client().WatchDebugLog(args)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		lines, err := watcher.Next()
+		if params.ErrCode(err) == params.CodeStopped {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			fmt.Fprintln(ctx.Stdout, line)
+		}
+	}
 }
@@ -5,7 +5,7 @@ package main
 
 import (
 	. "launchpad.net/gocheck"
-	"launchpad.net/juju-core/cmd"
+
 	"launchpad.net/juju-core/testing"
 )
 
@@ -14,32 +14,66 @@ type DebugLogSuite struct {
 
 var _ = Suite(&DebugLogSuite{})
 
-func runDebugLog(c *C, args ...string) (*DebugLogCommand, error) {
-	cmd := &DebugLogCommand{
-		sshCmd: &dummySSHCommand{},
-	}
-	_, err := testing.RunCommand(c, cmd, args)
-	return cmd, err
-}
-
-type dummySSHCommand struct {
-	SSHCommand
-	runCalled bool
-}
-
-func (c *dummySSHCommand) Run(ctx *cmd.Context) error {
-	c.runCalled = true
-	return nil
-}
+var debugLogInitTests = []struct {
+	args     []string
+	entities []string
+	level    string
+	modules  []string
+	lines    int
+	replay   bool
+	noTail   bool
+	err      string
+}{{
+	args:  nil,
+	lines: 10,
+}, {
+	args:     []string{"--include", "0,wordpress/1", "-i", "unit-mysql-0,mysql"},
+	entities: []string{"unit-mysql-0", "service-mysql"},
+	lines:    10,
+}, {
+	args:     []string{"-i", "1/lxc/0", "--level", "warning", "--include-module", "juju.worker,juju.cmd"},
+	entities: []string{"machine-1-lxc-0"},
+	level:    "warning",
+	modules:  []string{"juju.worker", "juju.cmd"},
+	lines:    10,
+}, {
+	args:   []string{"-n", "50", "--no-tail"},
+	lines:  50,
+	noTail: true,
+}, {
+	args:   []string{"--replay"},
+	lines:  10,
+	replay: true,
+}, {
+	args: []string{"--include", "Word press"},
+	err:  `invalid entity "Word press"`,
+}, {
+	args: []string{"-l", "loud"},
+	err:  `invalid log level "loud"`,
+}, {
+	args: []string{"--lines=-1"},
+	err:  `invalid number of lines -1`,
+}, {
+	args: []string{"tail -f"},
+	err:  `unrecognized args: \["tail -f"\]`,
+}}
 
-// debug-log is implemented by invoking juju ssh with the correct arguments.
-// This test checks for the expected invocation.
-func (s *DebugLogSuite) TestDebugLogInvokesSSHCommand(c *C) {
+func (s *DebugLogSuite) TestInit(c *C) {
 	defer testing.MakeEmptyFakeHome(c).Restore()
-	debugLogCmd, err := runDebugLog(c)
-	c.Assert(err, IsNil)
-	debugCmd := debugLogCmd.sshCmd.(*dummySSHCommand)
-	c.Assert(debugCmd.runCalled, Equals, true)
-	c.Assert(debugCmd.Target, Equals, "0")
-	c.Assert([]string{"tail -f /var/log/juju/all-machines.log"}, DeepEquals, debugCmd.Args)
+	for i, t := range debugLogInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		debugLogCmd := &DebugLogCommand{}
+		err := testing.InitCommand(debugLogCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(debugLogCmd.Entities, DeepEquals, t.entities)
+		c.Check(debugLogCmd.Level, Equals, t.level)
+		c.Check(debugLogCmd.Modules, DeepEquals, t.modules)
+		c.Check(debugLogCmd.Lines, Equals, t.lines)
+		c.Check(debugLogCmd.Replay, Equals, t.replay)
+		c.Check(debugLogCmd.NoTail, Equals, t.noTail)
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/constraints"
+	coreerrors "launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/state"
 	"os"
@@ -51,6 +52,11 @@ Examples:
  juju deploy mysql --to 23       (Deploy to machine 23)
  juju deploy mysql --to 24/lxc/3 (Deploy to lxc container 3 on host machine 24)
  juju deploy mysql --to lxc:25   (Deploy to a new lxc container on host machine 25)
+
+With --dry-run, nothing is changed; the charm URL the charm name
+resolves to, the constraints of the new units, the existing machines
+they would be placed on, and the number and instance type of the new
+machines that would be started are shown.
 `
 
 func (c *DeployCommand) Info() *cmd.Info {
@@ -112,6 +118,12 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.DryRun {
+		return c.dryRun(ctx, conn, curl, repo)
+	}
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	// TODO(fwereade) it's annoying to roundtrip the bytes through the client
 	// here, but it's the original behaviour and not convenient to change.
 	// PutCharm will always be required in some form for local charms; and we
@@ -121,17 +133,9 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
-	numUnits := c.NumUnits
-	if ch.Meta().Subordinate {
-		empty := constraints.Value{}
-		if c.Constraints != empty {
-			return errors.New("cannot use --constraints with subordinate service")
-		}
-		if numUnits == 1 && c.ToMachineSpec == "" {
-			numUnits = 0
-		} else {
-			return errors.New("cannot use --num-units or --to with subordinate service")
-		}
+	numUnits, err := c.unitCount(ch.Meta())
+	if err != nil {
+		return err
 	}
 	serviceName := c.ServiceName
 	if serviceName == "" {
@@ -158,3 +162,55 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	})
 	return err
 }
+
+// unitCount returns the number of units to deploy for a charm with the
+// given metadata.
+func (c *DeployCommand) unitCount(meta *charm.Meta) (int, error) {
+	if !meta.Subordinate {
+		return c.NumUnits, nil
+	}
+	empty := constraints.Value{}
+	if c.Constraints != empty {
+		return 0, errors.New("cannot use --constraints with subordinate service")
+	}
+	if c.NumUnits != 1 || c.ToMachineSpec != "" {
+		return 0, errors.New("cannot use --num-units or --to with subordinate service")
+	}
+	return 0, nil
+}
+
+// dryRun shows the charm that would be deployed and where its units
+// would be placed.
+func (c *DeployCommand) dryRun(ctx *cmd.Context, conn *juju.Conn, curl *charm.URL, repo charm.Repository) error {
+	if curl.Revision == -1 {
+		rev, err := repo.Latest(curl)
+		if err != nil {
+			return err
+		}
+		curl = curl.WithRevision(rev)
+	}
+	ch, err := repo.Get(curl)
+	if err != nil {
+		return err
+	}
+	numUnits, err := c.unitCount(ch.Meta())
+	if err != nil {
+		return err
+	}
+	serviceName := c.ServiceName
+	if serviceName == "" {
+		serviceName = ch.Meta().Name
+	}
+	if _, err := conn.State.Service(serviceName); err == nil {
+		return fmt.Errorf("service %q already exists", serviceName)
+	} else if !coreerrors.IsNotFoundError(err) {
+		return err
+	}
+	var p *juju.UnitPlacement
+	if numUnits > 0 {
+		if p, err = conn.PlanUnits(curl.Series, c.Constraints, numUnits, c.ToMachineSpec); err != nil {
+			return err
+		}
+	}
+	return c.writeDryRun(ctx, curl, serviceName, numUnits, p)
+}
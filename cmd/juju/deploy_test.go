@@ -115,6 +115,47 @@ func (s *DeploySuite) TestSubordinateCharm(c *C) {
 	s.AssertService(c, "logging", curl, 0, 0)
 }
 
+func (s *DeploySuite) TestDryRun(c *C) {
+	coretesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := s.State.SetEnvironConstraints(constraints.MustParse("mem=2G"))
+	c.Assert(err, IsNil)
+	ctx, err := coretesting.RunCommand(c, &DeployCommand{}, []string{
+		"local:dummy", "some-service-name", "-n", "2", "--constraints", "cpu-cores=2", "--dry-run",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(coretesting.Stdout(ctx), Equals, ""+
+		"charm: local:precise/dummy-1\n"+
+		"service: some-service-name\n"+
+		"units: 2\n"+
+		"constraints: cpu-cores=2 mem=2048M\n"+
+		"new-machines: 2\n")
+
+	// Nothing was changed.
+	curl := charm.MustParseURL("local:precise/dummy-1")
+	_, err = s.State.Charm(curl)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	_, err = s.State.Service("some-service-name")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	machines, err := s.State.AllMachines()
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 0)
+}
+
+func (s *DeploySuite) TestDryRunSubordinate(c *C) {
+	coretesting.Charms.BundlePath(s.SeriesPath, "logging")
+	ctx, err := coretesting.RunCommand(c, &DeployCommand{}, []string{"local:logging", "--dry-run"})
+	c.Assert(err, IsNil)
+	c.Assert(coretesting.Stdout(ctx), Equals, "charm: local:precise/logging-1\nservice: logging\nunits: 0\n")
+}
+
+func (s *DeploySuite) TestDryRunExistingService(c *C) {
+	coretesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy")
+	c.Assert(err, IsNil)
+	err = runDeploy(c, "local:dummy", "--dry-run")
+	c.Assert(err, ErrorMatches, `service "dummy" already exists`)
+}
+
 func (s *DeploySuite) TestConfig(c *C) {
 	coretesting.Charms.BundlePath(s.SeriesPath, "dummy")
 	path := setupConfigfile(c, c.MkDir())
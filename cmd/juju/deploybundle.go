@@ -0,0 +1,82 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// DeployBundleCommand deploys the services and relations in a bundle.
+type DeployBundleCommand struct {
+	EnvCommandBase
+	Filename string
+}
+
+const deployBundleDoc = `
+Deploy the services and relations described in a bundle file. The
+bundle is written in YAML; for example:
+
+    services:
+      wordpress:
+        charm: cs:precise/wordpress
+        num_units: 2
+        options:
+          blog-title: My Blog
+        constraints: mem=2G
+        expose: true
+      mysql:
+        charm: cs:precise/mysql-27
+        num_units: 1
+    relations:
+      - [wordpress, mysql]
+
+Charm URLs are interpreted as by "juju deploy", but only charms from the
+charm store may be used. If a charm URL has no revision, the latest
+revision is deployed.
+
+The whole bundle is checked before any change is made to the
+environment. Services and relations that already exist are left alone,
+as long as they have the charm, configuration, constraints and exposure
+given in the bundle; if they differ, nothing is deployed.
+`
+
+func (c *DeployBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "deploy-bundle",
+		Args:    "<bundle file>",
+		Purpose: "deploy the services and relations in a bundle",
+		Doc:     deployBundleDoc,
+	}
+}
+
+func (c *DeployBundleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no bundle file specified")
+	}
+	c.Filename = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *DeployBundleCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.Filename))
+	if err != nil {
+		return err
+	}
+	// Check the bundle locally first, to give early warning of
+	// simple mistakes.
+	if _, err := bundle.Parse(data); err != nil {
+		return err
+	}
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().DeployBundle(string(data))
+}
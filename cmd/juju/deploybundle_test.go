@@ -0,0 +1,88 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/apiserver/client"
+	"launchpad.net/juju-core/testing"
+)
+
+type DeployBundleSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&DeployBundleSuite{})
+
+func (s *DeployBundleSuite) TestInit(c *C) {
+	for i, test := range []struct {
+		args     []string
+		filename string
+		err      string
+	}{{
+		err: "no bundle file specified",
+	}, {
+		args:     []string{"bundle.yaml"},
+		filename: "bundle.yaml",
+	}, {
+		args: []string{"bundle.yaml", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		deployBundleCmd := &DeployBundleCommand{}
+		err := testing.InitCommand(deployBundleCmd, test.args)
+		if test.err != "" {
+			c.Check(err, ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(deployBundleCmd.Filename, Equals, test.filename)
+	}
+}
+
+func (s *DeployBundleSuite) TestDeployBundle(c *C) {
+	store := testing.NewMockCharmStore()
+	orig := client.CharmStore
+	client.CharmStore = store
+	defer func() { client.CharmStore = orig }()
+	bundle := testing.Charms.Bundle(c.MkDir(), "dummy")
+	err := store.SetCharm(charm.MustParseURL("cs:precise/dummy-1"), bundle)
+	c.Assert(err, IsNil)
+
+	dir := c.MkDir()
+	err = ioutil.WriteFile(filepath.Join(dir, "bundle.yaml"), []byte(`
+services:
+  dummy:
+    charm: dummy
+    num_units: 1
+    options:
+      title: Bundled
+`), 0644)
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommandInDir(c, &DeployBundleCommand{}, []string{"bundle.yaml"}, dir)
+	c.Assert(err, IsNil)
+
+	svc, err := s.State.Service("dummy")
+	c.Assert(err, IsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"title": "Bundled"})
+	units, err := svc.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 1)
+}
+
+func (s *DeployBundleSuite) TestDeployBundleInvalid(c *C) {
+	path := filepath.Join(c.MkDir(), "bundle.yaml")
+	err := ioutil.WriteFile(path, []byte("services: {}"), 0644)
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &DeployBundleCommand{}, []string{path})
+	c.Assert(err, ErrorMatches, "bundle has no services")
+}
@@ -6,6 +6,9 @@ package main
 import (
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
 )
 
 // DestroyEnvironmentCommand destroys an environment.
@@ -25,5 +28,16 @@ func (c *DestroyEnvironmentCommand) Run(_ *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	// An environment whose state cannot be reached may still need
+	// destroying, so blocks are only enforced when they can be read.
+	if conn, err := juju.NewConn(environ); err != nil {
+		log.Warningf("cannot check whether the environment may be destroyed: %v", err)
+	} else {
+		err := conn.State.CheckBlocked(state.DestroyBlock)
+		conn.Close()
+		if err != nil {
+			return err
+		}
+	}
 	return environ.Destroy(nil)
 }
@@ -45,5 +45,8 @@ func (c *DestroyMachineCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 	return conn.State.DestroyMachines(c.MachineIds...)
 }
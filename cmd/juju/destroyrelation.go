@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/statecmd"
 )
@@ -40,6 +41,9 @@ func (c *DestroyRelationCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 
 	params := params.DestroyRelation{
 		Endpoints: c.Endpoints,
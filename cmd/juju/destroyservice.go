@@ -44,6 +44,9 @@ func (c *DestroyServiceCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 
 	params := params.ServiceDestroy{
 		ServiceName: c.ServiceName,
@@ -49,6 +49,9 @@ func (c *DestroyUnitCommand) Run(_ *cmd.Context) (err error) {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 	params := params.DestroyServiceUnits{
 		UnitNames: c.UnitNames,
 	}
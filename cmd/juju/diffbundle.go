@@ -0,0 +1,100 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"launchpad.net/gnuflag"
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// DiffBundleCommand reports the differences between a bundle and the
+// environment.
+type DiffBundleCommand struct {
+	EnvCommandBase
+	Filename string
+	out      cmd.Output
+}
+
+const diffBundleDoc = `
+Compare a bundle file, in the format accepted by "juju deploy-bundle",
+with the services and relations in the environment, and report:
+
+ - services in the bundle that are missing from the environment, and
+   services in the environment that are not in the bundle;
+ - charm URLs that differ; a charm URL without a revision matches any
+   revision of the charm;
+ - configuration settings that differ, after applying the charm
+   defaults to settings left out of the bundle;
+ - constraints that differ;
+ - relations in the bundle that are missing from the environment, and
+   relations in the environment that are not in the bundle.
+
+Where a value differs, both the bundle's value and the environment's
+are reported. Nothing is reported if the environment matches the
+bundle. Settings of options that appear to hold secrets, such as
+passwords, are only compared for environment administrators.
+
+Examples:
+ juju diff-bundle stack.yaml
+ juju diff-bundle --format json stack.yaml
+`
+
+func (c *DiffBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "diff-bundle",
+		Args:    "<bundle file>",
+		Purpose: "compare a bundle with the services and relations in the environment",
+		Doc:     diffBundleDoc,
+	}
+}
+
+func (c *DiffBundleCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *DiffBundleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no bundle file specified")
+	}
+	c.Filename = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *DiffBundleCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.Filename))
+	if err != nil {
+		return err
+	}
+	if _, err := bundle.Parse(data); err != nil {
+		return err
+	}
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	result, err := conn.State.Client().DiffBundle(string(data))
+	if err != nil {
+		return err
+	}
+	var diff bundle.Diff
+	if err := goyaml.Unmarshal([]byte(result), &diff); err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+	return c.out.Write(ctx, diff)
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type DiffBundleSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&DiffBundleSuite{})
+
+func (s *DiffBundleSuite) TestInit(c *C) {
+	err := testing.InitCommand(&DiffBundleCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no bundle file specified")
+	err = testing.InitCommand(&DiffBundleCommand{}, []string{"stack.yaml", "extra"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["extra"\]`)
+	diffBundleCmd := &DiffBundleCommand{}
+	err = testing.InitCommand(diffBundleCmd, []string{"stack.yaml"})
+	c.Assert(err, IsNil)
+	c.Assert(diffBundleCmd.Filename, Equals, "stack.yaml")
+}
+
+func (s *DiffBundleSuite) runDiffBundle(c *C, yaml string, args ...string) (string, error) {
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "stack.yaml"), []byte(yaml), 0644)
+	c.Assert(err, IsNil)
+	ctx, err := testing.RunCommandInDir(c, &DiffBundleCommand{}, append(args, "stack.yaml"), dir)
+	if err != nil {
+		return "", err
+	}
+	return testing.Stdout(ctx), nil
+}
+
+func (s *DiffBundleSuite) TestDiffBundle(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	err = svc.UpdateConfigSettings(charm.Settings{"title": "Deployed"})
+	c.Assert(err, IsNil)
+
+	out, err := s.runDiffBundle(c, `
+services:
+  dummy:
+    charm: local:series/dummy
+    options:
+      title: Deployed
+`)
+	c.Assert(err, IsNil)
+	c.Assert(out, Equals, "")
+
+	out, err = s.runDiffBundle(c, `
+services:
+  dummy:
+    charm: local:series/dummy-2
+    options:
+      skill-level: 3
+  wordpress:
+    charm: local:series/wordpress
+`, "--format", "json")
+	c.Assert(err, IsNil)
+	c.Assert(out, Equals, `{"missing-services":["wordpress"],`+
+		`"services":{"dummy":{"charm":{"bundle":"local:series/dummy-2","environment":"local:series/dummy-1"},`+
+		`"options":{"skill-level":{"bundle":3,"environment":null},"title":{"bundle":"My Title","environment":"Deployed"}}}}}`+"\n")
+}
+
+func (s *DiffBundleSuite) TestDiffBundleInvalid(c *C) {
+	_, err := s.runDiffBundle(c, "services: {}")
+	c.Assert(err, ErrorMatches, "bundle has no services")
+}
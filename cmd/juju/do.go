@@ -0,0 +1,86 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// DoCommand queues a charm action to be run by a unit.
+type DoCommand struct {
+	EnvCommandBase
+	UnitName   string
+	ActionName string
+	Params     map[string]string
+}
+
+const doDoc = `
+Queue an action, as defined in the actions.yaml file of the unit's charm,
+to be run by the unit. Action parameters are given as key=value pairs, and
+are checked against the types declared by the charm; parameters that are
+not given take their default values.
+
+The unit runs the action between hooks, and records its results. The id
+of the queued action is printed; use "juju action-result" with the id to
+see the progress and results of the action.
+
+Example:
+
+    juju do mysql/0 snapshot outfile=/tmp/db.bz2
+`
+
+func (c *DoCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "do",
+		Args:    "<unit> <action> [key=value ...]",
+		Purpose: "queue an action to be run by a unit",
+		Doc:     doDoc,
+	}
+}
+
+func (c *DoCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no unit specified")
+	}
+	c.UnitName, args = args[0], args[1:]
+	if !state.IsUnitName(c.UnitName) {
+		return fmt.Errorf("invalid unit name %q", c.UnitName)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no action specified")
+	}
+	c.ActionName, args = args[0], args[1:]
+	if !charm.IsValidActionName(c.ActionName) {
+		return fmt.Errorf("invalid action name %q", c.ActionName)
+	}
+	c.Params = make(map[string]string)
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Params[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *DoCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	id, err := conn.State.Client().EnqueueAction(c.UnitName, c.ActionName, c.Params)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, id)
+	return nil
+}
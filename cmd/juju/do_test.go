@@ -0,0 +1,63 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type DoSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&DoSuite{})
+
+var doInitErrorTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, "no unit specified"},
+	{[]string{"dummy"}, `invalid unit name "dummy"`},
+	{[]string{"dummy/0"}, "no action specified"},
+	{[]string{"dummy/0", "Snap"}, `invalid action name "Snap"`},
+	{[]string{"dummy/0", "snapshot", "outfile"}, `expected "key=value", got "outfile"`},
+}
+
+func (s *DoSuite) TestInitErrors(c *C) {
+	for i, t := range doInitErrorTests {
+		c.Logf("test %d: %q", i, t.args)
+		err := testing.InitCommand(&DoCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *DoSuite) TestInit(c *C) {
+	doCmd := &DoCommand{}
+	err := testing.InitCommand(doCmd, []string{"dummy/0", "snapshot", "outfile=x=y", "level="})
+	c.Assert(err, IsNil)
+	c.Assert(doCmd.UnitName, Equals, "dummy/0")
+	c.Assert(doCmd.ActionName, Equals, "snapshot")
+	c.Assert(doCmd.Params, DeepEquals, map[string]string{"outfile": "x=y", "level": ""})
+}
+
+func (s *DoSuite) TestDo(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &DoCommand{}, []string{"dummy/0", "snapshot", "outfile=db.gz"})
+	c.Assert(err, IsNil)
+	pending, err := s.State.PendingActions(unit.Name())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 1)
+	c.Assert(testing.Stdout(ctx), Equals, pending[0].Id()+"\n")
+	c.Assert(pending[0].Params(), DeepEquals, map[string]interface{}{"outfile": "db.gz"})
+
+	_, err = testing.RunCommand(c, &DoCommand{}, []string{"dummy/0", "explode"})
+	c.Assert(err, ErrorMatches, `unknown action "explode"`)
+}
@@ -8,6 +8,7 @@ import (
 	"launchpad.net/gnuflag"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
 	"strings"
 )
 
@@ -132,6 +133,9 @@ func (c *SetEnvironmentCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 
 	// Here is the magic around setting the attributes:
 	// TODO(thumper): get this magic under test somewhere, and update other call-sites to use it.
@@ -0,0 +1,77 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// ExportBundleCommand writes a bundle describing the services and
+// relations in an environment.
+type ExportBundleCommand struct {
+	EnvCommandBase
+	RedactSecrets bool
+	out           cmd.Output
+}
+
+const exportBundleDoc = `
+Write a bundle describing the services in the environment and the
+relations between them, in the format accepted by "juju deploy-bundle".
+
+For each service, the bundle records its charm, number of units,
+constraints, exposure, annotations and any configuration settings that
+differ from the charm's defaults.
+
+Settings of options that appear to hold secrets, such as passwords and
+keys, may be left out of the bundle with --redact-secrets; the names of
+the settings left out are reported. They are always left out for users
+that are not environment administrators.
+`
+
+func (c *ExportBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "export-bundle",
+		Purpose: "export the services and relations in the environment as a bundle",
+		Doc:     exportBundleDoc,
+	}
+}
+
+func (c *ExportBundleCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.BoolVar(&c.RedactSecrets, "redact-secrets", false, "leave out settings that appear to hold secrets")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+	})
+}
+
+func (c *ExportBundleCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ExportBundleCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	data, redacted, err := conn.State.Client().ExportBundle(c.RedactSecrets)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Parse([]byte(data))
+	if err != nil {
+		return err
+	}
+	if len(redacted) > 0 {
+		fmt.Fprintf(ctx.Stderr, "redacted settings: %s\n", strings.Join(redacted, ", "))
+	}
+	return c.out.Write(ctx, b)
+}
@@ -0,0 +1,64 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type ExportBundleSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ExportBundleSuite{})
+
+func (s *ExportBundleSuite) TestInit(c *C) {
+	err := testing.InitCommand(&ExportBundleCommand{}, []string{"extra"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["extra"\]`)
+	exportBundleCmd := &ExportBundleCommand{}
+	err = testing.InitCommand(exportBundleCmd, []string{"--redact-secrets"})
+	c.Assert(err, IsNil)
+	c.Assert(exportBundleCmd.RedactSecrets, Equals, true)
+}
+
+func (s *ExportBundleSuite) TestExportBundle(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	err = svc.UpdateConfigSettings(charm.Settings{"title": "Exported"})
+	c.Assert(err, IsNil)
+	_, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &ExportBundleCommand{}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `services:
+  dummy:
+    charm: local:series/dummy-1
+    num_units: 1
+    options:
+      title: Exported
+    expose: true
+`)
+	c.Assert(testing.Stderr(ctx), Equals, "")
+}
+
+func (s *ExportBundleSuite) TestExportBundleToFile(c *C) {
+	_, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	dir := c.MkDir()
+	_, err = testing.RunCommandInDir(c, &ExportBundleCommand{}, []string{"-o", "bundle.yaml"}, dir)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "bundle.yaml"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "services:\n  dummy:\n    charm: local:series/dummy-1\n")
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"errors"
+	"time"
 
 	"launchpad.net/gnuflag"
 	"launchpad.net/juju-core/cmd"
@@ -17,19 +18,31 @@ import (
 type GetCommand struct {
 	EnvCommandBase
 	ServiceName string
+	History     bool
 	out         cmd.Output
 }
 
+const getDoc = `
+Show the configuration options of the specified service, with their
+current values.
+
+With --history, show the recorded changes to the service's
+configuration instead, oldest first. Each change is identified by a
+revision that may be given to "juju set --revert".
+`
+
 func (c *GetCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "get",
 		Args:    "<service>",
 		Purpose: "get service config options",
+		Doc:     getDoc,
 	}
 }
 
 func (c *GetCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.EnvCommandBase.SetFlags(f)
+	f.BoolVar(&c.History, "history", false, "show the history of changes to the configuration")
 	// TODO(dfc) add json formatting ?
 	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
 		"yaml": cmd.FormatYaml,
@@ -48,6 +61,9 @@ func (c *GetCommand) Init(args []string) error {
 // Run fetches the configuration of the service and formats
 // the result as a YAML string.
 func (c *GetCommand) Run(ctx *cmd.Context) error {
+	if c.History {
+		return c.showHistory(ctx)
+	}
 	conn, err := juju.NewConnFromName(c.EnvName)
 	if err != nil {
 		return err
@@ -70,3 +86,42 @@ func (c *GetCommand) Run(ctx *cmd.Context) error {
 	}
 	return c.out.Write(ctx, resultsMap)
 }
+
+// showHistory fetches the configuration history of the service and
+// formats the result as a YAML list of changes.
+func (c *GetCommand) showHistory(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	history, err := conn.State.Client().ServiceConfigHistory(c.ServiceName)
+	if err != nil {
+		return err
+	}
+	changes := []map[string]interface{}{}
+	for _, change := range history {
+		items := make(map[string]interface{})
+		for _, item := range change.Items {
+			values := make(map[string]interface{})
+			if item.OldValue != nil {
+				values["old"] = item.OldValue
+			}
+			if item.NewValue != nil {
+				values["new"] = item.NewValue
+			}
+			items[item.Key] = values
+		}
+		entry := map[string]interface{}{
+			"revision": change.Revision,
+			"charm":    change.CharmURL,
+			"time":     change.Time.Format(time.RFC3339),
+			"changes":  items,
+		}
+		if change.User != "" {
+			entry["user"] = change.User
+		}
+		changes = append(changes, entry)
+	}
+	return c.out.Write(ctx, changes)
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// HistoryCommand shows the audit log of operations requested by clients.
+type HistoryCommand struct {
+	EnvCommandBase
+	Entity string
+	User   string
+	After  time.Time
+	Before time.Time
+	after  string
+	before string
+	out    cmd.Output
+}
+
+const historyDoc = `
+Show the recorded operations that clients have requested of the
+environment, oldest first, with the user that requested each, its
+arguments and whether it failed. Secrets, such as passwords, are not
+recorded. Only the most recent operations are kept.
+
+The operations shown may be restricted to those concerning an entity,
+given as a machine id, service or unit name, or tag; to those requested
+by a user; and to those recorded within a time range. Times are given
+in RFC3339 format, as in 2013-10-16T13:00:00Z, or as dates, as in
+2013-10-16.
+
+Examples:
+ juju history --entity wordpress   (Show operations on the wordpress service)
+ juju history --user bob --after 2013-10-16
+`
+
+func (c *HistoryCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "history",
+		Purpose: "show the operations requested by clients",
+		Doc:     historyDoc,
+	}
+}
+
+func (c *HistoryCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Entity, "entity", "", "show only operations concerning the given entity")
+	f.StringVar(&c.User, "user", "", "show only operations requested by the given user")
+	f.StringVar(&c.after, "after", "", "show only operations recorded at or after the given time")
+	f.StringVar(&c.before, "before", "", "show only operations recorded before the given time")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *HistoryCommand) Init(args []string) (err error) {
+	if c.Entity != "" {
+		if c.Entity, err = entityTag(c.Entity); err != nil {
+			return err
+		}
+	}
+	if c.User != "" && !strings.HasPrefix(c.User, "user-") {
+		c.User = state.UserTag(c.User)
+	}
+	if c.after != "" {
+		if c.After, err = parseHistoryTime(c.after); err != nil {
+			return fmt.Errorf("invalid --after value: %v", err)
+		}
+	}
+	if c.before != "" {
+		if c.Before, err = parseHistoryTime(c.before); err != nil {
+			return fmt.Errorf("invalid --before value: %v", err)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// entityTag returns the tag of the entity identified by s, which may
+// be a machine id, a service or unit name, or a tag.
+func entityTag(s string) (string, error) {
+	for _, prefix := range []string{"machine-", "service-", "unit-", "user-", "environment-"} {
+		if strings.HasPrefix(s, prefix) {
+			return s, nil
+		}
+	}
+	switch {
+	case state.IsMachineId(s):
+		return state.MachineTag(s), nil
+	case state.IsUnitName(s):
+		return state.UnitTag(s), nil
+	case state.IsServiceName(s):
+		return state.ServiceTag(s), nil
+	}
+	return "", fmt.Errorf("invalid entity %q", s)
+}
+
+// parseHistoryTime parses a time in RFC3339 format, or a date.
+func parseHistoryTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", s); err != nil {
+			return time.Time{}, fmt.Errorf("expected time or date, got %q", s)
+		}
+	}
+	return t, nil
+}
+
+func (c *HistoryCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	entries, err := conn.State.Client().AuditLog(params.AuditLog{
+		Entity: c.Entity,
+		User:   c.User,
+		After:  c.After,
+		Before: c.Before,
+	})
+	if err != nil {
+		return err
+	}
+	result := []map[string]interface{}{}
+	for _, entry := range entries {
+		out := map[string]interface{}{
+			"time":   entry.Time.UTC().Format(time.RFC3339),
+			"user":   entry.User,
+			"method": entry.Method,
+		}
+		var args interface{}
+		if err := json.Unmarshal([]byte(entry.Args), &args); err == nil {
+			out["args"] = args
+		} else {
+			out["args"] = entry.Args
+		}
+		if len(entry.Entities) > 0 {
+			out["entities"] = entry.Entities
+		}
+		if entry.Error != "" {
+			out["error"] = entry.Error
+		}
+		result = append(result, out)
+	}
+	return c.out.Write(ctx, result)
+}
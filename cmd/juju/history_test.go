@@ -0,0 +1,124 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type HistorySuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&HistorySuite{})
+
+var historyInitTests = []struct {
+	args   []string
+	entity string
+	user   string
+	after  time.Time
+	before time.Time
+	err    string
+}{{
+	args: nil,
+}, {
+	args:   []string{"--entity", "wordpress", "--user", "bob"},
+	entity: "service-wordpress",
+	user:   "user-bob",
+}, {
+	args:   []string{"--entity", "wordpress/0", "--user", "user-bob"},
+	entity: "unit-wordpress-0",
+	user:   "user-bob",
+}, {
+	args:   []string{"--entity", "1/lxc/0"},
+	entity: "machine-1-lxc-0",
+}, {
+	args:   []string{"--entity", "user-bob"},
+	entity: "user-bob",
+}, {
+	args:   []string{"--after", "2013-10-16", "--before", "2013-10-16T13:30:00+01:00"},
+	after:  time.Date(2013, 10, 16, 0, 0, 0, 0, time.UTC),
+	before: time.Date(2013, 10, 16, 12, 30, 0, 0, time.UTC),
+}, {
+	args: []string{"--entity", "Word press"},
+	err:  `invalid entity "Word press"`,
+}, {
+	args: []string{"--after", "yesterday"},
+	err:  `invalid --after value: expected time or date, got "yesterday"`,
+}, {
+	args: []string{"--before", "16/10/2013"},
+	err:  `invalid --before value: expected time or date, got "16/10/2013"`,
+}, {
+	args: []string{"wordpress"},
+	err:  `unrecognized args: \["wordpress"\]`,
+}}
+
+func (s *HistorySuite) TestInit(c *C) {
+	for i, t := range historyInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		historyCmd := &HistoryCommand{}
+		err := testing.InitCommand(historyCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(historyCmd.Entity, Equals, t.entity)
+		c.Check(historyCmd.User, Equals, t.user)
+		c.Check(historyCmd.After.Equal(t.after), Equals, true)
+		c.Check(historyCmd.Before.Equal(t.before), Equals, true)
+	}
+}
+
+func (s *HistorySuite) TestHistory(c *C) {
+	t0 := time.Date(2013, 10, 16, 12, 0, 0, 0, time.UTC)
+	err := s.State.AddAuditEntry(state.AuditEntry{
+		Time:     t0,
+		User:     "user-admin",
+		Method:   "AddUser",
+		Args:     `{"Password":"<redacted>","Role":"read-only","Username":"bob"}`,
+		Entities: []string{"user-bob"},
+	})
+	c.Assert(err, IsNil)
+	err = s.State.AddAuditEntry(state.AuditEntry{
+		Time:     t0.Add(time.Minute),
+		User:     "user-bob",
+		Method:   "ServiceDestroy",
+		Args:     `{"ServiceName":"wordpress"}`,
+		Entities: []string{"service-wordpress"},
+		Error:    "permission denied",
+	})
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &HistoryCommand{}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `- args:
+    Password: <redacted>
+    Role: read-only
+    Username: bob
+  entities:
+  - user-bob
+  method: AddUser
+  time: "2013-10-16T12:00:00Z"
+  user: user-admin
+- args:
+    ServiceName: wordpress
+  entities:
+  - service-wordpress
+  error: permission denied
+  method: ServiceDestroy
+  time: "2013-10-16T12:01:00Z"
+  user: user-bob
+`)
+
+	ctx, err = testing.RunCommand(c, &HistoryCommand{}, []string{"--user", "bob", "--format", "json"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `[{"args":{"ServiceName":"wordpress"},"entities":["service-wordpress"],"error":"permission denied","method":"ServiceDestroy","time":"2013-10-16T12:01:00Z","user":"user-bob"}]`+"\n")
+}
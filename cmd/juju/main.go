@@ -52,6 +52,7 @@ func Main(args []string) {
 	juju.Register(&BootstrapCommand{})
 	juju.Register(&AddMachineCommand{})
 	juju.Register(&DeployCommand{})
+	juju.Register(&DeployBundleCommand{})
 	juju.Register(&AddRelationCommand{})
 	juju.Register(&AddUnitCommand{})
 
@@ -62,15 +63,35 @@ func Main(args []string) {
 	juju.Register(&DestroyUnitCommand{})
 	juju.Register(&DestroyEnvironmentCommand{})
 
+	// Protection commands.
+	juju.Register(&BlockCommand{})
+	juju.Register(&UnblockCommand{})
+
+	// Backup and restore commands.
+	juju.Register(&BackupCommand{})
+	juju.Register(&RestoreCommand{})
+
 	// Reporting commands.
 	juju.Register(&StatusCommand{})
+	juju.Register(&StatusHistoryCommand{})
+	juju.Register(&ExportBundleCommand{})
+	juju.Register(&DiffBundleCommand{})
+	juju.Register(&HistoryCommand{})
+	juju.Register(&ShowRelationCommand{})
 	juju.Register(&SwitchCommand{})
+	juju.Register(&WaitCommand{})
 
 	// Error resolution commands.
 	juju.Register(&SCPCommand{})
 	juju.Register(&SSHCommand{})
 	juju.Register(&ResolvedCommand{})
-	juju.Register(&DebugLogCommand{sshCmd: &SSHCommand{}})
+	juju.Register(&PauseUnitCommand{})
+	juju.Register(&ResumeUnitCommand{})
+	juju.Register(&DebugLogCommand{})
+	juju.Register(&DebugHooksCommand{})
+	juju.Register(&RunCommand{})
+	juju.Register(&DoCommand{})
+	juju.Register(&ActionResultCommand{})
 
 	// Configuration commands.
 	juju.Register(&InitCommand{})
@@ -87,6 +108,11 @@ func Main(args []string) {
 	juju.Register(&UpgradeJujuCommand{})
 	juju.Register(&UpgradeCharmCommand{})
 
+	// User management commands.
+	juju.Register(&AddUserCommand{})
+	juju.Register(&RemoveUserCommand{})
+	juju.Register(&ChangePasswordCommand{})
+
 	// Charm publishing commands.
 	juju.Register(&PublishCommand{})
 
@@ -211,18 +211,28 @@ func (s *MainSuite) TestActualRunJujuArgsAfterCommand(c *C) {
 }
 
 var commandNames = []string{
+	"action-result",
 	"add-machine",
 	"add-relation",
 	"add-unit",
+	"add-user",
+	"backup",
+	"block",
 	"bootstrap",
+	"change-password",
+	"debug-hooks",
 	"debug-log",
 	"deploy",
+	"deploy-bundle",
 	"destroy-environment",
 	"destroy-machine",
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"diff-bundle",
+	"do",
 	"env", // alias for switch
+	"export-bundle",
 	"expose",
 	"generate-config", // alias for init
 	"get",
@@ -230,27 +240,37 @@ var commandNames = []string{
 	"get-env", // alias for get-environment
 	"get-environment",
 	"help",
+	"history",
 	"image-metadata",
 	"init",
+	"pause-unit",
 	"publish",
 	"remove-relation", // alias for destroy-relation
 	"remove-unit",     // alias for destroy-unit
+	"remove-user",
 	"resolved",
+	"restore",
+	"resume-unit",
+	"run",
 	"scp",
 	"set",
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
+	"show-relation",
 	"ssh",
 	"stat", // alias for status
 	"status",
+	"status-history",
 	"switch",
 	"sync-tools",
 	"terminate-machine", // alias for destroy-machine
+	"unblock",
 	"unexpose",
 	"upgrade-charm",
 	"upgrade-juju",
 	"version",
+	"wait",
 }
 
 func (s *MainSuite) TestHelpCommands(c *C) {
@@ -0,0 +1,96 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// PauseUnitCommand pauses the hooks of one or more units.
+type PauseUnitCommand struct {
+	EnvCommandBase
+	UnitNames []string
+}
+
+const pauseUnitDoc = `
+Pause the hooks of the given units, for example during maintenance.
+While a unit is paused, its agent keeps track of changes to the
+service's configuration, relations and charm, but does not run the
+hooks that respond to them; the hooks are run once the unit is resumed
+with "juju resume-unit". Commands started by "juju run" and actions
+are still run. A paused unit that is destroyed runs its hooks as usual.
+
+Paused units are shown as such by "juju status".
+`
+
+func (c *PauseUnitCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "pause-unit",
+		Args:    "<unit> [...]",
+		Purpose: "pause the hooks of service units",
+		Doc:     pauseUnitDoc,
+	}
+}
+
+func (c *PauseUnitCommand) Init(args []string) (err error) {
+	c.UnitNames, err = parseUnitNames(args)
+	return err
+}
+
+func (c *PauseUnitCommand) Run(_ *cmd.Context) error {
+	return setUnitsPaused(c.EnvName, c.UnitNames, true)
+}
+
+// ResumeUnitCommand resumes the hooks of one or more paused units.
+type ResumeUnitCommand struct {
+	EnvCommandBase
+	UnitNames []string
+}
+
+func (c *ResumeUnitCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "resume-unit",
+		Args:    "<unit> [...]",
+		Purpose: "resume the hooks of units paused with juju pause-unit",
+		Doc:     "Hooks queued while the units were paused are run once they are resumed.",
+	}
+}
+
+func (c *ResumeUnitCommand) Init(args []string) (err error) {
+	c.UnitNames, err = parseUnitNames(args)
+	return err
+}
+
+func (c *ResumeUnitCommand) Run(_ *cmd.Context) error {
+	return setUnitsPaused(c.EnvName, c.UnitNames, false)
+}
+
+// parseUnitNames checks that args holds one or more valid unit names,
+// and returns them.
+func parseUnitNames(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no units specified")
+	}
+	for _, name := range args {
+		if !state.IsUnitName(name) {
+			return nil, fmt.Errorf("invalid unit name %q", name)
+		}
+	}
+	return args, nil
+}
+
+// setUnitsPaused pauses or resumes the hooks of the named units in the
+// named environment.
+func setUnitsPaused(envName string, unitNames []string, paused bool) error {
+	conn, err := juju.NewAPIConnFromName(envName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().SetUnitsPaused(unitNames, paused)
+}
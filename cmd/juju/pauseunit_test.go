@@ -0,0 +1,88 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type PauseUnitSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&PauseUnitSuite{})
+
+var pauseUnitInitTests = []struct {
+	args  []string
+	units []string
+	err   string
+}{{
+	err: "no units specified",
+}, {
+	args: []string{"jeremy-fisher"},
+	err:  `invalid unit name "jeremy-fisher"`,
+}, {
+	args:  []string{"dummy/0", "dummy/1"},
+	units: []string{"dummy/0", "dummy/1"},
+}}
+
+func (s *PauseUnitSuite) TestInit(c *C) {
+	for i, t := range pauseUnitInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		pauseCmd := &PauseUnitCommand{}
+		err := testing.InitCommand(pauseCmd, t.args)
+		resumeCmd := &ResumeUnitCommand{}
+		resumeErr := testing.InitCommand(resumeCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			c.Check(resumeErr, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(resumeErr, IsNil)
+		c.Check(pauseCmd.UnitNames, DeepEquals, t.units)
+		c.Check(resumeCmd.UnitNames, DeepEquals, t.units)
+	}
+}
+
+func (s *PauseUnitSuite) assertPaused(c *C, name string, paused bool) {
+	unit, err := s.State.Unit(name)
+	c.Assert(err, IsNil)
+	c.Assert(unit.IsPaused(), Equals, paused)
+}
+
+func (s *PauseUnitSuite) TestPauseResume(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "-n", "2", "local:dummy", "dummy")
+	c.Assert(err, IsNil)
+
+	_, err = testing.RunCommand(c, &PauseUnitCommand{}, []string{"dummy/0", "dummy/1"})
+	c.Assert(err, IsNil)
+	s.assertPaused(c, "dummy/0", true)
+	s.assertPaused(c, "dummy/1", true)
+
+	_, err = testing.RunCommand(c, &ResumeUnitCommand{}, []string{"dummy/1"})
+	c.Assert(err, IsNil)
+	s.assertPaused(c, "dummy/0", true)
+	s.assertPaused(c, "dummy/1", false)
+
+	_, err = testing.RunCommand(c, &PauseUnitCommand{}, []string{"dummy/9"})
+	c.Assert(err, ErrorMatches, `unit "dummy/9" not found`)
+}
+
+func (s *PauseUnitSuite) TestBlocked(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "dummy")
+	c.Assert(err, IsNil)
+	err = s.State.SwitchBlockOn(state.ChangeBlock, "maintenance")
+	c.Assert(err, IsNil)
+
+	_, err = testing.RunCommand(c, &PauseUnitCommand{}, []string{"dummy/0"})
+	c.Assert(err, ErrorMatches, "operation blocked by the all-changes block: maintenance")
+	s.assertPaused(c, "dummy/0", false)
+}
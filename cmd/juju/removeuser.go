@@ -0,0 +1,42 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// RemoveUserCommand removes a user from the environment.
+type RemoveUserCommand struct {
+	EnvCommandBase
+	User string
+}
+
+func (c *RemoveUserCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove-user",
+		Args:    "<username>",
+		Purpose: "remove a user from the environment",
+	}
+}
+
+func (c *RemoveUserCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no username specified")
+	}
+	c.User = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *RemoveUserCommand) Run(_ *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().RemoveUser(c.User)
+}
@@ -0,0 +1,39 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type RemoveUserSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&RemoveUserSuite{})
+
+func (s *RemoveUserSuite) TestRemoveUser(c *C) {
+	_, err := s.State.AddUserWithRole("foo", "secret", state.ReadOnlyRole)
+	c.Assert(err, IsNil)
+
+	_, err = testing.RunCommand(c, &RemoveUserCommand{}, []string{"foo"})
+	c.Assert(err, IsNil)
+	_, err = s.State.User("foo")
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+
+	_, err = testing.RunCommand(c, &RemoveUserCommand{}, []string{"foo"})
+	c.Assert(err, ErrorMatches, `user "foo" not found`)
+}
+
+func (s *RemoveUserSuite) TestInit(c *C) {
+	err := testing.InitCommand(&RemoveUserCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no username specified")
+	err = testing.InitCommand(&RemoveUserCommand{}, []string{"foo", "bar"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["bar"\]`)
+}
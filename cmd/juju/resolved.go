@@ -51,6 +51,9 @@ func (c *ResolvedCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	unit, err := conn.State.Unit(c.UnitName)
 	if err != nil {
 		return err
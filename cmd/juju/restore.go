@@ -0,0 +1,257 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/backup"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/utils"
+)
+
+// RestoreCommand restores the state server of an environment from a
+// backup made with BackupCommand.
+type RestoreCommand struct {
+	EnvCommandBase
+	Constraints constraints.Value
+	Filename    string
+}
+
+const restoreDoc = `
+Restore the state server of an environment from a backup made with
+"juju backup", after the original state server has been lost.
+
+A new state server is bootstrapped, and the state database and
+configuration held in the backup are restored onto it. All other
+machines in the environment are then updated to use the new state
+server.
+
+The backup must have been made from the same environment: the
+environment's CA certificate must match the one in the backup.
+The original state server must no longer be running.
+`
+
+// restoreSSHAttempt governs how long restore will wait for the newly
+// bootstrapped state server to accept ssh connections.
+var restoreSSHAttempt = utils.AttemptStrategy{
+	Total: 10 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
+func (c *RestoreCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "restore",
+		Args:    "<backupfile>",
+		Purpose: "restore the state server from a backup",
+		Doc:     restoreDoc,
+	}
+}
+
+func (c *RestoreCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "set environment constraints")
+}
+
+func (c *RestoreCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no backup file specified")
+	}
+	c.Filename, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *RestoreCommand) Run(ctx *cmd.Context) error {
+	filename := ctx.AbsPath(c.Filename)
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	archive, err := backup.ReadArchive(f)
+	if err != nil {
+		return err
+	}
+	environ, err := environs.NewFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	if err := archive.CheckEnviron(environ.Config()); err != nil {
+		return err
+	}
+	if err := checkStateServerGone(environ); err != nil {
+		return err
+	}
+
+	// Bootstrap a new state server, and restore the backup onto it.
+	log.Infof("bootstrapping new state server...")
+	if err := environ.Storage().Remove(environs.StateFile); err != nil {
+		return fmt.Errorf("cannot remove provider state: %v", err)
+	}
+	if err := environs.Bootstrap(environ, c.Constraints); err != nil {
+		return fmt.Errorf("cannot bootstrap new state server: %v", err)
+	}
+	info, _, err := environs.StateInfo(environ)
+	if err != nil {
+		return err
+	}
+	host, _, err := net.SplitHostPort(info.Addrs[0])
+	if err != nil {
+		return err
+	}
+	cfg := environ.Config()
+	statePort, apiPort := cfg.StatePort(), cfg.APIPort()
+	log.Infof("restoring backup onto %s...", host)
+	script := backup.RestoreScript(environs.DataDir, host, statePort, apiPort)
+	for a := restoreSSHAttempt.Start(); a.Next(); {
+		if _, err = f.Seek(0, 0); err != nil {
+			return err
+		}
+		ssh := sshCommand(host, script)
+		ssh.Stdin = f
+		ssh.Stdout = ctx.Stderr
+		ssh.Stderr = ctx.Stderr
+		if err = ssh.Run(); !isSSHConnectError(err) {
+			break
+		}
+		log.Debugf("cannot connect to %s: %v", host, err)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot restore backup: %v", err)
+	}
+
+	// Make the restored state refer to the new state server
+	// instance, and start the state server's agent.
+	conn, err := juju.NewConn(environ)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	bootstrapState, err := environs.LoadState(environ.Storage())
+	if err != nil {
+		return err
+	}
+	machine, err := conn.State.Machine("0")
+	if err != nil {
+		return err
+	}
+	if err := machine.UpdateInstanceId(bootstrapState.StateInstances[0]); err != nil {
+		return err
+	}
+	if err := runSSH(ctx, host, backup.StartStateServerScript()); err != nil {
+		return fmt.Errorf("cannot start state server: %v", err)
+	}
+
+	// Point the agents on every other machine at the new state server.
+	if err := updateAgents(ctx, conn, host, statePort, apiPort); err != nil {
+		return err
+	}
+	missing, err := archive.MissingFiles(environ.Storage())
+	if err != nil {
+		return err
+	}
+	for _, name := range missing {
+		fmt.Fprintf(ctx.Stderr, "warning: %q is missing from the environment storage\n", name)
+	}
+	return nil
+}
+
+// checkStateServerGone returns an error if the environment's provider
+// state refers to a state server instance that still exists.
+func checkStateServerGone(environ environs.Environ) error {
+	bootstrapState, err := environs.LoadState(environ.Storage())
+	if err != nil || len(bootstrapState.StateInstances) == 0 {
+		return nil
+	}
+	_, err = environ.Instances(bootstrapState.StateInstances)
+	if err == environs.ErrNoInstances {
+		return nil
+	}
+	if err != nil && err != environs.ErrPartialInstances {
+		return err
+	}
+	return fmt.Errorf("the old state server is still running; destroy it before restoring")
+}
+
+// updateAgents runs the script returned from backup.UpdateAgentsScript
+// on every provisioned machine other than the state server. Machines
+// that cannot be updated are reported, but do not cause the restore
+// to fail.
+func updateAgents(ctx *cmd.Context, conn *juju.Conn, host string, statePort, apiPort int) error {
+	machines, err := conn.State.AllMachines()
+	if err != nil {
+		return err
+	}
+	var ids []instance.Id
+	var machineIds []string
+	for _, m := range machines {
+		if m.Id() == "0" || m.Life() == state.Dead {
+			continue
+		}
+		id, err := m.InstanceId()
+		if state.IsNotProvisionedError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		machineIds = append(machineIds, m.Id())
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	insts, err := conn.Environ.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances && err != environs.ErrNoInstances {
+		return err
+	}
+	script := backup.UpdateAgentsScript(environs.DataDir, host, statePort, apiPort)
+	for i, inst := range insts {
+		if inst == nil {
+			fmt.Fprintf(ctx.Stderr, "cannot update machine %s: instance %q not found\n", machineIds[i], ids[i])
+			continue
+		}
+		addr, err := inst.DNSName()
+		if err == nil {
+			log.Infof("updating machine %s at %s...", machineIds[i], addr)
+			err = runSSH(ctx, addr, script)
+		}
+		if err != nil {
+			fmt.Fprintf(ctx.Stderr, "cannot update machine %s: %v\n", machineIds[i], err)
+		}
+	}
+	return nil
+}
+
+// runSSH runs the given script as root on the given host, sending
+// its output to ctx's standard error.
+func runSSH(ctx *cmd.Context, host, script string) error {
+	ssh := sshCommand(host, script)
+	ssh.Stdout = ctx.Stderr
+	ssh.Stderr = ctx.Stderr
+	return ssh.Run()
+}
+
+// isSSHConnectError reports whether err results from ssh failing to
+// connect to the remote host, rather than from the remote command.
+func isSSHConnectError(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.ExitStatus() == 255
+}
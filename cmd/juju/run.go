@@ -0,0 +1,190 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// RunCommand is responsible for running arbitrary commands on remote machines.
+type RunCommand struct {
+	EnvCommandBase
+	out      cmd.Output
+	timeout  time.Duration
+	machines []string
+	services []string
+	units    []string
+	commands string
+}
+
+const runDoc = `
+Run the commands on the specified targets.
+
+Targets are specified using either machine ids, service names or unit
+names. At least one target specifier is needed.
+
+Multiple values can be set for --machine, --service, and --unit by using
+comma separated values.
+
+If the target is a machine, the command is run directly by the machine
+agent on that machine.
+
+If the target is a service, the command is run on all units for that
+service. For example, if there was a service "mysql" and that service
+had two units, "mysql/0" and "mysql/1", then
+  --service mysql
+is equivalent to
+  --unit mysql/0,mysql/1
+
+Commands run for services or units are executed in a "hook context" for
+the unit, so that hook tools such as unit-get and relation-get are
+available. Commands are never run at the same time as a hook for the
+same machine.
+
+If only one target is specified, the output of the command is written
+directly to stdout and stderr, and juju run exits with the same code as
+the command. Otherwise the results for each target are formatted as
+yaml or json.
+
+If the commands have not completed within the timeout, they are
+reported as timed out. Timeouts longer than 15 minutes are reduced to
+15 minutes.
+`
+
+func (c *RunCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "run",
+		Args:    "<commands>",
+		Purpose: "run the commands on the remote targets specified",
+		Doc:     runDoc,
+	}
+}
+
+func (c *RunCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "smart", map[string]cmd.Formatter{
+		"yaml":  cmd.FormatYaml,
+		"json":  cmd.FormatJson,
+		"smart": cmd.FormatSmart,
+	})
+	f.DurationVar(&c.timeout, "timeout", 5*time.Minute, "how long to wait before the remote command is considered to have failed")
+	f.Var(newStringsValue(&c.machines), "machine", "one or more machine ids")
+	f.Var(newStringsValue(&c.services), "service", "one or more service names")
+	f.Var(newStringsValue(&c.units), "unit", "one or more unit ids")
+}
+
+func (c *RunCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no commands specified")
+	}
+	c.commands, args = args[0], args[1:]
+	if err := cmd.CheckEmpty(args); err != nil {
+		return err
+	}
+	if len(c.machines) == 0 && len(c.services) == 0 && len(c.units) == 0 {
+		return fmt.Errorf("you must specify a target, either through --machine, --service or --unit")
+	}
+	var nameErrors []string
+	for _, machineId := range c.machines {
+		if !state.IsMachineId(machineId) {
+			nameErrors = append(nameErrors, fmt.Sprintf("  %q is not a valid machine id", machineId))
+		}
+	}
+	for _, service := range c.services {
+		if !state.IsServiceName(service) {
+			nameErrors = append(nameErrors, fmt.Sprintf("  %q is not a valid service name", service))
+		}
+	}
+	for _, unit := range c.units {
+		if !state.IsUnitName(unit) {
+			nameErrors = append(nameErrors, fmt.Sprintf("  %q is not a valid unit name", unit))
+		}
+	}
+	if len(nameErrors) > 0 {
+		return fmt.Errorf("The following run targets are not valid:\n%s",
+			strings.Join(nameErrors, "\n"))
+	}
+	return nil
+}
+
+// runResult is the yaml and json representation of the result of
+// running commands on a single target.
+type runResult struct {
+	MachineId string `yaml:"MachineId" json:"MachineId"`
+	UnitId    string `yaml:"UnitId,omitempty" json:"UnitId,omitempty"`
+	Code      int    `yaml:"ReturnCode" json:"ReturnCode"`
+	Stdout    string `yaml:"Stdout,omitempty" json:"Stdout,omitempty"`
+	Stderr    string `yaml:"Stderr,omitempty" json:"Stderr,omitempty"`
+	Error     string `yaml:"Error,omitempty" json:"Error,omitempty"`
+}
+
+func (c *RunCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	results, err := conn.State.Client().Run(params.RunParams{
+		Commands: c.commands,
+		Timeout:  c.timeout,
+		Machines: c.machines,
+		Services: c.services,
+		Units:    c.units,
+	})
+	if err != nil {
+		return err
+	}
+	// If we are just dealing with one result, and the user has not
+	// specified a format, write the output directly.
+	if len(results) == 1 && c.out.Name() == "smart" {
+		result := results[0]
+		ctx.Stdout.Write(result.Stdout)
+		ctx.Stderr.Write(result.Stderr)
+		if result.Error != "" {
+			return fmt.Errorf("%s", result.Error)
+		}
+		if result.Code != 0 {
+			return cmd.NewRcPassthroughError(result.Code)
+		}
+		return nil
+	}
+	values := make([]runResult, len(results))
+	for i, result := range results {
+		values[i] = runResult{
+			MachineId: result.MachineId,
+			UnitId:    result.UnitId,
+			Code:      result.Code,
+			Stdout:    string(result.Stdout),
+			Stderr:    string(result.Stderr),
+			Error:     result.Error,
+		}
+	}
+	return c.out.Write(ctx, values)
+}
+
+// stringsValue implements gnuflag.Value for a comma separated list of
+// strings, allowing flags to be specified once with several values.
+type stringsValue []string
+
+func newStringsValue(p *[]string) *stringsValue {
+	return (*stringsValue)(p)
+}
+
+func (v *stringsValue) Set(s string) error {
+	*v = strings.Split(s, ",")
+	return nil
+}
+
+func (v *stringsValue) String() string {
+	return strings.Join(*v, ",")
+}
@@ -0,0 +1,172 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/errors"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type RunSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&RunSuite{})
+
+func (*RunSuite) TestInit(c *C) {
+	for i, test := range []struct {
+		message  string
+		args     []string
+		commands string
+		timeout  time.Duration
+		machines []string
+		services []string
+		units    []string
+		errMatch string
+	}{{
+		message:  "no args",
+		errMatch: "no commands specified",
+	}, {
+		message:  "no target",
+		args:     []string{"sudo reboot"},
+		errMatch: "you must specify a target, either through --machine, --service or --unit",
+	}, {
+		message:  "too many args",
+		args:     []string{"--unit=foo/0", "sudo reboot", "oops"},
+		errMatch: `unrecognized args: \["oops"\]`,
+	}, {
+		message: "bad names",
+		args:    []string{"--machine=foo", "--service=foo/0", "--unit=foo", "sudo reboot"},
+		errMatch: "The following run targets are not valid:\n" +
+			`  "foo" is not a valid machine id` + "\n" +
+			`  "foo/0" is not a valid service name` + "\n" +
+			`  "foo" is not a valid unit name`,
+	}, {
+		message:  "all the args",
+		args:     []string{"--machine=0,1/lxc/2", "--service=wordpress", "--unit=mysql/0,mysql/1", "--timeout=1m", "uname -a"},
+		commands: "uname -a",
+		timeout:  time.Minute,
+		machines: []string{"0", "1/lxc/2"},
+		services: []string{"wordpress"},
+		units:    []string{"mysql/0", "mysql/1"},
+	}, {
+		message:  "default timeout",
+		args:     []string{"--unit=mysql/0", "uname -a"},
+		commands: "uname -a",
+		timeout:  5 * time.Minute,
+		units:    []string{"mysql/0"},
+	}} {
+		c.Logf("%d: %s", i, test.message)
+		runCmd := &RunCommand{}
+		err := testing.InitCommand(runCmd, test.args)
+		if test.errMatch == "" {
+			c.Assert(err, IsNil)
+			c.Check(runCmd.commands, Equals, test.commands)
+			c.Check(runCmd.timeout, Equals, test.timeout)
+			c.Check(runCmd.machines, DeepEquals, test.machines)
+			c.Check(runCmd.services, DeepEquals, test.services)
+			c.Check(runCmd.units, DeepEquals, test.units)
+		} else {
+			c.Check(err, ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+// fakeRunAgent completes the commands queued for the given receiver
+// with the supplied result, until stopped.
+func (s *RunSuite) fakeRunAgent(c *C, receiver string, result state.RunResult) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(testing.ShortWait):
+			}
+			pending, err := s.State.PendingRunCommands(receiver)
+			c.Check(err, IsNil)
+			for _, rc := range pending {
+				err := rc.Complete(result)
+				if !errors.IsNotFoundError(err) {
+					c.Check(err, IsNil)
+				}
+			}
+			s.State.StartSync()
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *RunSuite) addMachines(c *C, n int) {
+	for i := 0; i < n; i++ {
+		_, err := s.State.AddMachine("series", state.JobHostUnits)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *RunSuite) TestSingleTarget(c *C) {
+	s.addMachines(c, 1)
+	stop := s.fakeRunAgent(c, "machine-0", state.RunResult{
+		Code:   42,
+		Stdout: []byte("hello\n"),
+		Stderr: []byte("world\n"),
+	})
+	defer stop()
+
+	ctx := testing.Context(c)
+	code := cmd.Main(&RunCommand{}, ctx, []string{"--machine=0", "hostname"})
+	c.Assert(code, Equals, 42)
+	c.Assert(testing.Stdout(ctx), Equals, "hello\n")
+	c.Assert(testing.Stderr(ctx), Equals, "world\n")
+}
+
+func (s *RunSuite) TestSingleTargetError(c *C) {
+	s.addMachines(c, 1)
+	stop := s.fakeRunAgent(c, "machine-0", state.RunResult{
+		Code:   -1,
+		Stdout: []byte("partial\n"),
+		Error:  "timed out",
+	})
+	defer stop()
+
+	ctx, err := testing.RunCommand(c, &RunCommand{}, []string{"--machine=0", "sleep 1000"})
+	c.Assert(err, ErrorMatches, "timed out")
+	c.Assert(testing.Stdout(ctx), Equals, "partial\n")
+}
+
+func (s *RunSuite) TestMultipleTargets(c *C) {
+	s.addMachines(c, 2)
+	for _, tag := range []string{"machine-0", "machine-1"} {
+		stop := s.fakeRunAgent(c, tag, state.RunResult{Stdout: []byte(tag)})
+		defer stop()
+	}
+
+	ctx, err := testing.RunCommand(c, &RunCommand{}, []string{"--machine=0,1", "hostname"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, ""+
+		"- MachineId: \"0\"\n"+
+		"  ReturnCode: 0\n"+
+		"  Stdout: machine-0\n"+
+		"- MachineId: \"1\"\n"+
+		"  ReturnCode: 0\n"+
+		"  Stdout: machine-1\n")
+
+	ctx, err = testing.RunCommand(c, &RunCommand{}, []string{"--machine=0,1", "--format=json", "hostname"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, ""+
+		`[{"MachineId":"0","ReturnCode":0,"Stdout":"machine-0"},`+
+		`{"MachineId":"1","ReturnCode":0,"Stdout":"machine-1"}]`+"\n")
+}
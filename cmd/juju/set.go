@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"launchpad.net/gnuflag"
-	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
 )
@@ -20,20 +19,30 @@ type SetCommand struct {
 	ServiceName     string
 	SettingsStrings map[string]string
 	SettingsYAML    cmd.FileVar
+	Revert          int
 }
 
+const setDoc = `
+Set one or more configuration options for the specified service.
+
+Each change to a service's configuration is recorded, and may be seen
+with "juju get --history". The --revert option restores the
+configuration in effect after the change with the given revision.
+`
+
 func (c *SetCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "set",
 		Args:    "<service> name=value ...",
 		Purpose: "set service config options",
-		Doc:     "Set one or more configuration options for the specified service.",
+		Doc:     setDoc,
 	}
 }
 
 func (c *SetCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.EnvCommandBase.SetFlags(f)
 	f.Var(&c.SettingsYAML, "config", "path to yaml-formatted service config")
+	f.IntVar(&c.Revert, "revert", 0, "revert to the configuration with the given revision")
 }
 
 func (c *SetCommand) Init(args []string) error {
@@ -43,6 +52,12 @@ func (c *SetCommand) Init(args []string) error {
 	if c.SettingsYAML.Path != "" && len(args) > 1 {
 		return errors.New("cannot specify --config when using key=value arguments")
 	}
+	if c.Revert < 0 {
+		return fmt.Errorf("invalid revision %d", c.Revert)
+	}
+	if c.Revert != 0 && (c.SettingsYAML.Path != "" || len(args) > 1) {
+		return errors.New("cannot specify --revert with --config or key=value arguments")
+	}
 	c.ServiceName = args[0]
 	settings, err := parse(args[1:])
 	if err != nil {
@@ -54,38 +69,25 @@ func (c *SetCommand) Init(args []string) error {
 
 // Run updates the configuration of a service.
 func (c *SetCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
-	service, err := conn.State.Service(c.ServiceName)
-	if err != nil {
-		return err
-	}
-	ch, _, err := service.Charm()
-	if err != nil {
-		return err
-	}
-	var settings charm.Settings
-	if c.SettingsYAML.Path != "" {
+	client := conn.State.Client()
+	switch {
+	case c.Revert != 0:
+		return client.ServiceRevertConfig(c.ServiceName, c.Revert)
+	case c.SettingsYAML.Path != "":
 		settingsYAML, err := c.SettingsYAML.Read(ctx)
 		if err != nil {
 			return err
 		}
-		settings, err = ch.Config().ParseSettingsYAML(settingsYAML, c.ServiceName)
-		if err != nil {
-			return err
-		}
-	} else if len(c.SettingsStrings) > 0 {
-		settings, err = ch.Config().ParseSettingsStrings(c.SettingsStrings)
-		if err != nil {
-			return err
-		}
-	} else {
-		return nil
+		return client.ServiceSetYAML(c.ServiceName, string(settingsYAML))
+	case len(c.SettingsStrings) > 0:
+		return client.ServiceSet(c.ServiceName, c.SettingsStrings)
 	}
-	return service.UpdateConfigSettings(settings)
+	return nil
 }
 
 // parse parses the option k=v strings into a map of options to be
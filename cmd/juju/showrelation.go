@@ -0,0 +1,102 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// ShowRelationCommand shows the settings of the units in relations.
+type ShowRelationCommand struct {
+	EnvCommandBase
+	Endpoint string
+	UnitName string
+	out      cmd.Output
+}
+
+const showRelationDoc = `
+Show the relations of a service endpoint, given as <service>:<relation>
+or as <service> for all the relations of the service. For each unit of
+the related services, the settings it has set in the relation are
+shown, together with whether it is in the relation's scope and whether
+its agent is alive. If a unit is given, only that unit is shown.
+
+Examples:
+ juju show-relation wordpress:db
+ juju show-relation mysql wordpress/0
+`
+
+func (c *ShowRelationCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-relation",
+		Args:    "<service>[:<relation>] [<unit>]",
+		Purpose: "show the settings of the units in a relation",
+		Doc:     showRelationDoc,
+	}
+}
+
+func (c *ShowRelationCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *ShowRelationCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no relation endpoint specified")
+	}
+	c.Endpoint, args = args[0], args[1:]
+	if name := strings.SplitN(c.Endpoint, ":", 2)[0]; !state.IsServiceName(name) {
+		return fmt.Errorf("invalid service name %q", name)
+	}
+	if len(args) > 0 {
+		c.UnitName, args = args[0], args[1:]
+		if !state.IsUnitName(c.UnitName) {
+			return fmt.Errorf("invalid unit name %q", c.UnitName)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ShowRelationCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	relations, err := conn.State.Client().ShowRelation(c.Endpoint, c.UnitName)
+	if err != nil {
+		return err
+	}
+	result := []map[string]interface{}{}
+	for _, rel := range relations {
+		units := make(map[string]interface{})
+		for _, unit := range rel.Units {
+			out := map[string]interface{}{
+				"in-scope":    unit.InScope,
+				"agent-alive": unit.AgentAlive,
+			}
+			if unit.Settings != nil {
+				out["settings"] = unit.Settings
+			}
+			units[unit.Unit] = out
+		}
+		result = append(result, map[string]interface{}{
+			"relation": rel.Key,
+			"id":       rel.Id,
+			"units":    units,
+		})
+	}
+	return c.out.Write(ctx, result)
+}
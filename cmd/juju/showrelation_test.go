@@ -0,0 +1,104 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type ShowRelationSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ShowRelationSuite{})
+
+var showRelationInitTests = []struct {
+	args     []string
+	endpoint string
+	unitName string
+	err      string
+}{{
+	args: nil,
+	err:  "no relation endpoint specified",
+}, {
+	args:     []string{"wordpress:db"},
+	endpoint: "wordpress:db",
+}, {
+	args:     []string{"mysql", "wordpress/0"},
+	endpoint: "mysql",
+	unitName: "wordpress/0",
+}, {
+	args: []string{"Word press:db"},
+	err:  `invalid service name "Word press"`,
+}, {
+	args: []string{"wordpress:db", "wordpress"},
+	err:  `invalid unit name "wordpress"`,
+}, {
+	args: []string{"wordpress:db", "wordpress/0", "mysql/0"},
+	err:  `unrecognized args: \["mysql/0"\]`,
+}}
+
+func (s *ShowRelationSuite) TestInit(c *C) {
+	for i, t := range showRelationInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		showRelationCmd := &ShowRelationCommand{}
+		err := testing.InitCommand(showRelationCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(showRelationCmd.Endpoint, Equals, t.endpoint)
+		c.Check(showRelationCmd.UnitName, Equals, t.unitName)
+	}
+}
+
+func (s *ShowRelationSuite) TestShowRelation(c *C) {
+	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	mysql, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, IsNil)
+	wu, err := wordpress.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = mysql.AddUnit()
+	c.Assert(err, IsNil)
+	wru, err := rel.Unit(wu)
+	c.Assert(err, IsNil)
+	err = wru.EnterScope(map[string]interface{}{"private-address": "10.0.0.1"})
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &ShowRelationCommand{}, []string{"wordpress:db"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, fmt.Sprintf(`- id: %d
+  relation: %s
+  units:
+    mysql/0:
+      agent-alive: false
+      in-scope: false
+    wordpress/0:
+      agent-alive: false
+      in-scope: true
+      settings:
+        private-address: 10.0.0.1
+`, rel.Id(), rel))
+
+	ctx, err = testing.RunCommand(c, &ShowRelationCommand{}, []string{"mysql", "mysql/0", "--format", "json"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, fmt.Sprintf(
+		`[{"id":%d,"relation":%q,"units":{"mysql/0":{"agent-alive":false,"in-scope":false}}}]`+"\n",
+		rel.Id(), rel.String(),
+	))
+
+	_, err = testing.RunCommand(c, &ShowRelationCommand{}, []string{"wordpress:cache"})
+	c.Assert(err, ErrorMatches, `"wordpress:cache" has no relations`)
+}
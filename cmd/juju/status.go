@@ -264,11 +264,15 @@ func (context *statusContext) processUnit(unit *state.Unit) (status unitStatus)
 	if unit.IsPrincipal() {
 		status.Machine, _ = unit.AssignedMachineId()
 	}
+	status.Paused = unit.IsPaused()
 	status.Life,
 		status.AgentVersion,
 		status.AgentState,
 		status.AgentStateInfo,
 		status.Err = processAgent(unit)
+	if status.Err == nil {
+		status.WorkloadState, status.WorkloadStateInfo, status.Err = processWorkload(unit)
+	}
 	if subUnits := unit.SubordinateNames(); len(subUnits) > 0 {
 		status.Subordinates = make(map[string]unitStatus)
 		for _, name := range subUnits {
@@ -279,6 +283,16 @@ func (context *statusContext) processUnit(unit *state.Unit) (status unitStatus)
 	return
 }
 
+// processWorkload retrieves the status of a unit's workload. It is
+// not reported until the unit's charm has set it.
+func processWorkload(unit *state.Unit) (status params.Status, info string, err error) {
+	status, info, err = unit.WorkloadStatus()
+	if err != nil || status == params.StatusUnknown {
+		return "", "", err
+	}
+	return status, info, nil
+}
+
 func (context *statusContext) unitByName(name string) *state.Unit {
 	serviceName := strings.Split(name, "/")[0]
 	return context.units[serviceName][name]
@@ -439,15 +453,18 @@ func (s serviceStatus) GetYAML() (tag string, value interface{}) {
 }
 
 type unitStatus struct {
-	Err            error                 `json:"-" yaml:",omitempty"`
-	AgentState     params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
-	AgentStateInfo string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
-	AgentVersion   string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
-	Life           string                `json:"life,omitempty" yaml:"life,omitempty"`
-	Machine        string                `json:"machine,omitempty" yaml:"machine,omitempty"`
-	OpenedPorts    []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
-	PublicAddress  string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
-	Subordinates   map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
+	Err               error                 `json:"-" yaml:",omitempty"`
+	AgentState        params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
+	AgentStateInfo    string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
+	AgentVersion      string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
+	WorkloadState     params.Status         `json:"workload-state,omitempty" yaml:"workload-state,omitempty"`
+	WorkloadStateInfo string                `json:"workload-state-info,omitempty" yaml:"workload-state-info,omitempty"`
+	Life              string                `json:"life,omitempty" yaml:"life,omitempty"`
+	Machine           string                `json:"machine,omitempty" yaml:"machine,omitempty"`
+	OpenedPorts       []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
+	Paused            bool                  `json:"paused,omitempty" yaml:"paused,omitempty"`
+	PublicAddress     string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
+	Subordinates      map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
 }
 
 type unitStatusNoMarshal unitStatus
@@ -844,6 +844,89 @@ var statusTests = []testCase{
 				},
 			},
 		},
+	), test(
+		"a paused unit",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addCharm{"dummy"},
+		addService{"dummy-service", "dummy"},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addAliveUnit{"dummy-service", "1"},
+		setUnitStatus{"dummy-service/0", params.StatusStarted, ""},
+		pauseUnit{"dummy-service/0"},
+
+		expect{
+			"paused units are reported",
+			M{
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"dummy-service": M{
+						"charm":   "local:series/dummy-1",
+						"exposed": false,
+						"units": M{
+							"dummy-service/0": M{
+								"machine":     "1",
+								"agent-state": "started",
+								"paused":      true,
+							},
+						},
+					},
+				},
+			},
+		},
+	), test(
+		"units with a workload status",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addCharm{"dummy"},
+		addService{"dummy-service", "dummy"},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addMachine{machineId: "2", job: state.JobHostUnits},
+		startAliveMachine{"2"},
+		setMachineStatus{"2", params.StatusStarted, ""},
+		addAliveUnit{"dummy-service", "1"},
+		setUnitStatus{"dummy-service/0", params.StatusStarted, ""},
+		setUnitWorkloadStatus{"dummy-service/0", params.StatusBlocked, "need a database"},
+		addAliveUnit{"dummy-service", "2"},
+		setUnitStatus{"dummy-service/1", params.StatusStarted, ""},
+
+		expect{
+			"workload status is reported once the charm has set it",
+			M{
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+					"2": machine2,
+				},
+				"services": M{
+					"dummy-service": M{
+						"charm":   "local:series/dummy-1",
+						"exposed": false,
+						"units": M{
+							"dummy-service/0": M{
+								"machine":             "1",
+								"agent-state":         "started",
+								"workload-state":      "blocked",
+								"workload-state-info": "need a database",
+							},
+							"dummy-service/1": M{
+								"machine":     "2",
+								"agent-state": "started",
+							},
+						},
+					},
+				},
+			},
+		},
 	),
 }
 
@@ -1077,6 +1160,30 @@ func (oup openUnitPort) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+type pauseUnit struct {
+	unitName string
+}
+
+func (pu pauseUnit) step(c *C, ctx *context) {
+	u, err := ctx.st.Unit(pu.unitName)
+	c.Assert(err, IsNil)
+	err = u.SetPaused()
+	c.Assert(err, IsNil)
+}
+
+type setUnitWorkloadStatus struct {
+	unitName   string
+	status     params.Status
+	statusInfo string
+}
+
+func (sus setUnitWorkloadStatus) step(c *C, ctx *context) {
+	u, err := ctx.st.Unit(sus.unitName)
+	c.Assert(err, IsNil)
+	err = u.SetWorkloadStatus(sus.status, sus.statusInfo)
+	c.Assert(err, IsNil)
+}
+
 type ensureDyingUnit struct {
 	unitName string
 }
@@ -0,0 +1,83 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+)
+
+// StatusHistoryCommand shows the recorded status changes of a unit or
+// machine.
+type StatusHistoryCommand struct {
+	EnvCommandBase
+	Entity string
+	out    cmd.Output
+}
+
+const statusHistoryDoc = `
+Show the recorded changes to the status of a unit or machine, given as
+a unit name, machine id or tag, oldest first. Only the most recent
+changes are kept.
+
+Examples:
+ juju status-history wordpress/0
+ juju status-history 1 --format json
+`
+
+func (c *StatusHistoryCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "status-history",
+		Args:    "<unit or machine>",
+		Purpose: "show the status changes of a unit or machine",
+		Doc:     statusHistoryDoc,
+	}
+}
+
+func (c *StatusHistoryCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *StatusHistoryCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no unit or machine specified")
+	}
+	if c.Entity, err = entityTag(args[0]); err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *StatusHistoryCommand) Run(ctx *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	changes, err := conn.State.Client().StatusHistory(c.Entity)
+	if err != nil {
+		return err
+	}
+	result := []map[string]interface{}{}
+	for _, change := range changes {
+		out := map[string]interface{}{
+			"time":   change.Time.UTC().Format(time.RFC3339),
+			"status": change.Status,
+		}
+		if change.Info != "" {
+			out["info"] = change.Info
+		}
+		result = append(result, out)
+	}
+	return c.out.Write(ctx, result)
+}
@@ -0,0 +1,87 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/json"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+)
+
+type StatusHistorySuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&StatusHistorySuite{})
+
+var statusHistoryInitTests = []struct {
+	args   []string
+	entity string
+	err    string
+}{{
+	args: nil,
+	err:  "no unit or machine specified",
+}, {
+	args:   []string{"wordpress/0"},
+	entity: "unit-wordpress-0",
+}, {
+	args:   []string{"1/lxc/0"},
+	entity: "machine-1-lxc-0",
+}, {
+	args:   []string{"unit-wordpress-0"},
+	entity: "unit-wordpress-0",
+}, {
+	args: []string{"Word press"},
+	err:  `invalid entity "Word press"`,
+}, {
+	args: []string{"wordpress/0", "wordpress/1"},
+	err:  `unrecognized args: \["wordpress/1"\]`,
+}}
+
+func (s *StatusHistorySuite) TestInit(c *C) {
+	for i, t := range statusHistoryInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		statusHistoryCmd := &StatusHistoryCommand{}
+		err := testing.InitCommand(statusHistoryCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(statusHistoryCmd.Entity, Equals, t.entity)
+	}
+}
+
+func (s *StatusHistorySuite) TestStatusHistory(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusError, "hook failed")
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &StatusHistoryCommand{}, []string{"dummy/0", "--format", "json"})
+	c.Assert(err, IsNil)
+	var changes []map[string]string
+	err = json.Unmarshal([]byte(testing.Stdout(ctx)), &changes)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0]["status"], Equals, "error")
+	c.Assert(changes[0]["info"], Equals, "hook failed")
+	c.Assert(changes[1]["status"], Equals, "started")
+	_, ok := changes[1]["info"]
+	c.Assert(ok, Equals, false)
+	for _, change := range changes {
+		c.Assert(change["time"], Not(Equals), "")
+	}
+
+	_, err = testing.RunCommand(c, &StatusHistoryCommand{}, []string{"dummy"})
+	c.Assert(err, ErrorMatches, `entity "service-dummy" does not have a status history`)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/statecmd"
 )
@@ -41,6 +42,9 @@ func (c *UnexposeCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	params := params.ServiceUnexpose{ServiceName: c.ServiceName}
 	return statecmd.ServiceUnexpose(conn.State, params)
 }
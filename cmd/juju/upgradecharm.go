@@ -4,14 +4,19 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"launchpad.net/gnuflag"
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils"
 	"os"
+	"strings"
+	"time"
 )
 
 // UpgradeCharm is responsible for upgrading a service's charm.
@@ -22,6 +27,7 @@ type UpgradeCharmCommand struct {
 	RepoPath    string // defaults to JUJU_REPOSITORY
 	SwitchURL   string
 	Revision    int // defaults to -1 (latest)
+	BatchSize   int // defaults to 0 (all units at once)
 }
 
 const upgradeCharmDoc = `
@@ -64,8 +70,23 @@ charm.
 Use of the --force flag is not generally recommended; units upgraded
 while in an error state will not have upgrade-charm hooks executed,
 and may cause unexpected behavior.
+
+By default all the units of the service are upgraded at once. With
+--batch-size, the units are upgraded that many at a time, and the next
+batch is only upgraded once every unit of the previous one runs the new
+charm and reports started status. If a unit reports an error, the
+upgrade is aborted and the remaining units keep running the old charm;
+once the failure is dealt with, running upgrade-charm again resumes the
+upgrade.
 `
 
+// upgradeBatchAttempt governs how long upgrade-charm waits for each
+// batch of units to be upgraded when --batch-size is used.
+var upgradeBatchAttempt = utils.AttemptStrategy{
+	Total: 30 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
 func (c *UpgradeCharmCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "upgrade-charm",
@@ -81,6 +102,7 @@ func (c *UpgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.IntVar(&c.BatchSize, "batch-size", 0, "upgrade units this many at a time")
 }
 
 func (c *UpgradeCharmCommand) Init(args []string) error {
@@ -91,13 +113,16 @@ func (c *UpgradeCharmCommand) Init(args []string) error {
 		}
 		c.ServiceName = args[0]
 	case 0:
-		return errors.New("no service specified")
+		return fmt.Errorf("no service specified")
 	default:
 		return cmd.CheckEmpty(args[1:])
 	}
 	if c.SwitchURL != "" && c.Revision != -1 {
 		return fmt.Errorf("--switch and --revision are mutually exclusive")
 	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("invalid --batch-size %d", c.BatchSize)
+	}
 	return nil
 }
 
@@ -109,6 +134,9 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	service, err := conn.State.Service(c.ServiceName)
 	if err != nil {
 		return err
@@ -144,6 +172,14 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		}
 		newURL = newURL.WithRevision(latest)
 	}
+	if *newURL == *oldURL && service.CharmUpgradeRestricted() {
+		// An upgrade in batches was aborted; resume it.
+		sch, _, err := service.Charm()
+		if err != nil {
+			return err
+		}
+		return c.upgrade(service, sch)
+	}
 	bumpRevision := false
 	if *newURL == *oldURL {
 		if explicitRevision {
@@ -168,5 +204,119 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	return c.upgrade(service, sch)
+}
+
+// upgrade changes the charm of the service. If a batch size was given,
+// existing units are upgraded in batches of that size.
+func (c *UpgradeCharmCommand) upgrade(service *state.Service, sch *state.Charm) error {
+	if c.BatchSize == 0 {
+		return service.SetCharm(sch, c.Force)
+	}
+	units, err := service.AllUnits()
+	if err != nil {
+		return err
+	}
+	// Units that have not yet installed a charm will install the new
+	// one, so only the units running another charm are upgraded.
+	var pending []*state.Unit
+	for _, unit := range units {
+		if curl, _ := unit.CharmURL(); curl != nil && *curl != *sch.URL() {
+			pending = append(pending, unit)
+		}
+	}
+	if err := service.SetCharmRestricted(sch, c.Force); err != nil {
+		return err
+	}
+	for len(pending) > 0 {
+		n := c.BatchSize
+		if n > len(pending) {
+			n = len(pending)
+		}
+		if err := upgradeBatch(service, sch.URL(), pending[:n]); err != nil {
+			return fmt.Errorf("upgrade of service %q aborted: %v", service, err)
+		}
+		pending = pending[n:]
+	}
+	// Lift the restriction now that all units are upgraded.
 	return service.SetCharm(sch, c.Force)
 }
+
+// upgradeBatch allows the given units to upgrade to the charm with the
+// given URL, and waits until they all run it and report started status.
+// It fails if any of the units reports an error.
+func upgradeBatch(service *state.Service, curl *charm.URL, units []*state.Unit) error {
+	names := make([]string, len(units))
+	since := make([]time.Time, len(units))
+	for i, unit := range units {
+		names[i] = unit.Name()
+		// Only the status changes after the upgrade is allowed are
+		// relevant. They are told apart by the time of the last
+		// change recorded before, so that the times compared are all
+		// recorded by the same clock.
+		history, err := unit.StatusHistory()
+		if err != nil {
+			return err
+		}
+		if len(history) > 0 {
+			since[i] = history[len(history)-1].Time
+		}
+	}
+	log.Infof("upgrading units %s", strings.Join(names, ", "))
+	if err := service.AllowCharmUpgrade(names...); err != nil {
+		return err
+	}
+	for a := upgradeBatchAttempt.Start(); a.Next(); {
+		var waiting []string
+		for i, unit := range units {
+			upgraded, err := unitUpgraded(unit, curl, since[i])
+			if err != nil {
+				return err
+			}
+			if !upgraded {
+				waiting = append(waiting, unit.Name())
+			}
+		}
+		if len(waiting) == 0 {
+			return nil
+		}
+		if !a.HasNext() {
+			return fmt.Errorf("timed out waiting for units %s to be upgraded", strings.Join(waiting, ", "))
+		}
+	}
+	panic("unreachable")
+}
+
+// unitUpgraded returns whether the unit runs the charm with the given
+// URL and reports started status after the given time. It returns an
+// error if the unit reported an error after that time.
+func unitUpgraded(unit *state.Unit, curl *charm.URL, since time.Time) (bool, error) {
+	if err := unit.Refresh(); errors.IsNotFoundError(err) {
+		// A removed unit does not hold up the upgrade.
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	history, err := unit.StatusHistory()
+	if err != nil {
+		return false, err
+	}
+	started := false
+	for _, change := range history {
+		if !change.Time.After(since) {
+			continue
+		}
+		switch change.Status {
+		case params.StatusError:
+			return false, fmt.Errorf("unit %q failed: %s", unit, change.Info)
+		case params.StatusStarted:
+			started = true
+		}
+	}
+	status, _, err := unit.Status()
+	if err != nil {
+		return false, err
+	}
+	unitURL, _ := unit.CharmURL()
+	return started && status == params.StatusStarted && unitURL != nil && *unitURL == *curl, nil
+}
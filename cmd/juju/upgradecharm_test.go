@@ -10,9 +10,12 @@ import (
 	"launchpad.net/juju-core/charm"
 	jujutesting "launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils"
 	"os"
 	"path"
+	"time"
 )
 
 type UpgradeCharmErrorsSuite struct {
@@ -89,6 +92,12 @@ func (s *UpgradeCharmErrorsSuite) TestInvalidRevision(c *C) {
 	c.Assert(err, ErrorMatches, `invalid value "blah" for flag --revision: strconv.ParseInt: parsing "blah": invalid syntax`)
 }
 
+func (s *UpgradeCharmErrorsSuite) TestInvalidBatchSize(c *C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--batch-size=-1")
+	c.Assert(err, ErrorMatches, "invalid --batch-size -1")
+}
+
 type UpgradeCharmSuccessSuite struct {
 	jujutesting.RepoSuite
 	path string
@@ -216,3 +225,126 @@ func (s *UpgradeCharmSuccessSuite) TestSwitch(c *C) {
 	c.Assert(curl.String(), Equals, "local:precise/myriak-42")
 	s.assertLocalRevision(c, 42, myriakPath)
 }
+
+// addStartedUnits adds units to the riak service, running its current
+// charm and reporting started status.
+func (s *UpgradeCharmSuccessSuite) addStartedUnits(c *C, n int) []*state.Unit {
+	curl, _ := s.riak.CharmURL()
+	units, err := s.riak.AllUnits()
+	c.Assert(err, IsNil)
+	for len(units) < n {
+		unit, err := s.riak.AddUnit()
+		c.Assert(err, IsNil)
+		units = append(units, unit)
+	}
+	for _, unit := range units {
+		err := unit.SetCharmURL(curl)
+		c.Assert(err, IsNil)
+		err = unit.SetStatus(params.StatusStarted, "")
+		c.Assert(err, IsNil)
+	}
+	return units
+}
+
+// runFakeUnitAgents upgrades the given units as unit agents would once
+// they are allowed to, recording the order in which they are upgraded.
+// The unit named failUnit reports an error instead of started status.
+func (s *UpgradeCharmSuccessSuite) runFakeUnitAgents(c *C, units []*state.Unit, failUnit string) (upgraded chan string, stop func()) {
+	upgraded = make(chan string, len(units))
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		pending := units
+		for len(pending) > 0 {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+			svc, err := s.State.Service("riak")
+			if !c.Check(err, IsNil) {
+				return
+			}
+			var next []*state.Unit
+			for _, unit := range pending {
+				if !svc.CharmUpgradeAllowed(unit.Name()) {
+					next = append(next, unit)
+					continue
+				}
+				curl, _ := svc.CharmURL()
+				err := unit.SetCharmURL(curl)
+				if !c.Check(err, IsNil) {
+					return
+				}
+				if unit.Name() == failUnit {
+					err = unit.SetStatus(params.StatusError, `hook failed: "upgrade-charm"`)
+				} else {
+					err = unit.SetStatus(params.StatusStarted, "")
+				}
+				if !c.Check(err, IsNil) {
+					return
+				}
+				upgraded <- unit.Name()
+			}
+			pending = next
+		}
+	}()
+	return upgraded, func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *UpgradeCharmSuccessSuite) patchBatchAttempt() func() {
+	old := upgradeBatchAttempt
+	upgradeBatchAttempt = utils.AttemptStrategy{
+		Total: testing.LongWait,
+		Delay: 10 * time.Millisecond,
+	}
+	return func() { upgradeBatchAttempt = old }
+}
+
+func (s *UpgradeCharmSuccessSuite) TestUpgradeInBatches(c *C) {
+	defer s.patchBatchAttempt()()
+	units := s.addStartedUnits(c, 3)
+	upgraded, stop := s.runFakeUnitAgents(c, units, "")
+	defer stop()
+
+	err := runUpgradeCharm(c, "riak", "--batch-size=2")
+	c.Assert(err, IsNil)
+	curl := s.assertUpgraded(c, 8, false)
+	c.Assert(s.riak.CharmUpgradeRestricted(), Equals, false)
+	for _, unit := range units {
+		err := unit.Refresh()
+		c.Assert(err, IsNil)
+		unitURL, _ := unit.CharmURL()
+		c.Assert(unitURL, DeepEquals, curl)
+	}
+	c.Assert(upgraded, HasLen, 3)
+}
+
+func (s *UpgradeCharmSuccessSuite) TestUpgradeInBatchesAborted(c *C) {
+	defer s.patchBatchAttempt()()
+	units := s.addStartedUnits(c, 2)
+	upgraded, stop := s.runFakeUnitAgents(c, units, units[0].Name())
+	defer stop()
+
+	err := runUpgradeCharm(c, "riak", "--batch-size=1")
+	c.Assert(err, ErrorMatches, `upgrade of service "riak" aborted: unit "riak/0" failed: hook failed: "upgrade-charm"`)
+	s.assertUpgraded(c, 8, false)
+	c.Assert(s.riak.CharmUpgradeRestricted(), Equals, true)
+	c.Assert(s.riak.CharmUpgradeAllowed(units[0].Name()), Equals, true)
+	c.Assert(s.riak.CharmUpgradeAllowed(units[1].Name()), Equals, false)
+	c.Assert(<-upgraded, Equals, units[0].Name())
+
+	// Once the failure is dealt with, the upgrade can be resumed.
+	err = units[0].SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	err = runUpgradeCharm(c, "riak", "--batch-size=1")
+	c.Assert(err, IsNil)
+	s.assertUpgraded(c, 8, false)
+	s.assertLocalRevision(c, 8, s.path)
+	c.Assert(s.riak.CharmUpgradeRestricted(), Equals, false)
+	c.Assert(<-upgraded, Equals, units[1].Name())
+}
@@ -14,6 +14,7 @@ import (
 	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/version"
 )
 
@@ -101,6 +102,9 @@ func (c *UpgradeJujuCommand) Run(_ *cmd.Context) (err error) {
 		return err
 	}
 	defer conn.Close()
+	if err := conn.State.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	defer func() {
 		if err == errUpToDate {
 			log.Noticef(err.Error())
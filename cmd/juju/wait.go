@@ -0,0 +1,74 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+)
+
+// WaitCommand waits for the environment to reach a steady state.
+type WaitCommand struct {
+	EnvCommandBase
+	Services []string
+	Timeout  time.Duration
+}
+
+const waitDoc = `
+Wait until the environment reaches a steady state, in which every
+machine is provisioned and started, and every unit is started, runs
+the charm of its service and has no hooks waiting to run, as reported
+by its agent. If services are given, only those services, their units
+and the machines hosting them are waited for.
+
+The command fails as soon as a unit or machine is in an error state, or
+if the steady state is not reached within the timeout. A zero timeout
+waits forever.
+
+Examples:
+ juju wait
+ juju wait --timeout 1h wordpress mysql
+`
+
+func (c *WaitCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "wait",
+		Args:    "[<service> ...]",
+		Purpose: "wait for the environment to reach a steady state",
+		Doc:     waitDoc,
+	}
+}
+
+func (c *WaitCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.DurationVar(&c.Timeout, "timeout", 30*time.Minute, "how long to wait for the steady state")
+}
+
+func (c *WaitCommand) Init(args []string) error {
+	for _, name := range args {
+		if !state.IsServiceName(name) {
+			return fmt.Errorf("invalid service name %q", name)
+		}
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %v", c.Timeout)
+	}
+	c.Services = args
+	return nil
+}
+
+func (c *WaitCommand) Run(_ *cmd.Context) error {
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.State.Client().WaitSteady(c.Services, c.Timeout)
+}
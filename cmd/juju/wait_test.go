@@ -0,0 +1,164 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/instance"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+)
+
+type WaitSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&WaitSuite{})
+
+func runWait(c *C, args ...string) error {
+	_, err := testing.RunCommand(c, &WaitCommand{}, args)
+	return err
+}
+
+var waitInitTests = []struct {
+	args     []string
+	services []string
+	timeout  time.Duration
+	err      string
+}{{
+	timeout: 30 * time.Minute,
+}, {
+	args:     []string{"--timeout", "1h", "wordpress", "mysql"},
+	services: []string{"wordpress", "mysql"},
+	timeout:  time.Hour,
+}, {
+	args:    []string{"--timeout", "0"},
+	timeout: 0,
+}, {
+	args: []string{"--timeout", "-1s"},
+	err:  "invalid timeout -1s",
+}, {
+	args: []string{"invalid:name"},
+	err:  `invalid service name "invalid:name"`,
+}}
+
+func (s *WaitSuite) TestInit(c *C) {
+	for i, t := range waitInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		waitCmd := &WaitCommand{}
+		err := testing.InitCommand(waitCmd, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(waitCmd.Services, DeepEquals, t.services)
+		c.Check(waitCmd.Timeout, Equals, t.timeout)
+	}
+}
+
+// deployDummy deploys the dummy charm as the given service, and
+// returns its unit and the machine the unit is assigned to.
+func (s *WaitSuite) deployDummy(c *C, name string) (*state.Unit, *state.Machine) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", name)
+	c.Assert(err, IsNil)
+	unit, err := s.State.Unit(name + "/0")
+	c.Assert(err, IsNil)
+	id, err := unit.AssignedMachineId()
+	c.Assert(err, IsNil)
+	machine, err := s.State.Machine(id)
+	c.Assert(err, IsNil)
+	return unit, machine
+}
+
+// start makes the unit and its machine look as though their agents
+// had started them.
+func (s *WaitSuite) start(c *C, unit *state.Unit, machine *state.Machine) {
+	err := machine.SetProvisioned(instance.Id("i-"+machine.Id()), "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	err = machine.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	svc, err := unit.Service()
+	c.Assert(err, IsNil)
+	curl, _ := svc.CharmURL()
+	err = unit.SetCharmURL(curl)
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	err = unit.SetIdle(true)
+	c.Assert(err, IsNil)
+}
+
+func (s *WaitSuite) TestWaitEmptyEnvironment(c *C) {
+	err := runWait(c)
+	c.Assert(err, IsNil)
+}
+
+func (s *WaitSuite) TestWaitSteady(c *C) {
+	unit, machine := s.deployDummy(c, "dummy")
+	s.start(c, unit, machine)
+	err := runWait(c, "--timeout", "10s")
+	c.Assert(err, IsNil)
+}
+
+func (s *WaitSuite) TestWaitHooksPending(c *C) {
+	unit, machine := s.deployDummy(c, "dummy")
+	s.start(c, unit, machine)
+	err := unit.SetIdle(false)
+	c.Assert(err, IsNil)
+	err = runWait(c, "--timeout", "100ms")
+	c.Assert(err, ErrorMatches, `timed out waiting for unit "dummy/0"`)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		err := unit.SetIdle(true)
+		c.Check(err, IsNil)
+	}()
+	err = runWait(c, "--timeout", "10s")
+	c.Assert(err, IsNil)
+}
+
+func (s *WaitSuite) TestWaitTimeout(c *C) {
+	s.deployDummy(c, "dummy")
+	err := runWait(c, "--timeout", "100ms")
+	c.Assert(err, ErrorMatches, `timed out waiting for machine 0, unit "dummy/0"`)
+}
+
+func (s *WaitSuite) TestWaitServices(c *C) {
+	unit, machine := s.deployDummy(c, "dummy")
+	s.start(c, unit, machine)
+	s.deployDummy(c, "other")
+	err := runWait(c, "--timeout", "10s", "dummy")
+	c.Assert(err, IsNil)
+	err = runWait(c, "--timeout", "100ms", "other")
+	c.Assert(err, ErrorMatches, `timed out waiting for machine 1, unit "other/0"`)
+	err = runWait(c, "--timeout", "100ms", "missing")
+	c.Assert(err, ErrorMatches, `timed out waiting for service "missing"`)
+}
+
+func (s *WaitSuite) TestWaitUnitError(c *C) {
+	unit, machine := s.deployDummy(c, "dummy")
+	s.start(c, unit, machine)
+	err := unit.SetStatus(params.StatusError, `hook failed: "install"`)
+	c.Assert(err, IsNil)
+	err = runWait(c, "--timeout", "10s")
+	c.Assert(err, ErrorMatches, `unit "dummy/0" is in an error state: hook failed: "install"`)
+}
+
+func (s *WaitSuite) TestWaitUnitErrorWhileWaiting(c *C) {
+	unit, _ := s.deployDummy(c, "dummy")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		err := unit.SetStatus(params.StatusError, `hook failed: "install"`)
+		c.Check(err, IsNil)
+	}()
+	err := runWait(c, "--timeout", "10s")
+	c.Assert(err, ErrorMatches, `unit "dummy/0" is in an error state: hook failed: "install"`)
+}
@@ -15,6 +15,7 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	localstorage "launchpad.net/juju-core/environs/local/storage"
+	"launchpad.net/juju-core/environs/manual"
 	"launchpad.net/juju-core/environs/provider"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/log"
@@ -25,6 +26,7 @@ import (
 	"launchpad.net/juju-core/state/apiserver"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/juju-core/worker/cleaner"
+	"launchpad.net/juju-core/worker/commandrunner"
 	"launchpad.net/juju-core/worker/firewaller"
 	"launchpad.net/juju-core/worker/machiner"
 	"launchpad.net/juju-core/worker/provisioner"
@@ -35,6 +37,10 @@ const bootstrapMachineId = "0"
 
 var retryDelay = 3 * time.Second
 
+// initDir holds the directory containing the upstart configuration
+// of the machine agent.
+var initDir = "/etc/init"
+
 // MachineAgent is a cmd.Command responsible for running a machine agent.
 type MachineAgent struct {
 	cmd.CommandBase
@@ -123,11 +129,31 @@ func (a *MachineAgent) Run(_ *cmd.Context) error {
 		// TODO(rog) go1.1: use method expression
 		return a.APIWorker(ensureStateWorker)
 	})
-	err := agentDone(a.runner.Wait())
+	err := a.runner.Wait()
+	if err == worker.ErrTerminateAgent && manual.IsManualNonce(a.Conf.MachineNonce) {
+		// The machine was not started by the provider, so nothing
+		// else will clean up after the agent.
+		if err := a.uninstallAgent(); err != nil {
+			log.Errorf("cannot uninstall machine agent: %v", err)
+		}
+	}
+	err = agentDone(err)
 	a.tomb.Kill(err)
 	return err
 }
 
+// uninstallAgent removes the upstart configuration and the data
+// directory of the machine agent. The agent is not restarted by
+// upstart once it exits normally.
+func (a *MachineAgent) uninstallAgent() error {
+	log.Infof("uninstalling machine agent %v", a.Tag())
+	confPath := filepath.Join(initDir, "jujud-"+a.Tag()+".conf")
+	if err := os.Remove(confPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(a.Conf.DataDir)
+}
+
 func allFatal(error) bool {
 	return true
 }
@@ -216,6 +242,9 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 		// TODO(rog) use id instead of *Machine (or introduce Clone method)
 		return NewUpgrader(st, m, dataDir), nil
 	})
+	runner.StartWorker("commandrunner", func() (worker.Worker, error) {
+		return commandrunner.NewCommandRunner(st, m.Tag(), dataDir), nil
+	})
 	// At this stage, since we don't embed lxc containers, just start an lxc
 	// provisioner task for non-lxc containers.  Since we have only LXC
 	// containers and normal machines, this effectively means that we only
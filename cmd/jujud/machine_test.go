@@ -5,6 +5,8 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
 	"time"
@@ -146,6 +148,33 @@ func (s *MachineSuite) TestWithDeadMachine(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *MachineSuite) TestDeadManualMachineUninstallsAgent(c *C) {
+	m, err := s.State.InjectMachineWithNonce("series", constraints.Value{}, "manual:10.0.0.1", "manual:10.0.0.1", instance.HardwareCharacteristics{}, state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = m.SetPassword("machine-password")
+	c.Assert(err, IsNil)
+	conf, _ := s.agentSuite.primeAgent(c, m.Tag(), "machine-password")
+	conf.MachineNonce = "manual:10.0.0.1"
+	conf.APIInfo.Nonce = conf.MachineNonce
+	err = conf.Write()
+	c.Assert(err, IsNil)
+	initDir = c.MkDir()
+	defer func() { initDir = "/etc/init" }()
+	confPath := filepath.Join(initDir, "jujud-"+m.Tag()+".conf")
+	err = ioutil.WriteFile(confPath, []byte("upstart conf"), 0644)
+	c.Assert(err, IsNil)
+
+	err = m.EnsureDead()
+	c.Assert(err, IsNil)
+	a := s.newAgent(c, m)
+	err = runWithTimeout(a)
+	c.Assert(err, IsNil)
+	_, err = os.Stat(confPath)
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(conf.DataDir)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
 func (s *MachineSuite) TestDyingMachine(c *C) {
 	m, _, _ := s.primeAgent(c, state.JobHostUnits)
 	a := s.newAgent(c, m)
@@ -231,7 +231,7 @@ func (c *SuperCommand) Run(ctx *Context) error {
 		}
 	}
 	err := c.subcmd.Run(ctx)
-	if err != nil && err != ErrSilent {
+	if err != nil && err != ErrSilent && !IsRcPassthroughError(err) {
 		log.Errorf("command failed: %v", err)
 	} else {
 		log.Infof("command finished")
@@ -56,6 +56,8 @@ func (c *TestCommand) Run(ctx *cmd.Context) error {
 		return errors.New("BAM!")
 	case "silent-error":
 		return cmd.ErrSilent
+	case "rc-passthrough":
+		return cmd.NewRcPassthroughError(42)
 	case "echo":
 		_, err := io.Copy(ctx.Stdout, ctx.Stdin)
 		return err
@@ -36,17 +36,17 @@ func (lxc *lxcInstance) WaitDNSName() (string, error) {
 }
 
 // OpenPorts implements instance.Instance.OpenPorts.
-func (lxc *lxcInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (lxc *lxcInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	return fmt.Errorf("not implemented")
 }
 
 // ClosePorts implements instance.Instance.ClosePorts.
-func (lxc *lxcInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (lxc *lxcInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	return fmt.Errorf("not implemented")
 }
 
 // Ports implements instance.Instance.Ports.
-func (lxc *lxcInstance) Ports(machineId string) ([]instance.Port, error) {
+func (lxc *lxcInstance) Ports(machineId string) ([]instance.PortRange, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
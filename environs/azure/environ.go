@@ -709,21 +709,21 @@ func (env *azureEnviron) Destroy(ensureInsts []instance.Instance) error {
 }
 
 // OpenPorts is specified in the Environ interface.
-func (env *azureEnviron) OpenPorts(ports []instance.Port) error {
+func (env *azureEnviron) OpenPorts(ports []instance.PortRange) error {
 	// TODO: implement this.
 	return nil
 }
 
 // ClosePorts is specified in the Environ interface.
-func (env *azureEnviron) ClosePorts(ports []instance.Port) error {
+func (env *azureEnviron) ClosePorts(ports []instance.PortRange) error {
 	// TODO: implement this.
 	return nil
 }
 
 // Ports is specified in the Environ interface.
-func (env *azureEnviron) Ports() ([]instance.Port, error) {
+func (env *azureEnviron) Ports() ([]instance.PortRange, error) {
 	// TODO: implement this.
-	return []instance.Port{}, nil
+	return []instance.PortRange{}, nil
 }
 
 // Provider is specified in the Environ interface.
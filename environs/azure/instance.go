@@ -49,19 +49,19 @@ func (azInstance *azureInstance) WaitDNSName() (string, error) {
 }
 
 // OpenPorts is specified in the Instance interface.
-func (azInstance *azureInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (azInstance *azureInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	// TODO: implement this.
 	return nil
 }
 
 // ClosePorts is specified in the Instance interface.
-func (azInstance *azureInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (azInstance *azureInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	// TODO: implement this.
 	return nil
 }
 
 // Ports is specified in the Instance interface.
-func (azInstance *azureInstance) Ports(machineId string) ([]instance.Port, error) {
+func (azInstance *azureInstance) Ports(machineId string) ([]instance.PortRange, error) {
 	// TODO: implement this.
-	return []instance.Port{}, nil
+	return []instance.PortRange{}, nil
 }
@@ -0,0 +1,311 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The backup package implements the pieces of "juju backup" and
+// "juju restore" that do not depend on talking to remote machines:
+// the shell scripts run on the state server and on other machines,
+// and the reading and writing of backup archives.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/utils"
+)
+
+// The names of the entries in a backup archive. The archive holds a
+// single directory, under which are stored a mongodump of the state
+// database, the state server's agent configuration and certificate,
+// the environment's CA certificate and key, and a manifest of the
+// files in the environment's provider storage.
+const (
+	rootDir         = "juju-backup"
+	dumpDir         = rootDir + "/dump/"
+	agentConfFile   = rootDir + "/agents/machine-0/agent.conf"
+	serverPEMFile   = rootDir + "/server.pem"
+	caCertFile      = rootDir + "/ca-cert.pem"
+	caKeyFile       = rootDir + "/ca-private-key.pem"
+	manifestFile    = rootDir + "/storage-manifest"
+	stateServerTag  = "machine-0"
+	stateServerJob  = "jujud-" + stateServerTag
+	stateDBJob      = "juju-db"
+	cloudInitMarker = "/var/lib/cloud/instance/boot-finished"
+)
+
+// BackupScript returns a shell script that, when run as root on the
+// state server, writes to its standard output an uncompressed tar
+// archive holding a dump of the state database and the state server's
+// own configuration. The state server's agent and database are stopped
+// while the dump is taken, so that it is consistent, and are restarted
+// however the script exits.
+func BackupScript(dataDir string) string {
+	agentDir := path.Join(dataDir, "agents", stateServerTag)
+	return fmt.Sprintf(`
+set -e
+tmpdir=$(mktemp -d)
+trap 'initctl start %[1]s || true; initctl start %[2]s || true; rm -rf "$tmpdir"' EXIT
+mkdir -p "$tmpdir"/%[3]s
+initctl stop %[2]s || true
+initctl stop %[1]s || true
+mongodump --dbpath %[4]s --out "$tmpdir"/%[5]s >&2
+cp %[6]s "$tmpdir"/%[3]s/
+cp %[7]s "$tmpdir"/%[8]s
+tar cf - -C "$tmpdir" %[9]s
+`[1:],
+		stateDBJob, stateServerJob,
+		path.Dir(agentConfFile),
+		utils.ShQuote(path.Join(dataDir, "db")),
+		dumpDir,
+		utils.ShQuote(path.Join(agentDir, "agent.conf")),
+		utils.ShQuote(path.Join(dataDir, "server.pem")),
+		serverPEMFile,
+		rootDir,
+	)
+}
+
+// UpdateAddressesScript returns a shell script that changes the state
+// and API server addresses held in the configuration of every agent
+// under dataDir so that they refer to the given host.
+func UpdateAddressesScript(dataDir, host string, statePort, apiPort int) string {
+	return fmt.Sprintf(`
+for conf in %s/agents/*/agent.conf; do
+    [ -f "$conf" ] || continue
+    sed -i -e 's/^\(\s*- \)[^ ]*:%d$/\1%s:%d/' -e 's/^\(\s*- \)[^ ]*:%d$/\1%s:%d/' "$conf"
+done
+`[1:],
+		utils.ShQuote(dataDir),
+		statePort, host, statePort,
+		apiPort, host, apiPort,
+	)
+}
+
+// UpdateAgentsScript returns a shell script that, when run as root on
+// a machine in the environment, points all the agents on the machine
+// at the state server running on the given host, and restarts them.
+func UpdateAgentsScript(dataDir, host string, statePort, apiPort int) string {
+	return "set -e\n" + UpdateAddressesScript(dataDir, host, statePort, apiPort) + `
+for job in /etc/init/jujud-*.conf; do
+    [ -f "$job" ] || continue
+    job=$(basename "$job" .conf)
+    initctl stop "$job" || true
+    initctl start "$job"
+done
+`[1:]
+}
+
+// RestoreScript returns a shell script that, when run as root on a
+// freshly bootstrapped state server running on the given host, reads a
+// backup archive from its standard input and replaces the new state
+// server's database and configuration with those from the archive.
+// The state database is left running, but the state server's agent is
+// not started, so that the restored state can be fixed up first.
+func RestoreScript(dataDir, host string, statePort, apiPort int) string {
+	agentDir := path.Join(dataDir, "agents", stateServerTag)
+	return fmt.Sprintf(`
+set -e
+tmpdir=$(mktemp -d)
+trap 'rm -rf "$tmpdir"' EXIT
+while [ ! -f %[1]s ]; do
+    sleep 5
+done
+tar xzf - -C "$tmpdir"
+initctl stop %[2]s || true
+initctl stop %[3]s || true
+mongorestore --drop --dbpath %[4]s "$tmpdir"/%[5]s >&2
+cp "$tmpdir"/%[6]s %[7]s
+cp "$tmpdir"/%[8]s %[9]s
+%[10]sinitctl start %[3]s
+`[1:],
+		cloudInitMarker,
+		stateServerJob, stateDBJob,
+		utils.ShQuote(path.Join(dataDir, "db")),
+		dumpDir,
+		agentConfFile, utils.ShQuote(path.Join(agentDir, "agent.conf")),
+		serverPEMFile, utils.ShQuote(path.Join(dataDir, "server.pem")),
+		UpdateAddressesScript(dataDir, host, statePort, apiPort),
+	)
+}
+
+// StartStateServerScript returns a shell script that starts the state
+// server's agent, once the state restored by the script returned from
+// RestoreScript has been fixed up.
+func StartStateServerScript() string {
+	return "initctl start " + stateServerJob + "\n"
+}
+
+// WriteArchive writes a gzipped backup archive to w. The archive holds
+// the entries of the uncompressed tar stream read from remote, as
+// produced by the script returned from BackupScript, together with the
+// environment's CA certificate and key taken from cfg, and a manifest
+// of the files in the environment's storage.
+func WriteArchive(w io.Writer, remote io.Reader, cfg *config.Config, storage environs.StorageReader) (err error) {
+	defer utils.ErrorContextf(&err, "cannot write backup archive")
+	caCert, ok := cfg.CACert()
+	if !ok {
+		return fmt.Errorf("environment configuration has no ca-cert")
+	}
+	caKey, ok := cfg.CAPrivateKey()
+	if !ok {
+		return fmt.Errorf("environment configuration has no ca-private-key")
+	}
+	names, err := storage.List("")
+	if err != nil {
+		return fmt.Errorf("cannot list environment storage: %v", err)
+	}
+	var manifest bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintln(&manifest, name)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	tr := tar.NewReader(remote)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read state server backup: %v", err)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	for _, f := range []struct {
+		name string
+		data []byte
+		mode int64
+	}{
+		{caCertFile, caCert, 0644},
+		{caKeyFile, caKey, 0600},
+		{manifestFile, manifest.Bytes(), 0644},
+	} {
+		hdr := &tar.Header{
+			Name:     f.name,
+			Mode:     f.mode,
+			Size:     int64(len(f.data)),
+			ModTime:  now,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// Archive holds the information from a backup archive that is needed
+// to restore it.
+type Archive struct {
+	// CACert and CAKey hold the environment's CA certificate and key.
+	CACert []byte
+	CAKey  []byte
+
+	// Manifest lists the files that were in the environment's
+	// storage when the backup was made.
+	Manifest []string
+}
+
+// ReadArchive reads the gzipped backup archive from r, checks that it
+// holds everything needed to restore the state server, and returns
+// the information held in it.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backup archive: %v", err)
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	var archive Archive
+	var manifest []byte
+	found := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read backup archive: %v", err)
+		}
+		name := path.Clean(hdr.Name)
+		var data *[]byte
+		switch {
+		case name == caCertFile:
+			data = &archive.CACert
+		case name == caKeyFile:
+			data = &archive.CAKey
+		case name == manifestFile:
+			data = &manifest
+		case strings.HasPrefix(name, dumpDir):
+			name = dumpDir
+		}
+		found[name] = true
+		if data != nil {
+			if *data, err = ioutil.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("cannot read backup archive: %v", err)
+			}
+		}
+	}
+	for _, name := range []string{dumpDir, agentConfFile, serverPEMFile, caCertFile, caKeyFile, manifestFile} {
+		if !found[name] {
+			return nil, fmt.Errorf("backup archive has no %q", strings.TrimSuffix(name, "/"))
+		}
+	}
+	for _, name := range strings.Split(string(manifest), "\n") {
+		if name != "" {
+			archive.Manifest = append(archive.Manifest, name)
+		}
+	}
+	return &archive, nil
+}
+
+// CheckEnviron returns an error if the archive was not made from the
+// environment with the given configuration.
+func (a *Archive) CheckEnviron(cfg *config.Config) error {
+	caCert, _ := cfg.CACert()
+	if !bytes.Equal(caCert, a.CACert) {
+		return fmt.Errorf("backup archive was made from a different environment (CA certificates differ)")
+	}
+	return nil
+}
+
+// MissingFiles returns the files listed in the archive's storage
+// manifest that are no longer found in the given storage.
+func (a *Archive) MissingFiles(storage environs.StorageReader) ([]string, error) {
+	names, err := storage.List("")
+	if err != nil {
+		return nil, fmt.Errorf("cannot list environment storage: %v", err)
+	}
+	present := make(map[string]bool)
+	for _, name := range names {
+		present[name] = true
+	}
+	var missing []string
+	for _, name := range a.Manifest {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/environs/backup"
+	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/testing"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type backupSuite struct {
+	testing.LoggingSuite
+}
+
+var _ = Suite(&backupSuite{})
+
+// fakeStorage is a read-only environs.StorageReader holding the
+// given files.
+type fakeStorage map[string]string
+
+func (s fakeStorage) Get(name string) (io.ReadCloser, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, errors.NotFoundf("file %q", name)
+	}
+	return ioutil.NopCloser(strings.NewReader(data)), nil
+}
+
+func (s fakeStorage) List(prefix string) ([]string, error) {
+	var names []string
+	for name := range s {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s fakeStorage) URL(name string) (string, error) {
+	return "http://example.com/" + name, nil
+}
+
+func newConfig(c *C) *config.Config {
+	cfg, err := config.New(map[string]interface{}{
+		"name":            "backup",
+		"type":            "dummy",
+		"authorized-keys": "i-am-a-key",
+		"ca-cert":         testing.CACert,
+		"ca-private-key":  testing.CAKey,
+	})
+	c.Assert(err, IsNil)
+	return cfg
+}
+
+// remoteTar returns an uncompressed tar stream holding the given
+// files, as produced on the state server by BackupScript.
+func remoteTar(c *C, files ...string) io.Reader {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range files {
+		data := "contents of " + name
+		err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(data)),
+		})
+		c.Assert(err, IsNil)
+		_, err = tw.Write([]byte(data))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	return &buf
+}
+
+var stateServerFiles = []string{
+	"juju-backup/dump/juju/machines.bson",
+	"juju-backup/agents/machine-0/agent.conf",
+	"juju-backup/server.pem",
+}
+
+func (s *backupSuite) TestWriteReadArchive(c *C) {
+	cfg := newConfig(c)
+	storage := fakeStorage{
+		"provider-state":           "state",
+		"tools/juju-1.16.0.tgz":    "tools",
+		"charms/local_3a_wp-1.zip": "charm",
+	}
+	var buf bytes.Buffer
+	err := backup.WriteArchive(&buf, remoteTar(c, stateServerFiles...), cfg, storage)
+	c.Assert(err, IsNil)
+
+	// The archive holds the state server's files along with
+	// those added locally.
+	gzr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, IsNil)
+	tr := tar.NewReader(gzr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		names = append(names, hdr.Name)
+	}
+	c.Assert(names, DeepEquals, append(stateServerFiles,
+		"juju-backup/ca-cert.pem",
+		"juju-backup/ca-private-key.pem",
+		"juju-backup/storage-manifest",
+	))
+
+	archive, err := backup.ReadArchive(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, IsNil)
+	c.Assert(string(archive.CACert), Equals, testing.CACert)
+	c.Assert(string(archive.CAKey), Equals, testing.CAKey)
+	c.Assert(archive.Manifest, DeepEquals, []string{
+		"charms/local_3a_wp-1.zip",
+		"provider-state",
+		"tools/juju-1.16.0.tgz",
+	})
+
+	err = archive.CheckEnviron(cfg)
+	c.Assert(err, IsNil)
+	archive.CACert = []byte("some other certificate")
+	err = archive.CheckEnviron(cfg)
+	c.Assert(err, ErrorMatches, `backup archive was made from a different environment \(CA certificates differ\)`)
+
+	delete(storage, "tools/juju-1.16.0.tgz")
+	missing, err := archive.MissingFiles(storage)
+	c.Assert(err, IsNil)
+	c.Assert(missing, DeepEquals, []string{"tools/juju-1.16.0.tgz"})
+}
+
+func (s *backupSuite) TestWriteArchiveBadRemote(c *C) {
+	var buf bytes.Buffer
+	remote := strings.NewReader("this is not a tar archive, but it is long enough to need a header block")
+	err := backup.WriteArchive(&buf, remote, newConfig(c), fakeStorage{})
+	c.Assert(err, ErrorMatches, "cannot write backup archive: cannot read state server backup: .*")
+}
+
+func (s *backupSuite) TestReadArchiveMissingEntries(c *C) {
+	for i, missing := range stateServerFiles {
+		c.Logf("test %d: without %s", i, missing)
+		var files []string
+		for _, name := range stateServerFiles {
+			if name != missing {
+				files = append(files, name)
+			}
+		}
+		var buf bytes.Buffer
+		err := backup.WriteArchive(&buf, remoteTar(c, files...), newConfig(c), fakeStorage{})
+		c.Assert(err, IsNil)
+		_, err = backup.ReadArchive(&buf)
+		expect := missing
+		if strings.HasPrefix(missing, "juju-backup/dump/") {
+			expect = "juju-backup/dump"
+		}
+		c.Assert(err, ErrorMatches, fmt.Sprintf("backup archive has no %q", expect))
+	}
+}
+
+func (s *backupSuite) TestReadArchiveNotGzipped(c *C) {
+	_, err := backup.ReadArchive(strings.NewReader("hello"))
+	c.Assert(err, ErrorMatches, "cannot read backup archive: .*")
+}
+
+var agentConf = `
+tag: unit-wordpress-0
+stateinfo:
+  addrs:
+  - 10.0.0.1:37017
+  - old.example.com:37017
+apiinfo:
+  addrs:
+  - 10.0.0.1:17070
+oldpassword: foo
+`[1:]
+
+func (s *backupSuite) TestUpdateAddressesScript(c *C) {
+	dataDir := c.MkDir()
+	confFile := filepath.Join(dataDir, "agents", "unit-wordpress-0", "agent.conf")
+	err := os.MkdirAll(filepath.Dir(confFile), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(confFile, []byte(agentConf), 0644)
+	c.Assert(err, IsNil)
+
+	script := backup.UpdateAddressesScript(dataDir, "new.example.com", 37017, 17070)
+	out, err := exec.Command("bash", "-c", script).CombinedOutput()
+	c.Assert(err, IsNil, Commentf("output: %s", out))
+
+	data, err := ioutil.ReadFile(confFile)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `
+tag: unit-wordpress-0
+stateinfo:
+  addrs:
+  - new.example.com:37017
+  - new.example.com:37017
+apiinfo:
+  addrs:
+  - new.example.com:17070
+oldpassword: foo
+`[1:])
+}
+
+func (s *backupSuite) TestScriptsQuoteDataDir(c *C) {
+	dataDir := "/var/lib/juju dir"
+	for i, script := range []string{
+		backup.BackupScript(dataDir),
+		backup.RestoreScript(dataDir, "host", 37017, 17070),
+		backup.UpdateAgentsScript(dataDir, "host", 37017, 17070),
+	} {
+		c.Logf("test %d", i)
+		c.Assert(script, Matches, `(?s).*'/var/lib/juju dir.*`)
+		c.Assert(script, Not(Matches), `(?s).*[^']/var/lib/juju dir.*`)
+	}
+}
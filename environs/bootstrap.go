@@ -70,7 +70,7 @@ func VerifyBootstrapInit(env Environ, shortAttempt utils.AttemptStrategy) error
 func BootstrapUsers(st *state.State, cfg *config.Config, passwordHash string) error {
 	logger.Debugf("adding admin user")
 	// Set up initial authentication.
-	u, err := st.AddUser("admin", "")
+	u, err := st.AddUser(state.AdminUser, "")
 	if err != nil {
 		return err
 	}
@@ -44,6 +44,10 @@ const (
 
 	// DefaultApiPort is the default port the API server is listening on.
 	DefaultApiPort int = 17070
+
+	// DefaultUpdateStatusInterval is how often the update-status hook
+	// runs when the environment does not say otherwise.
+	DefaultUpdateStatusInterval = 5 * time.Minute
 )
 
 // Config holds an immutable environment configuration.
@@ -158,6 +162,20 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	// Check that the hook timeout parses ok if set.
+	if v, ok := cfg.m["hook-timeout"].(string); ok && v != "" {
+		if d, err := time.ParseDuration(v); err != nil || d < 0 {
+			return fmt.Errorf("invalid hook-timeout in environment configuration: %q", v)
+		}
+	}
+
+	// Check that the update-status hook interval parses ok if set.
+	if v, ok := cfg.m["update-status-interval"].(string); ok && v != "" {
+		if d, err := time.ParseDuration(v); err != nil || d < 0 {
+			return fmt.Errorf("invalid update-status-interval in environment configuration: %q", v)
+		}
+	}
+
 	// Check firewall mode.
 	firewallMode := cfg.FirewallMode()
 	switch firewallMode {
@@ -341,6 +359,28 @@ func (c *Config) SSLHostnameVerification() bool {
 	return c.m["ssl-hostname-verification"].(bool)
 }
 
+// HookTimeout returns the time a charm hook may run before the unit
+// agent kills it. Zero means that hooks may run forever. Charms may
+// set their own timeout in their metadata.
+func (c *Config) HookTimeout() time.Duration {
+	// The value was checked by Validate.
+	d, _ := time.ParseDuration(c.asString("hook-timeout"))
+	return d
+}
+
+// UpdateStatusInterval returns how often the unit agent runs the
+// update-status hook while the unit is idle. Zero means that the hook
+// is never run.
+func (c *Config) UpdateStatusInterval() time.Duration {
+	v, ok := c.m["update-status-interval"].(string)
+	if !ok || v == "" {
+		return DefaultUpdateStatusInterval
+	}
+	// The value was checked by Validate.
+	d, _ := time.ParseDuration(v)
+	return d
+}
+
 // UnknownAttrs returns a copy of the raw configuration attributes
 // that are supposedly specific to the environment type. They could
 // also be wrong attributes, though. Only the specific environment
@@ -388,6 +428,8 @@ var fields = schema.Fields{
 	"ssl-hostname-verification": schema.Bool(),
 	"state-port":                schema.ForceInt(),
 	"api-port":                  schema.ForceInt(),
+	"hook-timeout":              schema.String(),
+	"update-status-interval":    schema.String(),
 }
 
 var defaults = schema.Defaults{
@@ -405,6 +447,8 @@ var defaults = schema.Defaults{
 	"ssl-hostname-verification": true,
 	"state-port":                schema.Omit,
 	"api-port":                  schema.Omit,
+	"hook-timeout":              schema.Omit,
+	"update-status-interval":    schema.Omit,
 }
 
 var checker = schema.FieldMap(fields, defaults)
@@ -345,6 +345,43 @@ var configTests = []configTest{
 			"api-port": "illegal",
 		},
 		err: `api-port: expected number, got "illegal"`,
+	}, {
+		about: "Explicit hook timeout",
+		attrs: attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"hook-timeout": "30m",
+		},
+	}, {
+		about: "Invalid hook timeout",
+		attrs: attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"hook-timeout": "forever",
+		},
+		err: `invalid hook-timeout in environment configuration: "forever"`,
+	}, {
+		about: "Explicit update-status interval",
+		attrs: attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"update-status-interval": "1m",
+		},
+	}, {
+		about: "Disabled update-status interval",
+		attrs: attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"update-status-interval": "0",
+		},
+	}, {
+		about: "Invalid update-status interval",
+		attrs: attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"update-status-interval": "-1m",
+		},
+		err: `invalid update-status-interval in environment configuration: "-1m"`,
 	},
 }
 
@@ -562,6 +599,22 @@ func (test configTest) check(c *gc.C, home *testing.FakeHome) {
 	if v, ok := test.attrs["ssl-hostname-verification"]; ok {
 		c.Assert(cfg.SSLHostnameVerification(), gc.Equals, v)
 	}
+
+	if v, _ := test.attrs["hook-timeout"].(string); v != "" {
+		timeout, err := time.ParseDuration(v)
+		c.Assert(err, gc.IsNil)
+		c.Assert(cfg.HookTimeout(), gc.Equals, timeout)
+	} else {
+		c.Assert(cfg.HookTimeout(), gc.Equals, time.Duration(0))
+	}
+
+	if v, _ := test.attrs["update-status-interval"].(string); v != "" {
+		interval, err := time.ParseDuration(v)
+		c.Assert(err, gc.IsNil)
+		c.Assert(cfg.UpdateStatusInterval(), gc.Equals, interval)
+	} else {
+		c.Assert(cfg.UpdateStatusInterval(), gc.Equals, config.DefaultUpdateStatusInterval)
+	}
 }
 
 func (*ConfigSuite) TestConfigAttrs(c *gc.C) {
@@ -91,14 +91,14 @@ type OpOpenPorts struct {
 	Env        string
 	MachineId  string
 	InstanceId instance.Id
-	Ports      []instance.Port
+	Ports      []instance.PortRange
 }
 
 type OpClosePorts struct {
 	Env        string
 	MachineId  string
 	InstanceId instance.Id
-	Ports      []instance.Port
+	Ports      []instance.PortRange
 }
 
 type OpPutFile struct {
@@ -126,7 +126,7 @@ type environState struct {
 	mu            sync.Mutex
 	maxId         int // maximum instance id allocated so far.
 	insts         map[instance.Id]*dummyInstance
-	globalPorts   map[instance.Port]bool
+	globalPorts   map[instance.PortRange]bool
 	firewallMode  config.FirewallMode
 	bootstrapped  bool
 	storageDelay  time.Duration
@@ -238,7 +238,7 @@ func newState(name string, ops chan<- Operation, fwmode config.FirewallMode) *en
 		name:         name,
 		ops:          ops,
 		insts:        make(map[instance.Id]*dummyInstance),
-		globalPorts:  make(map[instance.Port]bool),
+		globalPorts:  make(map[instance.PortRange]bool),
 		firewallMode: fwmode,
 	}
 	s.storage = newStorage(s, "/"+name+"/private")
@@ -575,7 +575,7 @@ func (e *environ) StartInstance(machineId, machineNonce string, series string, c
 	i := &dummyInstance{
 		state:     e.state,
 		id:        instance.Id(fmt.Sprintf("%s-%d", e.state.name, e.state.maxId)),
-		ports:     make(map[instance.Port]bool),
+		ports:     make(map[instance.PortRange]bool),
 		machineId: machineId,
 		series:    series,
 	}
@@ -676,7 +676,7 @@ func (e *environ) AllInstances() ([]instance.Instance, error) {
 	return insts, nil
 }
 
-func (e *environ) OpenPorts(ports []instance.Port) error {
+func (e *environ) OpenPorts(ports []instance.PortRange) error {
 	e.state.mu.Lock()
 	defer e.state.mu.Unlock()
 	if e.state.firewallMode != config.FwGlobal {
@@ -689,7 +689,7 @@ func (e *environ) OpenPorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []instance.Port) error {
+func (e *environ) ClosePorts(ports []instance.PortRange) error {
 	e.state.mu.Lock()
 	defer e.state.mu.Unlock()
 	if e.state.firewallMode != config.FwGlobal {
@@ -702,7 +702,7 @@ func (e *environ) ClosePorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() (ports []instance.Port, err error) {
+func (e *environ) Ports() (ports []instance.PortRange, err error) {
 	e.state.mu.Lock()
 	defer e.state.mu.Unlock()
 	if e.state.firewallMode != config.FwGlobal {
@@ -722,7 +722,7 @@ func (*environ) Provider() environs.EnvironProvider {
 
 type dummyInstance struct {
 	state     *environState
-	ports     map[instance.Port]bool
+	ports     map[instance.PortRange]bool
 	id        instance.Id
 	machineId string
 	series    string
@@ -746,7 +746,7 @@ func (inst *dummyInstance) WaitDNSName() (string, error) {
 	return environs.WaitDNSName(inst)
 }
 
-func (inst *dummyInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (inst *dummyInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	defer delay()
 	log.Infof("environs/dummy: openPorts %s, %#v", machineId, ports)
 	if inst.state.firewallMode != config.FwInstance {
@@ -770,7 +770,7 @@ func (inst *dummyInstance) OpenPorts(machineId string, ports []instance.Port) er
 	return nil
 }
 
-func (inst *dummyInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (inst *dummyInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	defer delay()
 	if inst.state.firewallMode != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode for closing ports on instance: %q",
@@ -793,7 +793,7 @@ func (inst *dummyInstance) ClosePorts(machineId string, ports []instance.Port) e
 	return nil
 }
 
-func (inst *dummyInstance) Ports(machineId string) (ports []instance.Port, err error) {
+func (inst *dummyInstance) Ports(machineId string) (ports []instance.PortRange, err error) {
 	defer delay()
 	if inst.state.firewallMode != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode for retrieving ports from instance: %q",
@@ -54,6 +54,7 @@ type environ struct {
 }
 
 var _ environs.Environ = (*environ)(nil)
+var _ environs.InstanceTypeMatcher = (*environ)(nil)
 
 type ec2Instance struct {
 	e *environ
@@ -313,6 +314,30 @@ func (e *environ) StartInstance(machineId, machineNonce string, series string, c
 
 const ebsStorage = "ebs"
 
+// MatchInstanceType implements environs.InstanceTypeMatcher.
+func (e *environ) MatchInstanceType(series string, cons constraints.Value) (*instances.InstanceType, error) {
+	possibleTools, err := environs.FindInstanceTools(e, series, cons)
+	if err != nil {
+		return nil, err
+	}
+	storage := ebsStorage
+	baseURLs, err := e.getImageBaseURLs()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := findInstanceSpec(baseURLs, &instances.InstanceConstraint{
+		Region:      e.ecfg().region(),
+		Series:      series,
+		Arches:      possibleTools.Arches(),
+		Constraints: cons,
+		Storage:     &storage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spec.InstanceType, nil
+}
+
 // internalStartInstance is the internal version of StartInstance, used by
 // Bootstrap as well as via StartInstance itself.
 // TODO(bug 1199847): Some of this work can be shared between providers.
@@ -521,20 +546,20 @@ func (e *environ) Destroy(ensureInsts []instance.Instance) error {
 	return e.Storage().RemoveAll()
 }
 
-func portsToIPPerms(ports []instance.Port) []ec2.IPPerm {
+func portsToIPPerms(ports []instance.PortRange) []ec2.IPPerm {
 	ipPerms := make([]ec2.IPPerm, len(ports))
 	for i, p := range ports {
 		ipPerms[i] = ec2.IPPerm{
 			Protocol:  p.Protocol,
-			FromPort:  p.Number,
-			ToPort:    p.Number,
+			FromPort:  p.FromPort,
+			ToPort:    p.ToPort,
 			SourceIPs: []string{"0.0.0.0/0"},
 		}
 	}
 	return ipPerms
 }
 
-func (e *environ) openPortsInGroup(name string, ports []instance.Port) error {
+func (e *environ) openPortsInGroup(name string, ports []instance.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
@@ -564,7 +589,7 @@ func (e *environ) openPortsInGroup(name string, ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) closePortsInGroup(name string, ports []instance.Port) error {
+func (e *environ) closePortsInGroup(name string, ports []instance.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
@@ -579,7 +604,7 @@ func (e *environ) closePortsInGroup(name string, ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) portsInGroup(name string) (ports []instance.Port, err error) {
+func (e *environ) portsInGroup(name string) (ports []instance.PortRange, err error) {
 	g := ec2.SecurityGroup{Name: name}
 	resp, err := e.ec2().SecurityGroups([]ec2.SecurityGroup{g}, nil)
 	if err != nil {
@@ -593,18 +618,17 @@ func (e *environ) portsInGroup(name string) (ports []instance.Port, err error) {
 			log.Warningf("environs/ec2: unexpected IP permission found: %v", p)
 			continue
 		}
-		for i := p.FromPort; i <= p.ToPort; i++ {
-			ports = append(ports, instance.Port{
-				Protocol: p.Protocol,
-				Number:   i,
-			})
-		}
+		ports = append(ports, instance.PortRange{
+			Protocol: p.Protocol,
+			FromPort: p.FromPort,
+			ToPort:   p.ToPort,
+		})
 	}
 	state.SortPorts(ports)
 	return ports, nil
 }
 
-func (e *environ) OpenPorts(ports []instance.Port) error {
+func (e *environ) OpenPorts(ports []instance.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode for opening ports on environment: %q",
 			e.Config().FirewallMode())
@@ -616,7 +640,7 @@ func (e *environ) OpenPorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []instance.Port) error {
+func (e *environ) ClosePorts(ports []instance.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode for closing ports on environment: %q",
 			e.Config().FirewallMode())
@@ -628,7 +652,7 @@ func (e *environ) ClosePorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() ([]instance.Port, error) {
+func (e *environ) Ports() ([]instance.PortRange, error) {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode for retrieving ports from environment: %q",
 			e.Config().FirewallMode())
@@ -687,7 +711,7 @@ func (e *environ) jujuGroupName() string {
 	return "juju-" + e.name
 }
 
-func (inst *ec2Instance) OpenPorts(machineId string, ports []instance.Port) error {
+func (inst *ec2Instance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode for opening ports on instance: %q",
 			inst.e.Config().FirewallMode())
@@ -700,7 +724,7 @@ func (inst *ec2Instance) OpenPorts(machineId string, ports []instance.Port) erro
 	return nil
 }
 
-func (inst *ec2Instance) ClosePorts(machineId string, ports []instance.Port) error {
+func (inst *ec2Instance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode for closing ports on instance: %q",
 			inst.e.Config().FirewallMode())
@@ -713,7 +737,7 @@ func (inst *ec2Instance) ClosePorts(machineId string, ports []instance.Port) err
 	return nil
 }
 
-func (inst *ec2Instance) Ports(machineId string) ([]instance.Port, error) {
+func (inst *ec2Instance) Ports(machineId string) ([]instance.PortRange, error) {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode for retrieving ports from instance: %q",
 			inst.e.Config().FirewallMode())
@@ -9,6 +9,7 @@ import (
 
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/environs/instances"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
@@ -180,21 +181,30 @@ type Environ interface {
 	// same remote environment may become invalid
 	Destroy(insts []instance.Instance) error
 
-	// OpenPorts opens the given ports for the whole environment.
+	// OpenPorts opens the given port ranges for the whole environment.
 	// Must only be used if the environment was setup with the
 	// FwGlobal firewall mode.
-	OpenPorts(ports []instance.Port) error
+	OpenPorts(ports []instance.PortRange) error
 
-	// ClosePorts closes the given ports for the whole environment.
+	// ClosePorts closes the given port ranges for the whole environment.
 	// Must only be used if the environment was setup with the
 	// FwGlobal firewall mode.
-	ClosePorts(ports []instance.Port) error
+	ClosePorts(ports []instance.PortRange) error
 
-	// Ports returns the ports opened for the whole environment.
+	// Ports returns the port ranges opened for the whole environment.
 	// Must only be used if the environment was setup with the
 	// FwGlobal firewall mode.
-	Ports() ([]instance.Port, error)
+	Ports() ([]instance.PortRange, error)
 
 	// Provider returns the EnvironProvider that created this Environ.
 	Provider() EnvironProvider
 }
+
+// InstanceTypeMatcher is implemented by environments that choose an
+// instance type when starting an instance.
+type InstanceTypeMatcher interface {
+	// MatchInstanceType returns the instance type that StartInstance
+	// would choose for an instance of the given series with the given
+	// constraints, without starting anything.
+	MatchInstanceType(series string, cons constraints.Value) (*instances.InstanceType, error)
+}
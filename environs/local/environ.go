@@ -388,17 +388,17 @@ func (env *localEnviron) Destroy(insts []instance.Instance) error {
 }
 
 // OpenPorts is specified in the Environ interface.
-func (env *localEnviron) OpenPorts(ports []instance.Port) error {
+func (env *localEnviron) OpenPorts(ports []instance.PortRange) error {
 	return fmt.Errorf("open ports not implemented")
 }
 
 // ClosePorts is specified in the Environ interface.
-func (env *localEnviron) ClosePorts(ports []instance.Port) error {
+func (env *localEnviron) ClosePorts(ports []instance.PortRange) error {
 	return fmt.Errorf("close ports not implemented")
 }
 
 // Ports is specified in the Environ interface.
-func (env *localEnviron) Ports() ([]instance.Port, error) {
+func (env *localEnviron) Ports() ([]instance.PortRange, error) {
 	return nil, nil
 }
 
@@ -48,19 +48,19 @@ func (inst *localInstance) WaitDNSName() (string, error) {
 }
 
 // OpenPorts implements instance.Instance.OpenPorts.
-func (inst *localInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (inst *localInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	logger.Infof("OpenPorts called for %s:%v", machineId, ports)
 	return nil
 }
 
 // ClosePorts implements instance.Instance.ClosePorts.
-func (inst *localInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (inst *localInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	logger.Infof("ClosePorts called for %s:%v", machineId, ports)
 	return nil
 }
 
 // Ports implements instance.Instance.Ports.
-func (inst *localInstance) Ports(machineId string) ([]instance.Port, error) {
+func (inst *localInstance) Ports(machineId string) ([]instance.PortRange, error) {
 	return nil, nil
 }
 
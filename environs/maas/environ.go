@@ -452,19 +452,19 @@ func (environ *maasEnviron) Destroy(ensureInsts []instance.Instance) error {
 }
 
 // MAAS does not do firewalling so these port methods do nothing.
-func (*maasEnviron) OpenPorts([]instance.Port) error {
+func (*maasEnviron) OpenPorts([]instance.PortRange) error {
 	logger.Debugf("unimplemented OpenPorts() called")
 	return nil
 }
 
-func (*maasEnviron) ClosePorts([]instance.Port) error {
+func (*maasEnviron) ClosePorts([]instance.PortRange) error {
 	logger.Debugf("unimplemented ClosePorts() called")
 	return nil
 }
 
-func (*maasEnviron) Ports() ([]instance.Port, error) {
+func (*maasEnviron) Ports() ([]instance.PortRange, error) {
 	logger.Debugf("unimplemented Ports() called")
-	return []instance.Port{}, nil
+	return []instance.PortRange{}, nil
 }
 
 func (*maasEnviron) Provider() environs.EnvironProvider {
@@ -53,17 +53,17 @@ func (mi *maasInstance) WaitDNSName() (string, error) {
 }
 
 // MAAS does not do firewalling so these port methods do nothing.
-func (mi *maasInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (mi *maasInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	logger.Debugf("unimplemented OpenPorts() called")
 	return nil
 }
 
-func (mi *maasInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (mi *maasInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	logger.Debugf("unimplemented ClosePorts() called")
 	return nil
 }
 
-func (mi *maasInstance) Ports(machineId string) ([]instance.Port, error) {
+func (mi *maasInstance) Ports(machineId string) ([]instance.PortRange, error) {
 	logger.Debugf("unimplemented Ports() called")
-	return []instance.Port{}, nil
+	return []instance.PortRange{}, nil
 }
@@ -0,0 +1,16 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+// SetRunSSHCommand replaces the function used to run scripts on hosts
+// over SSH, and returns a function that restores it.
+func SetRunSSHCommand(f func(host, script string) (string, error)) (restore func()) {
+	old := runSSHCommand
+	runSSHCommand = f
+	return func() {
+		runSSHCommand = old
+	}
+}
+
+var UbuntuArch = ubuntuArch
@@ -0,0 +1,221 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The manual package implements the provisioning of machines that
+// were not started by the environment's provider, but are reachable
+// over SSH.
+package manual
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/cloudinit"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/utils"
+	"launchpad.net/juju-core/worker/provisioner"
+)
+
+var logger = loggo.GetLogger("juju.environs.manual")
+
+// NoncePrefix prefixes the instance ids and provisioning nonces of
+// manually provisioned machines.
+const NoncePrefix = "manual:"
+
+// IsManualNonce returns whether the given provisioning nonce is that
+// of a manually provisioned machine.
+func IsManualNonce(nonce string) bool {
+	return strings.HasPrefix(nonce, NoncePrefix)
+}
+
+// ProvisionMachineArgs holds the arguments to ProvisionMachine.
+type ProvisionMachineArgs struct {
+	// Host is the machine to provision, in the form [user@]host.
+	Host string
+
+	// State is the state of the environment the machine is added to.
+	State *state.State
+
+	// Environ is the environment the machine is added to. It is used
+	// to find tools and the addresses of the state servers.
+	Environ environs.Environ
+
+	// Constraints are recorded as the constraints of the machine.
+	Constraints constraints.Value
+}
+
+// ProvisionMachine connects to the host over SSH, detects its series
+// and hardware, and adds it to the environment as a new machine. The
+// tools and the machine agent are installed on the host by running
+// the script that would otherwise have been given to cloud-init.
+func ProvisionMachine(args ProvisionMachineArgs) (_ *state.Machine, err error) {
+	defer utils.ErrorContextf(&err, "cannot provision machine %q", args.Host)
+	hostname := args.Host
+	if at := strings.Index(hostname, "@"); at != -1 {
+		hostname = hostname[at+1:]
+	}
+	if hostname == "" {
+		return nil, fmt.Errorf("no host specified")
+	}
+
+	logger.Infof("detecting series and hardware of %s", args.Host)
+	info, err := detectHost(args.Host)
+	if err != nil {
+		return nil, err
+	}
+	if info.provisioned {
+		return nil, fmt.Errorf("machine is already provisioned")
+	}
+	possibleTools, err := environs.FindInstanceTools(args.Environ, info.series, constraints.Value{Arch: info.hc.Arch})
+	if err != nil {
+		return nil, err
+	}
+
+	instanceId := instance.Id(NoncePrefix + hostname)
+	nonce := NoncePrefix + hostname
+	machine, err := args.State.InjectMachineWithNonce(info.series, args.Constraints, instanceId, nonce, info.hc, state.JobHostUnits)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Do not leave behind a machine that will never have an agent.
+		if err := machine.EnsureDead(); err != nil {
+			logger.Errorf("cannot ensure machine %v is dead: %v", machine, err)
+		} else if err := machine.Remove(); err != nil {
+			logger.Errorf("cannot remove machine %v: %v", machine, err)
+		}
+	}()
+
+	auth, err := provisioner.NewSimpleAuthenticator(args.Environ)
+	if err != nil {
+		return nil, err
+	}
+	stateInfo, apiInfo, err := auth.SetupAuthentication(machine)
+	if err != nil {
+		return nil, err
+	}
+	mcfg := environs.NewMachineConfig(machine.Id(), nonce, stateInfo, apiInfo)
+	mcfg.Tools = possibleTools[0]
+	if err := environs.FinishMachineConfig(mcfg, args.Environ.Config(), args.Constraints); err != nil {
+		return nil, err
+	}
+	cloudcfg, err := cloudinit.New(mcfg)
+	if err != nil {
+		return nil, err
+	}
+	script, err := cloudcfg.RenderScript()
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("installing machine agent on %s", args.Host)
+	if _, err := runSSHCommand(args.Host, script); err != nil {
+		return nil, fmt.Errorf("cannot install machine agent: %v", err)
+	}
+	return machine, nil
+}
+
+// hostInfo holds what is detected about a host before it is
+// provisioned.
+type hostInfo struct {
+	series      string
+	hc          instance.HardwareCharacteristics
+	provisioned bool
+}
+
+// detectionScript prints the series, architecture, memory in kB and
+// number of cores of a host, one per line, followed by "provisioned"
+// if a machine agent is already installed on it.
+const detectionScript = `set -e
+lsb_release -cs
+uname -m
+grep '^MemTotal:' /proc/meminfo | awk '{print $2}'
+grep -c '^processor' /proc/cpuinfo
+if ls /etc/init/jujud-machine-*.conf >/dev/null 2>&1; then echo provisioned; fi
+`
+
+// detectHost runs detectionScript on the host.
+func detectHost(host string) (*hostInfo, error) {
+	out, err := runSSHCommand(host, detectionScript)
+	if err != nil {
+		return nil, fmt.Errorf("cannot detect series and hardware: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 4 {
+		return nil, fmt.Errorf("cannot detect series and hardware: unexpected output %q", out)
+	}
+	info := &hostInfo{
+		series:      strings.TrimSpace(lines[0]),
+		provisioned: len(lines) > 4 && strings.TrimSpace(lines[4]) == "provisioned",
+	}
+	arch := ubuntuArch(strings.TrimSpace(lines[1]))
+	info.hc.Arch = &arch
+	memKB, err := strconv.ParseUint(strings.TrimSpace(lines[2]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse memory size %q", lines[2])
+	}
+	mem := memKB / 1024
+	info.hc.Mem = &mem
+	cores, err := strconv.ParseUint(strings.TrimSpace(lines[3]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse number of cores %q", lines[3])
+	}
+	info.hc.CpuCores = &cores
+	return info, nil
+}
+
+// ubuntuArch returns the Ubuntu architecture name of the machine
+// architecture reported by uname.
+func ubuntuArch(arch string) string {
+	switch {
+	case arch == "x86_64":
+		return "amd64"
+	case len(arch) == 4 && arch[0] == 'i' && strings.HasSuffix(arch, "86"):
+		return "i386"
+	case strings.HasPrefix(arch, "arm"):
+		return "arm"
+	}
+	return arch
+}
+
+// runSSHCommand runs the given script as root on the host, which is
+// of the form [user@]host, and returns its standard output. The host
+// key is checked against the user's known hosts; the key of a host
+// that is not yet known is accepted and added to them.
+var runSSHCommand = func(host, script string) (string, error) {
+	cmd := exec.Command("ssh",
+		"-o", "StrictHostKeyChecking accept-new",
+		"-o", "PasswordAuthentication no",
+		host,
+		"sudo", "/bin/bash",
+	)
+	cmd.Stdin = strings.NewReader(script)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Report the last line of output, which usually holds the
+		// error. The script that installs the machine agent sends
+		// its error output to standard output.
+		output := strings.TrimSpace(stderr.String())
+		if output == "" {
+			output = strings.TrimSpace(stdout.String())
+		}
+		lines := strings.Split(output, "\n")
+		if last := lines[len(lines)-1]; last != "" {
+			return "", fmt.Errorf("%v (%s)", err, last)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual_test
+
+import (
+	"fmt"
+	"strings"
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs/manual"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/version"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type ProvisionerSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = Suite(&ProvisionerSuite{})
+
+// fakeSSH records the scripts run on hosts, and answers the detection
+// script with the given output.
+type fakeSSH struct {
+	detected  string
+	installed []string
+	hosts     []string
+	err       error
+}
+
+func (f *fakeSSH) run(host, script string) (string, error) {
+	f.hosts = append(f.hosts, host)
+	if strings.Contains(script, "lsb_release") {
+		return f.detected, nil
+	}
+	f.installed = append(f.installed, script)
+	return "", f.err
+}
+
+func detectionOutput(extra string) string {
+	return fmt.Sprintf("%s\n%s\n2097152\n4\n%s", version.Current.Series, version.Current.Arch, extra)
+}
+
+func (s *ProvisionerSuite) TestProvisionMachine(c *C) {
+	ssh := &fakeSSH{detected: detectionOutput("")}
+	defer manual.SetRunSSHCommand(ssh.run)()
+
+	m, err := manual.ProvisionMachine(manual.ProvisionMachineArgs{
+		Host:        "ubuntu@10.0.0.1",
+		State:       s.State,
+		Environ:     s.Conn.Environ,
+		Constraints: constraints.MustParse("mem=1G"),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(ssh.hosts, DeepEquals, []string{"ubuntu@10.0.0.1", "ubuntu@10.0.0.1"})
+	c.Assert(ssh.installed, HasLen, 1)
+	c.Assert(ssh.installed[0], Matches, "(?s)#!/bin/bash\n.*jujud.*")
+	c.Assert(ssh.installed[0], Matches, "(?s).*manual:10.0.0.1.*")
+	// The environment's authorized keys are installed too.
+	c.Assert(ssh.installed[0], Matches, `(?s).*\nprintf '%s\\n' .* >> "\$home/\.ssh/authorized_keys"\n.*`)
+
+	c.Assert(m.Series(), Equals, version.Current.Series)
+	c.Assert(m.Jobs(), DeepEquals, []state.MachineJob{state.JobHostUnits})
+	instanceId, err := m.InstanceId()
+	c.Assert(err, IsNil)
+	c.Assert(instanceId, Equals, instance.Id("manual:10.0.0.1"))
+	c.Assert(m.CheckProvisioned("manual:10.0.0.1"), Equals, true)
+	hc, err := m.HardwareCharacteristics()
+	c.Assert(err, IsNil)
+	c.Assert(*hc.Arch, Equals, version.Current.Arch)
+	c.Assert(*hc.Mem, Equals, uint64(2048))
+	c.Assert(*hc.CpuCores, Equals, uint64(4))
+	cons, err := m.Constraints()
+	c.Assert(err, IsNil)
+	c.Assert(cons, DeepEquals, constraints.MustParse("mem=1G"))
+}
+
+func (s *ProvisionerSuite) TestProvisionMachineAlreadyProvisioned(c *C) {
+	ssh := &fakeSSH{detected: detectionOutput("provisioned")}
+	defer manual.SetRunSSHCommand(ssh.run)()
+
+	_, err := manual.ProvisionMachine(manual.ProvisionMachineArgs{
+		Host:    "10.0.0.1",
+		State:   s.State,
+		Environ: s.Conn.Environ,
+	})
+	c.Assert(err, ErrorMatches, `cannot provision machine "10.0.0.1": machine is already provisioned`)
+	c.Assert(ssh.installed, HasLen, 0)
+	machines, err := s.State.AllMachines()
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 0)
+}
+
+func (s *ProvisionerSuite) TestProvisionMachineDetectionFailure(c *C) {
+	ssh := &fakeSSH{detected: "precise\n"}
+	defer manual.SetRunSSHCommand(ssh.run)()
+
+	_, err := manual.ProvisionMachine(manual.ProvisionMachineArgs{
+		Host:    "10.0.0.1",
+		State:   s.State,
+		Environ: s.Conn.Environ,
+	})
+	c.Assert(err, ErrorMatches, `cannot provision machine "10.0.0.1": cannot detect series and hardware: unexpected output "precise\\n"`)
+}
+
+func (s *ProvisionerSuite) TestProvisionMachineInstallFailure(c *C) {
+	ssh := &fakeSSH{
+		detected: detectionOutput(""),
+		err:      fmt.Errorf("exit status 1"),
+	}
+	defer manual.SetRunSSHCommand(ssh.run)()
+
+	_, err := manual.ProvisionMachine(manual.ProvisionMachineArgs{
+		Host:    "10.0.0.1",
+		State:   s.State,
+		Environ: s.Conn.Environ,
+	})
+	c.Assert(err, ErrorMatches, `cannot provision machine "10.0.0.1": cannot install machine agent: exit status 1`)
+
+	// The machine added for the host has been removed.
+	machines, err := s.State.AllMachines()
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 0)
+}
+
+func (s *ProvisionerSuite) TestUbuntuArch(c *C) {
+	for uname, arch := range map[string]string{
+		"x86_64": "amd64",
+		"i686":   "i386",
+		"i386":   "i386",
+		"armv7l": "arm",
+		"ppc64":  "ppc64",
+	} {
+		c.Check(manual.UbuntuArch(uname), Equals, arch)
+	}
+}
+
+func (s *ProvisionerSuite) TestIsManualNonce(c *C) {
+	c.Assert(manual.IsManualNonce("manual:10.0.0.1"), Equals, true)
+	c.Assert(manual.IsManualNonce(state.BootstrapNonce), Equals, false)
+}
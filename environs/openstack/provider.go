@@ -206,6 +206,7 @@ type environ struct {
 }
 
 var _ environs.Environ = (*environ)(nil)
+var _ environs.InstanceTypeMatcher = (*environ)(nil)
 
 type openstackInstance struct {
 	*nova.ServerDetail
@@ -309,7 +310,7 @@ func (inst *openstackInstance) WaitDNSName() (string, error) {
 
 // TODO: following 30 lines nearly verbatim from environs/ec2
 
-func (inst *openstackInstance) OpenPorts(machineId string, ports []instance.Port) error {
+func (inst *openstackInstance) OpenPorts(machineId string, ports []instance.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode for opening ports on instance: %q",
 			inst.e.Config().FirewallMode())
@@ -322,7 +323,7 @@ func (inst *openstackInstance) OpenPorts(machineId string, ports []instance.Port
 	return nil
 }
 
-func (inst *openstackInstance) ClosePorts(machineId string, ports []instance.Port) error {
+func (inst *openstackInstance) ClosePorts(machineId string, ports []instance.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode for closing ports on instance: %q",
 			inst.e.Config().FirewallMode())
@@ -335,7 +336,7 @@ func (inst *openstackInstance) ClosePorts(machineId string, ports []instance.Por
 	return nil
 }
 
-func (inst *openstackInstance) Ports(machineId string) ([]instance.Port, error) {
+func (inst *openstackInstance) Ports(machineId string) ([]instance.PortRange, error) {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode for retrieving ports from instance: %q",
 			inst.e.Config().FirewallMode())
@@ -636,6 +637,24 @@ func (e *environ) assignPublicIP(fip *nova.FloatingIP, serverId string) (err err
 	return err
 }
 
+// MatchInstanceType implements environs.InstanceTypeMatcher.
+func (e *environ) MatchInstanceType(series string, cons constraints.Value) (*instances.InstanceType, error) {
+	possibleTools, err := environs.FindInstanceTools(e, series, cons)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := findInstanceSpec(e, &instances.InstanceConstraint{
+		Region:      e.ecfg().region(),
+		Series:      series,
+		Arches:      possibleTools.Arches(),
+		Constraints: cons,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spec.InstanceType, nil
+}
+
 // internalStartInstance is the internal version of StartInstance, used by
 // Bootstrap as well as via StartInstance itself.
 // machineConfig will be filled out with further details, but should contain
@@ -881,7 +900,7 @@ func (e *environ) machinesFilter() *nova.Filter {
 	return filter
 }
 
-func (e *environ) openPortsInGroup(name string, ports []instance.Port) error {
+func (e *environ) openPortsInGroup(name string, ports []instance.PortRange) error {
 	novaclient := e.nova()
 	group, err := novaclient.SecurityGroupByName(name)
 	if err != nil {
@@ -890,8 +909,8 @@ func (e *environ) openPortsInGroup(name string, ports []instance.Port) error {
 	for _, port := range ports {
 		_, err := novaclient.CreateSecurityGroupRule(nova.RuleInfo{
 			ParentGroupId: group.Id,
-			FromPort:      port.Number,
-			ToPort:        port.Number,
+			FromPort:      port.FromPort,
+			ToPort:        port.ToPort,
 			IPProtocol:    port.Protocol,
 			Cidr:          "0.0.0.0/0",
 		})
@@ -903,7 +922,7 @@ func (e *environ) openPortsInGroup(name string, ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) closePortsInGroup(name string, ports []instance.Port) error {
+func (e *environ) closePortsInGroup(name string, ports []instance.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
@@ -916,8 +935,8 @@ func (e *environ) closePortsInGroup(name string, ports []instance.Port) error {
 	for _, port := range ports {
 		for _, p := range (*group).Rules {
 			if p.IPProtocol == nil || *p.IPProtocol != port.Protocol ||
-				p.FromPort == nil || *p.FromPort != port.Number ||
-				p.ToPort == nil || *p.ToPort != port.Number {
+				p.FromPort == nil || *p.FromPort != port.FromPort ||
+				p.ToPort == nil || *p.ToPort != port.ToPort {
 				continue
 			}
 			err := novaclient.DeleteSecurityGroupRule(p.Id)
@@ -930,18 +949,17 @@ func (e *environ) closePortsInGroup(name string, ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) portsInGroup(name string) (ports []instance.Port, err error) {
+func (e *environ) portsInGroup(name string) (ports []instance.PortRange, err error) {
 	group, err := e.nova().SecurityGroupByName(name)
 	if err != nil {
 		return nil, err
 	}
 	for _, p := range (*group).Rules {
-		for i := *p.FromPort; i <= *p.ToPort; i++ {
-			ports = append(ports, instance.Port{
-				Protocol: *p.IPProtocol,
-				Number:   i,
-			})
-		}
+		ports = append(ports, instance.PortRange{
+			Protocol: *p.IPProtocol,
+			FromPort: *p.FromPort,
+			ToPort:   *p.ToPort,
+		})
 	}
 	state.SortPorts(ports)
 	return ports, nil
@@ -949,7 +967,7 @@ func (e *environ) portsInGroup(name string) (ports []instance.Port, err error) {
 
 // TODO: following 30 lines nearly verbatim from environs/ec2
 
-func (e *environ) OpenPorts(ports []instance.Port) error {
+func (e *environ) OpenPorts(ports []instance.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode for opening ports on environment: %q",
 			e.Config().FirewallMode())
@@ -961,7 +979,7 @@ func (e *environ) OpenPorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []instance.Port) error {
+func (e *environ) ClosePorts(ports []instance.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode for closing ports on environment: %q",
 			e.Config().FirewallMode())
@@ -973,7 +991,7 @@ func (e *environ) ClosePorts(ports []instance.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() ([]instance.Port, error) {
+func (e *environ) Ports() ([]instance.PortRange, error) {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode for retrieving ports from environment: %q",
 			e.Config().FirewallMode())
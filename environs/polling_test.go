@@ -56,11 +56,11 @@ func (inst *dnsNameFakeInstance) WaitDNSName() (string, error) {
 	return environs.WaitDNSName(inst)
 }
 
-func (*dnsNameFakeInstance) Addresses() ([]instance.Address, error)   { return nil, nil }
-func (*dnsNameFakeInstance) Id() instance.Id                          { return "" }
-func (*dnsNameFakeInstance) OpenPorts(string, []instance.Port) error  { return nil }
-func (*dnsNameFakeInstance) ClosePorts(string, []instance.Port) error { return nil }
-func (*dnsNameFakeInstance) Ports(string) ([]instance.Port, error)    { return nil, nil }
+func (*dnsNameFakeInstance) Addresses() ([]instance.Address, error)        { return nil, nil }
+func (*dnsNameFakeInstance) Id() instance.Id                               { return "" }
+func (*dnsNameFakeInstance) OpenPorts(string, []instance.PortRange) error  { return nil }
+func (*dnsNameFakeInstance) ClosePorts(string, []instance.PortRange) error { return nil }
+func (*dnsNameFakeInstance) Ports(string) ([]instance.PortRange, error)    { return nil, nil }
 
 func (pollingSuite) TestWaitDNSNameReturnsDNSNameIfAvailable(c *C) {
 	inst := dnsNameFakeInstance{name: "anansi"}
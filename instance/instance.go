@@ -17,14 +17,52 @@ var ErrNoDNSName = errors.New("DNS name not allocated")
 // instance (physical or virtual machine allocated in the provider).
 type Id string
 
-// Port identifies a network port number for a particular protocol.
-type Port struct {
+// PortRange identifies a range of network port numbers for a particular
+// protocol. A single port is a range whose first and last ports are the
+// same. ICMP has no ports, so both ports of an ICMP range are -1.
+type PortRange struct {
 	Protocol string
-	Number   int
+	FromPort int
+	ToPort   int
 }
 
-func (p Port) String() string {
-	return fmt.Sprintf("%d/%s", p.Number, p.Protocol)
+// String returns the range in the form used by the open-port hook
+// tool, such as "80/tcp", "10000-20000/udp" or "icmp".
+func (p PortRange) String() string {
+	if p.Protocol == "icmp" {
+		return p.Protocol
+	}
+	if p.FromPort == p.ToPort {
+		return fmt.Sprintf("%d/%s", p.FromPort, p.Protocol)
+	}
+	return fmt.Sprintf("%d-%d/%s", p.FromPort, p.ToPort, p.Protocol)
+}
+
+// Validate returns an error if the range does not hold a valid
+// protocol and port numbers.
+func (p PortRange) Validate() error {
+	switch p.Protocol {
+	case "tcp", "udp":
+		if p.FromPort < 1 || p.ToPort > 65535 || p.FromPort > p.ToPort {
+			return fmt.Errorf("invalid port range %d-%d/%s", p.FromPort, p.ToPort, p.Protocol)
+		}
+	case "icmp":
+		if p.FromPort != -1 || p.ToPort != -1 {
+			return fmt.Errorf("icmp does not have ports")
+		}
+	default:
+		return fmt.Errorf("invalid protocol %q", p.Protocol)
+	}
+	return nil
+}
+
+// ConflictsWith returns whether the two ranges share any port. ICMP
+// ranges never conflict.
+func (p PortRange) ConflictsWith(other PortRange) bool {
+	if p.Protocol != other.Protocol || p.Protocol == "icmp" {
+		return false
+	}
+	return p.FromPort <= other.ToPort && other.FromPort <= p.ToPort
 }
 
 // Instance represents the the realization of a machine in state.
@@ -48,18 +86,18 @@ type Instance interface {
 	// implementations now delegate to environs.WaitDNSName.
 	WaitDNSName() (string, error)
 
-	// OpenPorts opens the given ports on the instance, which
+	// OpenPorts opens the given port ranges on the instance, which
 	// should have been started with the given machine id.
-	OpenPorts(machineId string, ports []Port) error
+	OpenPorts(machineId string, ports []PortRange) error
 
-	// ClosePorts closes the given ports on the instance, which
+	// ClosePorts closes the given port ranges on the instance, which
 	// should have been started with the given machine id.
-	ClosePorts(machineId string, ports []Port) error
+	ClosePorts(machineId string, ports []PortRange) error
 
-	// Ports returns the set of ports open on the instance, which
+	// Ports returns the set of port ranges open on the instance, which
 	// should have been started with the given machine id.
-	// The ports are returned as sorted by state.SortPorts.
-	Ports(machineId string) ([]Port, error)
+	// The ranges are returned as sorted by state.SortPorts.
+	Ports(machineId string) ([]PortRange, error)
 }
 
 // HardwareCharacteristics represents the characteristics of the instance (if known).
@@ -4,7 +4,11 @@
 package instance_test
 
 import (
+	"encoding/json"
+
+	"labix.org/v2/mgo/bson"
 	. "launchpad.net/gocheck"
+
 	"launchpad.net/juju-core/instance"
 )
 
@@ -197,3 +201,113 @@ func (s *HardwareSuite) TestParseHardware(c *C) {
 		c.Assert(cons1, DeepEquals, hwc)
 	}
 }
+
+type PortRangeSuite struct{}
+
+var _ = Suite(&PortRangeSuite{})
+
+var portRangeTests = []struct {
+	ports instance.PortRange
+	str   string
+	err   string
+}{
+	{instance.PortRange{"tcp", 80, 80}, "80/tcp", ""},
+	{instance.PortRange{"udp", 10000, 20000}, "10000-20000/udp", ""},
+	{instance.PortRange{"icmp", -1, -1}, "icmp", ""},
+	{instance.PortRange{"tcp", 0, 0}, "0/tcp", "invalid port range 0-0/tcp"},
+	{instance.PortRange{"tcp", 1, 65536}, "1-65536/tcp", "invalid port range 1-65536/tcp"},
+	{instance.PortRange{"udp", 20, 10}, "20-10/udp", "invalid port range 20-10/udp"},
+	{instance.PortRange{"icmp", 8, 8}, "icmp", "icmp does not have ports"},
+	{instance.PortRange{"http", 80, 80}, "80/http", `invalid protocol "http"`},
+}
+
+func (s *PortRangeSuite) TestStringAndValidate(c *C) {
+	for i, t := range portRangeTests {
+		c.Logf("test %d: %#v", i, t.ports)
+		c.Check(t.ports.String(), Equals, t.str)
+		err := t.ports.Validate()
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+}
+
+var conflictsWithTests = []struct {
+	a, b     instance.PortRange
+	conflict bool
+}{
+	{instance.PortRange{"tcp", 80, 80}, instance.PortRange{"tcp", 80, 80}, true},
+	{instance.PortRange{"tcp", 80, 80}, instance.PortRange{"udp", 80, 80}, false},
+	{instance.PortRange{"tcp", 80, 80}, instance.PortRange{"tcp", 81, 90}, false},
+	{instance.PortRange{"tcp", 80, 85}, instance.PortRange{"tcp", 85, 90}, true},
+	{instance.PortRange{"udp", 10000, 20000}, instance.PortRange{"udp", 15000, 15000}, true},
+	{instance.PortRange{"icmp", -1, -1}, instance.PortRange{"icmp", -1, -1}, false},
+}
+
+func (s *PortRangeSuite) TestConflictsWith(c *C) {
+	for i, t := range conflictsWithTests {
+		c.Logf("test %d: %v %v", i, t.a, t.b)
+		c.Check(t.a.ConflictsWith(t.b), Equals, t.conflict)
+		c.Check(t.b.ConflictsWith(t.a), Equals, t.conflict)
+	}
+}
+
+var unmarshalPortRangeTests = []struct {
+	about string
+	bson  bson.M
+	json  string
+	ports instance.PortRange
+}{{
+	about: "range",
+	bson:  bson.M{"protocol": "udp", "fromport": 10000, "toport": 20000},
+	json:  `{"Protocol": "udp", "FromPort": 10000, "ToPort": 20000}`,
+	ports: instance.PortRange{"udp", 10000, 20000},
+}, {
+	about: "icmp",
+	bson:  bson.M{"protocol": "icmp", "fromport": -1, "toport": -1},
+	json:  `{"Protocol": "icmp", "FromPort": -1, "ToPort": -1}`,
+	ports: instance.PortRange{"icmp", -1, -1},
+}, {
+	about: "single port stored before ranges were supported",
+	bson:  bson.M{"protocol": "tcp", "number": 80},
+	json:  `{"Protocol": "tcp", "Number": 80}`,
+	ports: instance.PortRange{"tcp", 80, 80},
+}}
+
+func (s *PortRangeSuite) TestUnmarshal(c *C) {
+	for i, t := range unmarshalPortRangeTests {
+		c.Logf("test %d: %s", i, t.about)
+		data, err := bson.Marshal(t.bson)
+		c.Assert(err, IsNil)
+		var fromBSON instance.PortRange
+		err = bson.Unmarshal(data, &fromBSON)
+		c.Assert(err, IsNil)
+		c.Check(fromBSON, Equals, t.ports)
+
+		var fromJSON instance.PortRange
+		err = json.Unmarshal([]byte(t.json), &fromJSON)
+		c.Assert(err, IsNil)
+		c.Check(fromJSON, Equals, t.ports)
+	}
+}
+
+func (s *PortRangeSuite) TestMarshalRoundTrip(c *C) {
+	ports := instance.PortRange{"tcp", 8000, 8080}
+	data, err := bson.Marshal(bson.M{"ports": ports})
+	c.Assert(err, IsNil)
+	var doc struct{ Ports instance.PortRange }
+	err = bson.Unmarshal(data, &doc)
+	c.Assert(err, IsNil)
+	c.Assert(doc.Ports, Equals, ports)
+
+	// The first port is also written as Number for older API clients.
+	data, err = json.Marshal(ports)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{"Protocol":"tcp","Number":8000,"FromPort":8000,"ToPort":8080}`)
+	var fromJSON instance.PortRange
+	err = json.Unmarshal(data, &fromJSON)
+	c.Assert(err, IsNil)
+	c.Assert(fromJSON, Equals, ports)
+}
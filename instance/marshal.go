@@ -0,0 +1,54 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instance
+
+import (
+	"encoding/json"
+
+	"labix.org/v2/mgo/bson"
+)
+
+// portRangeDoc is the serialized form of a PortRange. Before port
+// ranges were supported a single port was stored as Number, so
+// Number is still read when FromPort and ToPort are absent, and it
+// is written to JSON so that older API clients see the first port.
+type portRangeDoc struct {
+	Protocol string
+	Number   int `bson:",omitempty"`
+	FromPort int
+	ToPort   int
+}
+
+func (doc portRangeDoc) portRange() PortRange {
+	if doc.FromPort == 0 && doc.ToPort == 0 && doc.Number != 0 {
+		return PortRange{doc.Protocol, doc.Number, doc.Number}
+	}
+	return PortRange{doc.Protocol, doc.FromPort, doc.ToPort}
+}
+
+// SetBSON updates the range with the data stored in the bson.Raw
+// parameter, which may hold a single port stored as Number.
+func (p *PortRange) SetBSON(raw bson.Raw) error {
+	var doc portRangeDoc
+	if err := raw.Unmarshal(&doc); err != nil {
+		return err
+	}
+	*p = doc.portRange()
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p PortRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(portRangeDoc{p.Protocol, p.FromPort, p.FromPort, p.ToPort})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PortRange) UnmarshalJSON(data []byte) error {
+	var doc portRangeDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*p = doc.portRange()
+	return nil
+}
@@ -44,6 +44,16 @@ func NewAPIConn(environ environs.Environ, dialOpts api.DialOpts) (*APIConn, erro
 	}, nil
 }
 
+// NewAPIConnFromName returns an APIConn pointing at the environName
+// environment, or the default environment if not specified.
+func NewAPIConnFromName(environName string) (*APIConn, error) {
+	environ, err := environs.NewFromName(environName)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPIConn(environ, api.DefaultDialOpts())
+}
+
 // Close terminates the connection to the environment and releases
 // any associated resources.
 func (c *APIConn) Close() error {
@@ -48,3 +48,13 @@ func (*NewAPIConnSuite) TestNewConn(c *C) {
 
 	c.Assert(conn.Close(), IsNil)
 }
+
+func (*NewAPIConnSuite) TestNewAPIConnFromName(c *C) {
+	defer coretesting.MakeSampleHome(c).Restore()
+	bootstrapEnv(c, "")
+	conn, err := juju.NewAPIConnFromName("")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	c.Assert(conn.Environ.Name(), Equals, coretesting.SampleEnvName)
+	c.Assert(conn.State, NotNil)
+}
@@ -0,0 +1,587 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
+)
+
+// bundleService holds what is needed to deploy a service in a bundle,
+// as gathered while checking the bundle.
+type bundleService struct {
+	name     string
+	spec     *bundle.Service
+	curl     *charm.URL
+	meta     *charm.Meta
+	settings charm.Settings
+	cons     constraints.Value
+	exists   bool
+}
+
+// DeployBundle deploys the services and relations in the given bundle,
+// taking charms from repo. The whole bundle is checked against the
+// charms' metadata and the current state of the environment before any
+// changes are made. Services and relations in the bundle that already
+// exist are left alone, as long as they match the bundle; existing
+// services are not scaled to the bundle's number of units.
+func (conn *Conn) DeployBundle(b *bundle.Bundle, repo charm.Repository) error {
+	if err := b.Verify(); err != nil {
+		return err
+	}
+	cfg, err := conn.State.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	var services []*bundleService
+	metas := make(map[string]*charm.Meta)
+	for _, name := range b.ServiceNames() {
+		bs, err := conn.checkBundleService(name, b.Services[name], repo, cfg.DefaultSeries())
+		if err != nil {
+			return fmt.Errorf("cannot deploy service %q: %v", name, err)
+		}
+		if !bs.exists {
+			metas[name] = bs.meta
+		}
+		services = append(services, bs)
+	}
+	var relations [][]state.Endpoint
+	seen := make(map[string]bool)
+	for _, rel := range b.Relations {
+		eps, err := conn.State.InferEndpointsWithCharms(rel, metas)
+		if err != nil {
+			return fmt.Errorf("cannot add relation %q: %v", strings.Join(rel, " "), err)
+		}
+		key := endpointsKey(eps)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		_, err = conn.State.EndpointsRelation(eps...)
+		if err == nil {
+			log.Infof("juju: relation %q already exists", key)
+			continue
+		} else if !errors.IsNotFoundError(err) {
+			return err
+		}
+		relations = append(relations, eps)
+	}
+
+	// Everything has been checked, so make the changes.
+	for _, bs := range services {
+		if bs.exists {
+			log.Infof("juju: service %q already exists", bs.name)
+			continue
+		}
+		ch, err := conn.PutCharm(bs.curl, repo, false)
+		if err != nil {
+			return err
+		}
+		svc, err := conn.DeployService(DeployServiceParams{
+			ServiceName:    bs.name,
+			Charm:          ch,
+			ConfigSettings: bs.settings,
+			Constraints:    bs.cons,
+			NumUnits:       bs.spec.NumUnits,
+		})
+		if err != nil {
+			return err
+		}
+		if bs.spec.Expose {
+			if err := svc.SetExposed(); err != nil {
+				return err
+			}
+		}
+		if len(bs.spec.Annotations) > 0 {
+			if err := svc.SetAnnotations(bs.spec.Annotations); err != nil {
+				return err
+			}
+		}
+	}
+	for _, eps := range relations {
+		if _, err := conn.State.AddRelation(eps...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBundleService checks that the service with the given name and
+// bundle specification can be deployed, or that it already exists
+// with the same charm and settings.
+func (conn *Conn) checkBundleService(name string, spec *bundle.Service, repo charm.Repository, series string) (*bundleService, error) {
+	cons, err := constraints.Parse(spec.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	bs := &bundleService{
+		name: name,
+		spec: spec,
+		cons: cons,
+	}
+	svc, err := conn.State.Service(name)
+	if err == nil {
+		bs.exists = true
+		return bs, checkExistingService(svc, bs)
+	} else if !errors.IsNotFoundError(err) {
+		return nil, err
+	}
+	curl, err := charm.InferURL(spec.Charm, series)
+	if err != nil {
+		return nil, err
+	}
+	if curl.Revision == -1 {
+		rev, err := repo.Latest(curl)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get latest charm revision: %v", err)
+		}
+		curl = curl.WithRevision(rev)
+	}
+	ch, err := repo.Get(curl)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get charm: %v", err)
+	}
+	bs.curl = curl
+	bs.meta = ch.Meta()
+	if bs.settings, err = parseBundleOptions(ch.Config(), name, spec.Options); err != nil {
+		return nil, err
+	}
+	if bs.meta.Subordinate {
+		if spec.NumUnits != 0 {
+			return nil, fmt.Errorf("subordinate service must be deployed without units")
+		}
+		if cons != (constraints.Value{}) {
+			return nil, fmt.Errorf("subordinate service must be deployed without constraints")
+		}
+	}
+	return bs, nil
+}
+
+// checkExistingService returns an error if the existing service svc
+// does not match the bundle service bs.
+func checkExistingService(svc *state.Service, bs *bundleService) error {
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return err
+	}
+	bs.meta = ch.Meta()
+	curl, err := charm.InferURL(bs.spec.Charm, ch.URL().Series)
+	if err != nil {
+		return err
+	}
+	have := ch.URL()
+	if curl.Revision == -1 {
+		have = have.WithRevision(-1)
+	}
+	if *have != *curl {
+		return fmt.Errorf("service already exists with charm %q", ch.URL())
+	}
+	settings, err := parseBundleOptions(ch.Config(), bs.name, bs.spec.Options)
+	if err != nil {
+		return err
+	}
+	current, err := svc.ConfigSettings()
+	if err != nil {
+		return err
+	}
+	if current, err = ch.Config().ValidateSettings(current); err != nil {
+		return err
+	}
+	settings = nonDefaultSettings(ch.Config(), settings)
+	current = nonDefaultSettings(ch.Config(), current)
+	if !reflect.DeepEqual(settings, current) {
+		return fmt.Errorf("service already exists with different configuration")
+	}
+	if !bs.meta.Subordinate {
+		cons, err := svc.Constraints()
+		if err != nil {
+			return err
+		}
+		if cons.String() != bs.cons.String() {
+			return fmt.Errorf("service already exists with constraints %q", cons)
+		}
+	}
+	if svc.IsExposed() != bs.spec.Expose {
+		if svc.IsExposed() {
+			return fmt.Errorf("service already exists and is exposed")
+		}
+		return fmt.Errorf("service already exists and is not exposed")
+	}
+	return nil
+}
+
+// parseBundleOptions returns the charm settings held in the options
+// of the named service in a bundle. As with "juju set", string values
+// are parsed according to the type of the corresponding option, and
+// empty values are ignored.
+func parseBundleOptions(config *charm.Config, name string, options map[string]interface{}) (charm.Settings, error) {
+	if len(options) == 0 {
+		return charm.Settings{}, nil
+	}
+	data, err := goyaml.Marshal(map[string]interface{}{name: options})
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.ParseSettingsYAML(data, name)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range settings {
+		if value == nil {
+			delete(settings, name)
+		}
+	}
+	return settings, nil
+}
+
+// nonDefaultSettings returns the valid settings that are not
+// nil and differ from the default values in config.
+func nonDefaultSettings(config *charm.Config, settings charm.Settings) charm.Settings {
+	defaults := config.DefaultSettings()
+	out := make(charm.Settings)
+	for name, value := range config.FilterSettings(settings) {
+		if value != nil && !reflect.DeepEqual(value, defaults[name]) {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// endpointsKey returns a string identifying the relation between the
+// given endpoints, independent of their order.
+func endpointsKey(eps []state.Endpoint) string {
+	names := make([]string, len(eps))
+	for i, ep := range eps {
+		names[i] = ep.String()
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// ExportBundle returns a bundle that describes the services in the
+// environment and the relations between them, such that deploying the
+// bundle into an empty environment reproduces them. Only configuration
+// settings that differ from the charm defaults are included. If
+// redactSecrets is true, settings of options that appear to hold
+// secrets (see bundle.IsSecretOption) are left out, and their names
+// are returned in the form <service>:<option>.
+func (conn *Conn) ExportBundle(redactSecrets bool) (b *bundle.Bundle, redacted []string, err error) {
+	services, err := conn.State.AllServices()
+	if err != nil {
+		return nil, nil, err
+	}
+	b = &bundle.Bundle{
+		Services: make(map[string]*bundle.Service),
+	}
+	var relations []*state.Relation
+	seen := make(map[int]bool)
+	for _, svc := range services {
+		if svc.Life() != state.Alive {
+			continue
+		}
+		spec, svcRedacted, err := exportService(svc, redactSecrets)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot export service %q: %v", svc.Name(), err)
+		}
+		b.Services[svc.Name()] = spec
+		redacted = append(redacted, svcRedacted...)
+		rels, err := svc.Relations()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rel := range rels {
+			if !seen[rel.Id()] && rel.Life() == state.Alive {
+				seen[rel.Id()] = true
+				relations = append(relations, rel)
+			}
+		}
+	}
+	if len(b.Services) == 0 {
+		return nil, nil, fmt.Errorf("environment has no services")
+	}
+	for _, rel := range relations {
+		eps := rel.Endpoints()
+		if len(eps) != 2 {
+			// Peer relations are established automatically.
+			continue
+		}
+		if b.Services[eps[0].ServiceName] == nil || b.Services[eps[1].ServiceName] == nil {
+			continue
+		}
+		pair := []string{eps[0].String(), eps[1].String()}
+		sort.Strings(pair)
+		b.Relations = append(b.Relations, pair)
+	}
+	sort.Sort(relationSlice(b.Relations))
+	sort.Strings(redacted)
+	return b, redacted, nil
+}
+
+// exportService returns the bundle specification of the service svc,
+// and the names of any redacted settings.
+func exportService(svc *state.Service, redactSecrets bool) (*bundle.Service, []string, error) {
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return nil, nil, err
+	}
+	spec := &bundle.Service{
+		Charm:  ch.URL().String(),
+		Expose: svc.IsExposed(),
+	}
+	if !ch.Meta().Subordinate {
+		units, err := svc.AllUnits()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, unit := range units {
+			if unit.Life() == state.Alive {
+				spec.NumUnits++
+			}
+		}
+		cons, err := svc.Constraints()
+		if err != nil {
+			return nil, nil, err
+		}
+		spec.Constraints = cons.String()
+	}
+	settings, err := svc.ConfigSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+	var redacted []string
+	for name, value := range nonDefaultSettings(ch.Config(), settings) {
+		if redactSecrets && bundle.IsSecretOption(name) {
+			redacted = append(redacted, svc.Name()+":"+name)
+			continue
+		}
+		if spec.Options == nil {
+			spec.Options = make(map[string]interface{})
+		}
+		spec.Options[name] = value
+	}
+	annotations, err := svc.Annotations()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(annotations) > 0 {
+		spec.Annotations = annotations
+	}
+	return spec, redacted, nil
+}
+
+// relationSlice sorts bundle relations by their endpoints.
+type relationSlice [][]string
+
+func (s relationSlice) Len() int      { return len(s) }
+func (s relationSlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s relationSlice) Less(i, j int) bool {
+	return strings.Join(s[i], " ") < strings.Join(s[j], " ")
+}
+
+// DiffBundle returns the differences between the given bundle and the
+// services and relations in the environment. Charm URLs in the bundle
+// without a revision match any revision of the charm, and
+// configuration settings are compared after applying the charm
+// defaults. Relations involving services missing from the environment,
+// and relations whose endpoints cannot be inferred from the services'
+// charms, are reported as missing, as given in the bundle. If
+// redactSecrets is true, settings of options that appear to hold
+// secrets (see bundle.IsSecretOption) are not compared, so that their
+// values cannot be learned from the differences.
+func (conn *Conn) DiffBundle(b *bundle.Bundle, redactSecrets bool) (*bundle.Diff, error) {
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	services, err := conn.State.AllServices()
+	if err != nil {
+		return nil, err
+	}
+	diff := &bundle.Diff{}
+	live := make(map[string]*state.Service)
+	for _, svc := range services {
+		if svc.Life() != state.Alive {
+			continue
+		}
+		live[svc.Name()] = svc
+		if b.Services[svc.Name()] == nil {
+			diff.ExtraServices = append(diff.ExtraServices, svc.Name())
+		}
+	}
+	sort.Strings(diff.ExtraServices)
+	for _, name := range b.ServiceNames() {
+		svc := live[name]
+		if svc == nil {
+			diff.MissingServices = append(diff.MissingServices, name)
+			continue
+		}
+		sdiff, err := diffService(svc, b.Services[name], redactSecrets)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compare service %q: %v", name, err)
+		}
+		if sdiff != nil {
+			if diff.Services == nil {
+				diff.Services = make(map[string]*bundle.ServiceDiff)
+			}
+			diff.Services[name] = sdiff
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, rel := range b.Relations {
+		known := true
+		for _, ep := range rel {
+			if live[strings.SplitN(ep, ":", 2)[0]] == nil {
+				known = false
+			}
+		}
+		if !known {
+			diff.MissingRelations = append(diff.MissingRelations, rel)
+			continue
+		}
+		eps, err := conn.State.InferEndpoints(rel)
+		if err != nil {
+			// The relation cannot be established between the
+			// services as they are.
+			diff.MissingRelations = append(diff.MissingRelations, rel)
+			continue
+		}
+		key := endpointsKey(eps)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		_, err = conn.State.EndpointsRelation(eps...)
+		if errors.IsNotFoundError(err) {
+			diff.MissingRelations = append(diff.MissingRelations, strings.Split(key, " "))
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[int]bool)
+	for _, svc := range live {
+		rels, err := svc.Relations()
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range rels {
+			if seen[rel.Id()] || rel.Life() != state.Alive {
+				continue
+			}
+			seen[rel.Id()] = true
+			eps := rel.Endpoints()
+			if len(eps) != 2 {
+				// Peer relations are established automatically.
+				continue
+			}
+			if key := endpointsKey(eps); !wanted[key] {
+				diff.ExtraRelations = append(diff.ExtraRelations, strings.Split(key, " "))
+			}
+		}
+	}
+	sort.Sort(relationSlice(diff.MissingRelations))
+	sort.Sort(relationSlice(diff.ExtraRelations))
+	return diff, nil
+}
+
+// diffService returns the differences between the existing service svc
+// and its bundle specification, or nil if there are none. Secret
+// options are not compared if redactSecrets is true.
+func diffService(svc *state.Service, spec *bundle.Service, redactSecrets bool) (*bundle.ServiceDiff, error) {
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return nil, err
+	}
+	sdiff := &bundle.ServiceDiff{}
+	addOption := func(name string, want, got interface{}) {
+		if sdiff.Options == nil {
+			sdiff.Options = make(map[string]*bundle.ValueDiff)
+		}
+		sdiff.Options[name] = &bundle.ValueDiff{Bundle: want, Environment: got}
+	}
+	curl, err := charm.InferURL(spec.Charm, ch.URL().Series)
+	if err != nil {
+		return nil, err
+	}
+	have := ch.URL()
+	if curl.Revision == -1 {
+		have = have.WithRevision(-1)
+	}
+	if *have != *curl {
+		sdiff.Charm = &bundle.ValueDiff{Bundle: curl.String(), Environment: ch.URL().String()}
+	}
+
+	// Options unknown to the service's charm cannot be parsed, so
+	// they are reported as they are.
+	config := ch.Config()
+	options := make(map[string]interface{})
+	for name, value := range spec.Options {
+		if redactSecrets && bundle.IsSecretOption(name) {
+			continue
+		}
+		if _, ok := config.Options[name]; ok {
+			options[name] = value
+		} else {
+			addOption(name, value, nil)
+		}
+	}
+	settings, err := parseBundleOptions(config, svc.Name(), options)
+	if err != nil {
+		return nil, err
+	}
+	current, err := svc.ConfigSettings()
+	if err != nil {
+		return nil, err
+	}
+	if current, err = config.ValidateSettings(current); err != nil {
+		return nil, err
+	}
+	settings = nonDefaultSettings(config, settings)
+	current = nonDefaultSettings(config, current)
+	defaults := config.DefaultSettings()
+	for name := range config.Options {
+		if redactSecrets && bundle.IsSecretOption(name) {
+			continue
+		}
+		want, ok := settings[name]
+		if !ok {
+			want = defaults[name]
+		}
+		got, ok := current[name]
+		if !ok {
+			got = defaults[name]
+		}
+		if !reflect.DeepEqual(want, got) {
+			addOption(name, want, got)
+		}
+	}
+
+	if !ch.Meta().Subordinate {
+		want, err := constraints.Parse(spec.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		got, err := svc.Constraints()
+		if err != nil {
+			return nil, err
+		}
+		if want.String() != got.String() {
+			sdiff.Constraints = &bundle.ValueDiff{Bundle: want.String(), Environment: got.String()}
+		}
+	}
+	if sdiff.Charm == nil && sdiff.Options == nil && sdiff.Constraints == nil {
+		return nil, nil
+	}
+	return sdiff, nil
+}
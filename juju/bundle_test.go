@@ -0,0 +1,439 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type DeployBundleSuite struct {
+	testing.JujuConnSuite
+	repo        charm.Repository
+	oldCacheDir string
+}
+
+var _ = Suite(&DeployBundleSuite{})
+
+func (s *DeployBundleSuite) SetUpSuite(c *C) {
+	s.JujuConnSuite.SetUpSuite(c)
+	s.repo = &charm.LocalRepository{Path: coretesting.Charms.Path}
+	s.oldCacheDir, charm.CacheDir = charm.CacheDir, c.MkDir()
+}
+
+func (s *DeployBundleSuite) TearDownSuite(c *C) {
+	charm.CacheDir = s.oldCacheDir
+	s.JujuConnSuite.TearDownSuite(c)
+}
+
+const wordpressBundle = `
+services:
+  wordpress:
+    charm: local:series/wordpress
+    num_units: 2
+    options:
+      blog-title: Bundled
+    constraints: mem=2G
+    expose: true
+    annotations:
+      gui-x: "10"
+  mysql:
+    charm: local:series/mysql
+    num_units: 1
+  logging:
+    charm: local:series/logging
+relations:
+  - [wordpress, mysql]
+  - [wordpress, logging]
+  - ["mysql:server", "wordpress:db"]
+`
+
+func (s *DeployBundleSuite) deployBundle(c *C, yaml string) error {
+	b, err := bundle.Parse([]byte(yaml))
+	c.Assert(err, IsNil)
+	return s.Conn.DeployBundle(b, s.repo)
+}
+
+func (s *DeployBundleSuite) TestDeployBundle(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	curl, _ := wordpress.CharmURL()
+	c.Assert(curl.String(), Equals, "local:series/wordpress-3")
+	settings, err := wordpress.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"blog-title": "Bundled"})
+	cons, err := wordpress.Constraints()
+	c.Assert(err, IsNil)
+	c.Assert(cons.String(), Equals, "mem=2048M")
+	c.Assert(wordpress.IsExposed(), Equals, true)
+	annotations, err := wordpress.Annotations()
+	c.Assert(err, IsNil)
+	c.Assert(annotations, DeepEquals, map[string]string{"gui-x": "10"})
+	units, err := wordpress.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 2)
+
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, IsNil)
+	units, err = mysql.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 1)
+
+	rels, err := wordpress.Relations()
+	c.Assert(err, IsNil)
+	var keys []string
+	for _, rel := range rels {
+		keys = append(keys, rel.String())
+	}
+	sort.Strings(keys)
+	c.Assert(keys, DeepEquals, []string{
+		"logging:logging-directory wordpress:logging-dir",
+		"wordpress:db mysql:server",
+	})
+}
+
+func (s *DeployBundleSuite) TestDeployBundleTwice(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+	err = s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+
+	// The services have not been scaled again.
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	units, err := wordpress.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 2)
+}
+
+func (s *DeployBundleSuite) TestDeployBundleExistingDiffers(c *C) {
+	err := s.deployBundle(c, `
+services:
+  wordpress:
+    charm: local:series/wordpress
+    options:
+      blog-title: Bundled
+`)
+	c.Assert(err, IsNil)
+
+	for i, test := range []struct {
+		wordpress string
+		err       string
+	}{{
+		wordpress: "{charm: local:series/mysql, options: {blog-title: Bundled}}",
+		err:       `cannot deploy service "wordpress": service already exists with charm "local:series/wordpress-3"`,
+	}, {
+		wordpress: "{charm: local:series/wordpress}",
+		err:       `cannot deploy service "wordpress": service already exists with different configuration`,
+	}, {
+		wordpress: "{charm: local:series/wordpress, options: {blog-title: Other}}",
+		err:       `cannot deploy service "wordpress": service already exists with different configuration`,
+	}, {
+		wordpress: "{charm: local:series/wordpress, options: {blog-title: Bundled}, constraints: mem=1G}",
+		err:       `cannot deploy service "wordpress": service already exists with constraints ""`,
+	}, {
+		wordpress: "{charm: local:series/wordpress, options: {blog-title: Bundled}, expose: true}",
+		err:       `cannot deploy service "wordpress": service already exists and is not exposed`,
+	}} {
+		c.Logf("test %d: %s", i, test.wordpress)
+		err := s.deployBundle(c, "services: {mysql: {charm: local:series/mysql}, wordpress: "+test.wordpress+"}")
+		c.Assert(err, ErrorMatches, test.err)
+		// Nothing has been deployed.
+		_, err = s.State.Service("mysql")
+		c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	}
+}
+
+var deployBundleErrorTests = []struct {
+	about string
+	yaml  string
+	err   string
+}{{
+	about: "unknown charm",
+	yaml:  "services: {foo: {charm: local:series/nonexistent}}",
+	err:   `cannot deploy service "foo": cannot get latest charm revision: charm not found in ".*": local:series/nonexistent`,
+}, {
+	about: "bad option",
+	yaml:  "services: {foo: {charm: local:series/dummy, options: {skill-level: lots}}}",
+	err:   `cannot deploy service "foo": option "skill-level" expected int, got "lots"`,
+}, {
+	about: "unknown option",
+	yaml:  "services: {foo: {charm: local:series/dummy, options: {colour: blue}}}",
+	err:   `cannot deploy service "foo": unknown option "colour"`,
+}, {
+	about: "subordinate with units",
+	yaml:  "services: {foo: {charm: local:series/logging, num_units: 1}}",
+	err:   `cannot deploy service "foo": subordinate service must be deployed without units`,
+}, {
+	about: "subordinate with constraints",
+	yaml:  "services: {foo: {charm: local:series/logging, constraints: mem=1G}}",
+	err:   `cannot deploy service "foo": subordinate service must be deployed without constraints`,
+}, {
+	about: "relation to unknown service",
+	yaml:  "services: {foo: {charm: local:series/wordpress}}\nrelations: [[foo, bar]]",
+	err:   `cannot add relation "foo bar": service "bar" not found`,
+}, {
+	about: "impossible relation",
+	yaml:  "services: {foo: {charm: local:series/wordpress}, bar: {charm: local:series/riak}}\nrelations: [[foo, bar]]",
+	err:   `cannot add relation "foo bar": no relations found`,
+}, {
+	about: "unknown relation name",
+	yaml:  "services: {foo: {charm: local:series/wordpress}, bar: {charm: local:series/mysql}}\nrelations: [[foo:nope, bar]]",
+	err:   `cannot add relation "foo:nope bar": service "foo" has no "nope" relation`,
+}}
+
+func (s *DeployBundleSuite) TestDeployBundleErrors(c *C) {
+	for i, test := range deployBundleErrorTests {
+		c.Logf("test %d: %s", i, test.about)
+		err := s.deployBundle(c, test.yaml)
+		c.Check(err, ErrorMatches, test.err)
+		services, err := s.State.AllServices()
+		c.Assert(err, IsNil)
+		c.Assert(services, HasLen, 0)
+	}
+}
+
+func (s *DeployBundleSuite) TestDeployBundleExistingService(c *C) {
+	// Services in the bundle may be related to services
+	// already in the environment.
+	_, err := s.State.AddService("db", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	err = s.deployBundle(c, "services: {wp: {charm: local:series/wordpress}}\nrelations: [[wp, db]]")
+	c.Assert(err, IsNil)
+	_, err = s.State.KeyRelation("wp:db db:server")
+	c.Assert(err, IsNil)
+}
+
+func (s *DeployBundleSuite) TestExportBundle(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+	// A setting with the default value is not exported.
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	err = dummy.UpdateConfigSettings(charm.Settings{"title": "My Title", "skill-level": int64(9)})
+	c.Assert(err, IsNil)
+	// Dying units are not counted.
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, IsNil)
+	unit, err := mysql.AddUnit()
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	err = unit.Destroy()
+	c.Assert(err, IsNil)
+	err = unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(unit.Life(), Equals, state.Dying)
+
+	b, redacted, err := s.Conn.ExportBundle(false)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, HasLen, 0)
+	c.Assert(b, DeepEquals, &bundle.Bundle{
+		Services: map[string]*bundle.Service{
+			"wordpress": {
+				Charm:       "local:series/wordpress-3",
+				NumUnits:    2,
+				Options:     map[string]interface{}{"blog-title": "Bundled"},
+				Constraints: "mem=2048M",
+				Expose:      true,
+				Annotations: map[string]string{"gui-x": "10"},
+			},
+			"mysql": {
+				Charm:    "local:series/mysql-1",
+				NumUnits: 1,
+			},
+			"logging": {
+				Charm: "local:series/logging-1",
+			},
+			"dummy": {
+				Charm:   "local:series/dummy-1",
+				Options: map[string]interface{}{"skill-level": int64(9)},
+			},
+		},
+		Relations: [][]string{
+			{"logging:logging-directory", "wordpress:logging-dir"},
+			{"mysql:server", "wordpress:db"},
+		},
+	})
+
+	// The exported bundle matches the environment.
+	err = s.Conn.DeployBundle(b, s.repo)
+	c.Assert(err, IsNil)
+}
+
+// addSecretService adds a service named "dummy" whose charm has an
+// option that holds a secret, and sets it.
+func (s *DeployBundleSuite) addSecretService(c *C) {
+	repoPath := c.MkDir()
+	dir := coretesting.Charms.ClonedDirPath(filepath.Join(repoPath, "series"), "dummy")
+	err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+options:
+  title: {default: My Title, description: A title., type: string}
+  admin-password: {description: The admin password., type: string}
+`), 0644)
+	c.Assert(err, IsNil)
+	repo := &charm.LocalRepository{Path: repoPath}
+	ch, err := s.Conn.PutCharm(charm.MustParseURL("local:series/dummy"), repo, false)
+	c.Assert(err, IsNil)
+	svc, err := s.State.AddService("dummy", ch)
+	c.Assert(err, IsNil)
+	err = svc.UpdateConfigSettings(charm.Settings{"title": "Secrets", "admin-password": "sekrit"})
+	c.Assert(err, IsNil)
+}
+
+func (s *DeployBundleSuite) TestExportBundleRedactSecrets(c *C) {
+	s.addSecretService(c)
+	b, redacted, err := s.Conn.ExportBundle(false)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, HasLen, 0)
+	c.Assert(b.Services["dummy"].Options, DeepEquals, map[string]interface{}{
+		"title":          "Secrets",
+		"admin-password": "sekrit",
+	})
+
+	b, redacted, err = s.Conn.ExportBundle(true)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, DeepEquals, []string{"dummy:admin-password"})
+	c.Assert(b.Services["dummy"].Options, DeepEquals, map[string]interface{}{
+		"title": "Secrets",
+	})
+}
+
+func (s *DeployBundleSuite) TestExportBundleNoServices(c *C) {
+	_, _, err := s.Conn.ExportBundle(false)
+	c.Assert(err, ErrorMatches, "environment has no services")
+}
+
+func (s *DeployBundleSuite) TestDiffBundle(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+
+	b, err := bundle.Parse([]byte(wordpressBundle))
+	c.Assert(err, IsNil)
+	diff, err := s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Empty(), Equals, true)
+
+	b, err = bundle.Parse([]byte(`
+services:
+  wordpress:
+    charm: local:series/wordpress-2
+    options:
+      blog-title: Changed
+      colour: blue
+    constraints: mem=4G
+  mysql:
+    charm: local:series/mysql
+  varnish:
+    charm: local:series/varnish
+relations:
+  - [wordpress, mysql]
+  - [varnish, wordpress]
+`))
+	c.Assert(err, IsNil)
+	diff, err = s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff, DeepEquals, &bundle.Diff{
+		MissingServices: []string{"varnish"},
+		ExtraServices:   []string{"logging"},
+		Services: map[string]*bundle.ServiceDiff{
+			"wordpress": {
+				Charm: &bundle.ValueDiff{Bundle: "local:series/wordpress-2", Environment: "local:series/wordpress-3"},
+				Options: map[string]*bundle.ValueDiff{
+					"blog-title": {Bundle: "Changed", Environment: "Bundled"},
+					"colour":     {Bundle: "blue", Environment: nil},
+				},
+				Constraints: &bundle.ValueDiff{Bundle: "mem=4096M", Environment: "mem=2048M"},
+			},
+		},
+		MissingRelations: [][]string{{"varnish", "wordpress"}},
+		ExtraRelations:   [][]string{{"logging:logging-directory", "wordpress:logging-dir"}},
+	})
+	c.Assert(diff.Empty(), Equals, false)
+}
+
+func (s *DeployBundleSuite) TestDiffBundleDefaults(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+
+	// Settings left out of the bundle are compared with the charm
+	// defaults.
+	b, err := bundle.Parse([]byte(`
+services:
+  wordpress:
+    charm: local:series/wordpress
+    constraints: mem=2G
+`))
+	c.Assert(err, IsNil)
+	diff, err := s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Services, DeepEquals, map[string]*bundle.ServiceDiff{
+		"wordpress": {
+			Options: map[string]*bundle.ValueDiff{
+				"blog-title": {Bundle: "My Title", Environment: "Bundled"},
+			},
+		},
+	})
+}
+
+func (s *DeployBundleSuite) TestDiffBundleRelations(c *C) {
+	err := s.deployBundle(c, wordpressBundle)
+	c.Assert(err, IsNil)
+	b, err := bundle.Parse([]byte("services: {wordpress: {charm: local:series/wordpress}, varnish: {charm: local:series/varnish}}\nrelations: [[wordpress, varnish]]"))
+	c.Assert(err, IsNil)
+	// The relation cannot be inferred without the varnish service, so
+	// it is reported as given.
+	diff, err := s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff.MissingRelations, DeepEquals, [][]string{{"wordpress", "varnish"}})
+
+	b, err = bundle.Parse([]byte("services: {wordpress: {charm: local:series/wordpress}, mysql: {charm: local:series/mysql}}\nrelations: [[wordpress:nope, mysql]]"))
+	c.Assert(err, IsNil)
+	// Relations whose endpoints cannot be inferred are reported as
+	// given too.
+	diff, err = s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff.MissingRelations, DeepEquals, [][]string{{"wordpress:nope", "mysql"}})
+}
+
+func (s *DeployBundleSuite) TestDiffBundleRedactSecrets(c *C) {
+	s.addSecretService(c)
+	b, err := bundle.Parse([]byte(`
+services:
+  dummy:
+    charm: local:series/dummy
+    options:
+      title: Public
+      admin-password: guess
+`))
+	c.Assert(err, IsNil)
+	diff, err := s.Conn.DiffBundle(b, false)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Services["dummy"].Options, DeepEquals, map[string]*bundle.ValueDiff{
+		"title":          {Bundle: "Public", Environment: "Secrets"},
+		"admin-password": {Bundle: "guess", Environment: "sekrit"},
+	})
+
+	// Secret settings are not compared, so guesses reveal nothing.
+	diff, err = s.Conn.DiffBundle(b, true)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Services["dummy"].Options, DeepEquals, map[string]*bundle.ValueDiff{
+		"title": {Bundle: "Public", Environment: "Secrets"},
+	})
+}
@@ -324,38 +324,28 @@ func (conn *Conn) AddUnits(svc *state.Service, n int, machineIdSpec string) ([]*
 			if n != 1 {
 				return nil, fmt.Errorf("cannot add multiple units of service %q to a single machine", svc.Name())
 			}
-			// machineIdSpec may be an existing machine or container, eg 3/lxc/2
-			// or a new container on a machine, eg lxc:3
-			mid := machineIdSpec
-			var containerType instance.ContainerType
-			specParts := strings.Split(machineIdSpec, ":")
-			if len(specParts) > 1 {
-				firstPart := specParts[0]
-				var err error
-				if containerType, err = instance.ParseSupportedContainerType(firstPart); err == nil {
-					mid = strings.Join(specParts[1:], "/")
-				} else {
-					mid = machineIdSpec
-				}
-			}
-			if !state.IsMachineId(mid) {
-				return nil, fmt.Errorf("invalid force machine id %q", mid)
+			mid, containerType, err := parseMachineIdSpec(machineIdSpec)
+			if err != nil {
+				return nil, err
 			}
-
-			var err error
 			var m *state.Machine
 			// If a container is to be used, create it.
 			if containerType != "" {
-				params := state.AddMachineParams{
-					Series:        unit.Series(),
-					ParentId:      mid,
-					ContainerType: containerType,
-					Jobs:          []state.MachineJob{state.JobHostUnits},
+				var host *state.Machine
+				if host, err = conn.State.Machine(mid); err == nil {
+					err = checkTargetMachine(host, "")
 				}
-				m, err = conn.State.AddMachineWithConstraints(&params)
-
-			} else {
-				m, err = conn.State.Machine(mid)
+				if err == nil {
+					params := state.AddMachineParams{
+						Series:        unit.Series(),
+						ParentId:      mid,
+						ContainerType: containerType,
+						Jobs:          []state.MachineJob{state.JobHostUnits},
+					}
+					m, err = conn.State.AddMachineWithConstraints(&params)
+				}
+			} else if m, err = conn.State.Machine(mid); err == nil {
+				err = checkTargetMachine(m, unit.Series())
 			}
 			if err != nil {
 				return nil, fmt.Errorf("cannot assign unit %q to machine: %v", unit.Name(), err)
@@ -373,6 +363,42 @@ func (conn *Conn) AddUnits(svc *state.Service, n int, machineIdSpec string) ([]*
 	return units, nil
 }
 
+// parseMachineIdSpec parses a machine id spec, which may be an
+// existing machine or container, eg 3/lxc/2, or a new container on a
+// machine, eg lxc:3. It returns the machine id, and the type of the
+// new container if one is specified.
+func parseMachineIdSpec(machineIdSpec string) (string, instance.ContainerType, error) {
+	mid := machineIdSpec
+	var containerType instance.ContainerType
+	specParts := strings.Split(machineIdSpec, ":")
+	if len(specParts) > 1 {
+		firstPart := specParts[0]
+		var err error
+		if containerType, err = instance.ParseSupportedContainerType(firstPart); err == nil {
+			mid = strings.Join(specParts[1:], "/")
+		} else {
+			mid = machineIdSpec
+		}
+	}
+	if !state.IsMachineId(mid) {
+		return "", "", fmt.Errorf("invalid force machine id %q", mid)
+	}
+	return mid, containerType, nil
+}
+
+// checkTargetMachine returns an error if units of the given series
+// cannot be placed on m. If series is empty, only the ability to host
+// new containers is checked.
+func checkTargetMachine(m *state.Machine, series string) error {
+	if m.Life() != state.Alive {
+		return fmt.Errorf("machine %s is %s", m, m.Life())
+	}
+	if series != "" && m.Series() != series {
+		return fmt.Errorf("machine %s has series %q, but the unit requires %q", m, m.Series(), series)
+	}
+	return nil
+}
+
 // InitJujuHome initializes the charm and environs/config packages to use
 // default paths based on the $JUJU_HOME or $HOME environment variables.
 // This function should be called before calling NewConn or Conn.Deploy.
@@ -576,6 +576,115 @@ func (s *DeployLocalSuite) TestDeployForceMachineIdWithContainer(c *C) {
 	c.Assert(cons, DeepEquals, expectedCons)
 }
 
+func (s *DeployLocalSuite) TestDeployForceMachineIdDying(c *C) {
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = machine.Destroy()
+	c.Assert(err, IsNil)
+	for i, spec := range []string{"0", "lxc:0"} {
+		c.Logf("test %d: %s", i, spec)
+		_, err = s.Conn.DeployService(juju.DeployServiceParams{
+			ServiceName:   fmt.Sprintf("bob%d", i),
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: spec,
+		})
+		c.Check(err, ErrorMatches, fmt.Sprintf(`cannot assign unit "bob%d/0" to machine: machine 0 is dying`, i))
+	}
+	machines, err := s.State.AllMachines()
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 1)
+}
+
+func (s *DeployLocalSuite) TestDeployForceMachineIdSeriesMismatch(c *C) {
+	machine, err := s.State.AddMachine("other", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	_, err = s.Conn.DeployService(juju.DeployServiceParams{
+		ServiceName:   "bob",
+		Charm:         s.charm,
+		NumUnits:      1,
+		ToMachineSpec: machine.Id(),
+	})
+	c.Assert(err, ErrorMatches, `cannot assign unit "bob/0" to machine: machine 0 has series "other", but the unit requires "series"`)
+
+	// A new container takes the unit's series, whatever the host's.
+	service, err := s.Conn.DeployService(juju.DeployServiceParams{
+		ServiceName:   "fred",
+		Charm:         s.charm,
+		NumUnits:      1,
+		ToMachineSpec: fmt.Sprintf("%s:%s", instance.LXC, machine.Id()),
+	})
+	c.Assert(err, IsNil)
+	s.assertMachines(c, service, constraints.Value{}, "0/lxc/0")
+}
+
+func (s *DeployLocalSuite) TestPlanUnits(c *C) {
+	err := s.State.SetEnvironConstraints(constraints.MustParse("mem=2G"))
+	c.Assert(err, IsNil)
+	p, err := s.Conn.PlanUnits("series", constraints.MustParse("cpu-cores=2"), 2, "")
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &juju.UnitPlacement{
+		Constraints: constraints.MustParse("mem=2G cpu-cores=2"),
+		NewMachines: 2,
+	})
+
+	// Clean, empty machines known to satisfy the constraints are used.
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	hc := instance.MustParseHardware("mem=4G cpu-cores=2")
+	err = machine.SetProvisioned("i-0", "fake_nonce", &hc)
+	c.Assert(err, IsNil)
+	_, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	p, err = s.Conn.PlanUnits("series", constraints.MustParse("cpu-cores=2"), 2, "")
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &juju.UnitPlacement{
+		Constraints: constraints.MustParse("mem=2G cpu-cores=2"),
+		Machines:    []string{"0"},
+		NewMachines: 1,
+	})
+
+	// New containers are created on clean, empty machines first.
+	p, err = s.Conn.PlanUnits("series", constraints.MustParse("container=lxc"), 3, "")
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &juju.UnitPlacement{
+		Constraints:   constraints.MustParse("mem=2G container=lxc"),
+		Hosts:         []string{"0"},
+		NewMachines:   2,
+		ContainerType: instance.LXC,
+	})
+
+	// Units may be placed explicitly.
+	p, err = s.Conn.PlanUnits("series", constraints.Value{}, 1, "1")
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &juju.UnitPlacement{
+		Constraints: constraints.MustParse("mem=2G"),
+		Machines:    []string{"1"},
+	})
+	p, err = s.Conn.PlanUnits("series", constraints.Value{}, 1, "lxc:1")
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &juju.UnitPlacement{
+		Constraints:   constraints.MustParse("mem=2G"),
+		Hosts:         []string{"1"},
+		ContainerType: instance.LXC,
+	})
+	_, err = s.Conn.PlanUnits("other", constraints.Value{}, 1, "1")
+	c.Assert(err, ErrorMatches, `cannot assign unit to machine: machine 1 has series "series", but the unit requires "other"`)
+	_, err = s.Conn.PlanUnits("series", constraints.Value{}, 2, "1")
+	c.Assert(err, ErrorMatches, "cannot add multiple units to a single machine")
+
+	// Nothing was changed.
+	machines, err := s.State.AllMachines()
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 2)
+	for _, m := range machines {
+		c.Assert(m.Clean(), Equals, true)
+		containers, err := m.Containers()
+		c.Assert(err, IsNil)
+		c.Assert(containers, HasLen, 0)
+	}
+}
+
 func (s *DeployLocalSuite) assertCharm(c *C, service *state.Service, expect *charm.URL) {
 	curl, force := service.CharmURL()
 	c.Assert(curl, DeepEquals, expect)
@@ -0,0 +1,119 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/instance"
+)
+
+// UnitPlacement describes where AddUnits would place new units.
+type UnitPlacement struct {
+	// Constraints holds the constraints of the new units: those of
+	// the service, with those of the environment as fallbacks.
+	Constraints constraints.Value
+
+	// Machines holds the ids of the existing machines or containers
+	// the units would be assigned to.
+	Machines []string
+
+	// Hosts holds the ids of the existing machines on which new
+	// containers would be created for the units.
+	Hosts []string
+
+	// NewMachines holds the number of machines that would be started
+	// for the units.
+	NewMachines int
+
+	// ContainerType holds the type of the containers that would be
+	// created for the units, if any.
+	ContainerType instance.ContainerType
+
+	// InstanceType holds the name of the instance type that would be
+	// requested for new machines, if the environment chooses one.
+	InstanceType string
+}
+
+// PlanUnits reports where AddUnits would place n new units of a
+// service with the given series and constraints, without changing the
+// state.
+func (conn *Conn) PlanUnits(series string, scons constraints.Value, n int, machineIdSpec string) (*UnitPlacement, error) {
+	econs, err := conn.State.EnvironConstraints()
+	if err != nil {
+		return nil, err
+	}
+	p := &UnitPlacement{Constraints: scons.WithFallbacks(econs)}
+	if machineIdSpec != "" {
+		if n != 1 {
+			return nil, fmt.Errorf("cannot add multiple units to a single machine")
+		}
+		mid, containerType, err := parseMachineIdSpec(machineIdSpec)
+		if err != nil {
+			return nil, err
+		}
+		m, err := conn.State.Machine(mid)
+		if err == nil {
+			if containerType != "" {
+				err = checkTargetMachine(m, "")
+			} else {
+				err = checkTargetMachine(m, series)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot assign unit to machine: %v", err)
+		}
+		if containerType != "" {
+			p.Hosts = []string{mid}
+			p.ContainerType = containerType
+		} else {
+			p.Machines = []string{mid}
+		}
+		return p, nil
+	}
+
+	// Follow the AssignCleanEmpty policy used by AddUnits.
+	machines, err := conn.State.CleanEmptyMachines(series, p.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range machines {
+		if len(p.Machines) == n {
+			break
+		}
+		p.Machines = append(p.Machines, m.Id())
+	}
+	remaining := n - len(p.Machines)
+	if p.Constraints.HasContainer() && remaining > 0 {
+		// New containers are created on clean, empty machines first.
+		p.ContainerType = *p.Constraints.Container
+		hostCons := p.Constraints
+		noContainer := instance.NONE
+		hostCons.Container = &noContainer
+		hosts, err := conn.State.CleanEmptyMachines(series, hostCons)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range hosts {
+			if len(p.Hosts) == remaining {
+				break
+			}
+			p.Hosts = append(p.Hosts, m.Id())
+		}
+		remaining -= len(p.Hosts)
+	}
+	p.NewMachines = remaining
+	if p.NewMachines > 0 {
+		if matcher, ok := conn.Environ.(environs.InstanceTypeMatcher); ok {
+			itype, err := matcher.MatchInstanceType(series, p.Constraints)
+			if err != nil {
+				return nil, fmt.Errorf("cannot match instance type: %v", err)
+			}
+			p.InstanceType = itype.Name
+		}
+	}
+	return p, nil
+}
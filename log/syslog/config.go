@@ -30,8 +30,8 @@ $UDPServerRun 514
 
 # Messages received from remote rsyslog machines contain a leading space so we
 # need to account for that.
-$template JujuLogFormatLocal,"%HOSTNAME%:%msg:::drop-last-lf%\n"
-$template JujuLogFormat,"%HOSTNAME%:%msg:2:2048:drop-last-lf%\n"
+$template JujuLogFormatLocal,"%syslogtag:12:$% %msg:::drop-last-lf%\n"
+$template JujuLogFormat,"%syslogtag:6:$% %msg:2:2048:drop-last-lf%\n"
 
 :syslogtag, startswith, "juju-" /var/log/juju/all-machines.log;JujuLogFormat
 :syslogtag, startswith, "local-juju-" /var/log/juju/all-machines.log;JujuLogFormatLocal
@@ -0,0 +1,228 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The tailer package reads lines from the consolidated log file
+// written by rsyslog on the state server, selecting those that match
+// a filter, and optionally follows the file as lines are appended.
+package tailer
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"launchpad.net/loggo"
+	"launchpad.net/tomb"
+)
+
+// levels maps the names of log levels, as written in log lines, to
+// their values.
+var levels = map[string]loggo.Level{
+	"TRACE":    loggo.TRACE,
+	"DEBUG":    loggo.DEBUG,
+	"INFO":     loggo.INFO,
+	"WARNING":  loggo.WARNING,
+	"ERROR":    loggo.ERROR,
+	"CRITICAL": loggo.CRITICAL,
+}
+
+// ParseLevel returns the log level with the given name, as in "INFO"
+// or "warning".
+func ParseLevel(name string) (loggo.Level, bool) {
+	level, ok := levels[strings.ToUpper(name)]
+	return level, ok
+}
+
+// Filter selects lines of the consolidated log. Empty fields do not
+// restrict the selection.
+type Filter struct {
+	// Entities holds the tags of the entities whose log lines are
+	// selected.
+	Entities []string
+
+	// Level holds the lowest level of the log lines selected.
+	Level loggo.Level
+
+	// Modules holds the names of the modules whose log lines are
+	// selected. Log lines of their submodules are selected too.
+	Modules []string
+}
+
+// Match returns whether the given log line, without its trailing
+// newline, is selected by the filter. Lines in the consolidated log
+// look like this:
+//
+//	machine-0: 2013-10-16 12:00:00 INFO juju.worker.uniter uniter.go:99 message
+//
+// Lines that cannot be parsed are only selected when the filter does
+// not restrict their level or module.
+func (f *Filter) Match(line string) bool {
+	fields := strings.SplitN(line, " ", 6)
+	if len(f.Entities) > 0 && !f.matchEntity(strings.TrimSuffix(fields[0], ":")) {
+		return false
+	}
+	if f.Level == loggo.UNSPECIFIED && len(f.Modules) == 0 {
+		return true
+	}
+	if len(fields) < 5 {
+		return false
+	}
+	if level, ok := levels[fields[3]]; !ok || level < f.Level {
+		return false
+	}
+	return len(f.Modules) == 0 || f.matchModule(fields[4])
+}
+
+func (f *Filter) matchEntity(tag string) bool {
+	for _, entity := range f.Entities {
+		if tag == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchModule(module string) bool {
+	for _, m := range f.Modules {
+		if module == m || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBatch holds the largest number of lines sent at once on a
+// Tailer's Lines channel.
+const maxBatch = 1000
+
+// pollInterval holds the time waited for more lines when following a
+// log file whose end has been reached.
+var pollInterval = 500 * time.Millisecond
+
+// Tailer reads the lines of a log file that match a filter.
+type Tailer struct {
+	tomb   tomb.Tomb
+	file   *os.File
+	filter Filter
+	replay int
+	follow bool
+	lines  chan []string
+}
+
+// NewTailer returns a Tailer that sends on its Lines channel the last
+// replay lines of the file at path that match filter, or all matching
+// lines if replay is negative. If follow is true, it then sends
+// matching lines as they are appended to the file until it is
+// stopped; otherwise the channel is closed.
+func NewTailer(path string, filter Filter, replay int, follow bool) (*Tailer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tailer{
+		file:   file,
+		filter: filter,
+		replay: replay,
+		follow: follow,
+		lines:  make(chan []string),
+	}
+	go func() {
+		defer t.tomb.Done()
+		defer close(t.lines)
+		defer t.file.Close()
+		t.tomb.Kill(t.loop())
+	}()
+	return t, nil
+}
+
+// Lines returns a channel on which batches of matching lines, without
+// their trailing newlines, are sent.
+func (t *Tailer) Lines() <-chan []string {
+	return t.lines
+}
+
+// Stop stops the Tailer and returns any error encountered while it
+// was running.
+func (t *Tailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Err returns any error encountered while the Tailer was running.
+func (t *Tailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *Tailer) loop() error {
+	r := bufio.NewReader(t.file)
+	var replayed []string
+	var partial string
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			partial = line
+			break
+		} else if err != nil {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if !t.filter.Match(line) {
+			continue
+		}
+		replayed = append(replayed, line)
+		if t.replay >= 0 && len(replayed) > t.replay {
+			replayed = replayed[1:]
+		}
+	}
+	if err := t.send(replayed); err != nil {
+		return err
+	}
+	if !t.follow {
+		return nil
+	}
+	for {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			partial += line
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			line = strings.TrimSuffix(partial, "\n")
+			partial = ""
+			if t.filter.Match(line) {
+				lines = append(lines, line)
+			}
+		}
+		if err := t.send(lines); err != nil {
+			return err
+		}
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// send sends lines on the Lines channel, in batches of at most
+// maxBatch lines.
+func (t *Tailer) send(lines []string) error {
+	for len(lines) > 0 {
+		n := len(lines)
+		if n > maxBatch {
+			n = maxBatch
+		}
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case t.lines <- lines[:n]:
+		}
+		lines = lines[n:]
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tailer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	stdtesting "testing"
+	"time"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/log/tailer"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type TailerSuite struct{}
+
+var _ = Suite(&TailerSuite{})
+
+const sampleLog = `machine-0: 2013-10-16 12:00:00 INFO juju.cmd supercommand.go:286 running juju-1.16.0
+machine-0: 2013-10-16 12:00:01 DEBUG juju.state open.go:88 connection established
+unit-mysql-0: 2013-10-16 12:00:02 INFO juju.worker.uniter uniter.go:350 running "install" hook
+machine-1: 2013-10-16 12:00:03 WARNING juju.worker.machiner machiner.go:53 cannot set status
+unit-mysql-0: 2013-10-16 12:00:04 ERROR juju.worker.uniter.filter filter.go:120 tomb: dying
+unit-mysql-0: 2013-10-16 12:00:05 INFO juju.workerx x.go:1 not a uniter
+machine-1: not a log line
+`
+
+var filterTests = []struct {
+	about  string
+	filter tailer.Filter
+	match  []int
+}{{
+	about: "no filter",
+	match: []int{0, 1, 2, 3, 4, 5, 6},
+}, {
+	about:  "entity",
+	filter: tailer.Filter{Entities: []string{"unit-mysql-0"}},
+	match:  []int{2, 4, 5},
+}, {
+	about:  "several entities",
+	filter: tailer.Filter{Entities: []string{"machine-0", "machine-1"}},
+	match:  []int{0, 1, 3, 6},
+}, {
+	about:  "level",
+	filter: tailer.Filter{Level: loggo.WARNING},
+	match:  []int{3, 4},
+}, {
+	about:  "module",
+	filter: tailer.Filter{Modules: []string{"juju.worker.uniter"}},
+	match:  []int{2, 4},
+}, {
+	about: "entity, level and module",
+	filter: tailer.Filter{
+		Entities: []string{"unit-mysql-0"},
+		Level:    loggo.INFO,
+		Modules:  []string{"juju.worker"},
+	},
+	match: []int{2, 4},
+}}
+
+func (s *TailerSuite) TestFilterMatch(c *C) {
+	lines := strings.Split(strings.TrimSuffix(sampleLog, "\n"), "\n")
+	for i, t := range filterTests {
+		c.Logf("test %d: %s", i, t.about)
+		var match []int
+		for j, line := range lines {
+			if t.filter.Match(line) {
+				match = append(match, j)
+			}
+		}
+		c.Check(match, DeepEquals, t.match)
+	}
+}
+
+func (s *TailerSuite) TestParseLevel(c *C) {
+	level, ok := tailer.ParseLevel("warning")
+	c.Assert(ok, Equals, true)
+	c.Assert(level, Equals, loggo.WARNING)
+	_, ok = tailer.ParseLevel("loud")
+	c.Assert(ok, Equals, false)
+}
+
+func writeLog(c *C, content string) string {
+	path := filepath.Join(c.MkDir(), "all-machines.log")
+	f, err := os.Create(path)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	c.Assert(err, IsNil)
+	return path
+}
+
+func appendLog(c *C, path, content string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	c.Assert(err, IsNil)
+}
+
+func readLines(c *C, t *tailer.Tailer) ([]string, bool) {
+	select {
+	case lines, ok := <-t.Lines():
+		return lines, ok
+	case <-time.After(10 * time.Second):
+		c.Fatalf("timed out waiting for log lines")
+	}
+	panic("unreachable")
+}
+
+func (s *TailerSuite) TestReplay(c *C) {
+	path := writeLog(c, sampleLog)
+	filter := tailer.Filter{Entities: []string{"unit-mysql-0"}}
+	t, err := tailer.NewTailer(path, filter, 2, false)
+	c.Assert(err, IsNil)
+	lines, ok := readLines(c, t)
+	c.Assert(ok, Equals, true)
+	c.Assert(lines, DeepEquals, []string{
+		"unit-mysql-0: 2013-10-16 12:00:04 ERROR juju.worker.uniter.filter filter.go:120 tomb: dying",
+		"unit-mysql-0: 2013-10-16 12:00:05 INFO juju.workerx x.go:1 not a uniter",
+	})
+	_, ok = readLines(c, t)
+	c.Assert(ok, Equals, false)
+	c.Assert(t.Err(), IsNil)
+}
+
+func (s *TailerSuite) TestReplayAll(c *C) {
+	path := writeLog(c, sampleLog)
+	t, err := tailer.NewTailer(path, tailer.Filter{Level: loggo.WARNING}, -1, false)
+	c.Assert(err, IsNil)
+	defer t.Stop()
+	lines, ok := readLines(c, t)
+	c.Assert(ok, Equals, true)
+	c.Assert(lines, HasLen, 2)
+}
+
+func (s *TailerSuite) TestFollow(c *C) {
+	path := writeLog(c, sampleLog+"machine-0: 2013-10-16 12:00:06 INFO juju.cmd")
+	t, err := tailer.NewTailer(path, tailer.Filter{Entities: []string{"machine-0"}}, 1, true)
+	c.Assert(err, IsNil)
+	lines, ok := readLines(c, t)
+	c.Assert(ok, Equals, true)
+	c.Assert(lines, DeepEquals, []string{
+		"machine-0: 2013-10-16 12:00:01 DEBUG juju.state open.go:88 connection established",
+	})
+
+	// The partial line at the end of the file is sent once completed.
+	appendLog(c, path, " cmd.go:1 done\nmachine-1: 2013-10-16 12:00:07 INFO juju.cmd cmd.go:1 other\n")
+	lines, ok = readLines(c, t)
+	c.Assert(ok, Equals, true)
+	c.Assert(lines, DeepEquals, []string{
+		"machine-0: 2013-10-16 12:00:06 INFO juju.cmd cmd.go:1 done",
+	})
+
+	c.Assert(t.Stop(), IsNil)
+	_, ok = readLines(c, t)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TailerSuite) TestNewTailerMissingFile(c *C) {
+	_, err := tailer.NewTailer(filepath.Join(c.MkDir(), "missing.log"), tailer.Filter{}, 10, true)
+	c.Assert(err, ErrorMatches, "open .*missing.log: no such file or directory")
+}
@@ -0,0 +1,263 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// ActionStatus describes the progress of a queued Action.
+type ActionStatus string
+
+const (
+	// ActionPending indicates that the action has been queued but
+	// the unit has not yet reported a result.
+	ActionPending ActionStatus = "pending"
+
+	// ActionCompleted indicates that the unit has run the action
+	// successfully.
+	ActionCompleted ActionStatus = "completed"
+
+	// ActionFailed indicates that the unit has run the action, and
+	// that the action failed.
+	ActionFailed ActionStatus = "failed"
+)
+
+// actionDoc represents a charm action queued for execution by the
+// agent of a unit.
+type actionDoc struct {
+	Id       string `bson:"_id"`
+	Unit     string
+	Name     string
+	Params   map[string]interface{}
+	Enqueued time.Time
+	Status   ActionStatus
+	Results  map[string]interface{}
+	Message  string
+}
+
+// Action represents a charm action to be run by the agent of a unit,
+// as requested by "juju do".
+type Action struct {
+	st  *State
+	doc actionDoc
+}
+
+func newAction(st *State, doc *actionDoc) *Action {
+	return &Action{
+		st:  st,
+		doc: *doc,
+	}
+}
+
+// Id returns the identifier of the action.
+func (a *Action) Id() string {
+	return a.doc.Id
+}
+
+// UnitName returns the name of the unit that should run the action.
+func (a *Action) UnitName() string {
+	return a.doc.Unit
+}
+
+// Name returns the name of the action, as defined in the unit's charm.
+func (a *Action) Name() string {
+	return a.doc.Name
+}
+
+// Params returns the parameters of the action. Default values have
+// been added for parameters that were not given.
+func (a *Action) Params() map[string]interface{} {
+	return a.doc.Params
+}
+
+// Enqueued returns the time at which the action was queued.
+func (a *Action) Enqueued() time.Time {
+	return a.doc.Enqueued
+}
+
+// Status returns the progress of the action.
+func (a *Action) Status() ActionStatus {
+	return a.doc.Status
+}
+
+// Results returns the values set by the action while it ran, and a
+// message explaining why it failed, if it did.
+func (a *Action) Results() (map[string]interface{}, string) {
+	return a.doc.Results, a.doc.Message
+}
+
+// String returns a human-readable description of the action.
+func (a *Action) String() string {
+	return fmt.Sprintf("action %s (%s) for %s", a.doc.Id, a.doc.Name, a.doc.Unit)
+}
+
+// Refresh refreshes the contents of the Action from the underlying
+// state. It returns an error that satisfies errors.IsNotFoundError if
+// the action has been removed.
+func (a *Action) Refresh() error {
+	doc := actionDoc{}
+	err := a.st.actions.FindId(a.doc.Id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("action %s", a.doc.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh action %s: %v", a.doc.Id, err)
+	}
+	a.doc = doc
+	return nil
+}
+
+// Complete records that the action ran successfully, setting the
+// given results. It fails if a result has already been recorded.
+func (a *Action) Complete(results map[string]interface{}) error {
+	return a.finish(ActionCompleted, results, "")
+}
+
+// Fail records that the action failed, setting the given results and
+// explanatory message. It fails if a result has already been recorded.
+func (a *Action) Fail(results map[string]interface{}, message string) error {
+	return a.finish(ActionFailed, results, message)
+}
+
+func (a *Action) finish(status ActionStatus, results map[string]interface{}, message string) error {
+	ops := []txn.Op{{
+		C:      a.st.actions.Name,
+		Id:     a.doc.Id,
+		Assert: D{{"status", ActionPending}},
+		Update: D{{"$set", D{
+			{"status", status},
+			{"results", results},
+			{"message", message},
+		}}},
+	}}
+	if err := a.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := a.Refresh(); err != nil {
+			// The action has been removed.
+			return err
+		}
+		return fmt.Errorf("cannot finish %s: already finished", a)
+	} else if err != nil {
+		return fmt.Errorf("cannot finish %s: %v", a, err)
+	}
+	a.doc.Status = status
+	a.doc.Results = results
+	a.doc.Message = message
+	return nil
+}
+
+// Remove removes the action from the state, whether or not it has
+// finished.
+func (a *Action) Remove() error {
+	ops := []txn.Op{{
+		C:      a.st.actions.Name,
+		Id:     a.doc.Id,
+		Remove: true,
+	}}
+	if err := a.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot remove %s: %v", a, err)
+	}
+	return nil
+}
+
+// Actions returns the actions defined by the unit's charm. If the unit
+// has not yet set its charm, the charm of its service is used.
+func (u *Unit) Actions() (*charm.Actions, error) {
+	curl, ok := u.CharmURL()
+	if !ok {
+		svc, err := u.Service()
+		if err != nil {
+			return nil, err
+		}
+		curl, _ = svc.CharmURL()
+	}
+	ch, err := u.st.Charm(curl)
+	if err != nil {
+		return nil, err
+	}
+	return ch.Actions(), nil
+}
+
+// EnqueueAction queues the named action for execution by the agent of
+// the named unit. The action must be defined by the unit's charm, and
+// the parameters must be valid for it.
+func (st *State) EnqueueAction(unitName, name string, params map[string]interface{}) (a *Action, err error) {
+	defer utils.ErrorContextf(&err, "cannot enqueue action %q for unit %q", name, unitName)
+	unit, err := st.Unit(unitName)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := unit.Actions()
+	if err != nil {
+		return nil, err
+	}
+	if params, err = actions.ValidateParams(name, params); err != nil {
+		return nil, err
+	}
+	seq, err := st.sequence("action")
+	if err != nil {
+		return nil, err
+	}
+	doc := &actionDoc{
+		Id:       strconv.Itoa(seq),
+		Unit:     unitName,
+		Name:     name,
+		Params:   params,
+		Enqueued: time.Now(),
+		Status:   ActionPending,
+	}
+	ops := []txn.Op{{
+		C:      st.units.Name,
+		Id:     unitName,
+		Assert: notDeadDoc,
+	}, {
+		C:      st.actions.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, fmt.Errorf("unit is dead")
+	} else if err != nil {
+		return nil, err
+	}
+	return newAction(st, doc), nil
+}
+
+// Action returns the action with the given id.
+func (st *State) Action(id string) (*Action, error) {
+	doc := &actionDoc{}
+	err := st.actions.FindId(id).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("action %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get action %s: %v", id, err)
+	}
+	return newAction(st, doc), nil
+}
+
+// PendingActions returns all the actions queued for the named unit
+// that have not yet finished, in the order in which they were enqueued.
+func (st *State) PendingActions(unitName string) ([]*Action, error) {
+	var docs []actionDoc
+	sel := D{{"unit", unitName}, {"status", ActionPending}}
+	if err := st.actions.Find(sel).Sort("enqueued").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get actions for unit %q: %v", unitName, err)
+	}
+	actions := make([]*Action, len(docs))
+	for i := range docs {
+		actions[i] = newAction(st, &docs[i])
+	}
+	return actions, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/testing"
+)
+
+type ActionSuite struct {
+	ConnSuite
+	unit  *state.Unit
+	other *state.Unit
+}
+
+var _ = Suite(&ActionSuite{})
+
+func (s *ActionSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+	s.other, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionSuite) TestEnqueueAction(c *C) {
+	a, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	c.Assert(a.UnitName(), Equals, "dummy/0")
+	c.Assert(a.Name(), Equals, "snapshot")
+	c.Assert(a.Params(), DeepEquals, map[string]interface{}{"outfile": "foo.bz2"})
+	c.Assert(a.Status(), Equals, state.ActionPending)
+
+	a1, err := s.State.Action(a.Id())
+	c.Assert(err, IsNil)
+	c.Assert(a1.UnitName(), Equals, a.UnitName())
+	c.Assert(a1.Name(), Equals, a.Name())
+	c.Assert(a1.Params(), DeepEquals, a.Params())
+
+	a2, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", map[string]interface{}{"outfile": "bar.gz"})
+	c.Assert(err, IsNil)
+	c.Assert(a2.Params(), DeepEquals, map[string]interface{}{"outfile": "bar.gz"})
+	c.Assert(a2.Id(), Not(Equals), a.Id())
+}
+
+func (s *ActionSuite) TestEnqueueActionErrors(c *C) {
+	_, err := s.State.EnqueueAction(s.unit.Name(), "explode", nil)
+	c.Assert(err, ErrorMatches, `cannot enqueue action "explode" for unit "dummy/0": unknown action "explode"`)
+
+	_, err = s.State.EnqueueAction(s.unit.Name(), "snapshot", map[string]interface{}{"outfile": 1})
+	c.Assert(err, ErrorMatches, `cannot enqueue action "snapshot" for unit "dummy/0": invalid parameters for action "snapshot": outfile: expected string, got 1`)
+
+	_, err = s.State.EnqueueAction("dummy/9", "snapshot", nil)
+	c.Assert(err, ErrorMatches, `cannot enqueue action "snapshot" for unit "dummy/9": unit "dummy/9" not found`)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	_, err = s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, ErrorMatches, `cannot enqueue action "snapshot" for unit "dummy/0": unit is dead`)
+}
+
+func (s *ActionSuite) TestComplete(c *C) {
+	a, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	results := map[string]interface{}{"size": "42"}
+	err = a.Complete(results)
+	c.Assert(err, IsNil)
+	c.Assert(a.Status(), Equals, state.ActionCompleted)
+
+	err = a.Complete(results)
+	c.Assert(err, ErrorMatches, `cannot finish action \d+ \(snapshot\) for dummy/0: already finished`)
+	err = a.Fail(nil, "oops")
+	c.Assert(err, ErrorMatches, `cannot finish action \d+ \(snapshot\) for dummy/0: already finished`)
+
+	a, err = s.State.Action(a.Id())
+	c.Assert(err, IsNil)
+	c.Assert(a.Status(), Equals, state.ActionCompleted)
+	actual, message := a.Results()
+	c.Assert(actual, DeepEquals, results)
+	c.Assert(message, Equals, "")
+}
+
+func (s *ActionSuite) TestFail(c *C) {
+	a, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	err = a.Fail(map[string]interface{}{"size": "0"}, "disk full")
+	c.Assert(err, IsNil)
+	c.Assert(a.Status(), Equals, state.ActionFailed)
+
+	a, err = s.State.Action(a.Id())
+	c.Assert(err, IsNil)
+	c.Assert(a.Status(), Equals, state.ActionFailed)
+	actual, message := a.Results()
+	c.Assert(actual, DeepEquals, map[string]interface{}{"size": "0"})
+	c.Assert(message, Equals, "disk full")
+}
+
+func (s *ActionSuite) TestRemove(c *C) {
+	a, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	err = a.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.Action(a.Id())
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+	err = a.Refresh()
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+	err = a.Complete(nil)
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+}
+
+func (s *ActionSuite) TestPendingActions(c *C) {
+	var ids []string
+	for _, outfile := range []string{"one", "two", "three"} {
+		a, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", map[string]interface{}{"outfile": outfile})
+		c.Assert(err, IsNil)
+		ids = append(ids, a.Id())
+	}
+	_, err := s.State.EnqueueAction(s.other.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+
+	pending, err := s.State.PendingActions(s.unit.Name())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 3)
+	err = pending[1].Fail(nil, "")
+	c.Assert(err, IsNil)
+
+	pending, err = s.State.PendingActions(s.unit.Name())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 2)
+	c.Assert(pending[0].Id(), Equals, ids[0])
+	c.Assert(pending[0].Params()["outfile"], Equals, "one")
+	c.Assert(pending[1].Id(), Equals, ids[2])
+	c.Assert(pending[1].Params()["outfile"], Equals, "three")
+}
+
+func (s *ActionSuite) TestWatchActions(c *C) {
+	a0, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+
+	w := s.State.WatchActions(s.unit.Name())
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(a0.Id())
+	wc.AssertNoChange()
+
+	// Actions for other units are ignored.
+	_, err = s.State.EnqueueAction(s.other.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// Finishing or removing a known action is not reported.
+	err = a0.Complete(nil)
+	c.Assert(err, IsNil)
+	err = a0.Remove()
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// New actions are reported.
+	a1, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	a2, err := s.State.EnqueueAction(s.unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertChange(a1.Id(), a2.Id())
+	wc.AssertNoChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
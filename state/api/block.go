@@ -0,0 +1,31 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// SwitchBlockOn switches on the block of the given type, which is one
+// of "destroy-environment", "remove-object" and "all-changes". The
+// message is shown when an operation is blocked.
+func (c *Client) SwitchBlockOn(blockType, message string) error {
+	args := params.BlockSwitch{Type: blockType, Message: message}
+	return c.st.Call("Client", "", "SwitchBlockOn", args, nil)
+}
+
+// SwitchBlockOff switches off the block of the given type.
+func (c *Client) SwitchBlockOff(blockType string) error {
+	args := params.BlockSwitch{Type: blockType}
+	return c.st.Call("Client", "", "SwitchBlockOff", args, nil)
+}
+
+// ListBlocks returns the blocks that are switched on.
+func (c *Client) ListBlocks() ([]params.Block, error) {
+	var results params.BlockResults
+	if err := c.st.Call("Client", "", "ListBlocks", nil, &results); err != nil {
+		return nil, err
+	}
+	return results.Blocks, nil
+}
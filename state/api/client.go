@@ -70,6 +70,38 @@ func (c *Client) ServiceGet(service string) (*params.ServiceGetResults, error) {
 	return &results, err
 }
 
+// ServiceConfigHistory returns the recorded changes to the configuration
+// of the named service, oldest first.
+func (c *Client) ServiceConfigHistory(service string) ([]params.ServiceConfigChange, error) {
+	var results params.ServiceConfigHistoryResults
+	p := params.ServiceConfigHistory{ServiceName: service}
+	if err := c.st.Call("Client", "", "ServiceConfigHistory", p, &results); err != nil {
+		return nil, err
+	}
+	return results.Changes, nil
+}
+
+// StatusHistory returns the recorded changes to the status of the unit
+// or machine with the given tag, oldest first.
+func (c *Client) StatusHistory(tag string) ([]params.StatusChange, error) {
+	var results params.StatusHistoryResults
+	p := params.StatusHistory{Tag: tag}
+	if err := c.st.Call("Client", "", "StatusHistory", p, &results); err != nil {
+		return nil, err
+	}
+	return results.Changes, nil
+}
+
+// ServiceRevertConfig restores the configuration of the named service
+// to that in effect after the change with the given revision.
+func (c *Client) ServiceRevertConfig(service string, revision int) error {
+	p := params.ServiceRevertConfig{
+		ServiceName: service,
+		Revision:    revision,
+	}
+	return c.st.Call("Client", "", "ServiceRevertConfig", p, nil)
+}
+
 // AddRelation adds a relation between the specified endpoints and returns the relation info.
 func (c *Client) AddRelation(endpoints ...string) (*params.AddRelationResults, error) {
 	var addRelRes params.AddRelationResults
@@ -78,6 +110,18 @@ func (c *Client) AddRelation(endpoints ...string) (*params.AddRelationResults, e
 	return &addRelRes, err
 }
 
+// ShowRelation describes the relations of the given endpoint, as in
+// "mysql:server" or "mysql", and the participation of the units of
+// their services. If unit is not empty, only that unit is described.
+func (c *Client) ShowRelation(endpoint, unit string) ([]params.RelationDetails, error) {
+	var results params.ShowRelationResults
+	p := params.ShowRelation{Endpoint: endpoint, Unit: unit}
+	if err := c.st.Call("Client", "", "ShowRelation", p, &results); err != nil {
+		return nil, err
+	}
+	return results.Relations, nil
+}
+
 // DestroyRelation removes the relation between the specified endpoints.
 func (c *Client) DestroyRelation(endpoints ...string) error {
 	params := params.DestroyRelation{Endpoints: endpoints}
@@ -132,6 +176,15 @@ func (c *Client) AddServiceUnits(service string, numUnits int) ([]string, error)
 	return results.Units, err
 }
 
+// SetUnitsPaused pauses or resumes the hooks of the given units.
+func (c *Client) SetUnitsPaused(unitNames []string, paused bool) error {
+	p := params.SetUnitsPaused{
+		UnitNames: unitNames,
+		Paused:    paused,
+	}
+	return c.st.Call("Client", "", "SetUnitsPaused", p, nil)
+}
+
 // DestroyServiceUnits decreases the number of units dedicated to a service.
 func (c *Client) DestroyServiceUnits(unitNames []string) error {
 	params := params.DestroyServiceUnits{unitNames}
@@ -200,6 +253,64 @@ type WatchAll struct {
 	AllWatcherId string
 }
 
+// DeployBundle deploys the services and relations described by the
+// given bundle YAML. Only charms from the charm store may be used.
+func (c *Client) DeployBundle(yaml string) error {
+	p := params.DeployBundle{YAML: yaml}
+	return c.st.Call("Client", "", "DeployBundle", p, nil)
+}
+
+// ExportBundle returns a bundle, in YAML format, describing the
+// services and relations in the environment. If redactSecrets is
+// true, settings that appear to hold secrets are left out of the
+// bundle, and their names are returned.
+func (c *Client) ExportBundle(redactSecrets bool) (yaml string, redacted []string, err error) {
+	p := params.ExportBundle{RedactSecrets: redactSecrets}
+	var result params.ExportBundleResults
+	if err := c.st.Call("Client", "", "ExportBundle", p, &result); err != nil {
+		return "", nil, err
+	}
+	return result.YAML, result.Redacted, nil
+}
+
+// DiffBundle returns the differences between the bundle described by
+// the given YAML and the services and relations in the environment, as
+// a bundle.Diff in YAML format.
+func (c *Client) DiffBundle(yaml string) (string, error) {
+	p := params.DiffBundle{YAML: yaml}
+	var result params.DiffBundleResults
+	if err := c.st.Call("Client", "", "DiffBundle", p, &result); err != nil {
+		return "", err
+	}
+	return result.YAML, nil
+}
+
+// AddUser adds a user with the given password and role, which must be
+// either "admin" or "read-only".
+func (c *Client) AddUser(username, password, role string) error {
+	p := params.AddUser{
+		Username: username,
+		Password: password,
+		Role:     role,
+	}
+	return c.st.Call("Client", "", "AddUser", p, nil)
+}
+
+// RemoveUser removes the named user.
+func (c *Client) RemoveUser(username string) error {
+	p := params.RemoveUser{Username: username}
+	return c.st.Call("Client", "", "RemoveUser", p, nil)
+}
+
+// SetUserPassword changes the password of the named user.
+func (c *Client) SetUserPassword(username, password string) error {
+	p := params.SetUserPassword{
+		Username: username,
+		Password: password,
+	}
+	return c.st.Call("Client", "", "SetUserPassword", p, nil)
+}
+
 // WatchAll returns an AllWatcher, from which you can request the Next
 // collection of Deltas.
 func (c *Client) WatchAll() (*AllWatcher, error) {
@@ -225,3 +336,48 @@ func (c *Client) SetAnnotations(tag string, pairs map[string]string) error {
 	args := params.SetAnnotations{tag, pairs}
 	return c.st.Call("Client", "", "SetAnnotations", args, nil)
 }
+
+// Run runs the commands on the specified machines, units and units of
+// services, and returns the output and exit code of each.
+func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
+	results := new(params.RunResults)
+	err := c.st.Call("Client", "", "Run", run, results)
+	return results.Results, err
+}
+
+// EnqueueAction queues the named action to be run by the given unit,
+// and returns the id of the queued action. The parameters are parsed
+// according to the types declared by the unit's charm.
+func (c *Client) EnqueueAction(unitName, name string, actionParams map[string]string) (string, error) {
+	var result params.EnqueueActionResults
+	p := params.EnqueueAction{
+		UnitName: unitName,
+		Name:     name,
+		Params:   actionParams,
+	}
+	if err := c.st.Call("Client", "", "EnqueueAction", p, &result); err != nil {
+		return "", err
+	}
+	return result.ActionId, nil
+}
+
+// ActionResult returns the progress and results of the action with
+// the given id.
+func (c *Client) ActionResult(id string) (*params.ActionResultResults, error) {
+	var result params.ActionResultResults
+	p := params.ActionResult{ActionId: id}
+	if err := c.st.Call("Client", "", "ActionResult", p, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AuditLog returns the entries of the audit log of client operations
+// selected by filter, oldest first.
+func (c *Client) AuditLog(filter params.AuditLog) ([]params.AuditEntry, error) {
+	var result params.AuditLogResults
+	if err := c.st.Call("Client", "", "AuditLog", filter, &result); err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
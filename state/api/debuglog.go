@@ -0,0 +1,39 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// DebugLogWatcher holds information allowing us to get lines of the
+// consolidated log of the environment.
+type DebugLogWatcher struct {
+	client *Client
+	id     string
+}
+
+// WatchDebugLog returns a DebugLogWatcher, from which you can request
+// the Next lines of the consolidated log that match the given
+// parameters.
+func (c *Client) WatchDebugLog(args params.DebugLog) (*DebugLogWatcher, error) {
+	info := new(params.DebugLogWatcherId)
+	if err := c.st.Call("Client", "", "WatchDebugLog", args, info); err != nil {
+		return nil, err
+	}
+	return &DebugLogWatcher{c, info.DebugLogWatcherId}, nil
+}
+
+// Next returns the next lines of the log. Once all the lines have been
+// returned by a watcher that does not follow the log, it returns an
+// error with the code params.CodeStopped.
+func (watcher *DebugLogWatcher) Next() ([]string, error) {
+	info := new(params.DebugLogResults)
+	err := watcher.client.st.Call("DebugLogWatcher", watcher.id, "Next", nil, info)
+	return info.Lines, err
+}
+
+func (watcher *DebugLogWatcher) Stop() error {
+	return watcher.client.st.Call("DebugLogWatcher", watcher.id, "Stop", nil, nil)
+}
@@ -50,3 +50,25 @@ const (
 	// detected.
 	StatusDown Status = "down"
 )
+
+// The following statuses describe the workload of a unit, as set
+// by its charm.
+const (
+	// The charm has not reported the status of the workload.
+	StatusUnknown Status = "unknown"
+
+	// The charm is installing, configuring or otherwise changing the
+	// workload, which may not be available in the meantime.
+	StatusMaintenance Status = "maintenance"
+
+	// The workload cannot run until an operator intervenes, for
+	// example by adding a relation.
+	StatusBlocked Status = "blocked"
+
+	// The workload is waiting for something it needs that is not
+	// under the operator's control, such as a related service.
+	StatusWaiting Status = "waiting"
+
+	// The workload is ready and providing its service.
+	StatusActive Status = "active"
+)
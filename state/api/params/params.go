@@ -10,6 +10,7 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/instance"
+	"time"
 )
 
 // ErrorResults holds the results of calling a bulk operation which
@@ -55,6 +56,55 @@ type DestroyRelation struct {
 	Endpoints []string
 }
 
+// ShowRelation holds the parameters for making the ShowRelation call.
+// Endpoint holds a service name, optionally followed by a colon and the
+// name of one of its relation endpoints. If Unit is not empty, only the
+// named unit is described.
+type ShowRelation struct {
+	Endpoint string
+	Unit     string
+}
+
+// ShowRelationResults holds the results of the ShowRelation call.
+type ShowRelationResults struct {
+	Relations []RelationDetails
+}
+
+// RelationDetails describes a relation and the units of its services.
+type RelationDetails struct {
+	Key   string
+	Id    int
+	Units []RelationUnitDetails
+}
+
+// RelationUnitDetails describes the participation of a unit in a
+// relation. Settings holds the unit's settings in the relation, and is
+// nil if the unit has never entered the relation's scope.
+type RelationUnitDetails struct {
+	Unit       string
+	InScope    bool
+	AgentAlive bool
+	Settings   map[string]interface{}
+}
+
+// BlockSwitch holds the parameters for making the SwitchBlockOn and
+// SwitchBlockOff calls. Message is shown when an operation is blocked.
+type BlockSwitch struct {
+	Type    string
+	Message string
+}
+
+// Block describes a block that is switched on.
+type Block struct {
+	Type    string
+	Message string
+}
+
+// BlockResults holds the results of the ListBlocks call.
+type BlockResults struct {
+	Blocks []Block
+}
+
 // ServiceDeploy holds the parameters for making the ServiceDeploy call.
 type ServiceDeploy struct {
 	ServiceName   string
@@ -66,6 +116,37 @@ type ServiceDeploy struct {
 	ToMachineSpec string
 }
 
+// DeployBundle holds the parameters for making the DeployBundle call.
+// YAML holds the bundle, in the format defined by the bundle package.
+type DeployBundle struct {
+	YAML string
+}
+
+// ExportBundle holds the parameters for making the ExportBundle call.
+type ExportBundle struct {
+	RedactSecrets bool
+}
+
+// ExportBundleResults holds the results of the ExportBundle call.
+// YAML holds the exported bundle; Redacted holds the settings left
+// out of it, in the form <service>:<option>.
+type ExportBundleResults struct {
+	YAML     string
+	Redacted []string
+}
+
+// DiffBundle holds the parameters for making the DiffBundle call.
+// YAML holds the bundle, in the format defined by the bundle package.
+type DiffBundle struct {
+	YAML string
+}
+
+// DiffBundleResults holds the results of the DiffBundle call. YAML
+// holds the differences found, as a bundle.Diff in YAML format.
+type DiffBundleResults struct {
+	YAML string
+}
+
 // ServiceSetCharm sets the charm for a given service.
 type ServiceSetCharm struct {
 	ServiceName string
@@ -106,6 +187,63 @@ type ServiceGetResults struct {
 	Constraints constraints.Value
 }
 
+// ServiceConfigHistory holds parameters for making the
+// ServiceConfigHistory call.
+type ServiceConfigHistory struct {
+	ServiceName string
+}
+
+// ServiceConfigHistoryResults holds the results of the
+// ServiceConfigHistory call, oldest change first.
+type ServiceConfigHistoryResults struct {
+	Changes []ServiceConfigChange
+}
+
+// StatusHistory holds parameters for making the StatusHistory call.
+type StatusHistory struct {
+	Tag string
+}
+
+// StatusHistoryResults holds the results of the StatusHistory call,
+// oldest change first.
+type StatusHistoryResults struct {
+	Changes []StatusChange
+}
+
+// StatusChange describes a change to the status of a unit or machine.
+type StatusChange struct {
+	Status Status
+	Info   string
+	Time   time.Time
+}
+
+// ServiceConfigChange describes a change to the configuration of a
+// service. User holds the tag of the entity that made the change,
+// if known.
+type ServiceConfigChange struct {
+	Revision int
+	CharmURL string
+	User     string
+	Time     time.Time
+	Items    []ServiceConfigItemChange
+}
+
+// ServiceConfigItemChange describes a change to a single setting.
+// OldValue is nil if the setting was added, and NewValue is nil if
+// it was deleted.
+type ServiceConfigItemChange struct {
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// ServiceRevertConfig holds parameters for making the
+// ServiceRevertConfig call.
+type ServiceRevertConfig struct {
+	ServiceName string
+	Revision    int
+}
+
 // ServiceUnexpose holds parameters for the ServiceUnexpose call.
 type ServiceUnexpose struct {
 	ServiceName string
@@ -137,6 +275,13 @@ type AddServiceUnits struct {
 	ToMachineSpec string
 }
 
+// SetUnitsPaused holds the parameters for making the SetUnitsPaused
+// call.
+type SetUnitsPaused struct {
+	UnitNames []string
+	Paused    bool
+}
+
 // DestroyServiceUnits holds parameters for the DestroyUnits call.
 type DestroyServiceUnits struct {
 	UnitNames []string
@@ -191,6 +336,149 @@ type CharmInfo struct {
 	CharmURL string
 }
 
+// RunParams holds the parameters for making the Run call. The
+// commands are run on every machine and unit specified, and on
+// every unit of every service specified.
+type RunParams struct {
+	Commands string
+	Timeout  time.Duration
+	Machines []string
+	Services []string
+	Units    []string
+}
+
+// RunResult holds the result of running commands on a single machine
+// or unit. UnitId is empty when the commands were run on a machine.
+type RunResult struct {
+	MachineId string
+	UnitId    string
+	Code      int
+	Stdout    []byte
+	Stderr    []byte
+	Error     string
+}
+
+// RunResults holds the results of a Run call.
+type RunResults struct {
+	Results []RunResult
+}
+
+// EnqueueAction holds the parameters for making the EnqueueAction
+// call. The action parameters are given as strings, as on the command
+// line, and are parsed according to the types declared by the unit's
+// charm.
+type EnqueueAction struct {
+	UnitName string
+	Name     string
+	Params   map[string]string
+}
+
+// EnqueueActionResults holds the results of the EnqueueAction call.
+type EnqueueActionResults struct {
+	ActionId string
+}
+
+// ActionResult holds the parameters for making the ActionResult call.
+type ActionResult struct {
+	ActionId string
+}
+
+// ActionResultResults holds the results of the ActionResult call.
+// Status is one of "pending", "completed" and "failed"; Message
+// explains why a failed action failed.
+type ActionResultResults struct {
+	UnitName string
+	Name     string
+	Params   map[string]interface{}
+	Enqueued time.Time
+	Status   string
+	Results  map[string]interface{}
+	Message  string
+}
+
+// AuditLog holds the parameters for making the AuditLog call. Empty
+// fields do not restrict the entries returned; After and Before
+// select entries recorded at or after, and before, the given times.
+type AuditLog struct {
+	Entity string
+	User   string
+	After  time.Time
+	Before time.Time
+}
+
+// AuditEntry describes a client operation recorded in the audit log.
+// Args holds the JSON-encoded arguments of the operation, with secrets
+// redacted; Error is empty if the operation succeeded.
+type AuditEntry struct {
+	Time     time.Time
+	User     string
+	Method   string
+	Args     string
+	Entities []string
+	Error    string
+}
+
+// AuditLogResults holds the results of the AuditLog call.
+type AuditLogResults struct {
+	Entries []AuditEntry
+}
+
+// AddUser holds the parameters for making the AddUser call.
+// Role must be either "admin" or "read-only".
+type AddUser struct {
+	Username string
+	Password string
+	Role     string
+}
+
+// RemoveUser holds the parameters for making the RemoveUser call.
+type RemoveUser struct {
+	Username string
+}
+
+// SetUserPassword holds the parameters for making the SetUserPassword
+// call.
+type SetUserPassword struct {
+	Username string
+	Password string
+}
+
+// DebugLog holds the parameters for making the WatchDebugLog call.
+type DebugLog struct {
+	// Entities holds the tags of the entities whose log lines are
+	// wanted. If empty, log lines of all entities are sent.
+	Entities []string
+
+	// Level holds the name of the lowest level of the log lines
+	// wanted, as in "INFO". If empty, log lines of all levels are
+	// sent.
+	Level string
+
+	// Modules holds the names of the modules whose log lines are
+	// wanted, including those of their submodules. If empty, log
+	// lines of all modules are sent.
+	Modules []string
+
+	// Replay holds the number of existing log lines sent before any
+	// new ones. If negative, all existing log lines are sent.
+	Replay int
+
+	// Follow holds whether log lines are sent as they are written.
+	// If false, the watcher stops once the existing lines are sent.
+	Follow bool
+}
+
+// DebugLogWatcherId holds the id of a DebugLogWatcher.
+type DebugLogWatcherId struct {
+	DebugLogWatcherId string
+}
+
+// DebugLogResults holds log lines returned from calling
+// DebugLogWatcher.Next().
+type DebugLogResults struct {
+	Lines []string
+}
+
 // AllWatcherId holds the id of an AllWatcher.
 type AllWatcherId struct {
 	AllWatcherId string
@@ -346,9 +634,18 @@ type UnitInfo struct {
 	PublicAddress  string
 	PrivateAddress string
 	MachineId      string
-	Ports          []instance.Port
+	Ports          []instance.PortRange
 	Status         Status
 	StatusInfo     string
+
+	// WorkloadStatus and WorkloadStatusInfo hold the status of the
+	// unit's workload, as set by its charm.
+	WorkloadStatus     Status
+	WorkloadStatusInfo string
+
+	// Idle holds whether the unit's agent has reported that it has
+	// no hooks waiting to run.
+	Idle bool
 }
 
 func (i *UnitInfo) EntityId() EntityId {
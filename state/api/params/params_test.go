@@ -64,19 +64,23 @@ var marshalTestCases = []struct {
 			Service:  "Shazam",
 			Series:   "precise",
 			CharmURL: "cs:~user/precise/wordpress-42",
-			Ports: []instance.Port{
+			Ports: []instance.PortRange{
 				{
 					Protocol: "http",
-					Number:   80},
+					FromPort: 80,
+					ToPort:   80},
 			},
-			PublicAddress:  "testing.invalid",
-			PrivateAddress: "10.0.0.1",
-			MachineId:      "1",
-			Status:         "error",
-			StatusInfo:     "foo",
+			PublicAddress:      "testing.invalid",
+			PrivateAddress:     "10.0.0.1",
+			MachineId:          "1",
+			Status:             "error",
+			StatusInfo:         "foo",
+			WorkloadStatus:     "blocked",
+			WorkloadStatusInfo: "bar",
+			Idle:               true,
 		},
 	},
-	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80}], "Status": "error", "StatusInfo": "foo"}]`,
+	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80, "FromPort": 80, "ToPort": 80}], "Status": "error", "StatusInfo": "foo", "WorkloadStatus": "blocked", "WorkloadStatusInfo": "bar", "Idle": true}]`,
 }, {
 	about: "RelationInfo Delta",
 	value: params.Delta{
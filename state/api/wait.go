@@ -0,0 +1,142 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// WaitSteady blocks until the environment reaches a steady state, in
+// which every machine is provisioned and started, and every unit is
+// started, runs the charm of its service and has no hooks waiting to
+// run, as reported by its agent. If services are given,
+// only those services, their units and the machines hosting them are
+// considered. An error is returned as soon as a unit or machine is in
+// an error state, or if the steady state is not reached within the
+// timeout; a zero timeout waits forever.
+func (c *Client) WaitSteady(services []string, timeout time.Duration) error {
+	watcher, err := c.WatchAll()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	timedOut := make(chan struct{})
+	if timeout > 0 {
+		// Stopping the watcher interrupts any call to Next.
+		timer := time.AfterFunc(timeout, func() {
+			close(timedOut)
+			watcher.Stop()
+		})
+		defer timer.Stop()
+	}
+	env := newEnvSnapshot()
+	pending := []string{"the environment"}
+	for {
+		deltas, err := watcher.Next()
+		if err != nil {
+			select {
+			case <-timedOut:
+				return fmt.Errorf("timed out waiting for %s", strings.Join(pending, ", "))
+			default:
+			}
+			return err
+		}
+		env.update(deltas)
+		if pending, err = env.pending(services); err != nil {
+			return err
+		} else if len(pending) == 0 {
+			return nil
+		}
+	}
+}
+
+// envSnapshot holds the machines, services and units of an environment
+// as reported by an AllWatcher.
+type envSnapshot struct {
+	machines map[string]*params.MachineInfo
+	services map[string]*params.ServiceInfo
+	units    map[string]*params.UnitInfo
+}
+
+func newEnvSnapshot() *envSnapshot {
+	return &envSnapshot{
+		machines: make(map[string]*params.MachineInfo),
+		services: make(map[string]*params.ServiceInfo),
+		units:    make(map[string]*params.UnitInfo),
+	}
+}
+
+// update applies the given changes to the snapshot.
+func (env *envSnapshot) update(deltas []params.Delta) {
+	for _, d := range deltas {
+		switch info := d.Entity.(type) {
+		case *params.MachineInfo:
+			if d.Removed {
+				delete(env.machines, info.Id)
+			} else {
+				env.machines[info.Id] = info
+			}
+		case *params.ServiceInfo:
+			if d.Removed {
+				delete(env.services, info.Name)
+			} else {
+				env.services[info.Name] = info
+			}
+		case *params.UnitInfo:
+			if d.Removed {
+				delete(env.units, info.Name)
+			} else {
+				env.units[info.Name] = info
+			}
+		}
+	}
+}
+
+// pending returns descriptions of the entities that have not yet
+// reached a steady state, considering only the given services if any
+// are given. It returns an error if any of them is in an error state.
+func (env *envSnapshot) pending(services []string) ([]string, error) {
+	var pending []string
+	wanted := make(map[string]bool)
+	for _, name := range services {
+		wanted[name] = true
+		if env.services[name] == nil {
+			pending = append(pending, fmt.Sprintf("service %q", name))
+		}
+	}
+	hosts := make(map[string]bool)
+	for name, unit := range env.units {
+		if len(wanted) > 0 && !wanted[unit.Service] {
+			continue
+		}
+		if unit.MachineId != "" {
+			hosts[unit.MachineId] = true
+		}
+		if unit.Status == params.StatusError {
+			return nil, fmt.Errorf("unit %q is in an error state: %s", name, unit.StatusInfo)
+		}
+		svc := env.services[unit.Service]
+		if unit.Status != params.StatusStarted || !unit.Idle || svc == nil || unit.CharmURL != svc.CharmURL {
+			pending = append(pending, fmt.Sprintf("unit %q", name))
+		}
+	}
+	for id, machine := range env.machines {
+		if len(wanted) > 0 && !hosts[id] {
+			continue
+		}
+		if machine.Status == params.StatusError {
+			return nil, fmt.Errorf("machine %s is in an error state: %s", id, machine.StatusInfo)
+		}
+		if machine.InstanceId == "" || machine.Status != params.StatusStarted {
+			pending = append(pending, fmt.Sprintf("machine %s", id))
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
@@ -156,6 +156,8 @@ var scenarioStatus = &api.Status{
 // When the scenario is initialized, we have:
 // user-admin
 // user-other
+// user-readonly
+//  role=read-only
 // machine-0
 //  instance-id="i-machine-0"
 //  nonce="fake_nonce"
@@ -205,6 +207,11 @@ func (s *baseSuite) setUpScenario(c *C) (entities []string) {
 	setDefaultPassword(c, u)
 	add(u)
 
+	u, err = s.State.AddUserWithRole("readonly", "", state.ReadOnlyRole)
+	c.Assert(err, IsNil)
+	setDefaultPassword(c, u)
+	add(u)
+
 	m, err := s.State.AddMachine("series", state.JobManageEnviron)
 	c.Assert(err, IsNil)
 	c.Assert(m.Tag(), Equals, "machine-0")
@@ -0,0 +1,169 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// redacted replaces the values of arguments that may hold secrets
+// in the audit log.
+const redacted = "<redacted>"
+
+// audit records a call to the named method in the audit log, together
+// with its arguments, the tags of the entities it concerns, and the
+// error it returned. Client methods that may change the environment
+// defer it before doing anything else, so that calls refused for lack
+// of permission are recorded too. Failing to record a call does not
+// cause it to fail.
+func (c *Client) audit(method string, args interface{}, err *error, entities ...string) {
+	entry := state.AuditEntry{
+		User:     c.api.auth.GetAuthTag(),
+		Method:   method,
+		Args:     auditArgs(args),
+		Entities: entities,
+	}
+	if *err != nil {
+		entry.Error = (*err).Error()
+	}
+	if err := c.api.state.AddAuditEntry(entry); err != nil {
+		log.Warningf("api: %v", err)
+	}
+}
+
+// auditArgs returns args encoded as JSON, with the values of any
+// fields or keys whose names suggest that they hold secrets redacted.
+func auditArgs(args interface{}) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return redacted
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return redacted
+	}
+	redactSecrets(v)
+	if data, err = json.Marshal(v); err != nil {
+		return redacted
+	}
+	return string(data)
+}
+
+func redactSecrets(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSecretKey(key) {
+				v[key] = redacted
+			} else {
+				redactSecrets(value)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			redactSecrets(value)
+		}
+	}
+}
+
+// isSecretKey reports whether the field or key may hold a secret.
+// Field names are matched anywhere in the name, as in "AdminSecret";
+// other keys, such as charm options, are matched as for bundles.
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	if strings.Contains(lower, "password") || strings.Contains(lower, "secret") {
+		return true
+	}
+	return bundle.IsSecretOption(key)
+}
+
+// auditServiceDeployArgs returns the arguments of a ServiceDeploy call
+// as they are recorded in the audit log. Configuration in YAML format
+// may hold secrets under any key, so it is not recorded.
+func auditServiceDeployArgs(args params.ServiceDeploy) params.ServiceDeploy {
+	if args.ConfigYAML != "" {
+		args.ConfigYAML = redacted
+	}
+	return args
+}
+
+// serviceTags returns the tags of the named services.
+func serviceTags(serviceNames ...string) []string {
+	tags := make([]string, len(serviceNames))
+	for i, name := range serviceNames {
+		tags[i] = state.ServiceTag(name)
+	}
+	return tags
+}
+
+// unitTags returns the tags of the named units.
+func unitTags(unitNames ...string) []string {
+	tags := make([]string, len(unitNames))
+	for i, name := range unitNames {
+		tags[i] = state.UnitTag(name)
+	}
+	return tags
+}
+
+// endpointTags returns the tags of the services of the given
+// relation endpoints, as in "wordpress:db" or "mysql".
+func endpointTags(endpoints []string) []string {
+	tags := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		tags[i] = state.ServiceTag(strings.SplitN(ep, ":", 2)[0])
+	}
+	return tags
+}
+
+// bundleTags returns the tags of the services in the given bundle.
+func bundleTags(yaml string) []string {
+	b, err := bundle.Parse([]byte(yaml))
+	if err != nil {
+		return nil
+	}
+	return serviceTags(b.ServiceNames()...)
+}
+
+// runTags returns the tags of the machines, services and units on
+// which commands are run.
+func runTags(args params.RunParams) []string {
+	var tags []string
+	for _, id := range args.Machines {
+		tags = append(tags, state.MachineTag(id))
+	}
+	tags = append(tags, serviceTags(args.Services...)...)
+	return append(tags, unitTags(args.Units...)...)
+}
+
+// AuditLog returns the entries of the audit log selected by the
+// given filter, oldest first.
+func (c *Client) AuditLog(args params.AuditLog) (params.AuditLogResults, error) {
+	entries, err := c.api.state.AuditLog(state.AuditFilter{
+		Entity: args.Entity,
+		User:   args.User,
+		After:  args.After,
+		Before: args.Before,
+	})
+	if err != nil {
+		return params.AuditLogResults{}, err
+	}
+	results := make([]params.AuditEntry, len(entries))
+	for i, entry := range entries {
+		results[i] = params.AuditEntry{
+			Time:     entry.Time,
+			User:     entry.User,
+			Method:   entry.Method,
+			Args:     entry.Args,
+			Entities: entry.Entities,
+			Error:    entry.Error,
+		}
+	}
+	return params.AuditLogResults{Entries: results}, nil
+}
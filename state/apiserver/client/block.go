@@ -0,0 +1,53 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// SwitchBlockOn switches on a block that protects the environment
+// against accidental changes.
+func (c *Client) SwitchBlockOn(args params.BlockSwitch) (err error) {
+	defer c.audit("SwitchBlockOn", args, &err)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	t, err := state.ParseBlockType(args.Type)
+	if err != nil {
+		return err
+	}
+	return c.api.state.SwitchBlockOn(t, args.Message)
+}
+
+// SwitchBlockOff switches off a block. Blocks never prevent themselves
+// from being switched off.
+func (c *Client) SwitchBlockOff(args params.BlockSwitch) (err error) {
+	defer c.audit("SwitchBlockOff", args, &err)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	t, err := state.ParseBlockType(args.Type)
+	if err != nil {
+		return err
+	}
+	return c.api.state.SwitchBlockOff(t)
+}
+
+// ListBlocks returns the blocks that are switched on.
+func (c *Client) ListBlocks() (params.BlockResults, error) {
+	blocks, err := c.api.state.AllBlocks()
+	if err != nil {
+		return params.BlockResults{}, err
+	}
+	results := params.BlockResults{Blocks: make([]params.Block, len(blocks))}
+	for i, block := range blocks {
+		results.Blocks[i] = params.Block{
+			Type:    string(block.Type),
+			Message: block.Message,
+		}
+	}
+	return results, nil
+}
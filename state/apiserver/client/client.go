@@ -5,13 +5,24 @@ package client
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/bundle"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
 	"launchpad.net/juju-core/state/statecmd"
+	"launchpad.net/juju-core/state/watcher"
+	"launchpad.net/juju-core/utils/set"
 )
 
 type API struct {
@@ -52,6 +63,17 @@ func (r *API) Client(id string) (*Client, error) {
 	return r.client, nil
 }
 
+// requireAdmin returns an error unless the authenticated client user
+// has the admin role. Client methods that change the environment call
+// it before doing anything else; read-only users may call only those
+// methods that do not.
+func (c *Client) requireAdmin() error {
+	if !c.api.auth.AuthAdminClient() {
+		return common.ErrPerm
+	}
+	return nil
+}
+
 func (c *Client) Status() (api.Status, error) {
 	ms, err := c.api.state.AllMachines()
 	if err != nil {
@@ -80,7 +102,14 @@ func (c *Client) WatchAll() (params.AllWatcherId, error) {
 }
 
 // ServiceSet implements the server side of Client.ServerSet.
-func (c *Client) ServiceSet(p params.ServiceSet) error {
+func (c *Client) ServiceSet(p params.ServiceSet) (err error) {
+	defer c.audit("ServiceSet", p, &err, state.ServiceTag(p.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	svc, err := c.api.state.Service(p.ServiceName)
 	if err != nil {
 		return err
@@ -93,11 +122,18 @@ func (c *Client) ServiceSet(p params.ServiceSet) error {
 	if err != nil {
 		return err
 	}
-	return svc.UpdateConfigSettings(changes)
+	return svc.UpdateConfigSettingsAs(changes, c.api.auth.GetAuthTag())
 }
 
 // ServiceSetYAML implements the server side of Client.ServerSetYAML.
-func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) error {
+func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) (err error) {
+	defer c.audit("ServiceSetYAML", params.ServiceSetYAML{ServiceName: p.ServiceName, Config: redacted}, &err, state.ServiceTag(p.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	svc, err := c.api.state.Service(p.ServiceName)
 	if err != nil {
 		return err
@@ -110,16 +146,122 @@ func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) error {
 	if err != nil {
 		return err
 	}
-	return svc.UpdateConfigSettings(changes)
+	return svc.UpdateConfigSettingsAs(changes, c.api.auth.GetAuthTag())
+}
+
+// ServiceConfigHistory returns the recorded changes to the
+// configuration of a service. The values of options that appear to
+// hold secrets are redacted for users that are not administrators.
+func (c *Client) ServiceConfigHistory(args params.ServiceConfigHistory) (params.ServiceConfigHistoryResults, error) {
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.ServiceConfigHistoryResults{}, err
+	}
+	history, err := svc.ConfigHistory()
+	if err != nil {
+		return params.ServiceConfigHistoryResults{}, err
+	}
+	redactSecrets := !c.api.auth.AuthAdminClient()
+	changes := make([]params.ServiceConfigChange, len(history))
+	for i, change := range history {
+		items := make([]params.ServiceConfigItemChange, len(change.Changes))
+		for j, item := range change.Changes {
+			items[j] = params.ServiceConfigItemChange{
+				Key:      item.Key,
+				OldValue: item.OldValue,
+				NewValue: item.NewValue,
+			}
+			if redactSecrets && bundle.IsSecretOption(item.Key) {
+				items[j].OldValue = redactValue(item.OldValue)
+				items[j].NewValue = redactValue(item.NewValue)
+			}
+		}
+		changes[i] = params.ServiceConfigChange{
+			Revision: change.Revision,
+			CharmURL: change.CharmURL.String(),
+			User:     change.User,
+			Time:     change.Time,
+			Items:    items,
+		}
+	}
+	return params.ServiceConfigHistoryResults{Changes: changes}, nil
+}
+
+// redactValue returns the value that stands in for the given secret
+// value; unset values are left unset.
+func redactValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redacted
+}
+
+// StatusHistory returns the recorded changes to the status of the
+// given unit or machine.
+func (c *Client) StatusHistory(args params.StatusHistory) (params.StatusHistoryResults, error) {
+	entity, err := c.api.state.StatusHistorian(args.Tag)
+	if err != nil {
+		return params.StatusHistoryResults{}, err
+	}
+	history, err := entity.StatusHistory()
+	if err != nil {
+		return params.StatusHistoryResults{}, err
+	}
+	changes := make([]params.StatusChange, len(history))
+	for i, change := range history {
+		changes[i] = params.StatusChange{
+			Status: change.Status,
+			Info:   change.Info,
+			Time:   change.Time,
+		}
+	}
+	return params.StatusHistoryResults{Changes: changes}, nil
 }
 
-// ServiceGet returns the configuration for a service.
+// ServiceRevertConfig restores the configuration of a service to that
+// recorded in its history with the given revision.
+func (c *Client) ServiceRevertConfig(args params.ServiceRevertConfig) (err error) {
+	defer c.audit("ServiceRevertConfig", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return svc.RevertConfigSettings(args.Revision, c.api.auth.GetAuthTag())
+}
+
+// ServiceGet returns the configuration for a service. The values of
+// options that appear to hold secrets are redacted for users that are
+// not administrators, unless they are the charm defaults.
 func (c *Client) ServiceGet(args params.ServiceGet) (params.ServiceGetResults, error) {
-	return statecmd.ServiceGet(c.api.state, args)
+	results, err := statecmd.ServiceGet(c.api.state, args)
+	if err != nil || c.api.auth.AuthAdminClient() {
+		return results, err
+	}
+	for name, info := range results.Config {
+		info, ok := info.(map[string]interface{})
+		if !ok || !bundle.IsSecretOption(name) || info["default"] == true {
+			continue
+		}
+		info["value"] = redactValue(info["value"])
+	}
+	return results, nil
 }
 
 // Resolved implements the server side of Client.Resolved.
-func (c *Client) Resolved(p params.Resolved) error {
+func (c *Client) Resolved(p params.Resolved) (err error) {
+	defer c.audit("Resolved", p, &err, state.UnitTag(p.UnitName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	unit, err := c.api.state.Unit(p.UnitName)
 	if err != nil {
 		return err
@@ -127,15 +269,55 @@ func (c *Client) Resolved(p params.Resolved) error {
 	return unit.Resolve(p.Retry)
 }
 
+// SetUnitsPaused pauses or resumes the hooks of the given units.
+func (c *Client) SetUnitsPaused(args params.SetUnitsPaused) (err error) {
+	defer c.audit("SetUnitsPaused", args, &err, unitTags(args.UnitNames...)...)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
+	for _, name := range args.UnitNames {
+		unit, err := c.api.state.Unit(name)
+		if err != nil {
+			return err
+		}
+		if args.Paused {
+			err = unit.SetPaused()
+		} else {
+			err = unit.ClearPaused()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ServiceExpose changes the juju-managed firewall to expose any ports that
 // were also explicitly marked by units as open.
-func (c *Client) ServiceExpose(args params.ServiceExpose) error {
+func (c *Client) ServiceExpose(args params.ServiceExpose) (err error) {
+	defer c.audit("ServiceExpose", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	return statecmd.ServiceExpose(c.api.state, args)
 }
 
 // ServiceUnexpose changes the juju-managed firewall to unexpose any ports that
 // were also explicitly marked by units as open.
-func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) error {
+func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) (err error) {
+	defer c.audit("ServiceUnexpose", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	return statecmd.ServiceUnexpose(c.api.state, args)
 }
 
@@ -143,7 +325,14 @@ var CharmStore charm.Repository = charm.Store
 
 // ServiceDeploy fetches the charm from the charm store and deploys it. Local
 // charms are not supported.
-func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
+func (c *Client) ServiceDeploy(args params.ServiceDeploy) (err error) {
+	defer c.audit("ServiceDeploy", auditServiceDeployArgs(args), &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	curl, err := charm.ParseURL(args.CharmUrl)
 	if err != nil {
 		return err
@@ -182,8 +371,95 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 	return err
 }
 
+// DeployBundle deploys the services and relations in a bundle. As
+// with ServiceDeploy, only charms from the charm store may be used.
+func (c *Client) DeployBundle(args params.DeployBundle) (err error) {
+	defer c.audit("DeployBundle", params.DeployBundle{YAML: redacted}, &err, bundleTags(args.YAML)...)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
+	b, err := bundle.Parse([]byte(args.YAML))
+	if err != nil {
+		return err
+	}
+	conf, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	for _, name := range b.ServiceNames() {
+		curl, err := charm.InferURL(b.Services[name].Charm, conf.DefaultSeries())
+		if err != nil {
+			return err
+		}
+		if curl.Schema != "cs" {
+			return fmt.Errorf(`service %q: charm url has unsupported schema %q`, name, curl.Schema)
+		}
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return err
+	}
+	return conn.DeployBundle(b, CharmStore)
+}
+
+// ExportBundle returns a bundle describing the services and relations
+// in the environment. Secrets are always left out of the bundle for
+// users that are not administrators.
+func (c *Client) ExportBundle(args params.ExportBundle) (params.ExportBundleResults, error) {
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return params.ExportBundleResults{}, err
+	}
+	redactSecrets := args.RedactSecrets || !c.api.auth.AuthAdminClient()
+	b, redacted, err := conn.ExportBundle(redactSecrets)
+	if err != nil {
+		return params.ExportBundleResults{}, err
+	}
+	data, err := goyaml.Marshal(b)
+	if err != nil {
+		return params.ExportBundleResults{}, err
+	}
+	return params.ExportBundleResults{
+		YAML:     string(data),
+		Redacted: redacted,
+	}, nil
+}
+
+// DiffBundle returns the differences between a bundle and the services
+// and relations in the environment. Secret settings are not compared
+// for users that are not administrators.
+func (c *Client) DiffBundle(args params.DiffBundle) (params.DiffBundleResults, error) {
+	b, err := bundle.Parse([]byte(args.YAML))
+	if err != nil {
+		return params.DiffBundleResults{}, err
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return params.DiffBundleResults{}, err
+	}
+	diff, err := conn.DiffBundle(b, !c.api.auth.AuthAdminClient())
+	if err != nil {
+		return params.DiffBundleResults{}, err
+	}
+	data, err := goyaml.Marshal(diff)
+	if err != nil {
+		return params.DiffBundleResults{}, err
+	}
+	return params.DiffBundleResults{YAML: string(data)}, nil
+}
+
 // ServiceSetCharm sets the charm for a given service.
-func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
+func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) (err error) {
+	defer c.audit("ServiceSetCharm", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	service, err := c.api.state.Service(args.ServiceName)
 	if err != nil {
 		return err
@@ -210,7 +486,14 @@ func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
 }
 
 // AddServiceUnits adds a given number of units to a service.
-func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddServiceUnitsResults, error) {
+func (c *Client) AddServiceUnits(args params.AddServiceUnits) (result params.AddServiceUnitsResults, err error) {
+	defer c.audit("AddServiceUnits", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return params.AddServiceUnitsResults{}, err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return params.AddServiceUnitsResults{}, err
+	}
 	units, err := statecmd.AddServiceUnits(c.api.state, args)
 	if err != nil {
 		return params.AddServiceUnitsResults{}, err
@@ -223,12 +506,26 @@ func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddService
 }
 
 // DestroyServiceUnits removes a given set of service units.
-func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
+func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) (err error) {
+	defer c.audit("DestroyServiceUnits", args, &err, unitTags(args.UnitNames...)...)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 	return statecmd.DestroyServiceUnits(c.api.state, args)
 }
 
 // ServiceDestroy destroys a given service.
-func (c *Client) ServiceDestroy(args params.ServiceDestroy) error {
+func (c *Client) ServiceDestroy(args params.ServiceDestroy) (err error) {
+	defer c.audit("ServiceDestroy", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 	return statecmd.ServiceDestroy(c.api.state, args)
 }
 
@@ -238,20 +535,155 @@ func (c *Client) GetServiceConstraints(args params.GetServiceConstraints) (param
 }
 
 // SetServiceConstraints sets the constraints for a given service.
-func (c *Client) SetServiceConstraints(args params.SetServiceConstraints) error {
+func (c *Client) SetServiceConstraints(args params.SetServiceConstraints) (err error) {
+	defer c.audit("SetServiceConstraints", args, &err, state.ServiceTag(args.ServiceName))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	return statecmd.SetServiceConstraints(c.api.state, args)
 }
 
 // AddRelation adds a relation between the specified endpoints and returns the relation info.
-func (c *Client) AddRelation(args params.AddRelation) (params.AddRelationResults, error) {
+func (c *Client) AddRelation(args params.AddRelation) (result params.AddRelationResults, err error) {
+	defer c.audit("AddRelation", args, &err, endpointTags(args.Endpoints)...)
+	if err := c.requireAdmin(); err != nil {
+		return params.AddRelationResults{}, err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return params.AddRelationResults{}, err
+	}
 	return statecmd.AddRelation(c.api.state, args)
 }
 
 // DestroyRelation removes the relation between the specified endpoints.
-func (c *Client) DestroyRelation(args params.DestroyRelation) error {
+func (c *Client) DestroyRelation(args params.DestroyRelation) (err error) {
+	defer c.audit("DestroyRelation", args, &err, endpointTags(args.Endpoints)...)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
 	return statecmd.DestroyRelation(c.api.state, args)
 }
 
+// ShowRelation describes the relations of the given endpoint, and for
+// each unit of their services, its settings in the relation, whether
+// it is in the relation's scope and whether its agent is alive. As
+// relation settings may hold secrets, only admin users may see them.
+func (c *Client) ShowRelation(args params.ShowRelation) (params.ShowRelationResults, error) {
+	if err := c.requireAdmin(); err != nil {
+		return params.ShowRelationResults{}, err
+	}
+	parts := strings.SplitN(args.Endpoint, ":", 2)
+	svc, err := c.api.state.Service(parts[0])
+	if err != nil {
+		return params.ShowRelationResults{}, err
+	}
+	if len(parts) == 2 {
+		if _, err := svc.Endpoint(parts[1]); err != nil {
+			return params.ShowRelationResults{}, err
+		}
+	}
+	if args.Unit != "" {
+		if _, err := c.api.state.Unit(args.Unit); err != nil {
+			return params.ShowRelationResults{}, err
+		}
+	}
+	relations, err := svc.Relations()
+	if err != nil {
+		return params.ShowRelationResults{}, err
+	}
+	results := params.ShowRelationResults{}
+	for _, rel := range relations {
+		ep, err := rel.Endpoint(svc.Name())
+		if err != nil {
+			return params.ShowRelationResults{}, err
+		}
+		if len(parts) == 2 && ep.Relation.Name != parts[1] {
+			continue
+		}
+		units, err := relationUnitDetails(c.api.state, rel, args.Unit)
+		if err != nil {
+			return params.ShowRelationResults{}, err
+		}
+		if args.Unit != "" && len(units) == 0 {
+			continue
+		}
+		results.Relations = append(results.Relations, params.RelationDetails{
+			Key:   rel.String(),
+			Id:    rel.Id(),
+			Units: units,
+		})
+	}
+	if len(results.Relations) == 0 {
+		if args.Unit != "" {
+			return params.ShowRelationResults{}, fmt.Errorf("unit %q is not in any relation of %q", args.Unit, args.Endpoint)
+		}
+		return params.ShowRelationResults{}, fmt.Errorf("%q has no relations", args.Endpoint)
+	}
+	return results, nil
+}
+
+// relationUnitDetails describes the participation in rel of the units
+// of its services, sorted by name, or only of the named unit if
+// unitName is not empty.
+func relationUnitDetails(st *state.State, rel *state.Relation, unitName string) ([]params.RelationUnitDetails, error) {
+	var details []params.RelationUnitDetails
+	for _, ep := range rel.Endpoints() {
+		svc, err := st.Service(ep.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		units, err := svc.AllUnits()
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range units {
+			if unitName != "" && unit.Name() != unitName {
+				continue
+			}
+			ru, err := rel.Unit(unit)
+			if err != nil {
+				return nil, err
+			}
+			inScope, err := ru.InScope()
+			if err != nil {
+				return nil, err
+			}
+			alive, err := unit.AgentAlive()
+			if err != nil {
+				return nil, err
+			}
+			// The settings of a unit that has never entered the
+			// relation's scope are not found.
+			var settings map[string]interface{}
+			if node, err := ru.Settings(); err == nil {
+				settings = node.Map()
+			} else if !errors.IsNotFoundError(err) {
+				return nil, err
+			}
+			details = append(details, params.RelationUnitDetails{
+				Unit:       unit.Name(),
+				InScope:    inScope,
+				AgentAlive: alive,
+				Settings:   settings,
+			})
+		}
+	}
+	sort.Sort(relationUnitDetailsByName(details))
+	return details, nil
+}
+
+type relationUnitDetailsByName []params.RelationUnitDetails
+
+func (d relationUnitDetailsByName) Len() int           { return len(d) }
+func (d relationUnitDetailsByName) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d relationUnitDetailsByName) Less(i, j int) bool { return d[i].Unit < d[j].Unit }
+
 // CharmInfo returns information about the requested charm.
 func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
 	curl, err := charm.ParseURL(args.CharmURL)
@@ -307,10 +739,269 @@ func (c *Client) GetAnnotations(args params.GetAnnotations) (params.GetAnnotatio
 }
 
 // SetAnnotations stores annotations about a given entity.
-func (c *Client) SetAnnotations(args params.SetAnnotations) error {
+func (c *Client) SetAnnotations(args params.SetAnnotations) (err error) {
+	defer c.audit("SetAnnotations", args, &err, args.Tag)
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
 	entity, err := c.api.state.Annotator(args.Tag)
 	if err != nil {
 		return err
 	}
 	return entity.SetAnnotations(args.Pairs)
 }
+
+// AddUser adds a user to the environment.
+func (c *Client) AddUser(args params.AddUser) (err error) {
+	defer c.audit("AddUser", args, &err, state.UserTag(args.Username))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return err
+	}
+	if args.Password == "" {
+		return fmt.Errorf("no password specified for user %q", args.Username)
+	}
+	_, err = c.api.state.AddUserWithRole(args.Username, args.Password, state.UserRole(args.Role))
+	return err
+}
+
+// RemoveUser removes a user from the environment.
+func (c *Client) RemoveUser(args params.RemoveUser) (err error) {
+	defer c.audit("RemoveUser", args, &err, state.UserTag(args.Username))
+	if err := c.requireAdmin(); err != nil {
+		return err
+	}
+	if err := c.api.state.CheckBlocked(state.RemoveBlock); err != nil {
+		return err
+	}
+	if c.api.auth.AuthOwner("user-" + args.Username) {
+		return fmt.Errorf("cannot remove user %q: cannot remove the user you are logged in as", args.Username)
+	}
+	user, err := c.api.state.User(args.Username)
+	if err != nil {
+		return err
+	}
+	return user.Remove()
+}
+
+// SetUserPassword changes the password of a user. Any user may change
+// their own password; only admin users may change the passwords of
+// other users.
+func (c *Client) SetUserPassword(args params.SetUserPassword) (err error) {
+	defer c.audit("SetUserPassword", args, &err, state.UserTag(args.Username))
+	if !c.api.auth.AuthOwner("user-" + args.Username) {
+		if err := c.requireAdmin(); err != nil {
+			return err
+		}
+	}
+	if args.Password == "" {
+		return fmt.Errorf("no password specified for user %q", args.Username)
+	}
+	user, err := c.api.state.User(args.Username)
+	if err != nil {
+		return err
+	}
+	return user.SetPassword(args.Password)
+}
+
+// runTarget identifies a machine or unit on which commands are run.
+type runTarget struct {
+	tag       string
+	machineId string
+	unitId    string
+}
+
+// runTargets returns the machines and units on which the commands
+// specified by args should be run. Units named both directly and
+// through their service are only included once.
+func (c *Client) runTargets(args params.RunParams) ([]runTarget, error) {
+	var targets []runTarget
+	for _, id := range args.Machines {
+		machine, err := c.api.state.Machine(id)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, runTarget{tag: machine.Tag(), machineId: machine.Id()})
+	}
+	var units []*state.Unit
+	for _, name := range args.Services {
+		service, err := c.api.state.Service(name)
+		if err != nil {
+			return nil, err
+		}
+		serviceUnits, err := service.AllUnits()
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, serviceUnits...)
+	}
+	for _, name := range args.Units {
+		unit, err := c.api.state.Unit(name)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	seen := set.NewStrings()
+	for _, unit := range units {
+		if seen.Contains(unit.Name()) {
+			continue
+		}
+		seen.Add(unit.Name())
+		machineId, err := unit.AssignedMachineId()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, runTarget{tag: unit.Tag(), machineId: machineId, unitId: unit.Name()})
+	}
+	return targets, nil
+}
+
+// defaultRunTimeout is how long Run waits for commands to complete
+// when no timeout is specified, and maxRunTimeout is the longest
+// timeout it accepts, so that hung commands cannot hold a Run call
+// open forever.
+var (
+	defaultRunTimeout = 5 * time.Minute
+	maxRunTimeout     = 15 * time.Minute
+)
+
+// Run runs the commands on the specified machines, units and units of
+// services, and waits for them all to complete. Commands that have not
+// completed within the timeout are reported as having timed out.
+func (c *Client) Run(args params.RunParams) (result params.RunResults, err error) {
+	defer c.audit("Run", args, &err, runTags(args)...)
+	if err := c.requireAdmin(); err != nil {
+		return params.RunResults{}, err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return params.RunResults{}, err
+	}
+	if args.Commands == "" {
+		return params.RunResults{}, fmt.Errorf("no commands specified")
+	}
+	targets, err := c.runTargets(args)
+	if err != nil {
+		return params.RunResults{}, err
+	}
+	if len(targets) == 0 {
+		return params.RunResults{}, fmt.Errorf("no machines or units specified")
+	}
+	timeout := args.Timeout
+	if timeout <= 0 {
+		timeout = defaultRunTimeout
+	} else if timeout > maxRunTimeout {
+		timeout = maxRunTimeout
+	}
+	var commands []*state.RunCommand
+	defer func() {
+		// Nobody will be interested in the results after this point,
+		// so make sure commands that never ran are not run later.
+		for _, rc := range commands {
+			if err := rc.Remove(); err != nil {
+				log.Warningf("api: %v", err)
+			}
+		}
+	}()
+	for _, target := range targets {
+		rc, err := c.api.state.EnqueueRunCommand(target.tag, args.Commands, timeout)
+		if err != nil {
+			return params.RunResults{}, err
+		}
+		commands = append(commands, rc)
+	}
+	deadline := time.Now().Add(timeout)
+	results := make([]params.RunResult, len(targets))
+	for i, rc := range commands {
+		result, err := waitRunCommand(rc, deadline)
+		if err != nil {
+			return params.RunResults{}, err
+		}
+		results[i] = params.RunResult{
+			MachineId: targets[i].machineId,
+			UnitId:    targets[i].unitId,
+			Code:      result.Code,
+			Stdout:    result.Stdout,
+			Stderr:    result.Stderr,
+			Error:     result.Error,
+		}
+	}
+	return params.RunResults{Results: results}, nil
+}
+
+// EnqueueAction queues a charm action to be run by a unit, and returns
+// the id of the queued action.
+func (c *Client) EnqueueAction(args params.EnqueueAction) (result params.EnqueueActionResults, err error) {
+	defer c.audit("EnqueueAction", args, &err, state.UnitTag(args.UnitName))
+	if err := c.requireAdmin(); err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	if err := c.api.state.CheckBlocked(state.ChangeBlock); err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	unit, err := c.api.state.Unit(args.UnitName)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	actions, err := unit.Actions()
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	actionParams, err := actions.ParseParamsStrings(args.Name, args.Params)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	a, err := c.api.state.EnqueueAction(args.UnitName, args.Name, actionParams)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	return params.EnqueueActionResults{ActionId: a.Id()}, nil
+}
+
+// ActionResult returns the progress and results of a queued action.
+func (c *Client) ActionResult(args params.ActionResult) (params.ActionResultResults, error) {
+	a, err := c.api.state.Action(args.ActionId)
+	if err != nil {
+		return params.ActionResultResults{}, err
+	}
+	results, message := a.Results()
+	return params.ActionResultResults{
+		UnitName: a.UnitName(),
+		Name:     a.Name(),
+		Params:   a.Params(),
+		Enqueued: a.Enqueued(),
+		Status:   string(a.Status()),
+		Results:  results,
+		Message:  message,
+	}, nil
+}
+
+// waitRunCommand waits for the run command to complete, and returns its
+// result. If the deadline passes first, a timed out result is returned.
+func waitRunCommand(rc *state.RunCommand, deadline time.Time) (state.RunResult, error) {
+	timeout := time.After(deadline.Sub(time.Now()))
+	w := rc.Watch()
+	defer w.Stop()
+	for {
+		select {
+		case _, ok := <-w.Changes():
+			if !ok {
+				return state.RunResult{}, watcher.MustErr(w)
+			}
+			if err := rc.Refresh(); err != nil {
+				return state.RunResult{}, err
+			}
+			if result, done := rc.Result(); done {
+				return result, nil
+			}
+		case <-timeout:
+			return state.RunResult{Code: -1, Error: "timed out"}, nil
+		}
+	}
+	panic("unreachable")
+}
@@ -5,7 +5,13 @@ package client_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
 	. "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+	"launchpad.net/juju-core/bundle"
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/errors"
@@ -59,6 +65,151 @@ func (s *clientSuite) TestClientServiceSetYAML(c *C) {
 	})
 }
 
+func (s *clientSuite) TestClientServiceConfigHistory(c *C) {
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	err = dummy.UpdateConfigSettings(charm.Settings{"title": "xxx", "skill-level": 3})
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().ServiceSet("dummy", map[string]string{
+		"title":       "yyy",
+		"skill-level": "",
+	})
+	c.Assert(err, IsNil)
+
+	changes, err := s.APIState.Client().ServiceConfigHistory("dummy")
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Revision, Equals, 1)
+	c.Assert(changes[0].User, Equals, "")
+	c.Assert(changes[1].Revision, Equals, 2)
+	c.Assert(changes[1].User, Equals, "user-admin")
+	c.Assert(changes[1].CharmURL, Equals, "local:series/dummy-1")
+	c.Assert(changes[1].Items, DeepEquals, []params.ServiceConfigItemChange{
+		{Key: "skill-level", OldValue: float64(3)},
+		{Key: "title", OldValue: "xxx", NewValue: "yyy"},
+	})
+
+	_, err = s.APIState.Client().ServiceConfigHistory("unknown")
+	c.Assert(err, ErrorMatches, `service "unknown" not found`)
+}
+
+func (s *clientSuite) TestClientServiceConfigHistoryRedactsSecretsForReadOnlyUsers(c *C) {
+	s.setUpScenario(c)
+	s.addSecretService(c)
+	changes, err := s.APIState.Client().ServiceConfigHistory("secret")
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Items, DeepEquals, []params.ServiceConfigItemChange{
+		{Key: "admin-password", NewValue: "sekrit"},
+	})
+
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	changes, err = st.Client().ServiceConfigHistory("secret")
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Items, DeepEquals, []params.ServiceConfigItemChange{
+		{Key: "admin-password", NewValue: "<redacted>"},
+	})
+}
+
+func (s *clientSuite) TestClientStatusHistory(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusError, "hook failed")
+	c.Assert(err, IsNil)
+	err = unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+
+	changes, err := s.APIState.Client().StatusHistory(unit.Tag())
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Status, Equals, params.StatusError)
+	c.Assert(changes[0].Info, Equals, "hook failed")
+	c.Assert(changes[1].Status, Equals, params.StatusStarted)
+	c.Assert(changes[1].Info, Equals, "")
+
+	_, err = s.APIState.Client().StatusHistory("unit-dummy-9")
+	c.Assert(err, ErrorMatches, `unit "dummy/9" not found`)
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+	_, err = s.APIState.Client().StatusHistory("service-dummy")
+	c.Assert(err, ErrorMatches, `entity "service-dummy" does not have a status history`)
+}
+
+func (s *clientSuite) TestClientShowRelation(c *C) {
+	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	mysql, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, IsNil)
+	wu, err := wordpress.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = mysql.AddUnit()
+	c.Assert(err, IsNil)
+	wru, err := rel.Unit(wu)
+	c.Assert(err, IsNil)
+	err = wru.EnterScope(map[string]interface{}{"private-address": "10.0.0.1"})
+	c.Assert(err, IsNil)
+
+	expectWordpress := params.RelationUnitDetails{
+		Unit:     "wordpress/0",
+		InScope:  true,
+		Settings: map[string]interface{}{"private-address": "10.0.0.1"},
+	}
+	relations, err := s.APIState.Client().ShowRelation("wordpress:db", "")
+	c.Assert(err, IsNil)
+	c.Assert(relations, DeepEquals, []params.RelationDetails{{
+		Key: rel.String(),
+		Id:  rel.Id(),
+		Units: []params.RelationUnitDetails{
+			{Unit: "mysql/0"},
+			expectWordpress,
+		},
+	}})
+
+	relations, err = s.APIState.Client().ShowRelation("mysql", "wordpress/0")
+	c.Assert(err, IsNil)
+	c.Assert(relations, HasLen, 1)
+	c.Assert(relations[0].Units, DeepEquals, []params.RelationUnitDetails{expectWordpress})
+
+	_, err = s.APIState.Client().ShowRelation("wordpress:cache", "")
+	c.Assert(err, ErrorMatches, `"wordpress:cache" has no relations`)
+	_, err = s.APIState.Client().ShowRelation("wordpress:nonsense", "")
+	c.Assert(err, ErrorMatches, `service "wordpress" has no "nonsense" relation`)
+	_, err = s.APIState.Client().ShowRelation("unknown:db", "")
+	c.Assert(err, ErrorMatches, `service "unknown" not found`)
+	_, err = s.APIState.Client().ShowRelation("wordpress:db", "wordpress/9")
+	c.Assert(err, ErrorMatches, `unit "wordpress/9" not found`)
+}
+
+func (s *clientSuite) TestClientServiceRevertConfig(c *C) {
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	err = dummy.UpdateConfigSettings(charm.Settings{"title": "good"})
+	c.Assert(err, IsNil)
+	err = dummy.UpdateConfigSettings(charm.Settings{"title": "bad", "username": "nobody"})
+	c.Assert(err, IsNil)
+
+	err = s.APIState.Client().ServiceRevertConfig("dummy", 1)
+	c.Assert(err, IsNil)
+	settings, err := dummy.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"title": "good"})
+	history, err := dummy.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	c.Assert(history[2].User, Equals, "user-admin")
+
+	err = s.APIState.Client().ServiceRevertConfig("dummy", 42)
+	c.Assert(err, ErrorMatches, `config revision 42 of service "dummy" not found`)
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+}
+
 var clientAddServiceUnitsTests = []struct {
 	about    string
 	expected []string
@@ -74,6 +225,43 @@ var clientAddServiceUnitsTests = []struct {
 	},
 }
 
+func (s *clientSuite) TestClientBlocks(c *C) {
+	_, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	client := s.APIState.Client()
+
+	err = client.SwitchBlockOn("everything", "")
+	c.Assert(err, ErrorMatches, `unknown block type "everything"`)
+	err = client.SwitchBlockOn("remove-object", "production")
+	c.Assert(err, IsNil)
+	blocks, err := client.ListBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, DeepEquals, []params.Block{{"remove-object", "production"}})
+
+	// Removals are blocked, but other changes are not.
+	err = client.ServiceDestroy("dummy")
+	c.Assert(err, ErrorMatches, "operation blocked by the remove-object block: production")
+	c.Assert(params.ErrCode(err), Equals, params.CodeOperationBlocked)
+	_, err = client.AddServiceUnits("dummy", 1)
+	c.Assert(err, IsNil)
+
+	err = client.SwitchBlockOn("all-changes", "")
+	c.Assert(err, IsNil)
+	_, err = client.AddServiceUnits("dummy", 1)
+	c.Assert(err, ErrorMatches, "operation blocked by the all-changes block")
+	c.Assert(params.ErrCode(err), Equals, params.CodeOperationBlocked)
+
+	err = client.SwitchBlockOff("all-changes")
+	c.Assert(err, IsNil)
+	err = client.SwitchBlockOff("remove-object")
+	c.Assert(err, IsNil)
+	blocks, err = client.ListBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, HasLen, 0)
+	err = client.ServiceDestroy("dummy")
+	c.Assert(err, IsNil)
+}
+
 func (s *clientSuite) TestClientAddServiceUnits(c *C) {
 	_, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
 	c.Assert(err, IsNil)
@@ -246,6 +434,70 @@ func (s *clientSuite) TestClientServiceGet(c *C) {
 	})
 }
 
+func (s *clientSuite) TestClientServiceGetRedactsSecretsForReadOnlyUsers(c *C) {
+	s.setUpScenario(c)
+	s.addSecretService(c)
+	results, err := s.APIState.Client().ServiceGet("secret")
+	c.Assert(err, IsNil)
+	c.Assert(results.Config["admin-password"], DeepEquals, map[string]interface{}{
+		"type":        "string",
+		"value":       "sekrit",
+		"description": "The admin password.",
+	})
+
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	results, err = st.Client().ServiceGet("secret")
+	c.Assert(err, IsNil)
+	c.Assert(results.Config["admin-password"], DeepEquals, map[string]interface{}{
+		"type":        "string",
+		"value":       "<redacted>",
+		"description": "The admin password.",
+	})
+	c.Assert(results.Config["title"], DeepEquals, map[string]interface{}{
+		"type":        "string",
+		"value":       "My Title",
+		"description": "A title.",
+		"default":     true,
+	})
+}
+
+func (s *clientSuite) TestClientSetUnitsPaused(c *C) {
+	s.setUpScenario(c)
+	t0 := time.Now().Add(-time.Millisecond)
+	assertPaused := func(name string, paused bool) {
+		unit, err := s.State.Unit(name)
+		c.Assert(err, IsNil)
+		c.Assert(unit.IsPaused(), Equals, paused)
+	}
+	client := s.APIState.Client()
+	err := client.SetUnitsPaused([]string{"wordpress/0", "wordpress/1"}, true)
+	c.Assert(err, IsNil)
+	assertPaused("wordpress/0", true)
+	assertPaused("wordpress/1", true)
+
+	err = client.SetUnitsPaused([]string{"wordpress/1"}, false)
+	c.Assert(err, IsNil)
+	assertPaused("wordpress/0", true)
+	assertPaused("wordpress/1", false)
+
+	err = client.SetUnitsPaused([]string{"wordpress/99"}, true)
+	c.Assert(err, ErrorMatches, `unit "wordpress/99" not found`)
+
+	entries, err := client.AuditLog(params.AuditLog{After: t0})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	c.Assert(entries[0].Method, Equals, "SetUnitsPaused")
+	c.Assert(entries[0].Entities, DeepEquals, []string{"unit-wordpress-0", "unit-wordpress-1"})
+
+	err = client.SwitchBlockOn("all-changes", "maintenance")
+	c.Assert(err, IsNil)
+	err = client.SetUnitsPaused([]string{"wordpress/1"}, true)
+	c.Assert(err, ErrorMatches, "operation blocked by the all-changes block: maintenance")
+	c.Assert(params.ErrCode(err), Equals, params.CodeOperationBlocked)
+	assertPaused("wordpress/1", false)
+}
+
 func (s *clientSuite) TestClientServiceExpose(c *C) {
 	s.setUpScenario(c)
 	serviceName := "wordpress"
@@ -414,6 +666,175 @@ func (s *clientSuite) TestClientServiceDeployConfigError(c *C) {
 	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
 }
 
+func (s *clientSuite) TestClientDeployBundle(c *C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	addCharm(c, store, "wordpress")
+	addCharm(c, store, "mysql")
+	err := s.APIState.Client().DeployBundle(`
+services:
+  wordpress:
+    charm: cs:precise/wordpress
+    num_units: 1
+    expose: true
+  mysql:
+    charm: mysql
+    num_units: 1
+relations:
+  - [wordpress, mysql]
+`)
+	c.Assert(err, IsNil)
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	c.Assert(wordpress.IsExposed(), Equals, true)
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, IsNil)
+	curl, _ := mysql.CharmURL()
+	c.Assert(curl.String(), Equals, "cs:precise/mysql-1")
+	_, err = s.State.KeyRelation("wordpress:db mysql:server")
+	c.Assert(err, IsNil)
+}
+
+func (s *clientSuite) TestClientDeployBundleErrors(c *C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	addCharm(c, store, "wordpress")
+	for i, test := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: "services: {}",
+		err:  "bundle has no services",
+	}, {
+		yaml: "services: {wordpress: {charm: local:precise/wordpress}}",
+		err:  `service "wordpress": charm url has unsupported schema "local"`,
+	}, {
+		yaml: "services: {mysql: {charm: cs:precise/mysql}}",
+		err:  `cannot deploy service "mysql": cannot get latest charm revision: charm not found in mock store: cs:precise/mysql`,
+	}} {
+		c.Logf("test %d: %s", i, test.yaml)
+		err := s.APIState.Client().DeployBundle(test.yaml)
+		c.Check(err, ErrorMatches, test.err)
+		services, err := s.State.AllServices()
+		c.Assert(err, IsNil)
+		c.Assert(services, HasLen, 0)
+	}
+}
+
+func (s *clientSuite) TestClientExportBundle(c *C) {
+	s.setUpScenario(c)
+	data, redacted, err := s.APIState.Client().ExportBundle(true)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, HasLen, 0)
+	b, err := bundle.Parse([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(b.ServiceNames(), DeepEquals, []string{"logging", "mysql", "wordpress"})
+	c.Assert(b.Services["wordpress"].Charm, Equals, "local:series/wordpress-3")
+	c.Assert(b.Services["wordpress"].NumUnits, Equals, 2)
+	c.Assert(b.Relations, DeepEquals, [][]string{
+		{"logging:logging-directory", "wordpress:logging-dir"},
+	})
+}
+
+// addSecretService adds a service named "secret" with a secret
+// configuration option, admin-password, set to "sekrit".
+func (s *clientSuite) addSecretService(c *C) {
+	repoPath := c.MkDir()
+	dir := coretesting.Charms.ClonedDirPath(filepath.Join(repoPath, "series"), "dummy")
+	err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+options:
+  title: {default: My Title, description: A title., type: string}
+  admin-password: {description: The admin password., type: string}
+`), 0644)
+	c.Assert(err, IsNil)
+	repo := &charm.LocalRepository{Path: repoPath}
+	ch, err := s.Conn.PutCharm(charm.MustParseURL("local:series/dummy"), repo, false)
+	c.Assert(err, IsNil)
+	svc, err := s.State.AddService("secret", ch)
+	c.Assert(err, IsNil)
+	err = svc.UpdateConfigSettings(charm.Settings{"admin-password": "sekrit"})
+	c.Assert(err, IsNil)
+}
+
+func (s *clientSuite) TestClientExportBundleRedactsSecretsForReadOnlyUsers(c *C) {
+	s.setUpScenario(c)
+	s.addSecretService(c)
+	data, redacted, err := s.APIState.Client().ExportBundle(false)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, HasLen, 0)
+	b, err := bundle.Parse([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(b.Services["secret"].Options, DeepEquals, map[string]interface{}{
+		"admin-password": "sekrit",
+	})
+
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	data, redacted, err = st.Client().ExportBundle(false)
+	c.Assert(err, IsNil)
+	c.Assert(redacted, DeepEquals, []string{"secret:admin-password"})
+	b, err = bundle.Parse([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(b.Services["secret"].Options, HasLen, 0)
+}
+
+func (s *clientSuite) TestClientDiffBundle(c *C) {
+	s.setUpScenario(c)
+	data, err := s.APIState.Client().DiffBundle(`
+services:
+  wordpress:
+    charm: local:series/wordpress
+    constraints: mem=2G
+  mysql:
+    charm: local:series/mysql
+  varnish:
+    charm: cs:precise/varnish
+relations:
+  - [wordpress, mysql]
+`)
+	c.Assert(err, IsNil)
+	var diff bundle.Diff
+	err = goyaml.Unmarshal([]byte(data), &diff)
+	c.Assert(err, IsNil)
+	c.Assert(diff, DeepEquals, bundle.Diff{
+		MissingServices: []string{"varnish"},
+		ExtraServices:   []string{"logging"},
+		Services: map[string]*bundle.ServiceDiff{
+			"wordpress": {
+				Constraints: &bundle.ValueDiff{Bundle: "mem=2048M", Environment: ""},
+			},
+		},
+		MissingRelations: [][]string{{"mysql:server", "wordpress:db"}},
+		ExtraRelations:   [][]string{{"logging:logging-directory", "wordpress:logging-dir"}},
+	})
+
+	_, err = s.APIState.Client().DiffBundle("services: {}")
+	c.Assert(err, ErrorMatches, "bundle has no services")
+}
+
+func (s *clientSuite) TestClientDiffBundleRedactsSecretsForReadOnlyUsers(c *C) {
+	s.setUpScenario(c)
+	s.addSecretService(c)
+	bundleYAML := "services: {secret: {charm: local:series/dummy, options: {admin-password: guess}}}"
+	data, err := s.APIState.Client().DiffBundle(bundleYAML)
+	c.Assert(err, IsNil)
+	var diff bundle.Diff
+	err = goyaml.Unmarshal([]byte(data), &diff)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Services["secret"].Options, DeepEquals, map[string]*bundle.ValueDiff{
+		"admin-password": {Bundle: "guess", Environment: "sekrit"},
+	})
+
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	data, err = st.Client().DiffBundle(bundleYAML)
+	c.Assert(err, IsNil)
+	diff = bundle.Diff{}
+	err = goyaml.Unmarshal([]byte(data), &diff)
+	c.Assert(err, IsNil)
+	c.Assert(diff.Services["secret"], IsNil)
+}
+
 func (s *clientSuite) TestClientServiceSetCharm(c *C) {
 	store, restore := makeMockCharmStore()
 	defer restore()
@@ -580,3 +1001,374 @@ func (s *clientSuite) TestClientWatchAll(c *C) {
 		}
 	}
 }
+
+func (s *clientSuite) TestClientWatchDebugLog(c *C) {
+	path := filepath.Join(c.MkDir(), "all-machines.log")
+	err := ioutil.WriteFile(path, []byte(`machine-0: 2013-10-16 12:00:00 INFO juju.cmd supercommand.go:286 running jujud
+unit-wordpress-0: 2013-10-16 12:00:01 DEBUG juju.worker.uniter uniter.go:350 joining relation
+unit-wordpress-0: 2013-10-16 12:00:02 ERROR juju.worker.uniter uniter.go:350 hook failed
+machine-1: 2013-10-16 12:00:03 WARNING juju.worker.machiner machiner.go:53 cannot set status
+`), 0644)
+	c.Assert(err, IsNil)
+	defer client.SetDebugLogPath(path)()
+
+	watcher, err := s.APIState.Client().WatchDebugLog(params.DebugLog{
+		Entities: []string{"unit-wordpress-0", "machine-1"},
+		Level:    "warning",
+		Replay:   -1,
+	})
+	c.Assert(err, IsNil)
+	defer func() {
+		err := watcher.Stop()
+		c.Assert(err, IsNil)
+	}()
+	lines, err := watcher.Next()
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{
+		"unit-wordpress-0: 2013-10-16 12:00:02 ERROR juju.worker.uniter uniter.go:350 hook failed",
+		"machine-1: 2013-10-16 12:00:03 WARNING juju.worker.machiner machiner.go:53 cannot set status",
+	})
+	_, err = watcher.Next()
+	c.Assert(params.ErrCode(err), Equals, params.CodeStopped)
+
+	_, err = s.APIState.Client().WatchDebugLog(params.DebugLog{Level: "loud"})
+	c.Assert(err, ErrorMatches, `unknown log level "loud"`)
+}
+
+// fakeRunAgents completes the commands queued for the given receivers,
+// reporting each receiver's tag as its output, until stopped.
+func (s *clientSuite) fakeRunAgents(c *C, receivers ...string) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(coretesting.ShortWait):
+			}
+			for _, tag := range receivers {
+				pending, err := s.State.PendingRunCommands(tag)
+				c.Check(err, IsNil)
+				for _, rc := range pending {
+					err := rc.Complete(state.RunResult{Stdout: []byte(tag)})
+					if !errors.IsNotFoundError(err) {
+						c.Check(err, IsNil)
+					}
+				}
+			}
+			s.State.StartSync()
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *clientSuite) TestClientRun(c *C) {
+	s.setUpScenario(c)
+	stop := s.fakeRunAgents(c, "machine-0", "unit-wordpress-0", "unit-wordpress-1")
+	defer stop()
+	results, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Timeout:  coretesting.LongWait,
+		Machines: []string{"0"},
+		Services: []string{"wordpress"},
+		Units:    []string{"wordpress/1"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, DeepEquals, []params.RunResult{{
+		MachineId: "0",
+		Stdout:    []byte("machine-0"),
+	}, {
+		MachineId: "1",
+		UnitId:    "wordpress/0",
+		Stdout:    []byte("unit-wordpress-0"),
+	}, {
+		MachineId: "2",
+		UnitId:    "wordpress/1",
+		Stdout:    []byte("unit-wordpress-1"),
+	}})
+
+	// Commands are removed once their results have been collected.
+	for _, tag := range []string{"machine-0", "unit-wordpress-0", "unit-wordpress-1"} {
+		pending, err := s.State.PendingRunCommands(tag)
+		c.Assert(err, IsNil)
+		c.Assert(pending, HasLen, 0)
+	}
+}
+
+func (s *clientSuite) TestClientRunTimeout(c *C) {
+	s.setUpScenario(c)
+	results, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Timeout:  coretesting.ShortWait,
+		Units:    []string{"wordpress/0"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, DeepEquals, []params.RunResult{{
+		MachineId: "1",
+		UnitId:    "wordpress/0",
+		Code:      -1,
+		Error:     "timed out",
+	}})
+
+	// The timed out commands will not be run later.
+	pending, err := s.State.PendingRunCommands("unit-wordpress-0")
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 0)
+}
+
+func (s *clientSuite) TestClientRunDefaultAndMaxTimeout(c *C) {
+	s.setUpScenario(c)
+	defer client.SetRunTimeouts(coretesting.ShortWait, coretesting.ShortWait)()
+	for i, timeout := range []time.Duration{0, 24 * time.Hour} {
+		c.Logf("test %d: timeout %v", i, timeout)
+		results, err := s.APIState.Client().Run(params.RunParams{
+			Commands: "hostname",
+			Timeout:  timeout,
+			Units:    []string{"wordpress/0"},
+		})
+		c.Assert(err, IsNil)
+		c.Assert(results, DeepEquals, []params.RunResult{{
+			MachineId: "1",
+			UnitId:    "wordpress/0",
+			Code:      -1,
+			Error:     "timed out",
+		}})
+	}
+}
+
+func (s *clientSuite) TestClientRunErrors(c *C) {
+	s.setUpScenario(c)
+	for i, t := range []struct {
+		args params.RunParams
+		err  string
+	}{{
+		args: params.RunParams{Units: []string{"wordpress/0"}},
+		err:  "no commands specified",
+	}, {
+		args: params.RunParams{Commands: "hostname"},
+		err:  "no machines or units specified",
+	}, {
+		args: params.RunParams{Commands: "hostname", Machines: []string{"42"}},
+		err:  `machine 42 not found`,
+	}, {
+		args: params.RunParams{Commands: "hostname", Services: []string{"nosuch"}},
+		err:  `service "nosuch" not found`,
+	}, {
+		args: params.RunParams{Commands: "hostname", Units: []string{"nosuch/0"}},
+		err:  `unit "nosuch/0" not found`,
+	}} {
+		c.Logf("test %d: %+v", i, t.args)
+		_, err := s.APIState.Client().Run(t.args)
+		c.Assert(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *clientSuite) TestClientEnqueueAction(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+
+	id, err := s.APIState.Client().EnqueueAction("dummy/0", "snapshot", map[string]string{"outfile": "bar.gz"})
+	c.Assert(err, IsNil)
+	pending, err := s.State.PendingActions(unit.Name())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 1)
+	c.Assert(pending[0].Id(), Equals, id)
+	c.Assert(pending[0].Name(), Equals, "snapshot")
+	c.Assert(pending[0].Params(), DeepEquals, map[string]interface{}{"outfile": "bar.gz"})
+
+	_, err = s.APIState.Client().EnqueueAction("dummy/0", "explode", nil)
+	c.Assert(err, ErrorMatches, `unknown action "explode"`)
+	_, err = s.APIState.Client().EnqueueAction("dummy/0", "snapshot", map[string]string{"speed": "1"})
+	c.Assert(err, ErrorMatches, `action "snapshot" has no parameter "speed"`)
+	_, err = s.APIState.Client().EnqueueAction("dummy/9", "snapshot", nil)
+	c.Assert(err, ErrorMatches, `unit "dummy/9" not found`)
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+}
+
+func (s *clientSuite) TestClientActionResult(c *C) {
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	a, err := s.State.EnqueueAction(unit.Name(), "snapshot", nil)
+	c.Assert(err, IsNil)
+
+	result, err := s.APIState.Client().ActionResult(a.Id())
+	c.Assert(err, IsNil)
+	c.Assert(result.UnitName, Equals, "dummy/0")
+	c.Assert(result.Name, Equals, "snapshot")
+	c.Assert(result.Params, DeepEquals, map[string]interface{}{"outfile": "foo.bz2"})
+	c.Assert(result.Status, Equals, "pending")
+
+	err = a.Fail(map[string]interface{}{"size": "0"}, "disk full")
+	c.Assert(err, IsNil)
+	result, err = s.APIState.Client().ActionResult(a.Id())
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, "failed")
+	c.Assert(result.Results, DeepEquals, map[string]interface{}{"size": "0"})
+	c.Assert(result.Message, Equals, "disk full")
+
+	_, err = s.APIState.Client().ActionResult("999")
+	c.Assert(err, ErrorMatches, `action 999 not found`)
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+}
+
+func (s *clientSuite) TestClientAuditLog(c *C) {
+	s.setUpScenario(c)
+	// Times are stored with millisecond precision.
+	t0 := time.Now().Add(-time.Millisecond)
+	err := s.APIState.Client().AddUser("foo", "secret", "read-only")
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().DestroyRelation("nosuch1", "nosuch2")
+	c.Assert(err, NotNil)
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	err = st.Client().ServiceExpose("wordpress")
+	c.Assert(err, ErrorMatches, "permission denied")
+	// Read-only calls are not recorded.
+	_, err = s.APIState.Client().AuditLog(params.AuditLog{})
+	c.Assert(err, IsNil)
+
+	entries, err := s.APIState.Client().AuditLog(params.AuditLog{After: t0})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	for _, entry := range entries {
+		c.Assert(entry.Time.Before(t0), Equals, false)
+		entry.Time = time.Time{}
+	}
+	c.Assert(entries[0], DeepEquals, params.AuditEntry{
+		User:     "user-admin",
+		Method:   "AddUser",
+		Args:     `{"Password":"<redacted>","Role":"read-only","Username":"foo"}`,
+		Entities: []string{"user-foo"},
+	})
+	c.Assert(entries[1].Method, Equals, "DestroyRelation")
+	c.Assert(entries[1].Entities, DeepEquals, []string{"service-nosuch1", "service-nosuch2"})
+	c.Assert(entries[1].Error, Not(Equals), "")
+	c.Assert(entries[2], DeepEquals, params.AuditEntry{
+		User:     "user-readonly",
+		Method:   "ServiceExpose",
+		Args:     `{"ServiceName":"wordpress"}`,
+		Entities: []string{"service-wordpress"},
+		Error:    "permission denied",
+	})
+
+	entries, err = s.APIState.Client().AuditLog(params.AuditLog{After: t0, User: "user-readonly"})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Method, Equals, "ServiceExpose")
+
+	entries, err = s.APIState.Client().AuditLog(params.AuditLog{After: t0, Entity: "user-foo"})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Method, Equals, "AddUser")
+
+	entries, err = s.APIState.Client().AuditLog(params.AuditLog{Before: t0, Entity: "user-foo"})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+}
+
+func (s *clientSuite) TestClientAuditLogRedactsSecretOptions(c *C) {
+	s.setUpScenario(c)
+	t0 := time.Now().Add(-time.Millisecond)
+	// The options are unknown to the charm, but the call is recorded
+	// even though it fails.
+	err := s.APIState.Client().ServiceSet("wordpress", map[string]string{
+		"blog-title":  "Secrets",
+		"api-key":     "k",
+		"auth_token":  "t",
+		"credentials": "c",
+		"db-passwd":   "p",
+	})
+	c.Assert(err, ErrorMatches, `unknown option ".*"`)
+	entries, err := s.APIState.Client().AuditLog(params.AuditLog{After: t0})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Method, Equals, "ServiceSet")
+	c.Assert(entries[0].Args, Equals, `{"Options":{"api-key":"<redacted>","auth_token":"<redacted>","blog-title":"Secrets","credentials":"<redacted>","db-passwd":"<redacted>"},"ServiceName":"wordpress"}`)
+}
+
+func (s *clientSuite) TestClientAddUser(c *C) {
+	err := s.APIState.Client().AddUser("foo", "secret", "read-only")
+	c.Assert(err, IsNil)
+	u, err := s.State.User("foo")
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.ReadOnlyRole)
+	c.Assert(u.PasswordValid("secret"), Equals, true)
+
+	err = s.APIState.Client().AddUser("foo", "secret", "admin")
+	c.Assert(err, ErrorMatches, "user already exists")
+	err = s.APIState.Client().AddUser("bar", "secret", "superuser")
+	c.Assert(err, ErrorMatches, `invalid role "superuser" for user "bar"`)
+	err = s.APIState.Client().AddUser("bar", "", "admin")
+	c.Assert(err, ErrorMatches, `no password specified for user "bar"`)
+}
+
+func (s *clientSuite) TestClientRemoveUser(c *C) {
+	s.setUpScenario(c)
+	err := s.APIState.Client().RemoveUser("readonly")
+	c.Assert(err, IsNil)
+	_, err = s.State.User("readonly")
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+
+	err = s.APIState.Client().RemoveUser("readonly")
+	c.Assert(err, ErrorMatches, `user "readonly" not found`)
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+
+	err = s.APIState.Client().RemoveUser("admin")
+	c.Assert(err, ErrorMatches, `cannot remove user "admin": cannot remove the user you are logged in as`)
+
+	st := s.openAs(c, "user-other")
+	defer st.Close()
+	err = st.Client().RemoveUser("admin")
+	c.Assert(err, ErrorMatches, `cannot remove user "admin": the admin user cannot be removed`)
+}
+
+func (s *clientSuite) TestClientSetUserPassword(c *C) {
+	s.setUpScenario(c)
+
+	// A read-only user may change their own password,
+	// but nobody else's.
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	err := st.Client().SetUserPassword("readonly", "new password")
+	c.Assert(err, IsNil)
+	u, err := s.State.User("readonly")
+	c.Assert(err, IsNil)
+	c.Assert(u.PasswordValid("new password"), Equals, true)
+
+	err = st.Client().SetUserPassword("other", "new password")
+	c.Assert(err, ErrorMatches, "permission denied")
+
+	// An admin user may change anybody's password.
+	err = s.APIState.Client().SetUserPassword("other", "new password")
+	c.Assert(err, IsNil)
+	u, err = s.State.User("other")
+	c.Assert(err, IsNil)
+	c.Assert(u.PasswordValid("new password"), Equals, true)
+
+	err = s.APIState.Client().SetUserPassword("other", "")
+	c.Assert(err, ErrorMatches, `no password specified for user "other"`)
+	err = s.APIState.Client().SetUserPassword("nosuch", "foo")
+	c.Assert(err, ErrorMatches, `user "nosuch" not found`)
+}
+
+func (s *clientSuite) TestClientReadOnlyUser(c *C) {
+	s.setUpScenario(c)
+	st := s.openAs(c, "user-readonly")
+	defer st.Close()
+	_, err := st.Client().Status()
+	c.Assert(err, IsNil)
+	_, err = st.Client().CharmInfo("local:series/wordpress-3")
+	c.Assert(err, IsNil)
+	err = st.Client().ServiceDestroy("wordpress")
+	c.Assert(err, ErrorMatches, "permission denied")
+	c.Assert(params.ErrCode(err), Equals, params.CodeUnauthorized)
+}
@@ -0,0 +1,44 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/log/tailer"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// debugLogPath holds the path of the log file into which rsyslog on
+// the state server gathers the log messages of all machines.
+var debugLogPath = "/var/log/juju/all-machines.log"
+
+// WatchDebugLog returns a watcher that sends the lines of the
+// consolidated log selected by the given parameters. As log lines may
+// hold secrets, only admin users may watch them.
+func (c *Client) WatchDebugLog(args params.DebugLog) (params.DebugLogWatcherId, error) {
+	if err := c.requireAdmin(); err != nil {
+		return params.DebugLogWatcherId{}, err
+	}
+	filter := tailer.Filter{
+		Entities: args.Entities,
+		Modules:  args.Modules,
+	}
+	if args.Level != "" {
+		level, ok := tailer.ParseLevel(args.Level)
+		if !ok || level == loggo.UNSPECIFIED {
+			return params.DebugLogWatcherId{}, fmt.Errorf("unknown log level %q", args.Level)
+		}
+		filter.Level = level
+	}
+	t, err := tailer.NewTailer(debugLogPath, filter, args.Replay, args.Follow)
+	if err != nil {
+		return params.DebugLogWatcherId{}, fmt.Errorf("cannot read log: %v", err)
+	}
+	return params.DebugLogWatcherId{
+		DebugLogWatcherId: c.api.resources.Register(t),
+	}, nil
+}
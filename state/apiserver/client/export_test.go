@@ -0,0 +1,26 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"time"
+)
+
+// SetDebugLogPath sets the path of the log file read by WatchDebugLog,
+// and returns a function that restores the original value.
+func SetDebugLogPath(path string) (restore func()) {
+	old := debugLogPath
+	debugLogPath = path
+	return func() { debugLogPath = old }
+}
+
+// SetRunTimeouts sets the default and maximum timeouts used by Run,
+// and returns a function that restores the original values.
+func SetRunTimeouts(defaultTimeout, maxTimeout time.Duration) (restore func()) {
+	oldDefault, oldMax := defaultRunTimeout, maxRunTimeout
+	defaultRunTimeout, maxRunTimeout = defaultTimeout, maxTimeout
+	return func() {
+		defaultRunTimeout, maxRunTimeout = oldDefault, oldMax
+	}
+}
@@ -4,12 +4,16 @@
 package client_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
 	. "launchpad.net/gocheck"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
-	"strings"
+	"launchpad.net/juju-core/state/apiserver/client"
 )
 
 type permSuite struct {
@@ -35,7 +39,7 @@ var operationPermTests = []struct {
 }{{
 	about: "Client.Status",
 	op:    opClientStatus,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.ServiceSet",
 	op:    opClientServiceSet,
@@ -47,11 +51,43 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.ServiceGet",
 	op:    opClientServiceGet,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.ServiceConfigHistory",
+	op:    opClientServiceConfigHistory,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.StatusHistory",
+	op:    opClientStatusHistory,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.ShowRelation",
+	op:    opClientShowRelation,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.SwitchBlockOn",
+	op:    opClientSwitchBlockOn,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.SwitchBlockOff",
+	op:    opClientSwitchBlockOff,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ListBlocks",
+	op:    opClientListBlocks,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.ServiceRevertConfig",
+	op:    opClientServiceRevertConfig,
 	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.Resolved",
 	op:    opClientResolved,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.SetUnitsPaused",
+	op:    opClientSetUnitsPaused,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.ServiceExpose",
 	op:    opClientServiceExpose,
@@ -64,6 +100,18 @@ var operationPermTests = []struct {
 	about: "Client.ServiceDeploy",
 	op:    opClientServiceDeploy,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.DeployBundle",
+	op:    opClientDeployBundle,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ExportBundle",
+	op:    opClientExportBundle,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.DiffBundle",
+	op:    opClientDiffBundle,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.ServiceSetCharm",
 	op:    opClientServiceSetCharm,
@@ -71,7 +119,7 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.GetAnnotations",
 	op:    opClientGetAnnotations,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.SetAnnotations",
 	op:    opClientSetAnnotations,
@@ -91,7 +139,7 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.GetServiceConstraints",
 	op:    opClientGetServiceConstraints,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.SetServiceConstraints",
 	op:    opClientSetServiceConstraints,
@@ -99,11 +147,11 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.WatchAll",
 	op:    opClientWatchAll,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.CharmInfo",
 	op:    opClientCharmInfo,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-readonly"},
 }, {
 	about: "Client.AddRelation",
 	op:    opClientAddRelation,
@@ -112,6 +160,38 @@ var operationPermTests = []struct {
 	about: "Client.DestroyRelation",
 	op:    opClientDestroyRelation,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.Run",
+	op:    opClientRun,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.EnqueueAction",
+	op:    opClientEnqueueAction,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ActionResult",
+	op:    opClientActionResult,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.AddUser",
+	op:    opClientAddUser,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RemoveUser",
+	op:    opClientRemoveUser,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.SetUserPassword",
+	op:    opClientSetUserPassword,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AuditLog",
+	op:    opClientAuditLog,
+	allow: []string{"user-admin", "user-other", "user-readonly"},
+}, {
+	about: "Client.WatchDebugLog",
+	op:    opClientWatchDebugLog,
+	allow: []string{"user-admin", "user-other"},
 }}
 
 // allowed returns the set of allowed entities given an allow list and a
@@ -230,6 +310,47 @@ func opClientServiceGet(c *C, st *api.State, mst *state.State) (func(), error) {
 	return func() {}, nil
 }
 
+func opClientServiceConfigHistory(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ServiceConfigHistory("wordpress")
+	return func() {}, err
+}
+
+func opClientStatusHistory(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().StatusHistory("unit-wordpress-0")
+	return func() {}, err
+}
+
+func opClientShowRelation(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ShowRelation("wordpress:juju-info", "")
+	return func() {}, err
+}
+
+func opClientSwitchBlockOn(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().SwitchBlockOn("destroy-environment", "")
+	return func() {
+		err := mst.SwitchBlockOff(state.DestroyBlock)
+		c.Assert(err, IsNil)
+	}, err
+}
+
+func opClientSwitchBlockOff(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().SwitchBlockOff("destroy-environment")
+	return func() {}, err
+}
+
+func opClientListBlocks(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ListBlocks()
+	return func() {}, err
+}
+
+func opClientServiceRevertConfig(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceRevertConfig("wordpress", 999)
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientServiceExpose(c *C, st *api.State, mst *state.State) (func(), error) {
 	err := st.Client().ServiceExpose("wordpress")
 	if err != nil {
@@ -268,6 +389,19 @@ func opClientResolved(c *C, st *api.State, _ *state.State) (func(), error) {
 	return func() {}, nil
 }
 
+func opClientSetUnitsPaused(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().SetUnitsPaused([]string{"wordpress/0"}, true)
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		unit, err := mst.Unit("wordpress/0")
+		c.Assert(err, IsNil)
+		err = unit.ClearPaused()
+		c.Assert(err, IsNil)
+	}, nil
+}
+
 func opClientGetAnnotations(c *C, st *api.State, mst *state.State) (func(), error) {
 	ann, err := st.Client().GetAnnotations("service-wordpress")
 	if err != nil {
@@ -297,6 +431,24 @@ func opClientServiceDeploy(c *C, st *api.State, mst *state.State) (func(), error
 	return func() {}, err
 }
 
+func opClientDeployBundle(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().DeployBundle("services: {x: {charm: local:series/wordpress}}")
+	if err.Error() == `service "x": charm url has unsupported schema "local"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientExportBundle(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, _, err := st.Client().ExportBundle(false)
+	return func() {}, err
+}
+
+func opClientDiffBundle(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().DiffBundle("services: {x: {charm: local:series/wordpress}}")
+	return func() {}, err
+}
+
 func opClientServiceSetCharm(c *C, st *api.State, mst *state.State) (func(), error) {
 	err := st.Client().ServiceSetCharm("nosuch", "local:series/wordpress", false)
 	if params.ErrCode(err) == params.CodeNotFound {
@@ -350,3 +502,85 @@ func opClientWatchAll(c *C, st *api.State, mst *state.State) (func(), error) {
 	}
 	return func() {}, err
 }
+
+func opClientRun(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Units:    []string{"nosuch/0"},
+	})
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientEnqueueAction(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().EnqueueAction("nosuch/0", "snapshot", nil)
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientActionResult(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ActionResult("999")
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientAddUser(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().AddUser("newuser", "secret", "read-only")
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		u, err := mst.User("newuser")
+		c.Assert(err, IsNil)
+		c.Assert(u.Remove(), IsNil)
+	}, nil
+}
+
+func opClientRemoveUser(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().RemoveUser("nosuch")
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientSetUserPassword(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The admin user is used because its password is not needed
+	// to log in as any other entity.
+	err := st.Client().SetUserPassword("admin", "new password")
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		u, err := mst.User("admin")
+		c.Assert(err, IsNil)
+		c.Assert(u.SetPassword("user-admin password"), IsNil)
+	}, nil
+}
+
+func opClientAuditLog(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().AuditLog(params.AuditLog{})
+	return func() {}, err
+}
+
+func opClientWatchDebugLog(c *C, st *api.State, mst *state.State) (func(), error) {
+	path := filepath.Join(c.MkDir(), "all-machines.log")
+	err := ioutil.WriteFile(path, nil, 0644)
+	c.Assert(err, IsNil)
+	restore := client.SetDebugLogPath(path)
+	defer restore()
+	watcher, err := st.Client().WatchDebugLog(params.DebugLog{})
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		err := watcher.Stop()
+		c.Assert(err, IsNil)
+	}, nil
+}
@@ -54,6 +54,8 @@ func ServerError(err error) *params.Error {
 		code = params.CodeNotAssigned
 	case state.IsHasAssignedUnitsError(err):
 		code = params.CodeHasAssignedUnits
+	case state.IsBlockedError(err):
+		code = params.CodeOperationBlocked
 	default:
 		code = params.ErrCode(err)
 	}
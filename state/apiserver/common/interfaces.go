@@ -30,6 +30,10 @@ type Authorizer interface {
 	// is a client user.
 	AuthClient() bool
 
+	// AuthAdminClient returns whether the authenticated entity
+	// is a client user with the admin role.
+	AuthAdminClient() bool
+
 	// GetAuthTag returns the tag of the authenticated entity.
 	GetAuthTag() string
 }
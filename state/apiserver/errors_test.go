@@ -67,6 +67,9 @@ var errorTransformTests = []struct {
 }, {
 	err:  &state.HasAssignedUnitsError{"42", []string{"a"}},
 	code: params.CodeHasAssignedUnits,
+}, {
+	err:  &state.BlockedError{state.Block{state.ChangeBlock, "frozen"}},
+	code: params.CodeOperationBlocked,
 }, {
 	err:  stderrors.New("an error"),
 	code: "",
@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"launchpad.net/juju-core/log/tailer"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/apiserver/client"
 	"launchpad.net/juju-core/state/apiserver/common"
@@ -158,6 +159,25 @@ func (r *srvRoot) AllWatcher(id string) (*srvClientAllWatcher, error) {
 	}, nil
 }
 
+// DebugLogWatcher returns an object that provides API access to
+// methods on a log/tailer.Tailer, which reads the consolidated log of
+// the environment. Each client has its own current set of watchers,
+// stored in r.resources.
+func (r *srvRoot) DebugLogWatcher(id string) (*srvDebugLogWatcher, error) {
+	if err := r.requireClient(); err != nil {
+		return nil, err
+	}
+	t, ok := r.resources.Get(id).(*tailer.Tailer)
+	if !ok {
+		return nil, common.ErrUnknownWatcher
+	}
+	return &srvDebugLogWatcher{
+		tailer:    t,
+		id:        id,
+		resources: r.resources,
+	}, nil
+}
+
 // Pinger returns object with a single "Ping" method that does nothing.
 func (r *srvRoot) Pinger(id string) (srvPinger, error) {
 	return srvPinger{}, nil
@@ -192,6 +212,13 @@ func (r *srvRoot) AuthClient() bool {
 	return !isAgent(r.entity)
 }
 
+// AuthAdminClient returns whether the authenticated entity is a
+// client user with the admin role.
+func (r *srvRoot) AuthAdminClient() bool {
+	user, ok := r.entity.(*state.User)
+	return ok && user.Role() == state.AdminRole
+}
+
 // GetAuthTag returns the tag of the authenticated entity.
 func (r *srvRoot) GetAuthTag() string {
 	return r.entity.Tag()
@@ -10,6 +10,7 @@ type FakeAuthorizer struct {
 	Manager      bool
 	MachineAgent bool
 	Client       bool
+	AdminClient  bool
 }
 
 func (fa FakeAuthorizer) AuthOwner(tag string) bool {
@@ -28,6 +29,10 @@ func (fa FakeAuthorizer) AuthClient() bool {
 	return fa.Client
 }
 
+func (fa FakeAuthorizer) AuthAdminClient() bool {
+	return fa.AdminClient
+}
+
 func (fa FakeAuthorizer) GetAuthTag() string {
 	return fa.Tag
 }
@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"launchpad.net/juju-core/log/tailer"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
@@ -80,3 +81,33 @@ func (w *srvStringsWatcher) Next() (params.StringsWatchResult, error) {
 func (w *srvStringsWatcher) Stop() error {
 	return w.resources.Stop(w.id)
 }
+
+// srvDebugLogWatcher sends lines of the consolidated log of the
+// environment.
+type srvDebugLogWatcher struct {
+	tailer    *tailer.Tailer
+	id        string
+	resources *common.Resources
+}
+
+// Next returns the lines of the log selected by the watcher that have
+// not yet been returned, waiting for some if there are none. Once all
+// the lines of a watcher that does not follow the log have been
+// returned, it returns common.ErrStoppedWatcher.
+func (w *srvDebugLogWatcher) Next() (params.DebugLogResults, error) {
+	if lines, ok := <-w.tailer.Lines(); ok {
+		return params.DebugLogResults{
+			Lines: lines,
+		}, nil
+	}
+	err := w.tailer.Err()
+	if err == nil {
+		err = common.ErrStoppedWatcher
+	}
+	return params.DebugLogResults{}, err
+}
+
+// Stop stops the watcher.
+func (w *srvDebugLogWatcher) Stop() error {
+	return w.resources.Stop(w.id)
+}
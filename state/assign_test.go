@@ -756,6 +756,27 @@ func (s *assignCleanSuite) TestAssignUnit(c *C) {
 	}
 }
 
+func (s *assignCleanSuite) TestCleanEmptyMachines(c *C) {
+	_, container, cleanEmptyMachine := s.setupMachines(c)
+	machines, err := s.State.CleanEmptyMachines("series", constraints.Value{})
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 2)
+	c.Assert(machines[0].Id(), Equals, container.Id())
+	c.Assert(machines[1].Id(), Equals, cleanEmptyMachine.Id())
+	err = cleanEmptyMachine.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(cleanEmptyMachine.Clean(), jc.IsTrue)
+
+	// Machines that are not known to satisfy the constraints, or that
+	// have a different series, are not considered.
+	machines, err = s.State.CleanEmptyMachines("series", constraints.MustParse("mem=4G"))
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 0)
+	machines, err = s.State.CleanEmptyMachines("other", constraints.Value{})
+	c.Assert(err, IsNil)
+	c.Assert(machines, HasLen, 0)
+}
+
 func (s *assignCleanSuite) TestAssignUnitTwiceFails(c *C) {
 	s.setupMachines(c)
 	unit, err := s.wordpress.AddUnit()
@@ -0,0 +1,126 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"labix.org/v2/mgo/bson"
+)
+
+// AuditEntry records an operation that may have changed the
+// environment, as requested by a client.
+type AuditEntry struct {
+	// Time holds the time at which the operation finished.
+	Time time.Time
+
+	// User holds the tag of the entity that requested the operation.
+	User string
+
+	// Method holds the name of the requested operation.
+	Method string
+
+	// Args holds the arguments of the operation, encoded as JSON.
+	// Secrets, such as passwords, are not recorded.
+	Args string
+
+	// Entities holds the tags of the entities that are the subject
+	// of the operation.
+	Entities []string
+
+	// Error holds the error that caused the operation to fail, or is
+	// empty if it succeeded.
+	Error string
+}
+
+// auditDoc represents an AuditEntry in MongoDB. Audit entries are kept
+// in a capped collection, in which the oldest entries are discarded to
+// make room for new ones, and are not written in transactions.
+type auditDoc struct {
+	Id       bson.ObjectId `bson:"_id"`
+	Time     time.Time
+	User     string
+	Method   string
+	Args     string
+	Entities []string
+	Error    string
+}
+
+// AddAuditEntry records the given entry in the audit log. If the time
+// of the entry is not set, the current time is used.
+func (st *State) AddAuditEntry(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	err := st.auditLog.Insert(&auditDoc{
+		Id:       bson.NewObjectId(),
+		Time:     entry.Time.UTC(),
+		User:     entry.User,
+		Method:   entry.Method,
+		Args:     entry.Args,
+		Entities: entry.Entities,
+		Error:    entry.Error,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot add audit entry for %s: %v", entry.Method, err)
+	}
+	return nil
+}
+
+// AuditFilter selects entries of the audit log. Empty fields do not
+// restrict the selection.
+type AuditFilter struct {
+	// Entity selects the entries that have the given tag among
+	// their entities.
+	Entity string
+
+	// User selects the entries of operations requested by the
+	// entity with the given tag.
+	User string
+
+	// After and Before select the entries recorded at or after, and
+	// before, the given times.
+	After  time.Time
+	Before time.Time
+}
+
+// AuditLog returns the recorded entries of the audit log selected by
+// filter, oldest first. Only the most recent entries are kept.
+func (st *State) AuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	sel := D{}
+	if filter.Entity != "" {
+		sel = append(sel, D{{"entities", filter.Entity}}...)
+	}
+	if filter.User != "" {
+		sel = append(sel, D{{"user", filter.User}}...)
+	}
+	var timeSel D
+	if !filter.After.IsZero() {
+		timeSel = append(timeSel, D{{"$gte", filter.After.UTC()}}...)
+	}
+	if !filter.Before.IsZero() {
+		timeSel = append(timeSel, D{{"$lt", filter.Before.UTC()}}...)
+	}
+	if timeSel != nil {
+		sel = append(sel, D{{"time", timeSel}}...)
+	}
+	var docs []auditDoc
+	// Entries of a capped collection are returned in insertion order.
+	if err := st.auditLog.Find(sel).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %v", err)
+	}
+	entries := make([]AuditEntry, len(docs))
+	for i, doc := range docs {
+		entries[i] = AuditEntry{
+			Time:     doc.Time,
+			User:     doc.User,
+			Method:   doc.Method,
+			Args:     doc.Args,
+			Entities: doc.Entities,
+			Error:    doc.Error,
+		}
+	}
+	return entries, nil
+}
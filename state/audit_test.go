@@ -0,0 +1,97 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+)
+
+type AuditSuite struct {
+	ConnSuite
+}
+
+var _ = Suite(&AuditSuite{})
+
+func (s *AuditSuite) TestAuditLog(c *C) {
+	t0 := time.Date(2013, 10, 16, 12, 0, 0, 0, time.UTC)
+	entries := []state.AuditEntry{{
+		Time:     t0,
+		User:     "user-admin",
+		Method:   "ServiceDeploy",
+		Args:     `{"ServiceName":"wordpress"}`,
+		Entities: []string{"service-wordpress"},
+	}, {
+		Time:     t0.Add(time.Minute),
+		User:     "user-bob",
+		Method:   "AddRelation",
+		Args:     `{"Endpoints":["wordpress","mysql"]}`,
+		Entities: []string{"service-wordpress", "service-mysql"},
+	}, {
+		Time:     t0.Add(2 * time.Minute),
+		User:     "user-admin",
+		Method:   "ServiceDestroy",
+		Args:     `{"ServiceName":"mysql"}`,
+		Entities: []string{"service-mysql"},
+		Error:    `service "mysql" not found`,
+	}}
+	for _, entry := range entries {
+		err := s.State.AddAuditEntry(entry)
+		c.Assert(err, IsNil)
+	}
+
+	for i, t := range []struct {
+		filter state.AuditFilter
+		expect []int
+	}{{
+		filter: state.AuditFilter{},
+		expect: []int{0, 1, 2},
+	}, {
+		filter: state.AuditFilter{Entity: "service-mysql"},
+		expect: []int{1, 2},
+	}, {
+		filter: state.AuditFilter{User: "user-admin"},
+		expect: []int{0, 2},
+	}, {
+		filter: state.AuditFilter{User: "user-admin", Entity: "service-wordpress"},
+		expect: []int{0},
+	}, {
+		filter: state.AuditFilter{After: t0.Add(time.Minute)},
+		expect: []int{1, 2},
+	}, {
+		filter: state.AuditFilter{Before: t0.Add(time.Minute)},
+		expect: []int{0},
+	}, {
+		filter: state.AuditFilter{After: t0.Add(time.Minute), Before: t0.Add(2 * time.Minute)},
+		expect: []int{1},
+	}, {
+		filter: state.AuditFilter{User: "user-nobody"},
+		expect: []int{},
+	}} {
+		c.Logf("test %d: %#v", i, t.filter)
+		found, err := s.State.AuditLog(t.filter)
+		c.Assert(err, IsNil)
+		for k := range found {
+			found[k].Time = found[k].Time.UTC()
+		}
+		expect := []state.AuditEntry{}
+		for _, j := range t.expect {
+			expect = append(expect, entries[j])
+		}
+		c.Check(found, DeepEquals, expect)
+	}
+}
+
+func (s *AuditSuite) TestAddAuditEntryDefaultsTime(c *C) {
+	t0 := time.Now().Add(-time.Millisecond)
+	err := s.State.AddAuditEntry(state.AuditEntry{User: "user-admin", Method: "ServiceExpose"})
+	c.Assert(err, IsNil)
+	found, err := s.State.AuditLog(state.AuditFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(found, HasLen, 1)
+	c.Assert(found[0].Time.Before(t0), Equals, false)
+}
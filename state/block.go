@@ -0,0 +1,169 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/utils"
+)
+
+// BlockType identifies a kind of operation that may be blocked to
+// protect the environment from accidents.
+type BlockType string
+
+const (
+	// DestroyBlock prevents the environment from being destroyed.
+	DestroyBlock BlockType = "destroy-environment"
+
+	// RemoveBlock prevents machines, services, units and relations
+	// from being removed, as well as the environment being destroyed.
+	RemoveBlock BlockType = "remove-object"
+
+	// ChangeBlock prevents all changes to the environment.
+	ChangeBlock BlockType = "all-changes"
+)
+
+// BlockTypes holds all the kinds of block, from the least to the most
+// restrictive.
+var BlockTypes = []BlockType{DestroyBlock, RemoveBlock, ChangeBlock}
+
+// ParseBlockType returns the block type with the given name.
+func ParseBlockType(name string) (BlockType, error) {
+	for _, t := range BlockTypes {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown block type %q", name)
+}
+
+// blockedBy holds, for each kind of operation, the blocks that
+// prevent it. A block prevents the operations of its own type and of
+// all less restrictive types.
+var blockedBy = map[BlockType][]BlockType{
+	DestroyBlock: {DestroyBlock, RemoveBlock, ChangeBlock},
+	RemoveBlock:  {RemoveBlock, ChangeBlock},
+	ChangeBlock:  {ChangeBlock},
+}
+
+// Block describes a block that is switched on.
+type Block struct {
+	Type    BlockType
+	Message string
+}
+
+// blockDoc represents a Block in MongoDB.
+type blockDoc struct {
+	Type    BlockType `bson:"_id"`
+	Message string
+}
+
+// BlockedError is returned when an operation is prevented by a block.
+type BlockedError struct {
+	Block
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("operation blocked by the %s block", e.Type)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsBlockedError returns whether err is a BlockedError.
+func IsBlockedError(err error) bool {
+	_, ok := err.(*BlockedError)
+	return ok
+}
+
+// SwitchBlockOn switches on the block of the given type, with a message
+// that is shown when an operation is blocked. If the block is already
+// on, its message is replaced.
+func (st *State) SwitchBlockOn(t BlockType, msg string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot switch on %s block", t)
+	if _, ok := blockedBy[t]; !ok {
+		return fmt.Errorf("unknown block type %q", t)
+	}
+	for i := 0; i < 2; i++ {
+		ops := []txn.Op{{
+			C:      st.blocks.Name,
+			Id:     string(t),
+			Assert: txn.DocMissing,
+			Insert: &blockDoc{Type: t, Message: msg},
+		}}
+		if i > 0 {
+			ops = []txn.Op{{
+				C:      st.blocks.Name,
+				Id:     string(t),
+				Assert: txn.DocExists,
+				Update: D{{"$set", D{{"message", msg}}}},
+			}}
+		}
+		if err := st.runTransaction(ops); err != txn.ErrAborted {
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// SwitchBlockOff switches off the block of the given type. It does
+// nothing if the block is not on.
+func (st *State) SwitchBlockOff(t BlockType) (err error) {
+	defer utils.ErrorContextf(&err, "cannot switch off %s block", t)
+	if _, ok := blockedBy[t]; !ok {
+		return fmt.Errorf("unknown block type %q", t)
+	}
+	return onAbort(st.runTransaction([]txn.Op{{
+		C:      st.blocks.Name,
+		Id:     string(t),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}), nil)
+}
+
+// AllBlocks returns the blocks that are switched on, from the least to
+// the most restrictive.
+func (st *State) AllBlocks() ([]Block, error) {
+	var docs []blockDoc
+	if err := st.blocks.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get blocks: %v", err)
+	}
+	found := make(map[BlockType]string)
+	for _, doc := range docs {
+		found[doc.Type] = doc.Message
+	}
+	var blocks []Block
+	for _, t := range BlockTypes {
+		if msg, ok := found[t]; ok {
+			blocks = append(blocks, Block{t, msg})
+		}
+	}
+	return blocks, nil
+}
+
+// CheckBlocked returns a BlockedError if operations of the given type
+// are prevented by a block that is switched on.
+func (st *State) CheckBlocked(t BlockType) error {
+	types, ok := blockedBy[t]
+	if !ok {
+		return fmt.Errorf("unknown block type %q", t)
+	}
+	blocks, err := st.AllBlocks()
+	if err != nil {
+		return err
+	}
+	// Report the most restrictive block.
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, bt := range types {
+			if blocks[i].Type == bt {
+				return &BlockedError{blocks[i]}
+			}
+		}
+	}
+	return nil
+}
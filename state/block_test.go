@@ -0,0 +1,111 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+)
+
+type BlockSuite struct {
+	ConnSuite
+}
+
+var _ = Suite(&BlockSuite{})
+
+func (s *BlockSuite) TestSwitchBlockOnOff(c *C) {
+	blocks, err := s.State.AllBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, HasLen, 0)
+
+	err = s.State.SwitchBlockOn(state.ChangeBlock, "frozen")
+	c.Assert(err, IsNil)
+	err = s.State.SwitchBlockOn(state.DestroyBlock, "")
+	c.Assert(err, IsNil)
+	blocks, err = s.State.AllBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, DeepEquals, []state.Block{
+		{state.DestroyBlock, ""},
+		{state.ChangeBlock, "frozen"},
+	})
+
+	// Switching on a block that is on replaces its message.
+	err = s.State.SwitchBlockOn(state.ChangeBlock, "still frozen")
+	c.Assert(err, IsNil)
+	blocks, err = s.State.AllBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks[1], DeepEquals, state.Block{state.ChangeBlock, "still frozen"})
+
+	err = s.State.SwitchBlockOff(state.ChangeBlock)
+	c.Assert(err, IsNil)
+	err = s.State.SwitchBlockOff(state.RemoveBlock)
+	c.Assert(err, IsNil)
+	blocks, err = s.State.AllBlocks()
+	c.Assert(err, IsNil)
+	c.Assert(blocks, DeepEquals, []state.Block{{state.DestroyBlock, ""}})
+}
+
+func (s *BlockSuite) TestUnknownBlockType(c *C) {
+	err := s.State.SwitchBlockOn("everything", "")
+	c.Assert(err, ErrorMatches, `cannot switch on everything block: unknown block type "everything"`)
+	err = s.State.SwitchBlockOff("everything")
+	c.Assert(err, ErrorMatches, `cannot switch off everything block: unknown block type "everything"`)
+	err = s.State.CheckBlocked("everything")
+	c.Assert(err, ErrorMatches, `unknown block type "everything"`)
+	_, err = state.ParseBlockType("everything")
+	c.Assert(err, ErrorMatches, `unknown block type "everything"`)
+	t, err := state.ParseBlockType("remove-object")
+	c.Assert(err, IsNil)
+	c.Assert(t, Equals, state.RemoveBlock)
+}
+
+var checkBlockedTests = []struct {
+	on      state.BlockType
+	blocked []state.BlockType
+}{{
+	on:      state.DestroyBlock,
+	blocked: []state.BlockType{state.DestroyBlock},
+}, {
+	on:      state.RemoveBlock,
+	blocked: []state.BlockType{state.DestroyBlock, state.RemoveBlock},
+}, {
+	on:      state.ChangeBlock,
+	blocked: []state.BlockType{state.DestroyBlock, state.RemoveBlock, state.ChangeBlock},
+}}
+
+func (s *BlockSuite) TestCheckBlocked(c *C) {
+	for _, t := range state.BlockTypes {
+		c.Assert(s.State.CheckBlocked(t), IsNil)
+	}
+	for i, test := range checkBlockedTests {
+		c.Logf("test %d: %s", i, test.on)
+		err := s.State.SwitchBlockOn(test.on, "careful")
+		c.Assert(err, IsNil)
+		blocked := make(map[state.BlockType]bool)
+		for _, t := range test.blocked {
+			blocked[t] = true
+		}
+		for _, t := range state.BlockTypes {
+			err := s.State.CheckBlocked(t)
+			if !blocked[t] {
+				c.Check(err, IsNil)
+				continue
+			}
+			c.Check(state.IsBlockedError(err), Equals, true)
+			c.Check(err, ErrorMatches, "operation blocked by the "+string(test.on)+" block: careful")
+		}
+		err = s.State.SwitchBlockOff(test.on)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *BlockSuite) TestCheckBlockedReportsMostRestrictive(c *C) {
+	err := s.State.SwitchBlockOn(state.DestroyBlock, "")
+	c.Assert(err, IsNil)
+	err = s.State.SwitchBlockOn(state.RemoveBlock, "")
+	c.Assert(err, IsNil)
+	err = s.State.CheckBlocked(state.DestroyBlock)
+	c.Assert(err, ErrorMatches, "operation blocked by the remove-object block")
+}
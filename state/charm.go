@@ -14,6 +14,7 @@ type charmDoc struct {
 	URL          *charm.URL `bson:"_id"`
 	Meta         *charm.Meta
 	Config       *charm.Config
+	Actions      *charm.Actions
 	BundleURL    *url.URL
 	BundleSha256 string
 }
@@ -54,6 +55,15 @@ func (c *Charm) Config() *charm.Config {
 	return c.doc.Config
 }
 
+// Actions returns the actions definition of the charm.
+func (c *Charm) Actions() *charm.Actions {
+	if c.doc.Actions == nil {
+		// Charms added before actions were supported have none.
+		return charm.NewActions()
+	}
+	return c.doc.Actions
+}
+
 // BundleURL returns the url to the charm bundle in
 // the provider storage.
 func (c *Charm) BundleURL() *url.URL {
@@ -0,0 +1,146 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"labix.org/v2/mgo"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+)
+
+// maxConfigHistory holds the number of configuration changes that are
+// kept in the history of each service.
+var maxConfigHistory = 20
+
+// ConfigChange records a change to the configuration settings of a
+// service.
+type ConfigChange struct {
+	// Revision numbers the change within the service's history.
+	Revision int
+
+	// CharmURL holds the URL of the service's charm at the time
+	// of the change.
+	CharmURL *charm.URL
+
+	// User holds the tag of the entity that made the change, if known.
+	User string
+
+	// Time holds the time at which the change was made.
+	Time time.Time
+
+	// Changes holds the settings that were changed, sorted by key.
+	Changes []ItemChange
+
+	// Settings holds all the settings in effect after the change.
+	Settings charm.Settings
+}
+
+// configHistoryDoc represents a ConfigChange in MongoDB.
+type configHistoryDoc struct {
+	Id       string `bson:"_id"`
+	Service  string
+	Revision int
+	CharmURL *charm.URL
+	User     string
+	Time     time.Time
+	Changes  []ItemChange
+	Settings map[string]interface{}
+}
+
+func (doc *configHistoryDoc) change() *ConfigChange {
+	return &ConfigChange{
+		Revision: doc.Revision,
+		CharmURL: doc.CharmURL,
+		User:     doc.User,
+		Time:     doc.Time,
+		Changes:  doc.Changes,
+		Settings: doc.Settings,
+	}
+}
+
+// serviceConfigHistory returns the history of configuration changes
+// of services.
+func serviceConfigHistory(st *State) *history {
+	return &history{
+		name:       "config history",
+		coll:       st.configHistory,
+		ownerField: "service",
+		seqField:   "revision",
+		max:        maxConfigHistory,
+	}
+}
+
+// recordConfigChange adds a change to the service's configuration
+// history, and discards changes that are no longer kept.
+func (s *Service) recordConfigChange(user string, changes []ItemChange, settings map[string]interface{}) error {
+	return serviceConfigHistory(s.st).record(s.doc.Name, func(revision int) interface{} {
+		return &configHistoryDoc{
+			Id:       fmt.Sprintf("%s#%d", s.doc.Name, revision),
+			Service:  s.doc.Name,
+			Revision: revision,
+			CharmURL: s.doc.CharmURL,
+			User:     user,
+			Time:     time.Now().UTC(),
+			Changes:  changes,
+			Settings: settings,
+		}
+	})
+}
+
+// cleanupConfigHistory removes all recorded configuration changes for
+// the named service, which has been removed.
+func (st *State) cleanupConfigHistory(serviceName string) error {
+	return serviceConfigHistory(st).remove(serviceName)
+}
+
+// ConfigHistory returns the recorded changes to the service's
+// configuration settings, oldest first. Only the most recent changes
+// are kept.
+func (s *Service) ConfigHistory() ([]*ConfigChange, error) {
+	var docs []configHistoryDoc
+	err := s.st.configHistory.Find(D{{"service", s.doc.Name}}).Sort("revision").All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get config history for service %q: %v", s, err)
+	}
+	changes := make([]*ConfigChange, len(docs))
+	for i := range docs {
+		changes[i] = docs[i].change()
+	}
+	return changes, nil
+}
+
+// RevertConfigSettings restores the service's configuration settings
+// to those in effect after the change with the given revision. Settings
+// of options that the service's current charm does not have are
+// ignored. The revert is itself recorded as a change made by user.
+func (s *Service) RevertConfigSettings(revision int, user string) error {
+	var doc configHistoryDoc
+	err := s.st.configHistory.Find(D{{"service", s.doc.Name}, {"revision", revision}}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("config revision %d of service %q", revision, s)
+	} else if err != nil {
+		return fmt.Errorf("cannot get config history for service %q: %v", s, err)
+	}
+	ch, _, err := s.Charm()
+	if err != nil {
+		return err
+	}
+	target := ch.Config().FilterSettings(doc.Settings)
+	current, err := s.ConfigSettings()
+	if err != nil {
+		return err
+	}
+	changes := make(charm.Settings)
+	for name := range current {
+		changes[name] = nil
+	}
+	for name, value := range target {
+		changes[name] = value
+	}
+	return s.UpdateConfigSettingsAs(changes, user)
+}
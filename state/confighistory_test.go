@@ -0,0 +1,155 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type ConfigHistorySuite struct {
+	ConnSuite
+	charm   *state.Charm
+	service *state.Service
+}
+
+var _ = Suite(&ConfigHistorySuite{})
+
+func (s *ConfigHistorySuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	s.charm = s.AddTestingCharm(c, "dummy")
+	var err error
+	s.service, err = s.State.AddService("dummy", s.charm)
+	c.Assert(err, IsNil)
+}
+
+func (s *ConfigHistorySuite) TestConfigHistory(c *C) {
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+
+	start := time.Now()
+	err = s.service.UpdateConfigSettingsAs(charm.Settings{"title": "one"}, "user-admin")
+	c.Assert(err, IsNil)
+	err = s.service.UpdateConfigSettingsAs(charm.Settings{"title": "two", "skill-level": 5}, "user-other")
+	c.Assert(err, IsNil)
+	// Settings that do not change are not recorded.
+	err = s.service.UpdateConfigSettingsAs(charm.Settings{"title": "two"}, "user-other")
+	c.Assert(err, IsNil)
+	err = s.service.UpdateConfigSettings(charm.Settings{"title": nil})
+	c.Assert(err, IsNil)
+	end := time.Now()
+
+	history, err = s.service.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	for i, change := range history {
+		c.Assert(change.Revision, Equals, i+1)
+		c.Assert(change.CharmURL, DeepEquals, s.charm.URL())
+		c.Assert(change.Time, checkers.TimeBetween(start, end))
+	}
+	c.Assert(history[0].User, Equals, "user-admin")
+	c.Assert(history[0].Changes, DeepEquals, []state.ItemChange{
+		{state.ItemAdded, "title", nil, "one"},
+	})
+	c.Assert(history[0].Settings, DeepEquals, charm.Settings{"title": "one"})
+	c.Assert(history[1].User, Equals, "user-other")
+	c.Assert(history[1].Changes, DeepEquals, []state.ItemChange{
+		{state.ItemAdded, "skill-level", nil, int64(5)},
+		{state.ItemModified, "title", "one", "two"},
+	})
+	c.Assert(history[1].Settings, DeepEquals, charm.Settings{"title": "two", "skill-level": int64(5)})
+	c.Assert(history[2].User, Equals, "")
+	c.Assert(history[2].Changes, DeepEquals, []state.ItemChange{
+		{state.ItemDeleted, "title", "two", nil},
+	})
+	c.Assert(history[2].Settings, DeepEquals, charm.Settings{"skill-level": int64(5)})
+}
+
+func (s *ConfigHistorySuite) TestConfigHistoryIsBounded(c *C) {
+	defer state.SetMaxConfigHistory(3)()
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		err := s.service.UpdateConfigSettings(charm.Settings{"title": title})
+		c.Assert(err, IsNil)
+	}
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	for i, change := range history {
+		c.Assert(change.Revision, Equals, i+3)
+	}
+	c.Assert(history[2].Settings, DeepEquals, charm.Settings{"title": "e"})
+}
+
+func (s *ConfigHistorySuite) TestConfigHistoryDiscardedWithService(c *C) {
+	err := s.service.UpdateConfigSettings(charm.Settings{"title": "old"})
+	c.Assert(err, IsNil)
+	err = s.service.Destroy()
+	c.Assert(err, IsNil)
+	needed, err := s.State.NeedsCleanup()
+	c.Assert(err, IsNil)
+	c.Assert(needed, Equals, true)
+	err = s.State.Cleanup()
+	c.Assert(err, IsNil)
+	svc, err := s.State.AddService("dummy", s.charm)
+	c.Assert(err, IsNil)
+	history, err := svc.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+}
+
+func (s *ConfigHistorySuite) TestRevertConfigSettings(c *C) {
+	err := s.service.UpdateConfigSettings(charm.Settings{"title": "good", "outlook": "fine"})
+	c.Assert(err, IsNil)
+	err = s.service.UpdateConfigSettings(charm.Settings{"title": "bad", "outlook": nil, "skill-level": 1})
+	c.Assert(err, IsNil)
+
+	err = s.service.RevertConfigSettings(1, "user-admin")
+	c.Assert(err, IsNil)
+	settings, err := s.service.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"title": "good", "outlook": "fine"})
+
+	// The revert is recorded in the history.
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	c.Assert(history[2].User, Equals, "user-admin")
+	c.Assert(history[2].Changes, DeepEquals, []state.ItemChange{
+		{state.ItemAdded, "outlook", nil, "fine"},
+		{state.ItemDeleted, "skill-level", int64(1), nil},
+		{state.ItemModified, "title", "bad", "good"},
+	})
+}
+
+func (s *ConfigHistorySuite) TestRevertConfigSettingsIgnoresUnknownOptions(c *C) {
+	err := s.service.UpdateConfigSettings(charm.Settings{"title": "good", "outlook": "fine"})
+	c.Assert(err, IsNil)
+	newCh := s.AddConfigCharm(c, "dummy", `
+options:
+  title: {default: My Title, description: A title., type: string}
+`, 2)
+	err = s.service.SetCharm(newCh, false)
+	c.Assert(err, IsNil)
+	err = s.service.UpdateConfigSettings(charm.Settings{"title": "bad"})
+	c.Assert(err, IsNil)
+
+	err = s.service.RevertConfigSettings(1, "user-admin")
+	c.Assert(err, IsNil)
+	settings, err := s.service.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"title": "good"})
+}
+
+func (s *ConfigHistorySuite) TestRevertConfigSettingsNotFound(c *C) {
+	err := s.service.RevertConfigSettings(1, "user-admin")
+	c.Assert(err, ErrorMatches, `config revision 1 of service "dummy" not found`)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
@@ -168,6 +168,7 @@ func SetMachineInstanceId(m *Machine, instanceId string) {
 
 func init() {
 	logSize = logSizeTests
+	auditLogSize = logSizeTests
 }
 
 // MinUnitsRevno returns the Revno of the minUnits document
@@ -179,3 +180,21 @@ func MinUnitsRevno(st *State, serviceName string) (int, error) {
 	}
 	return doc.Revno, nil
 }
+
+// SetMaxConfigHistory sets the number of configuration changes kept
+// for each service, and returns a function that restores the original
+// value.
+func SetMaxConfigHistory(n int) (restore func()) {
+	old := maxConfigHistory
+	maxConfigHistory = n
+	return func() { maxConfigHistory = old }
+}
+
+// SetMaxStatusHistory sets the number of status changes kept for each
+// unit and machine, and returns a function that restores the original
+// value.
+func SetMaxStatusHistory(n int) (restore func()) {
+	old := maxStatusHistory
+	maxStatusHistory = n
+	return func() { maxStatusHistory = old }
+}
@@ -0,0 +1,74 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo"
+)
+
+// history describes a collection holding a history of changes for
+// each of a number of owners, such as services or units. The changes
+// of each owner are numbered in sequence, and only the most recent
+// are kept.
+//
+// History is not written in transactions: a change is recorded after
+// it has been made, and older changes are discarded.
+type history struct {
+	// name describes the history in error messages.
+	name string
+
+	coll *mgo.Collection
+
+	// ownerField and seqField name the fields of the history
+	// documents that hold the owner and the sequence number.
+	ownerField string
+	seqField   string
+
+	// max holds the number of changes kept for each owner.
+	max int
+}
+
+// record adds a change to the owner's history, and discards changes
+// that are no longer kept. The document for the change is returned by
+// newDoc, given its sequence number; its id must be unique for the
+// owner and sequence number.
+func (h *history) record(owner string, newDoc func(seq int) interface{}) error {
+	for i := 0; i < 5; i++ {
+		var last map[string]int
+		err := h.coll.Find(D{{h.ownerField, owner}}).
+			Select(D{{"_id", 0}, {h.seqField, 1}}).
+			Sort("-" + h.seqField).
+			One(&last)
+		if err != nil && err != mgo.ErrNotFound {
+			return fmt.Errorf("cannot read %s: %v", h.name, err)
+		}
+		seq := last[h.seqField] + 1
+		err = h.coll.Insert(newDoc(seq))
+		if mgo.IsDup(err) {
+			// Another change was recorded concurrently.
+			continue
+		} else if err != nil {
+			return fmt.Errorf("cannot record %s: %v", h.name, err)
+		}
+		_, err = h.coll.RemoveAll(D{
+			{h.ownerField, owner},
+			{h.seqField, D{{"$lte", seq - h.max}}},
+		})
+		if err != nil {
+			return fmt.Errorf("cannot prune %s: %v", h.name, err)
+		}
+		return nil
+	}
+	return ErrExcessiveContention
+}
+
+// remove removes the owner's whole history.
+func (h *history) remove(owner string) error {
+	if _, err := h.coll.RemoveAll(D{{h.ownerField, owner}}); err != nil {
+		return fmt.Errorf("cannot remove %s: %v", h.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// leadershipDoc records which unit of a service holds the service's
+// leadership lease, and when the lease expires unless it is renewed.
+type leadershipDoc struct {
+	Service  string `bson:"_id"`
+	Leader   string
+	Expiry   time.Time
+	TxnRevno int64 `bson:"txn-revno"`
+}
+
+// leaderSettingsKey returns the key of the settings that the leader
+// of the named service shares with the service's other units.
+func leaderSettingsKey(serviceName string) string {
+	return serviceGlobalKey(serviceName) + "#leader"
+}
+
+// leaseHeld returns whether the leadership lease recorded in doc is
+// still held at the given time. A lease is lost when it expires, and
+// also as soon as its holder is no longer alive or its agent's
+// presence pinger dies.
+func (st *State) leaseHeld(doc *leadershipDoc, now time.Time) (bool, error) {
+	if doc.Leader == "" || !now.Before(doc.Expiry) {
+		return false, nil
+	}
+	leader, err := st.Unit(doc.Leader)
+	if errors.IsNotFoundError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if leader.Life() != Alive {
+		return false, nil
+	}
+	return leader.AgentAlive()
+}
+
+// Leader returns the name of the unit that holds the service's
+// leadership lease, or the empty string if no unit holds it.
+func (s *Service) Leader() (string, error) {
+	var doc leadershipDoc
+	err := s.st.leaderships.FindId(s.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("cannot get leader of service %q: %v", s, err)
+	}
+	held, err := s.st.leaseHeld(&doc, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("cannot get leader of service %q: %v", s, err)
+	}
+	if !held {
+		return "", nil
+	}
+	return doc.Leader, nil
+}
+
+// ClaimLeadership attempts to make the unit the leader of its service
+// for the given duration. It succeeds, and the lease is renewed, if the
+// unit already holds the lease or no other unit does. It returns
+// whether the unit holds the lease; a unit that is not alive never
+// does.
+func (u *Unit) ClaimLeadership(duration time.Duration) (leader bool, err error) {
+	defer utils.ErrorContextf(&err, "cannot claim leadership for unit %q", u)
+	for i := 0; i < 5; i++ {
+		now := time.Now()
+		expiry := now.Add(duration)
+		var doc leadershipDoc
+		var op txn.Op
+		err := u.st.leaderships.FindId(u.doc.Service).One(&doc)
+		if err == mgo.ErrNotFound {
+			op = txn.Op{
+				C:      u.st.leaderships.Name,
+				Id:     u.doc.Service,
+				Assert: txn.DocMissing,
+				Insert: &leadershipDoc{
+					Service: u.doc.Service,
+					Leader:  u.doc.Name,
+					Expiry:  expiry,
+				},
+			}
+		} else if err != nil {
+			return false, err
+		} else {
+			if doc.Leader != u.doc.Name {
+				if held, err := u.st.leaseHeld(&doc, now); err != nil {
+					return false, err
+				} else if held {
+					return false, nil
+				}
+			}
+			op = txn.Op{
+				C:      u.st.leaderships.Name,
+				Id:     u.doc.Service,
+				Assert: D{{"txn-revno", doc.TxnRevno}},
+				Update: D{{"$set", D{{"leader", u.doc.Name}, {"expiry", expiry}}}},
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: isAliveDoc,
+		}, op}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			return err == nil, err
+		}
+		if alive, err := isAlive(u.st.units, u.doc.Name); err != nil || !alive {
+			return false, err
+		}
+	}
+	return false, ErrExcessiveContention
+}
+
+// LeaderSettings returns the settings that the leader of the service
+// shares with the service's other units.
+func (s *Service) LeaderSettings() (map[string]string, error) {
+	values, _, err := readSettingsDoc(s.st, leaderSettingsKey(s.doc.Name))
+	if err == mgo.ErrNotFound {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read leader settings of service %q: %v", s, err)
+	}
+	settings := make(map[string]string)
+	for k, v := range values {
+		settings[k], _ = v.(string)
+	}
+	return settings, nil
+}
+
+// SetLeaderSettings updates the settings that the leader of the unit's
+// service shares with the service's other units; settings with empty
+// values are removed. It fails unless the unit holds the service's
+// leadership lease.
+func (u *Unit) SetLeaderSettings(values map[string]string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set leader settings for unit %q", u)
+	key := leaderSettingsKey(u.doc.Service)
+	for i := 0; i < 5; i++ {
+		var doc leadershipDoc
+		err := u.st.leaderships.FindId(u.doc.Service).One(&doc)
+		if err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+		if doc.Leader != u.doc.Name || !time.Now().Before(doc.Expiry) {
+			return fmt.Errorf("unit is not the leader")
+		}
+		current, txnRevno, err := readSettingsDoc(u.st, key)
+		exists := err == nil
+		if err == mgo.ErrNotFound {
+			current = make(map[string]interface{})
+		} else if err != nil {
+			return err
+		}
+		var sets, unsets D
+		for k, v := range values {
+			if v != "" {
+				sets = append(sets, bson.DocElem{k, v})
+				current[k] = v
+			} else if _, ok := current[k]; ok {
+				unsets = append(unsets, bson.DocElem{k, 1})
+				delete(current, k)
+			}
+		}
+		settingsOp := txn.Op{
+			C:  u.st.settings.Name,
+			Id: key,
+		}
+		if !exists {
+			settingsOp.Assert = txn.DocMissing
+			settingsOp.Insert = current
+		} else {
+			settingsOp.Assert = D{{"txn-revno", txnRevno}}
+			var update D
+			if len(sets) > 0 {
+				update = append(update, bson.DocElem{"$set", sets})
+			}
+			if len(unsets) > 0 {
+				update = append(update, bson.DocElem{"$unset", unsets})
+			}
+			if len(update) > 0 {
+				settingsOp.Update = update
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.leaderships.Name,
+			Id:     u.doc.Service,
+			Assert: D{{"txn-revno", doc.TxnRevno}},
+		}, settingsOp}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// WatchLeaderSettings returns a watcher that notifies of changes to
+// the settings that the leader of the service shares with the
+// service's other units.
+func (s *Service) WatchLeaderSettings() NotifyWatcher {
+	return newEntityWatcher(s.st, s.st.settings, leaderSettingsKey(s.doc.Name))
+}
@@ -0,0 +1,174 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/presence"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type LeadershipSuite struct {
+	ConnSuite
+	service *state.Service
+	units   []*state.Unit
+}
+
+var _ = Suite(&LeadershipSuite{})
+
+func (s *LeadershipSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	s.units = nil
+	for i := 0; i < 2; i++ {
+		unit, err := s.service.AddUnit()
+		c.Assert(err, IsNil)
+		s.units = append(s.units, unit)
+	}
+}
+
+func (s *LeadershipSuite) setAgentAlive(c *C, u *state.Unit) *presence.Pinger {
+	pinger, err := u.SetAgentAlive()
+	c.Assert(err, IsNil)
+	s.State.Sync()
+	return pinger
+}
+
+func (s *LeadershipSuite) assertLeader(c *C, expect string) {
+	leader, err := s.service.Leader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, expect)
+}
+
+func (s *LeadershipSuite) TestClaimLeadership(c *C) {
+	s.assertLeader(c, "")
+	pinger := s.setAgentAlive(c, s.units[0])
+	defer pinger.Stop()
+
+	ok, err := s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.assertLeader(c, "wordpress/0")
+
+	// The lease is held, so another unit cannot claim it.
+	ok, err = s.units[1].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+	s.assertLeader(c, "wordpress/0")
+
+	// The leader can renew its lease.
+	ok, err = s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.assertLeader(c, "wordpress/0")
+}
+
+func (s *LeadershipSuite) TestLeaseExpires(c *C) {
+	pinger := s.setAgentAlive(c, s.units[0])
+	defer pinger.Stop()
+	ok, err := s.units[0].ClaimLeadership(50 * time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	time.Sleep(100 * time.Millisecond)
+	s.assertLeader(c, "")
+	ok, err = s.units[1].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.assertLeader(c, "wordpress/1")
+}
+
+func (s *LeadershipSuite) TestLeaseLostWithAgent(c *C) {
+	pinger := s.setAgentAlive(c, s.units[0])
+	ok, err := s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	err = pinger.Kill()
+	c.Assert(err, IsNil)
+	s.State.Sync()
+	s.assertLeader(c, "")
+	ok, err = s.units[1].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.assertLeader(c, "wordpress/1")
+}
+
+func (s *LeadershipSuite) TestLeaseLostWhenLeaderDestroyed(c *C) {
+	pinger := s.setAgentAlive(c, s.units[0])
+	defer pinger.Stop()
+	ok, err := s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	err = s.units[0].Destroy()
+	c.Assert(err, IsNil)
+	s.assertLeader(c, "")
+	ok, err = s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+	ok, err = s.units[1].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.assertLeader(c, "wordpress/1")
+}
+
+func (s *LeadershipSuite) TestLeaderSettings(c *C) {
+	settings, err := s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{})
+
+	err = s.units[0].SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, ErrorMatches, `cannot set leader settings for unit "wordpress/0": unit is not the leader`)
+
+	pinger := s.setAgentAlive(c, s.units[0])
+	defer pinger.Stop()
+	ok, err := s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	err = s.units[0].SetLeaderSettings(map[string]string{"foo": "bar", "baz": "qux"})
+	c.Assert(err, IsNil)
+	settings, err = s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{"foo": "bar", "baz": "qux"})
+
+	err = s.units[0].SetLeaderSettings(map[string]string{"foo": "", "baz": "quux", "missing": ""})
+	c.Assert(err, IsNil)
+	settings, err = s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{"baz": "quux"})
+
+	err = s.units[1].SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, ErrorMatches, `cannot set leader settings for unit "wordpress/1": unit is not the leader`)
+}
+
+func (s *LeadershipSuite) TestWatchLeaderSettings(c *C) {
+	w := s.service.WatchLeaderSettings()
+	defer func() { c.Assert(w.Stop(), IsNil) }()
+	wc := coretesting.NotifyAsserterC{
+		C:       c,
+		Chan:    w.Changes(),
+		Precond: s.State.StartSync,
+	}
+	wc.AssertOneReceive()
+
+	pinger := s.setAgentAlive(c, s.units[0])
+	defer pinger.Stop()
+	ok, err := s.units[0].ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	s.State.StartSync()
+	wc.AssertNoReceive()
+
+	err = s.units[0].SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, IsNil)
+	wc.AssertOneReceive()
+}
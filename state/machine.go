@@ -445,7 +445,10 @@ func (m *Machine) Remove() (err error) {
 	ops = append(ops, removeContainerRefOps(m.st, m.Id())...)
 	// The only abort conditions in play indicate that the machine has already
 	// been removed.
-	return onAbort(m.st.runTransaction(ops), nil)
+	if err := onAbort(m.st.runTransaction(ops), nil); err != nil {
+		return err
+	}
+	return removeStatusHistory(m.st, m.globalKey())
 }
 
 // Refresh refreshes the contents of the machine from the underlying
@@ -596,6 +599,38 @@ func (m *Machine) SetProvisioned(id instance.Id, nonce string, characteristics *
 	return fmt.Errorf("already set")
 }
 
+// UpdateInstanceId changes the provider instance id recorded for an
+// already provisioned machine. It is used when a state server is
+// restored from a backup onto a freshly bootstrapped instance.
+func (m *Machine) UpdateInstanceId(id instance.Id) (err error) {
+	defer utils.ErrorContextf(&err, "cannot update instance id of machine %q", m)
+	if id == "" {
+		return fmt.Errorf("instance id cannot be empty")
+	}
+	ops := []txn.Op{{
+		C:      m.st.machines.Name,
+		Id:     m.doc.Id,
+		Assert: append(isAliveDoc, D{{"nonce", D{{"$ne", ""}}}}...),
+		Update: D{{"$set", D{{"instanceid", id}}}},
+	}, {
+		C:      m.st.instanceData.Name,
+		Id:     m.doc.Id,
+		Assert: txn.DocExists,
+		Update: D{{"$set", D{{"instanceid", id}}}},
+	}}
+	if err = m.st.runTransaction(ops); err == nil {
+		m.doc.InstanceId = id
+		return nil
+	} else if err != txn.ErrAborted {
+		return err
+	} else if alive, err := isAlive(m.st.machines, m.doc.Id); err != nil {
+		return err
+	} else if !alive {
+		return errNotAlive
+	}
+	return fmt.Errorf("not provisioned")
+}
+
 // NotProvisionedError records an error when a machine is not provisioned.
 type NotProvisionedError struct {
 	machineId string
@@ -678,7 +713,9 @@ func (m *Machine) Status() (status params.Status, info string, err error) {
 	return
 }
 
-// SetStatus sets the status of the machine.
+// SetStatus sets the status of the machine, and records the change in
+// its status history. Failing to record the change is logged, but
+// does not cause SetStatus to fail.
 func (m *Machine) SetStatus(status params.Status, info string) error {
 	if status == params.StatusError && info == "" {
 		panic("machine error status with no info")
@@ -700,6 +737,7 @@ func (m *Machine) SetStatus(status params.Status, info string) error {
 	if err := m.st.runTransaction(ops); err != nil {
 		return fmt.Errorf("cannot set status of machine %q: %v", m, onAbort(err, errNotAlive))
 	}
+	recordStatusChange(m.st, m.globalKey(), doc)
 	return nil
 }
 
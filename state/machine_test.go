@@ -419,6 +419,29 @@ func (s *MachineSuite) TestMachineSetProvisionedWhenNotAlive(c *C) {
 	})
 }
 
+func (s *MachineSuite) TestMachineUpdateInstanceId(c *C) {
+	err := s.machine.UpdateInstanceId("umbrella/1")
+	c.Assert(err, ErrorMatches, `cannot update instance id of machine "0": not provisioned`)
+
+	err = s.machine.SetProvisioned("umbrella/0", "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	err = s.machine.UpdateInstanceId("")
+	c.Assert(err, ErrorMatches, `cannot update instance id of machine "0": instance id cannot be empty`)
+	err = s.machine.UpdateInstanceId("umbrella/1")
+	c.Assert(err, IsNil)
+	id, err := s.machine.InstanceId()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, instance.Id("umbrella/1"))
+
+	// Reload machine and check again.
+	m, err := s.State.Machine(s.machine.Id())
+	c.Assert(err, IsNil)
+	id, err = m.InstanceId()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, instance.Id("umbrella/1"))
+	c.Assert(m.CheckProvisioned("fake_nonce"), Equals, true)
+}
+
 func (s *MachineSuite) TestMachineRefresh(c *C) {
 	m0, err := s.State.AddMachine("series", state.JobHostUnits)
 	c.Assert(err, IsNil)
@@ -84,6 +84,7 @@ func (u *backingUnit) updated(st *State, store *multiwatcher.Store, id interface
 		PrivateAddress: u.PrivateAddress,
 		MachineId:      u.MachineId,
 		Ports:          u.Ports,
+		Idle:           u.Idle,
 	}
 	if u.CharmURL != nil {
 		info.CharmURL = u.CharmURL.String()
@@ -98,11 +99,19 @@ func (u *backingUnit) updated(st *State, store *multiwatcher.Store, id interface
 		}
 		info.Status = sdoc.Status
 		info.StatusInfo = sdoc.StatusInfo
+		wdoc, err := getWorkloadStatus(st, u.Name)
+		if err != nil {
+			return err
+		}
+		info.WorkloadStatus = wdoc.Status
+		info.WorkloadStatusInfo = wdoc.StatusInfo
 	} else {
 		// The entry already exists, so preserve the current status.
 		oldInfo := oldInfo.(*params.UnitInfo)
 		info.Status = oldInfo.Status
 		info.StatusInfo = oldInfo.StatusInfo
+		info.WorkloadStatus = oldInfo.WorkloadStatus
+		info.WorkloadStatusInfo = oldInfo.WorkloadStatusInfo
 	}
 	store.Update(info)
 	return nil
@@ -237,7 +246,11 @@ func (a *backingAnnotation) mongoId() interface{} {
 type backingStatus statusDoc
 
 func (s *backingStatus) updated(st *State, store *multiwatcher.Store, id interface{}) error {
-	parentId, ok := backingEntityIdForGlobalKey(id.(string))
+	key := id.(string)
+	if strings.HasSuffix(key, workloadKeySuffix) {
+		return s.updatedWorkload(store, strings.TrimSuffix(key, workloadKeySuffix))
+	}
+	parentId, ok := backingEntityIdForGlobalKey(key)
 	if !ok {
 		return nil
 	}
@@ -263,6 +276,25 @@ func (s *backingStatus) updated(st *State, store *multiwatcher.Store, id interfa
 	return nil
 }
 
+// updatedWorkload updates the workload status of the unit with the
+// given global key.
+func (s *backingStatus) updatedWorkload(store *multiwatcher.Store, unitKey string) error {
+	parentId, ok := backingEntityIdForGlobalKey(unitKey)
+	if !ok {
+		return nil
+	}
+	info, ok := store.Get(parentId).(*params.UnitInfo)
+	if !ok {
+		// The unit info doesn't exist. Ignore the status until it does.
+		return nil
+	}
+	newInfo := *info
+	newInfo.WorkloadStatus = s.Status
+	newInfo.WorkloadStatusInfo = s.StatusInfo
+	store.Update(&newInfo)
+	return nil
+}
+
 func (s *backingStatus) removed(st *State, store *multiwatcher.Store, id interface{}) error {
 	// If the status is removed, the parent will follow not long after,
 	// so do nothing.
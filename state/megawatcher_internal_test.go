@@ -128,12 +128,13 @@ func (s *storeManagerStateSuite) setUpScenario(c *C) (entities entityInfoSlice)
 		c.Assert(m.Tag(), Equals, fmt.Sprintf("machine-%d", i+1))
 
 		add(&params.UnitInfo{
-			Name:      fmt.Sprintf("wordpress/%d", i),
-			Service:   wordpress.Name(),
-			Series:    m.Series(),
-			MachineId: m.Id(),
-			Ports:     []instance.Port{},
-			Status:    params.StatusPending,
+			Name:           fmt.Sprintf("wordpress/%d", i),
+			Service:        wordpress.Name(),
+			Series:         m.Series(),
+			MachineId:      m.Id(),
+			Ports:          []instance.PortRange{},
+			Status:         params.StatusPending,
+			WorkloadStatus: params.StatusUnknown,
 		})
 		pairs := map[string]string{"name": fmt.Sprintf("bar %d", i)}
 		err = wu.SetAnnotations(pairs)
@@ -175,11 +176,12 @@ func (s *storeManagerStateSuite) setUpScenario(c *C) (entities entityInfoSlice)
 		c.Assert(ok, Equals, true)
 		c.Assert(deployer, Equals, fmt.Sprintf("unit-wordpress-%d", i))
 		add(&params.UnitInfo{
-			Name:    fmt.Sprintf("logging/%d", i),
-			Service: "logging",
-			Series:  "series",
-			Ports:   []instance.Port{},
-			Status:  params.StatusPending,
+			Name:           fmt.Sprintf("logging/%d", i),
+			Service:        "logging",
+			Series:         "series",
+			Ports:          []instance.PortRange{},
+			Status:         params.StatusPending,
+			WorkloadStatus: params.StatusUnknown,
 		})
 	}
 	return
@@ -325,6 +327,8 @@ var allWatcherChangedTests = []struct {
 			c.Assert(err, IsNil)
 			err = u.OpenPort("tcp", 12345)
 			c.Assert(err, IsNil)
+			err = u.SetIdle(true)
+			c.Assert(err, IsNil)
 			m, err := st.AddMachine("series", JobHostUnits)
 			c.Assert(err, IsNil)
 			err = u.AssignToMachine(m)
@@ -344,9 +348,11 @@ var allWatcherChangedTests = []struct {
 				PublicAddress:  "public",
 				PrivateAddress: "private",
 				MachineId:      "0",
-				Ports:          []instance.Port{{"tcp", 12345}},
+				Ports:          []instance.PortRange{{"tcp", 12345, 12345}},
 				Status:         params.StatusError,
 				StatusInfo:     "failure",
+				WorkloadStatus: params.StatusUnknown,
+				Idle:           true,
 			},
 		},
 	}, {
@@ -376,7 +382,7 @@ var allWatcherChangedTests = []struct {
 				Service:       "wordpress",
 				Series:        "series",
 				PublicAddress: "public",
-				Ports:         []instance.Port{{"udp", 17070}},
+				Ports:         []instance.PortRange{{"udp", 17070, 17070}},
 				Status:        params.StatusError,
 				StatusInfo:    "another failure",
 			},
@@ -636,6 +642,42 @@ var allWatcherChangedTests = []struct {
 			},
 		},
 	},
+	// Unit workload status changes
+	{
+		about: "no unit in state -> do nothing",
+		setUp: func(c *C, st *State) {},
+		change: watcher.Change{
+			C:  "statuses",
+			Id: "u#wordpress/0#workload",
+		},
+	}, {
+		about: "workload status is changed if the unit exists in the store",
+		add: []params.EntityInfo{&params.UnitInfo{
+			Name:           "wordpress/0",
+			Status:         params.StatusStarted,
+			WorkloadStatus: params.StatusUnknown,
+		}},
+		setUp: func(c *C, st *State) {
+			wordpress, err := st.AddService("wordpress", AddTestingCharm(c, st, "wordpress"))
+			c.Assert(err, IsNil)
+			u, err := wordpress.AddUnit()
+			c.Assert(err, IsNil)
+			err = u.SetWorkloadStatus(params.StatusBlocked, "need a database")
+			c.Assert(err, IsNil)
+		},
+		change: watcher.Change{
+			C:  "statuses",
+			Id: "u#wordpress/0#workload",
+		},
+		expectContents: []params.EntityInfo{
+			&params.UnitInfo{
+				Name:               "wordpress/0",
+				Status:             params.StatusStarted,
+				WorkloadStatus:     params.StatusBlocked,
+				WorkloadStatusInfo: "need a database",
+			},
+		},
+	},
 	// Machine status changes
 	{
 		about: "no machine in state -> do nothing",
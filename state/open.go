@@ -169,6 +169,10 @@ var indexes = []struct {
 	{"units", []string{"principal"}},
 	{"units", []string{"machineid"}},
 	{"users", []string{"name"}},
+	{"runcommands", []string{"receiver", "status"}},
+	{"confighistory", []string{"service", "revision"}},
+	{"statushistory", []string{"entitykey", "seq"}},
+	{"actions", []string{"unit", "status"}},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -179,6 +183,10 @@ var (
 	logSizeTests = 1000000
 )
 
+// The capped collection used for the audit log of client operations
+// defaults to 10MB, and is similarly tweaked in tests.
+var auditLogSize = 10000000
+
 func maybeUnauthorized(err error, msg string) error {
 	if err == nil {
 		return nil
@@ -231,6 +239,13 @@ func newState(session *mgo.Session, info *Info) (*State, error) {
 		cleanups:       db.C("cleanups"),
 		annotations:    db.C("annotations"),
 		statuses:       db.C("statuses"),
+		runCommands:    db.C("runcommands"),
+		configHistory:  db.C("confighistory"),
+		statusHistory:  db.C("statushistory"),
+		actions:        db.C("actions"),
+		auditLog:       db.C("auditlog"),
+		blocks:         db.C("blocks"),
+		leaderships:    db.C("leaderships"),
 	}
 	log := db.C("txns.log")
 	logInfo := mgo.CollectionInfo{Capped: true, MaxBytes: logSize}
@@ -240,6 +255,11 @@ func newState(session *mgo.Session, info *Info) (*State, error) {
 	if err != nil && err.Error() != "collection already exists" {
 		return nil, maybeUnauthorized(err, "cannot create log collection")
 	}
+	auditLogInfo := mgo.CollectionInfo{Capped: true, MaxBytes: auditLogSize}
+	err = st.auditLog.Create(&auditLogInfo)
+	if err != nil && err.Error() != "collection already exists" {
+		return nil, maybeUnauthorized(err, "cannot create audit log collection")
+	}
 	st.runner = txn.NewRunner(db.C("txns"))
 	st.runner.ChangeLog(db.C("txns.log"))
 	st.watcher = watcher.New(db.C("txns.log"))
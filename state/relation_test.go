@@ -293,5 +293,10 @@ func assertOneRelation(c *C, srv *state.Service, relId int, endpoints ...state.E
 	eps, err := rel.RelatedEndpoints(name)
 	c.Assert(err, IsNil)
 	c.Assert(eps, DeepEquals, []state.Endpoint{expectEp})
+	eps = rel.Endpoints()
+	c.Assert(eps, HasLen, len(endpoints))
+	for _, ep := range endpoints {
+		c.Assert(eps[0] == ep || eps[len(eps)-1] == ep, Equals, true)
+	}
 	return rel
 }
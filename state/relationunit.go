@@ -304,6 +304,20 @@ func (ru *RelationUnit) LeaveScope() error {
 	return fmt.Errorf("cannot leave scope for %s: inconsistent state", desc)
 }
 
+// InScope returns whether the unit has entered and not left its scope
+// in the relation.
+func (ru *RelationUnit) InScope() (bool, error) {
+	key, err := ru.key(ru.unit.Name())
+	if err != nil {
+		return false, err
+	}
+	count, err := ru.st.relationScopes.FindId(key).Count()
+	if err != nil {
+		return false, fmt.Errorf("cannot examine scope for unit %q in relation %q: %v", ru.unit, ru.relation, err)
+	}
+	return count > 0, nil
+}
+
 // WatchScope returns a watcher which notifies of counterpart units
 // entering and leaving the unit's scope.
 func (ru *RelationUnit) WatchScope() *RelationScopeWatcher {
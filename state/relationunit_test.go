@@ -342,6 +342,23 @@ func (s *RelationUnitSuite) TestAliveRelationScope(c *C) {
 	c.Assert(err, Equals, state.ErrCannotEnterScope)
 }
 
+func (s *RelationUnitSuite) TestInScope(c *C) {
+	pr := NewPeerRelation(c, &s.ConnSuite)
+	assertInScope := func(ru *state.RelationUnit, expect bool) {
+		inScope, err := ru.InScope()
+		c.Assert(err, IsNil)
+		c.Assert(inScope, Equals, expect)
+	}
+	assertInScope(pr.ru0, false)
+	err := pr.ru0.EnterScope(nil)
+	c.Assert(err, IsNil)
+	assertInScope(pr.ru0, true)
+	assertInScope(pr.ru1, false)
+	err = pr.ru0.LeaveScope()
+	c.Assert(err, IsNil)
+	assertInScope(pr.ru0, false)
+}
+
 func (s *RelationUnitSuite) TestPeerWatchScope(c *C) {
 	pr := NewPeerRelation(c, &s.ConnSuite)
 
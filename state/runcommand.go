@@ -0,0 +1,252 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// RunCommandStatus describes the progress of a queued RunCommand.
+type RunCommandStatus string
+
+const (
+	// RunCommandPending indicates that the commands have been queued
+	// but their receiver has not yet reported a result.
+	RunCommandPending RunCommandStatus = "pending"
+
+	// RunCommandCompleted indicates that the receiver has run the
+	// commands and recorded their result.
+	RunCommandCompleted RunCommandStatus = "completed"
+)
+
+// RunResult holds the outcome of executing a RunCommand.
+type RunResult struct {
+	Code   int
+	Stdout []byte
+	Stderr []byte
+	// Error holds a description of any failure to execute the
+	// commands, as distinct from the commands exiting with a
+	// non-zero code.
+	Error string
+}
+
+// runCommandDoc represents a set of commands queued for execution by
+// the agent of a unit or machine.
+type runCommandDoc struct {
+	Id       string `bson:"_id"`
+	Receiver string
+	Commands string
+	Timeout  time.Duration
+	Enqueued time.Time
+	Status   RunCommandStatus
+	Result   RunResult
+}
+
+// RunCommand represents a set of commands to be run by the agent of a
+// unit or machine, as requested by "juju run".
+type RunCommand struct {
+	st  *State
+	doc runCommandDoc
+}
+
+func newRunCommand(st *State, doc *runCommandDoc) *RunCommand {
+	return &RunCommand{
+		st:  st,
+		doc: *doc,
+	}
+}
+
+// Id returns the identifier of the run command.
+func (r *RunCommand) Id() string {
+	return r.doc.Id
+}
+
+// Receiver returns the tag of the unit or machine that should run
+// the commands.
+func (r *RunCommand) Receiver() string {
+	return r.doc.Receiver
+}
+
+// Commands returns the shell commands to run.
+func (r *RunCommand) Commands() string {
+	return r.doc.Commands
+}
+
+// Timeout returns the maximum time the commands may run for. A zero
+// value means the commands may run indefinitely.
+func (r *RunCommand) Timeout() time.Duration {
+	return r.doc.Timeout
+}
+
+// Enqueued returns the time at which the commands were queued.
+func (r *RunCommand) Enqueued() time.Time {
+	return r.doc.Enqueued
+}
+
+// Status returns the progress of the run command.
+func (r *RunCommand) Status() RunCommandStatus {
+	return r.doc.Status
+}
+
+// Result returns the outcome of the commands, and whether they
+// have completed.
+func (r *RunCommand) Result() (RunResult, bool) {
+	return r.doc.Result, r.doc.Status == RunCommandCompleted
+}
+
+// String returns a human-readable description of the run command.
+func (r *RunCommand) String() string {
+	return fmt.Sprintf("run command %s for %s", r.doc.Id, r.doc.Receiver)
+}
+
+// Refresh refreshes the contents of the RunCommand from the underlying
+// state. It returns an error that satisfies errors.IsNotFoundError if the
+// run command has been removed.
+func (r *RunCommand) Refresh() error {
+	doc := runCommandDoc{}
+	err := r.st.runCommands.FindId(r.doc.Id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("run command %s", r.doc.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh run command %s: %v", r.doc.Id, err)
+	}
+	r.doc = doc
+	return nil
+}
+
+// Complete records the result of running the commands. It fails if a
+// result has already been recorded, or if the run command has been
+// removed because nobody is waiting for its result any more.
+func (r *RunCommand) Complete(result RunResult) (err error) {
+	defer utils.ErrorContextf(&err, "cannot complete %s", r)
+	ops := []txn.Op{{
+		C:      r.st.runCommands.Name,
+		Id:     r.doc.Id,
+		Assert: D{{"status", RunCommandPending}},
+		Update: D{{"$set", D{
+			{"status", RunCommandCompleted},
+			{"result", result},
+		}}},
+	}}
+	if err := r.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := r.Refresh(); err != nil {
+			return err
+		}
+		return fmt.Errorf("already completed")
+	} else if err != nil {
+		return err
+	}
+	r.doc.Status = RunCommandCompleted
+	r.doc.Result = result
+	return nil
+}
+
+// Remove removes the run command from the state, whether or not it
+// has completed.
+func (r *RunCommand) Remove() error {
+	ops := []txn.Op{{
+		C:      r.st.runCommands.Name,
+		Id:     r.doc.Id,
+		Remove: true,
+	}}
+	if err := r.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot remove %s: %v", r, err)
+	}
+	return nil
+}
+
+// Watch returns a watcher for observing changes to the run command.
+func (r *RunCommand) Watch() NotifyWatcher {
+	return newEntityWatcher(r.st, r.st.runCommands, r.doc.Id)
+}
+
+// EnqueueRunCommand queues the supplied commands for execution by the
+// agent of the unit or machine with the given tag. Units run the
+// commands in a hook context, so that hook tools are available; machines
+// run them directly.
+func (st *State) EnqueueRunCommand(receiver, commands string, timeout time.Duration) (rc *RunCommand, err error) {
+	defer utils.ErrorContextf(&err, "cannot enqueue commands for %q", receiver)
+	if commands == "" {
+		return nil, fmt.Errorf("no commands specified")
+	}
+	entity, err := st.Lifer(receiver)
+	if err != nil {
+		return nil, err
+	}
+	var coll, id string
+	switch entity := entity.(type) {
+	case *Unit:
+		coll, id = st.units.Name, entity.Name()
+	case *Machine:
+		coll, id = st.machines.Name, entity.Id()
+	default:
+		return nil, fmt.Errorf("commands can only be run on units and machines")
+	}
+	seq, err := st.sequence("runcommand")
+	if err != nil {
+		return nil, err
+	}
+	doc := &runCommandDoc{
+		Id:       strconv.Itoa(seq),
+		Receiver: receiver,
+		Commands: commands,
+		Timeout:  timeout,
+		Enqueued: time.Now(),
+		Status:   RunCommandPending,
+	}
+	ops := []txn.Op{{
+		C:      coll,
+		Id:     id,
+		Assert: notDeadDoc,
+	}, {
+		C:      st.runCommands.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, fmt.Errorf("%s is dead", receiver)
+	} else if err != nil {
+		return nil, err
+	}
+	return newRunCommand(st, doc), nil
+}
+
+// RunCommand returns the run command with the given id.
+func (st *State) RunCommand(id string) (*RunCommand, error) {
+	doc := &runCommandDoc{}
+	err := st.runCommands.FindId(id).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("run command %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get run command %s: %v", id, err)
+	}
+	return newRunCommand(st, doc), nil
+}
+
+// PendingRunCommands returns all the run commands queued for the unit
+// or machine with the given tag that have not yet completed, in the
+// order in which they were enqueued.
+func (st *State) PendingRunCommands(receiver string) ([]*RunCommand, error) {
+	var docs []runCommandDoc
+	sel := D{{"receiver", receiver}, {"status", RunCommandPending}}
+	if err := st.runCommands.Find(sel).Sort("enqueued").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get run commands for %q: %v", receiver, err)
+	}
+	commands := make([]*RunCommand, len(docs))
+	for i := range docs {
+		commands[i] = newRunCommand(st, &docs[i])
+	}
+	return commands, nil
+}
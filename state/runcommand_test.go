@@ -0,0 +1,176 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/testing"
+)
+
+type RunCommandSuite struct {
+	ConnSuite
+	unit    *state.Unit
+	machine *state.Machine
+}
+
+var _ = Suite(&RunCommandSuite{})
+
+func (s *RunCommandSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	svc, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+	s.machine, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+}
+
+func (s *RunCommandSuite) TestEnqueueRunCommand(c *C) {
+	rc, err := s.State.EnqueueRunCommand(s.unit.Tag(), "hostname", time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(rc.Receiver(), Equals, "unit-wordpress-0")
+	c.Assert(rc.Commands(), Equals, "hostname")
+	c.Assert(rc.Timeout(), Equals, time.Minute)
+	c.Assert(rc.Status(), Equals, state.RunCommandPending)
+	_, done := rc.Result()
+	c.Assert(done, Equals, false)
+
+	rc1, err := s.State.RunCommand(rc.Id())
+	c.Assert(err, IsNil)
+	c.Assert(rc1.Receiver(), Equals, rc.Receiver())
+	c.Assert(rc1.Commands(), Equals, rc.Commands())
+
+	rc2, err := s.State.EnqueueRunCommand(s.machine.Tag(), "uptime", 0)
+	c.Assert(err, IsNil)
+	c.Assert(rc2.Receiver(), Equals, "machine-0")
+	c.Assert(rc2.Id(), Not(Equals), rc.Id())
+}
+
+func (s *RunCommandSuite) TestEnqueueRunCommandErrors(c *C) {
+	_, err := s.State.EnqueueRunCommand(s.unit.Tag(), "", 0)
+	c.Assert(err, ErrorMatches, `cannot enqueue commands for "unit-wordpress-0": no commands specified`)
+
+	_, err = s.State.EnqueueRunCommand("service-wordpress", "hostname", 0)
+	c.Assert(err, ErrorMatches, `cannot enqueue commands for "service-wordpress": commands can only be run on units and machines`)
+
+	_, err = s.State.EnqueueRunCommand("unit-wordpress-9", "hostname", 0)
+	c.Assert(err, ErrorMatches, `cannot enqueue commands for "unit-wordpress-9": unit "wordpress/9" not found`)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	_, err = s.State.EnqueueRunCommand(s.unit.Tag(), "hostname", 0)
+	c.Assert(err, ErrorMatches, `cannot enqueue commands for "unit-wordpress-0": unit-wordpress-0 is dead`)
+}
+
+func (s *RunCommandSuite) TestComplete(c *C) {
+	rc, err := s.State.EnqueueRunCommand(s.unit.Tag(), "hostname", 0)
+	c.Assert(err, IsNil)
+	result := state.RunResult{
+		Code:   1,
+		Stdout: []byte("out"),
+		Stderr: []byte("err"),
+	}
+	err = rc.Complete(result)
+	c.Assert(err, IsNil)
+	c.Assert(rc.Status(), Equals, state.RunCommandCompleted)
+
+	err = rc.Complete(result)
+	c.Assert(err, ErrorMatches, `cannot complete run command \d+ for unit-wordpress-0: already completed`)
+
+	rc, err = s.State.RunCommand(rc.Id())
+	c.Assert(err, IsNil)
+	actual, done := rc.Result()
+	c.Assert(done, Equals, true)
+	c.Assert(actual, DeepEquals, result)
+}
+
+func (s *RunCommandSuite) TestRemove(c *C) {
+	rc, err := s.State.EnqueueRunCommand(s.unit.Tag(), "hostname", 0)
+	c.Assert(err, IsNil)
+	err = rc.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.RunCommand(rc.Id())
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+	err = rc.Refresh()
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+	err = rc.Complete(state.RunResult{})
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+}
+
+func (s *RunCommandSuite) TestPendingRunCommands(c *C) {
+	var ids []string
+	for _, commands := range []string{"one", "two", "three"} {
+		rc, err := s.State.EnqueueRunCommand(s.unit.Tag(), commands, 0)
+		c.Assert(err, IsNil)
+		ids = append(ids, rc.Id())
+	}
+	_, err := s.State.EnqueueRunCommand(s.machine.Tag(), "other", 0)
+	c.Assert(err, IsNil)
+
+	pending, err := s.State.PendingRunCommands(s.unit.Tag())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 3)
+	err = pending[1].Complete(state.RunResult{})
+	c.Assert(err, IsNil)
+
+	pending, err = s.State.PendingRunCommands(s.unit.Tag())
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 2)
+	c.Assert(pending[0].Id(), Equals, ids[0])
+	c.Assert(pending[0].Commands(), Equals, "one")
+	c.Assert(pending[1].Id(), Equals, ids[2])
+	c.Assert(pending[1].Commands(), Equals, "three")
+}
+
+func (s *RunCommandSuite) TestWatchRunCommands(c *C) {
+	rc0, err := s.State.EnqueueRunCommand(s.unit.Tag(), "one", 0)
+	c.Assert(err, IsNil)
+
+	w := s.State.WatchRunCommands(s.unit.Tag())
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(rc0.Id())
+	wc.AssertNoChange()
+
+	// Commands for other receivers are ignored.
+	_, err = s.State.EnqueueRunCommand(s.machine.Tag(), "other", 0)
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// Completing or removing a known command is not reported.
+	err = rc0.Complete(state.RunResult{})
+	c.Assert(err, IsNil)
+	err = rc0.Remove()
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// New commands are reported.
+	rc1, err := s.State.EnqueueRunCommand(s.unit.Tag(), "two", 0)
+	c.Assert(err, IsNil)
+	rc2, err := s.State.EnqueueRunCommand(s.unit.Tag(), "three", 0)
+	c.Assert(err, IsNil)
+	wc.AssertChange(rc1.Id(), rc2.Id())
+	wc.AssertNoChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *RunCommandSuite) TestRunCommandWatch(c *C) {
+	rc, err := s.State.EnqueueRunCommand(s.unit.Tag(), "one", 0)
+	c.Assert(err, IsNil)
+	w := rc.Watch()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err = rc.Complete(state.RunResult{Stdout: []byte("hello")})
+	c.Assert(err, IsNil)
+	wc.AssertOneChange()
+}
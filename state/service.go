@@ -41,7 +41,11 @@ type serviceDoc struct {
 	RelationCount int
 	Exposed       bool
 	MinUnits      int
-	TxnRevno      int64 `bson:"txn-revno"`
+	// While CharmUpgradeRestricted is set, only the units named in
+	// CharmUpgradeUnits are upgraded to the service's charm.
+	CharmUpgradeRestricted bool
+	CharmUpgradeUnits      []string
+	TxnRevno               int64 `bson:"txn-revno"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -62,11 +66,17 @@ func (s *Service) Name() string {
 	return s.doc.Name
 }
 
+// ServiceTag returns the tag for the
+// service with the given name.
+func ServiceTag(serviceName string) string {
+	return "service-" + serviceName
+}
+
 // Tag returns a name identifying the service that is safe to use
 // as a file name.  The returned name will be different from other
 // Tag values returned by any other entities from the same state.
 func (s *Service) Tag() string {
-	return "service-" + s.Name()
+	return ServiceTag(s.Name())
 }
 
 // serviceGlobalKey returns the global database key for the service
@@ -221,8 +231,17 @@ func (s *Service) removeOps(asserts D) []txn.Op {
 		C:      s.st.settings.Name,
 		Id:     s.settingsKey(),
 		Remove: true,
+	}, {
+		C:      s.st.leaderships.Name,
+		Id:     s.doc.Name,
+		Remove: true,
+	}, {
+		C:      s.st.settings.Name,
+		Id:     leaderSettingsKey(s.doc.Name),
+		Remove: true,
 	}}
 	ops = append(ops, removeConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, s.st.newCleanupOp("confighistory", s.doc.Name))
 	return append(ops, annotationRemoveOp(s.st, s.globalKey()))
 }
 
@@ -287,15 +306,20 @@ func (s *Service) Endpoints() (eps []Endpoint, err error) {
 	if err != nil {
 		return nil, err
 	}
+	return metaEndpoints(s.doc.Name, ch.Meta()), nil
+}
+
+// metaEndpoints returns the relation endpoints of a service with the
+// given name running a charm with the given metadata.
+func metaEndpoints(serviceName string, meta *charm.Meta) (eps []Endpoint) {
 	collect := func(role charm.RelationRole, rels map[string]charm.Relation) {
 		for _, rel := range rels {
 			eps = append(eps, Endpoint{
-				ServiceName: s.doc.Name,
+				ServiceName: serviceName,
 				Relation:    rel,
 			})
 		}
 	}
-	meta := ch.Meta()
 	collect(charm.RolePeer, meta.Peers)
 	collect(charm.RoleProvider, meta.Provides)
 	collect(charm.RoleRequirer, meta.Requires)
@@ -308,7 +332,7 @@ func (s *Service) Endpoints() (eps []Endpoint, err error) {
 		},
 	})
 	sort.Sort(epSlice(eps))
-	return eps, nil
+	return eps
 }
 
 // Endpoint returns the relation endpoint with the supplied name, if it exists.
@@ -456,8 +480,20 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 
 // SetCharm changes the charm for the service. New units will be started with
 // this charm, and existing units will be upgraded to use it. If force is true,
-// units will be upgraded even if they are in an error state.
+// units will be upgraded even if they are in an error state. Any restriction
+// set by SetCharmRestricted is lifted.
 func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
+	return s.setCharm(ch, force, false)
+}
+
+// SetCharmRestricted changes the charm for the service like SetCharm,
+// but existing units are only upgraded once they have been allowed to
+// by AllowCharmUpgrade. The restriction is lifted by calling SetCharm.
+func (s *Service) SetCharmRestricted(ch *Charm, force bool) (err error) {
+	return s.setCharm(ch, force, true)
+}
+
+func (s *Service) setCharm(ch *Charm, force, restricted bool) (err error) {
 	if ch.Meta().Subordinate != s.doc.Subordinate {
 		return fmt.Errorf("cannot change a service's subordinacy")
 	}
@@ -486,10 +522,23 @@ func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
 				return err
 			}
 		}
+		// No unit is allowed to upgrade when the restriction is set;
+		// when it is lifted, the record of allowed units is dropped.
+		restrictUpdate := D{
+			{"charmupgraderestricted", restricted},
+			{"charmupgradeunits", []string{}},
+		}
+		ops = append(ops, txn.Op{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Update: D{{"$set", restrictUpdate}},
+		})
 
 		if err := s.st.runTransaction(ops); err == nil {
 			s.doc.CharmURL = ch.URL()
 			s.doc.ForceCharm = force
+			s.doc.CharmUpgradeRestricted = restricted
+			s.doc.CharmUpgradeUnits = nil
 			return nil
 		} else if err != txn.ErrAborted {
 			return err
@@ -506,6 +555,48 @@ func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
 	return ErrExcessiveContention
 }
 
+// CharmUpgradeRestricted returns whether existing units are only
+// upgraded to the service's charm once they are allowed to by
+// AllowCharmUpgrade.
+func (s *Service) CharmUpgradeRestricted() bool {
+	return s.doc.CharmUpgradeRestricted
+}
+
+// CharmUpgradeAllowed returns whether the named unit may be upgraded to
+// the service's charm.
+func (s *Service) CharmUpgradeAllowed(unitName string) bool {
+	if !s.doc.CharmUpgradeRestricted {
+		return true
+	}
+	for _, name := range s.doc.CharmUpgradeUnits {
+		if name == unitName {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowCharmUpgrade allows the named units to be upgraded to the
+// service's charm while the upgrade is restricted by SetCharmRestricted.
+func (s *Service) AllowCharmUpgrade(unitNames ...string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot allow charm upgrade of service %q", s)
+	ops := []txn.Op{{
+		C:      s.st.services.Name,
+		Id:     s.doc.Name,
+		Assert: isAliveDoc,
+		Update: D{{"$addToSet", D{{"charmupgradeunits", D{{"$each", unitNames}}}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, errNotAlive)
+	}
+	for _, name := range unitNames {
+		if !s.CharmUpgradeAllowed(name) {
+			s.doc.CharmUpgradeUnits = append(s.doc.CharmUpgradeUnits, name)
+		}
+	}
+	return nil
+}
+
 // String returns the service name.
 func (s *Service) String() string {
 	return s.doc.Name
@@ -572,6 +663,9 @@ func (s *Service) addUnitOps(principalName string, asserts D) (string, []txn.Op,
 			Insert: udoc,
 		},
 		createStatusOp(s.st, globalKey, sdoc),
+		createStatusOp(s.st, unitWorkloadGlobalKey(name), statusDoc{
+			Status: params.StatusUnknown,
+		}),
 		{
 			C:      s.st.services.Name,
 			Id:     s.doc.Name,
@@ -655,6 +749,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts D) ([]txn.Op, error) {
 	},
 		removeConstraintsOp(s.st, u.globalKey()),
 		removeStatusOp(s.st, u.globalKey()),
+		removeStatusOp(s.st, unitWorkloadGlobalKey(u.doc.Name)),
 		annotationRemoveOp(s.st, u.globalKey()),
 	)
 	if u.doc.CharmURL != nil {
@@ -742,6 +837,13 @@ func (s *Service) ConfigSettings() (charm.Settings, error) {
 // UpdateConfigSettings changes a service's charm config settings. Values set
 // to nil will be deleted; unknown and invalid values will return an error.
 func (s *Service) UpdateConfigSettings(changes charm.Settings) error {
+	return s.UpdateConfigSettingsAs(changes, "")
+}
+
+// UpdateConfigSettingsAs is like UpdateConfigSettings, but also records
+// the tag of the entity making the change in the service's configuration
+// history.
+func (s *Service) UpdateConfigSettingsAs(changes charm.Settings, user string) error {
 	charm, _, err := s.Charm()
 	if err != nil {
 		return err
@@ -765,8 +867,11 @@ func (s *Service) UpdateConfigSettings(changes charm.Settings) error {
 			node.Set(name, value)
 		}
 	}
-	_, err = node.Write()
-	return err
+	itemChanges, err := node.Write()
+	if err != nil || len(itemChanges) == 0 {
+		return err
+	}
+	return s.recordConfigChange(user, itemChanges, node.Map())
 }
 
 var ErrSubordinateConstraints = stderrors.New("constraints do not apply to subordinate services")
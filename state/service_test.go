@@ -64,6 +64,57 @@ func (s *ServiceSuite) TestSetCharm(c *C) {
 	c.Assert(err, ErrorMatches, `service "mysql" is not alive`)
 }
 
+func (s *ServiceSuite) TestSetCharmRestricted(c *C) {
+	c.Assert(s.mysql.CharmUpgradeRestricted(), Equals, false)
+	c.Assert(s.mysql.CharmUpgradeAllowed("mysql/0"), Equals, true)
+
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+	err := s.mysql.SetCharmRestricted(sch, false)
+	c.Assert(err, IsNil)
+	url, _ := s.mysql.CharmURL()
+	c.Assert(url, DeepEquals, sch.URL())
+	c.Assert(s.mysql.CharmUpgradeRestricted(), Equals, true)
+	c.Assert(s.mysql.CharmUpgradeAllowed("mysql/0"), Equals, false)
+
+	err = s.mysql.AllowCharmUpgrade("mysql/0", "mysql/1")
+	c.Assert(err, IsNil)
+	err = s.mysql.AllowCharmUpgrade("mysql/1")
+	c.Assert(err, IsNil)
+	for _, svc := range []*state.Service{s.mysql, s.refreshedService(c)} {
+		c.Assert(svc.CharmUpgradeAllowed("mysql/0"), Equals, true)
+		c.Assert(svc.CharmUpgradeAllowed("mysql/1"), Equals, true)
+		c.Assert(svc.CharmUpgradeAllowed("mysql/2"), Equals, false)
+	}
+
+	// Restricting the upgrade again forgets the allowed units.
+	err = s.mysql.SetCharmRestricted(sch, false)
+	c.Assert(err, IsNil)
+	svc := s.refreshedService(c)
+	c.Assert(svc.CharmUpgradeRestricted(), Equals, true)
+	c.Assert(svc.CharmUpgradeAllowed("mysql/0"), Equals, false)
+
+	// SetCharm lifts the restriction.
+	err = s.mysql.SetCharm(sch, false)
+	c.Assert(err, IsNil)
+	svc = s.refreshedService(c)
+	c.Assert(svc.CharmUpgradeRestricted(), Equals, false)
+	c.Assert(svc.CharmUpgradeAllowed("mysql/2"), Equals, true)
+
+	// AllowCharmUpgrade fails when the service is Dying.
+	_, err = s.mysql.AddUnit()
+	c.Assert(err, IsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, IsNil)
+	err = s.mysql.AllowCharmUpgrade("mysql/0")
+	c.Assert(err, ErrorMatches, `cannot allow charm upgrade of service "mysql": not found or not alive`)
+}
+
+func (s *ServiceSuite) refreshedService(c *C) *state.Service {
+	svc, err := s.State.Service(s.mysql.Name())
+	c.Assert(err, IsNil)
+	return svc
+}
+
 func (s *ServiceSuite) TestSetCharmErrors(c *C) {
 	logging := s.AddTestingCharm(c, "logging")
 	err := s.mysql.SetCharm(logging, false)
@@ -95,6 +95,13 @@ type State struct {
 	cleanups         *mgo.Collection
 	annotations      *mgo.Collection
 	statuses         *mgo.Collection
+	runCommands      *mgo.Collection
+	configHistory    *mgo.Collection
+	statusHistory    *mgo.Collection
+	actions          *mgo.Collection
+	auditLog         *mgo.Collection
+	blocks           *mgo.Collection
+	leaderships      *mgo.Collection
 	runner           *txn.Runner
 	transactionHooks chan ([]transactionHook)
 	watcher          *watcher.Watcher
@@ -215,15 +222,25 @@ func (st *State) AddMachineWithConstraints(params *AddMachineParams) (m *Machine
 // instance, configured to run the supplied jobs on the supplied series, using
 // the specified constraints.
 func (st *State) InjectMachine(series string, cons constraints.Value, instanceId instance.Id, hc instance.HardwareCharacteristics, jobs ...MachineJob) (m *Machine, err error) {
+	return st.InjectMachineWithNonce(series, cons, instanceId, BootstrapNonce, hc, jobs...)
+}
+
+// InjectMachineWithNonce is like InjectMachine, but records the given
+// provisioning nonce rather than the bootstrap nonce. It is used to add
+// machines that were provisioned outside the environment's provider.
+func (st *State) InjectMachineWithNonce(series string, cons constraints.Value, instanceId instance.Id, nonce string, hc instance.HardwareCharacteristics, jobs ...MachineJob) (m *Machine, err error) {
 	if instanceId == "" {
 		return nil, fmt.Errorf("cannot inject a machine without an instance id")
 	}
+	if nonce == "" {
+		return nil, fmt.Errorf("cannot inject a machine without a nonce")
+	}
 	return st.addMachine(&AddMachineParams{
 		Series:          series,
 		Constraints:     cons,
 		instanceId:      instanceId,
 		characteristics: hc,
-		nonce:           BootstrapNonce,
+		nonce:           nonce,
 		Jobs:            jobs,
 	})
 }
@@ -352,11 +369,20 @@ func (st *State) addMachineContainerOps(params *AddMachineParams, cons constrain
 			containerParams.hostId = mdoc.Id
 			containerParams.newHost = true
 		} else {
-			// If a parent machine is specified, make sure it exists.
-			_, err := st.Machine(containerParams.hostId)
+			// If a parent machine is specified, make sure it exists
+			// and will remain alive until the container is added.
+			host, err := st.Machine(containerParams.hostId)
 			if err != nil {
 				return nil, nil, nil, err
 			}
+			if host.Life() != Alive {
+				return nil, nil, nil, machineNotAliveErr
+			}
+			ops = []txn.Op{{
+				C:      st.machines.Name,
+				Id:     host.doc.Id,
+				Assert: isAliveDoc,
+			}}
 		}
 	}
 	return ops, instData, containerParams, nil
@@ -397,7 +423,10 @@ func (st *State) addMachine(params *AddMachineParams) (m *Machine, err error) {
 	ops = append(ops, machineOps...)
 
 	err = st.runTransaction(ops)
-	if err != nil {
+	if err == txn.ErrAborted && params.ParentId != "" {
+		// The parent machine is no longer alive.
+		return nil, machineNotAliveErr
+	} else if err != nil {
 		return nil, err
 	}
 	// Refresh to pick the txn-revno.
@@ -681,6 +710,7 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, b
 		URL:          curl,
 		Meta:         ch.Meta(),
 		Config:       ch.Config(),
+		Actions:      ch.Actions(),
 		BundleURL:    bundleURL,
 		BundleSha256: bundleSha256,
 	}
@@ -837,11 +867,20 @@ func (st *State) AllServices() (services []*Service, err error) {
 // uniquely specify a possible relation once all implicit relations have been
 // filtered, the endpoints corresponding to that relation will be returned.
 func (st *State) InferEndpoints(names []string) ([]Endpoint, error) {
+	return st.InferEndpointsWithCharms(names, nil)
+}
+
+// InferEndpointsWithCharms is like InferEndpoints, except that the
+// services named as keys in metas need not exist: their endpoints are
+// derived from the corresponding charm metadata instead. This allows
+// relations between services that have not yet been deployed to be
+// checked.
+func (st *State) InferEndpointsWithCharms(names []string, metas map[string]*charm.Meta) ([]Endpoint, error) {
 	// Collect all possible sane endpoint lists.
 	var candidates [][]Endpoint
 	switch len(names) {
 	case 1:
-		eps, err := st.endpoints(names[0], isPeer)
+		eps, err := st.endpoints(names[0], isPeer, metas)
 		if err != nil {
 			return nil, err
 		}
@@ -849,11 +888,11 @@ func (st *State) InferEndpoints(names []string) ([]Endpoint, error) {
 			candidates = append(candidates, []Endpoint{ep})
 		}
 	case 2:
-		eps1, err := st.endpoints(names[0], notPeer)
+		eps1, err := st.endpoints(names[0], notPeer, metas)
 		if err != nil {
 			return nil, err
 		}
-		eps2, err := st.endpoints(names[1], notPeer)
+		eps2, err := st.endpoints(names[1], notPeer, metas)
 		if err != nil {
 			return nil, err
 		}
@@ -906,8 +945,9 @@ func notPeer(ep Endpoint) bool {
 
 // endpoints returns all endpoints that could be intended by the
 // supplied endpoint name, and which cause the filter param to
-// return true.
-func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoint, error) {
+// return true. The endpoints of services named in metas are
+// derived from the corresponding charm metadata.
+func (st *State) endpoints(name string, filter func(ep Endpoint) bool, metas map[string]*charm.Meta) ([]Endpoint, error) {
 	var svcName, relName string
 	if i := strings.Index(name, ":"); i == -1 {
 		svcName = name
@@ -917,23 +957,30 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 	} else {
 		return nil, fmt.Errorf("invalid endpoint %q", name)
 	}
-	svc, err := st.Service(svcName)
-	if err != nil {
-		return nil, err
-	}
-	eps := []Endpoint{}
-	if relName != "" {
-		ep, err := svc.Endpoint(relName)
+	var eps []Endpoint
+	if meta, ok := metas[svcName]; ok {
+		eps = metaEndpoints(svcName, meta)
+	} else {
+		svc, err := st.Service(svcName)
 		if err != nil {
 			return nil, err
 		}
-		eps = append(eps, ep)
-	} else {
-		eps, err = svc.Endpoints()
-		if err != nil {
+		if eps, err = svc.Endpoints(); err != nil {
 			return nil, err
 		}
 	}
+	if relName != "" {
+		var named []Endpoint
+		for _, ep := range eps {
+			if ep.Name == relName {
+				named = append(named, ep)
+			}
+		}
+		if len(named) == 0 {
+			return nil, fmt.Errorf("service %q has no %q relation", svcName, relName)
+		}
+		eps = named
+	}
 	final := []Endpoint{}
 	for _, ep := range eps {
 		if filter(ep) {
@@ -1271,6 +1318,8 @@ func (st *State) Cleanup() error {
 			err = st.cleanupSettings(doc.Prefix)
 		case "units":
 			err = st.cleanupUnits(doc.Prefix)
+		case "confighistory":
+			err = st.cleanupConfigHistory(doc.Prefix)
 		default:
 			err = fmt.Errorf("unknown cleanup kind %q", doc.Kind)
 		}
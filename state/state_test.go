@@ -305,6 +305,22 @@ func (s *StateSuite) TestAddContainerErrors(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "cannot add a new container: no container type specified")
 }
 
+func (s *StateSuite) TestAddContainerToDyingMachine(c *gc.C) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = m.Destroy()
+	c.Assert(err, gc.IsNil)
+	params := state.AddMachineParams{
+		ParentId:      m.Id(),
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	_, err = s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.ErrorMatches, "cannot add a new container: machine is not alive")
+	s.assertMachineContainers(c, m, nil)
+}
+
 func (s *StateSuite) TestInjectMachineErrors(c *gc.C) {
 	hc := instance.HardwareCharacteristics{}
 	_, err := s.State.InjectMachine("", emptyCons, instance.Id("i-minvalid"), hc, state.JobHostUnits)
@@ -340,6 +356,20 @@ func (s *StateSuite) TestInjectMachine(c *gc.C) {
 	c.Assert(m.CheckProvisioned(state.BootstrapNonce), gc.Equals, true)
 }
 
+func (s *StateSuite) TestInjectMachineWithNonce(c *gc.C) {
+	hc := instance.HardwareCharacteristics{}
+	_, err := s.State.InjectMachineWithNonce("series", emptyCons, instance.Id("manual:host"), "", hc, state.JobHostUnits)
+	c.Assert(err, gc.ErrorMatches, "cannot inject a machine without a nonce")
+
+	m, err := s.State.InjectMachineWithNonce("series", emptyCons, instance.Id("manual:host"), "manual:host:nonce", hc, state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	instanceId, err := m.InstanceId()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instanceId, gc.Equals, instance.Id("manual:host"))
+	c.Assert(m.CheckProvisioned("manual:host:nonce"), gc.Equals, true)
+	c.Assert(m.CheckProvisioned(state.BootstrapNonce), gc.Equals, false)
+}
+
 func (s *StateSuite) TestAddContainerToInjectedMachine(c *gc.C) {
 	oneJob := []state.MachineJob{state.JobHostUnits}
 	hc := instance.HardwareCharacteristics{}
@@ -673,6 +703,37 @@ func (s *StateSuite) TestInferEndpoints(c *gc.C) {
 	}
 }
 
+func (s *StateSuite) TestInferEndpointsWithCharms(c *gc.C) {
+	// Only some of the services exist; the endpoints of the others
+	// are derived from their charms' metadata.
+	_, err := s.State.AddService("ms", s.AddTestingCharm(c, "mysql-alternative"))
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddService("wp", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, gc.IsNil)
+	riak := testing.Charms.Dir("riak").Meta()
+	metas := map[string]*charm.Meta{
+		"lg":  testing.Charms.Dir("logging").Meta(),
+		"rk1": riak,
+		"rk2": riak,
+	}
+
+	for i, t := range inferEndpointsTests {
+		c.Logf("test %d", i)
+		for j, input := range t.inputs {
+			c.Logf("  input %d", j)
+			eps, err := s.State.InferEndpointsWithCharms(input, metas)
+			if t.err == "" {
+				c.Assert(err, gc.IsNil)
+				c.Assert(eps, gc.DeepEquals, t.eps)
+			} else {
+				c.Assert(err, gc.ErrorMatches, t.err)
+			}
+		}
+	}
+	_, err = s.State.Service("lg")
+	c.Assert(errors.IsNotFoundError(err), gc.Equals, true)
+}
+
 func (s *StateSuite) TestEnvironConfig(c *gc.C) {
 	cfg, err := s.State.EnvironConfig()
 	c.Assert(err, gc.IsNil)
@@ -1028,15 +1089,16 @@ func (s *StateSuite) TestWatchMachineHardwareCharacteristics(c *gc.C) {
 }
 
 var sortPortsTests = []struct {
-	have, want []instance.Port
+	have, want []instance.PortRange
 }{
-	{nil, []instance.Port{}},
-	{[]instance.Port{{"b", 1}, {"a", 99}, {"a", 1}}, []instance.Port{{"a", 1}, {"a", 99}, {"b", 1}}},
+	{nil, []instance.PortRange{}},
+	{[]instance.PortRange{{"b", 1, 1}, {"a", 99, 99}, {"a", 1, 1}}, []instance.PortRange{{"a", 1, 1}, {"a", 99, 99}, {"b", 1, 1}}},
+	{[]instance.PortRange{{"a", 1, 10}, {"a", 2, 2}, {"a", 1, 5}}, []instance.PortRange{{"a", 1, 5}, {"a", 1, 10}, {"a", 2, 2}}},
 }
 
 func (*StateSuite) TestSortPorts(c *gc.C) {
 	for _, t := range sortPortsTests {
-		p := make([]instance.Port, len(t.have))
+		p := make([]instance.PortRange, len(t.have))
 		copy(p, t.have)
 		state.SortPorts(p)
 		c.Check(p, gc.DeepEquals, t.want)
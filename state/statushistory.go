@@ -0,0 +1,126 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// maxStatusHistory holds the number of status changes that are kept in
+// the history of each unit and machine.
+var maxStatusHistory = 50
+
+// StatusChange records a change to the status of a unit or machine.
+type StatusChange struct {
+	// Status holds the status that was set.
+	Status params.Status
+
+	// Info holds the information that was set with the status.
+	Info string
+
+	// Time holds the time at which the status was set.
+	Time time.Time
+}
+
+// StatusHistorian represents entities whose status changes are
+// recorded.
+type StatusHistorian interface {
+	StatusHistory() ([]*StatusChange, error)
+}
+
+// StatusHistorian attempts to return a StatusHistorian with the given
+// tag.
+func (st *State) StatusHistorian(tag string) (StatusHistorian, error) {
+	e, err := st.entity(tag)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := e.(StatusHistorian); ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("entity %q does not have a status history", tag)
+}
+
+// statusHistoryDoc represents a StatusChange in MongoDB.
+type statusHistoryDoc struct {
+	Id         string `bson:"_id"`
+	EntityKey  string
+	Seq        int
+	Status     params.Status
+	StatusInfo string
+	Time       time.Time
+}
+
+// entityStatusHistory returns the history of status changes of units
+// and machines, keyed by their global keys.
+func entityStatusHistory(st *State) *history {
+	return &history{
+		name:       "status history",
+		coll:       st.statusHistory,
+		ownerField: "entitykey",
+		seqField:   "seq",
+		max:        maxStatusHistory,
+	}
+}
+
+// recordStatusChange adds a change to the status history of the
+// entity with the given global key, and discards changes that are no
+// longer kept. The status has already been set, so failing to record
+// it is logged rather than returned.
+func recordStatusChange(st *State, globalKey string, doc statusDoc) {
+	err := entityStatusHistory(st).record(globalKey, func(seq int) interface{} {
+		return &statusHistoryDoc{
+			Id:         fmt.Sprintf("%s#%d", globalKey, seq),
+			EntityKey:  globalKey,
+			Seq:        seq,
+			Status:     doc.Status,
+			StatusInfo: doc.StatusInfo,
+			Time:       time.Now().UTC(),
+		}
+	})
+	if err != nil {
+		log.Warningf("state: cannot record status of %q: %v", globalKey, err)
+	}
+}
+
+// removeStatusHistory removes all recorded status changes of the
+// entity with the given global key.
+func removeStatusHistory(st *State, globalKey string) error {
+	return entityStatusHistory(st).remove(globalKey)
+}
+
+// statusHistory returns the recorded status changes of the entity with
+// the given global key, oldest first.
+func statusHistory(st *State, globalKey string) ([]*StatusChange, error) {
+	var docs []statusHistoryDoc
+	err := st.statusHistory.Find(D{{"entitykey", globalKey}}).Sort("seq").All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get status history: %v", err)
+	}
+	changes := make([]*StatusChange, len(docs))
+	for i, doc := range docs {
+		changes[i] = &StatusChange{
+			Status: doc.Status,
+			Info:   doc.StatusInfo,
+			Time:   doc.Time,
+		}
+	}
+	return changes, nil
+}
+
+// StatusHistory returns the recorded changes to the unit's status,
+// oldest first. Only the most recent changes are kept.
+func (u *Unit) StatusHistory() ([]*StatusChange, error) {
+	return statusHistory(u.st, u.globalKey())
+}
+
+// StatusHistory returns the recorded changes to the machine's status,
+// oldest first. Only the most recent changes are kept.
+func (m *Machine) StatusHistory() ([]*StatusChange, error) {
+	return statusHistory(m.st, m.globalKey())
+}
@@ -0,0 +1,132 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type StatusHistorySuite struct {
+	ConnSuite
+	unit    *state.Unit
+	machine *state.Machine
+}
+
+var _ = Suite(&StatusHistorySuite{})
+
+func (s *StatusHistorySuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	svc, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+	s.machine, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+}
+
+func (s *StatusHistorySuite) TestUnitStatusHistory(c *C) {
+	history, err := s.unit.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+
+	start := time.Now()
+	err = s.unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	err = s.unit.SetStatus(params.StatusError, "hook failed")
+	c.Assert(err, IsNil)
+	err = s.unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	end := time.Now()
+
+	history, err = s.unit.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	for i, change := range history {
+		c.Assert(change.Time, checkers.TimeBetween(start, end))
+		if i > 0 {
+			c.Assert(change.Time.Before(history[i-1].Time), Equals, false)
+		}
+	}
+	c.Assert(history[0].Status, Equals, params.StatusStarted)
+	c.Assert(history[1].Status, Equals, params.StatusError)
+	c.Assert(history[1].Info, Equals, "hook failed")
+	c.Assert(history[2].Status, Equals, params.StatusStarted)
+	c.Assert(history[2].Info, Equals, "")
+
+	// The history of other entities is not affected.
+	history, err = s.machine.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+}
+
+func (s *StatusHistorySuite) TestMachineStatusHistory(c *C) {
+	err := s.machine.SetStatus(params.StatusError, "cannot start instance")
+	c.Assert(err, IsNil)
+	err = s.machine.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+
+	history, err := s.machine.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Assert(history[0].Status, Equals, params.StatusError)
+	c.Assert(history[0].Info, Equals, "cannot start instance")
+	c.Assert(history[1].Status, Equals, params.StatusStarted)
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryPruned(c *C) {
+	defer state.SetMaxStatusHistory(3)()
+	for _, info := range []string{"a", "b", "c", "d", "e"} {
+		err := s.unit.SetStatus(params.StatusError, info)
+		c.Assert(err, IsNil)
+	}
+	history, err := s.unit.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	for i, info := range []string{"c", "d", "e"} {
+		c.Assert(history[i].Info, Equals, info)
+	}
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryRemoved(c *C) {
+	err := s.unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	err = s.machine.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	history, err := s.unit.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+
+	err = s.machine.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.machine.Remove()
+	c.Assert(err, IsNil)
+	history, err = s.machine.StatusHistory()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 0)
+}
+
+func (s *StatusHistorySuite) TestStatusHistorian(c *C) {
+	for _, tag := range []string{s.unit.Tag(), s.machine.Tag()} {
+		h, err := s.State.StatusHistorian(tag)
+		c.Assert(err, IsNil)
+		history, err := h.StatusHistory()
+		c.Assert(err, IsNil)
+		c.Assert(history, HasLen, 0)
+	}
+	_, err := s.State.StatusHistorian("service-wordpress")
+	c.Assert(err, ErrorMatches, `entity "service-wordpress" does not have a status history`)
+	_, err = s.State.StatusHistorian("unit-wordpress-9")
+	c.Assert(err, ErrorMatches, `unit "wordpress/9" not found`)
+}
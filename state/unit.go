@@ -77,8 +77,10 @@ type unitDoc struct {
 	PrivateAddress string
 	MachineId      string
 	Resolved       ResolvedMode
+	Paused         bool
+	Idle           bool
 	Tools          *tools.Tools `bson:",omitempty"`
-	Ports          []instance.Port
+	Ports          []instance.PortRange
 	Life           Life
 	TxnRevno       int64 `bson:"txn-revno"`
 	PasswordHash   string
@@ -162,6 +164,16 @@ func (u *Unit) globalKey() string {
 	return unitGlobalKey(u.doc.Name)
 }
 
+// workloadKeySuffix is appended to the global key of a unit to make
+// the key of the status of the unit's workload.
+const workloadKeySuffix = "#workload"
+
+// unitWorkloadGlobalKey returns the global database key for the
+// status of the named unit's workload.
+func unitWorkloadGlobalKey(name string) string {
+	return unitGlobalKey(name) + workloadKeySuffix
+}
+
 // Life returns whether the unit is Alive, Dying or Dead.
 func (u *Unit) Life() Life {
 	return u.doc.Life
@@ -396,7 +408,9 @@ func (u *Unit) Remove() (err error) {
 		case errAlreadyRemoved:
 			return nil
 		case nil:
-			if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			if err := u.st.runTransaction(ops); err == nil {
+				return removeStatusHistory(u.st, u.globalKey())
+			} else if err != txn.ErrAborted {
 				return err
 			}
 		default:
@@ -474,7 +488,9 @@ func (u *Unit) Status() (status params.Status, info string, err error) {
 	return
 }
 
-// SetStatus sets the status of the unit.
+// SetStatus sets the status of the unit, and records the change in
+// its status history. Failing to record the change is logged, but
+// does not cause SetStatus to fail.
 func (u *Unit) SetStatus(status params.Status, info string) error {
 	if status == params.StatusError && info == "" {
 		panic("unit error status with no info")
@@ -494,62 +510,225 @@ func (u *Unit) SetStatus(status params.Status, info string) error {
 	if err != nil {
 		return fmt.Errorf("cannot set status of unit %q: %v", u, onAbort(err, errDead))
 	}
+	recordStatusChange(u.st, u.globalKey(), doc)
 	return nil
 }
 
-// OpenPort sets the policy of the port with protocol and number to be opened.
-func (u *Unit) OpenPort(protocol string, number int) (err error) {
-	port := instance.Port{Protocol: protocol, Number: number}
-	defer utils.ErrorContextf(&err, "cannot open port %v for unit %q", port, u)
-	ops := []txn.Op{{
-		C:      u.st.units.Name,
-		Id:     u.doc.Name,
-		Assert: notDeadDoc,
-		Update: D{{"$addToSet", D{{"ports", port}}}},
-	}}
-	err = u.st.runTransaction(ops)
+// getWorkloadStatus returns the status of the named unit's workload.
+// Units added before workload statuses were recorded have no status
+// document, and their workload status is unknown.
+func getWorkloadStatus(st *State, name string) (statusDoc, error) {
+	doc, err := getStatus(st, unitWorkloadGlobalKey(name))
+	if errors.IsNotFoundError(err) {
+		return statusDoc{Status: params.StatusUnknown}, nil
+	}
+	return doc, err
+}
+
+// WorkloadStatus returns the status of the unit's workload, as set by
+// its charm.
+func (u *Unit) WorkloadStatus() (status params.Status, info string, err error) {
+	doc, err := getWorkloadStatus(u.st, u.doc.Name)
 	if err != nil {
-		return onAbort(err, errDead)
+		return "", "", err
 	}
-	found := false
-	for _, p := range u.doc.Ports {
-		if p == port {
-			break
-		}
+	return doc.Status, doc.StatusInfo, nil
+}
+
+// SetWorkloadStatus sets the status of the unit's workload. Only the
+// maintenance, blocked, waiting and active statuses may be set.
+func (u *Unit) SetWorkloadStatus(status params.Status, info string) error {
+	switch status {
+	case params.StatusMaintenance, params.StatusBlocked, params.StatusWaiting, params.StatusActive:
+	default:
+		return fmt.Errorf("cannot set workload status of unit %q: invalid status %q", u, status)
 	}
-	if !found {
-		u.doc.Ports = append(u.doc.Ports, port)
+	doc := statusDoc{
+		Status:     status,
+		StatusInfo: info,
 	}
-	return nil
+	key := unitWorkloadGlobalKey(u.doc.Name)
+	for i := 0; i < 5; i++ {
+		statusOp := updateStatusOp(u.st, key, doc)
+		if _, err := getStatus(u.st, key); errors.IsNotFoundError(err) {
+			statusOp = createStatusOp(u.st, key, doc)
+		} else if err != nil {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		},
+			statusOp,
+		}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			if err != nil {
+				return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+			}
+			return nil
+		}
+		if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+			return err
+		} else if !notDead {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, errDead)
+		}
+	}
+	return fmt.Errorf("cannot set workload status of unit %q: %v", u, ErrExcessiveContention)
+}
+
+// OpenPort sets the policy of the port with protocol and number to be opened.
+func (u *Unit) OpenPort(protocol string, number int) error {
+	return u.OpenPorts(protocol, number, number)
 }
 
 // ClosePort sets the policy of the port with protocol and number to be closed.
-func (u *Unit) ClosePort(protocol string, number int) (err error) {
-	port := instance.Port{Protocol: protocol, Number: number}
-	defer utils.ErrorContextf(&err, "cannot close port %v for unit %q", port, u)
-	ops := []txn.Op{{
-		C:      u.st.units.Name,
-		Id:     u.doc.Name,
-		Assert: notDeadDoc,
-		Update: D{{"$pull", D{{"ports", port}}}},
-	}}
-	err = u.st.runTransaction(ops)
-	if err != nil {
-		return onAbort(err, errDead)
+func (u *Unit) ClosePort(protocol string, number int) error {
+	return u.ClosePorts(protocol, number, number)
+}
+
+// OpenPorts sets the policy of the range of ports with protocol to be
+// opened. The range must not overlap any range opened by the unit or
+// by another unit on the same machine, unless it is the same range
+// opened by the unit; ICMP may be opened by any number of units.
+func (u *Unit) OpenPorts(protocol string, fromPort, toPort int) (err error) {
+	ports := instance.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	defer utils.ErrorContextf(&err, "cannot open ports %v for unit %q", ports, u)
+	if err := ports.Validate(); err != nil {
+		return err
 	}
-	newPorts := make([]instance.Port, 0, len(u.doc.Ports))
-	for _, p := range u.doc.Ports {
-		if p != port {
+	for i := 0; i < 5; i++ {
+		var doc unitDoc
+		err := u.st.units.FindId(u.doc.Name).One(&doc)
+		if err == mgo.ErrNotFound || err == nil && doc.Life == Dead {
+			return errDead
+		} else if err != nil {
+			return err
+		}
+		for _, p := range doc.Ports {
+			if p == ports {
+				u.doc.Ports = doc.Ports
+				return nil
+			}
+			if p.ConflictsWith(ports) {
+				return fmt.Errorf("ports %v are already open", p)
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: append(D{{"txn-revno", doc.TxnRevno}}, notDeadDoc...),
+			Update: D{{"$addToSet", D{{"ports", ports}}}},
+		}}
+		others, err := u.colocatedUnits()
+		if err != nil {
+			return err
+		}
+		for _, other := range others {
+			for _, p := range other.doc.Ports {
+				if p.ConflictsWith(ports) {
+					return fmt.Errorf("ports %v are already open for unit %q", p, other)
+				}
+			}
+			// Ensure that the other unit does not open conflicting
+			// ports in the meantime.
+			ops = append(ops, txn.Op{
+				C:      u.st.units.Name,
+				Id:     other.doc.Name,
+				Assert: D{{"txn-revno", other.doc.TxnRevno}},
+			})
+		}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			if err == nil {
+				u.doc.Ports = append(doc.Ports, ports)
+			}
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// ClosePorts sets the policy of the range of ports with protocol to be
+// closed. The range must be one that was opened by OpenPorts, or else
+// not overlap any open range.
+func (u *Unit) ClosePorts(protocol string, fromPort, toPort int) (err error) {
+	ports := instance.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	defer utils.ErrorContextf(&err, "cannot close ports %v for unit %q", ports, u)
+	if err := ports.Validate(); err != nil {
+		return err
+	}
+	for i := 0; i < 5; i++ {
+		var doc unitDoc
+		err := u.st.units.FindId(u.doc.Name).One(&doc)
+		if err == mgo.ErrNotFound || err == nil && doc.Life == Dead {
+			return errDead
+		} else if err != nil {
+			return err
+		}
+		found := false
+		newPorts := make([]instance.PortRange, 0, len(doc.Ports))
+		for _, p := range doc.Ports {
+			if p == ports {
+				found = true
+				continue
+			}
+			if p.ConflictsWith(ports) {
+				return fmt.Errorf("ports %v are open", p)
+			}
 			newPorts = append(newPorts, p)
 		}
+		if !found {
+			u.doc.Ports = doc.Ports
+			return nil
+		}
+		// The whole list is rewritten, rather than the range pulled,
+		// so that ports stored in the form used before ranges were
+		// supported are removed too.
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: append(D{{"txn-revno", doc.TxnRevno}}, notDeadDoc...),
+			Update: D{{"$set", D{{"ports", newPorts}}}},
+		}}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			if err == nil {
+				u.doc.Ports = newPorts
+			}
+			return err
+		}
 	}
-	u.doc.Ports = newPorts
-	return nil
+	return ErrExcessiveContention
 }
 
-// OpenedPorts returns a slice containing the open ports of the unit.
-func (u *Unit) OpenedPorts() []instance.Port {
-	ports := append([]instance.Port{}, u.doc.Ports...)
+// colocatedUnits returns the other units assigned to the same machine
+// as the unit.
+func (u *Unit) colocatedUnits() ([]*Unit, error) {
+	id, err := u.AssignedMachineId()
+	if IsNotAssigned(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	m, err := u.st.Machine(id)
+	if err != nil {
+		return nil, err
+	}
+	units, err := m.Units()
+	if err != nil {
+		return nil, err
+	}
+	var others []*Unit
+	for _, unit := range units {
+		if unit.doc.Name != u.doc.Name {
+			others = append(others, unit)
+		}
+	}
+	return others, nil
+}
+
+// OpenedPorts returns a slice containing the open port ranges of the unit.
+func (u *Unit) OpenedPorts() []instance.PortRange {
+	ports := append([]instance.PortRange{}, u.doc.Ports...)
 	SortPorts(ports)
 	return ports
 }
@@ -874,6 +1053,7 @@ func (u *Unit) assignToNewMachine(params *AddMachineParams, cons constraints.Val
 	//  * the unit is no longer alive
 	//  * the unit has been assigned to a different machine
 	//  * the parent machine we want to create a container on was clean but became dirty
+	//  * the parent machine is no longer alive
 	unit, err := u.st.Unit(u.Name())
 	if err != nil {
 		return err
@@ -889,6 +1069,9 @@ func (u *Unit) assignToNewMachine(params *AddMachineParams, cons constraints.Val
 		if err != nil {
 			return err
 		}
+		if m.Life() != Alive {
+			return machineNotAliveErr
+		}
 		if !m.Clean() {
 			return machineNotCleanErr
 		}
@@ -958,9 +1141,9 @@ func (u *Unit) AssignToNewMachineOrContainer() (err error) {
 		Jobs:          []MachineJob{JobHostUnits},
 	}
 	err = u.assignToNewMachine(params, *cons)
-	if err == machineNotCleanErr {
-		// The clean machine was used before we got a chance to use it so just
-		// stick the unit on a new machine.
+	if err == machineNotCleanErr || err == machineNotAliveErr {
+		// The clean machine was used or destroyed before we got a chance
+		// to use it so just stick the unit on a new machine.
 		return u.AssignToNewMachine()
 	}
 	return err
@@ -1031,12 +1214,39 @@ var hasNoContainersTerm = bson.DocElem{
 // findCleanMachineQuery returns a Mongo query to find clean (and possibly empty) machines with
 // characteristics matching the specified constraints.
 func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value) (*mgo.Query, error) {
+	return u.st.findCleanMachineQuery(u.doc.Series, requireEmpty, cons)
+}
+
+// CleanEmptyMachines returns the clean, empty machines that a new unit
+// of the given series and with the given constraints could be assigned
+// to under the AssignCleanEmpty policy, in the order in which they
+// would be tried. No unit is assigned.
+func (st *State) CleanEmptyMachines(series string, cons constraints.Value) ([]*Machine, error) {
+	query, err := st.findCleanMachineQuery(series, true, &cons)
+	if err != nil {
+		return nil, err
+	}
+	var docs []machineDoc
+	if err := query.All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get clean machines: %v", err)
+	}
+	machines := make([]*Machine, len(docs))
+	for i := range docs {
+		machines[i] = newMachine(st, &docs[i])
+	}
+	return machines, nil
+}
+
+// findCleanMachineQuery returns a Mongo query to find clean (and possibly
+// empty) machines of the given series with characteristics matching the
+// specified constraints.
+func (st *State) findCleanMachineQuery(series string, requireEmpty bool, cons *constraints.Value) (*mgo.Query, error) {
 	// Select all machines that can accept principal units and are clean.
 	var containerRefs []machineContainers
 	// If we need empty machines, first build up a list of machine ids which have containers
 	// so we can exclude those.
 	if requireEmpty {
-		err := u.st.containerRefs.Find(D{hasContainerTerm}).Select(bson.M{"_id": 1}).All(&containerRefs)
+		err := st.containerRefs.Find(D{hasContainerTerm}).Select(bson.M{"_id": 1}).All(&containerRefs)
 		if err != nil {
 			return nil, err
 		}
@@ -1047,7 +1257,7 @@ func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value)
 	}
 	terms := D{
 		{"life", Alive},
-		{"series", u.doc.Series},
+		{"series", series},
 		{"jobs", []MachineJob{JobHostUnits}},
 		{"clean", true},
 		{"_id", D{{"$nin", machinesWithContainers}}},
@@ -1083,7 +1293,7 @@ func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value)
 		suitableTerms = append(suitableTerms, bson.DocElem{"cpupower", D{{"$gte", *cons.CpuPower}}})
 	}
 	if len(suitableTerms) > 0 {
-		err := u.st.instanceData.Find(suitableTerms).Select(bson.M{"_id": 1}).All(&suitableInstanceData)
+		err := st.instanceData.Find(suitableTerms).Select(bson.M{"_id": 1}).All(&suitableInstanceData)
 		if err != nil {
 			return nil, err
 		}
@@ -1093,7 +1303,7 @@ func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value)
 		}
 		terms = append(terms, bson.DocElem{"_id", D{{"$in", suitableIds}}})
 	}
-	return u.st.machines.Find(terms), nil
+	return st.machines.Find(terms), nil
 }
 
 // assignToCleanMaybeEmptyMachine implements AssignToCleanMachine and AssignToCleanEmptyMachine.
@@ -1261,6 +1471,61 @@ func (u *Unit) SetResolved(mode ResolvedMode) (err error) {
 	return fmt.Errorf("already resolved")
 }
 
+// IsPaused returns whether the unit's hooks are paused. While a unit
+// is paused, its agent queues the hooks that respond to changes in the
+// service's configuration, relations and charm, but does not run them
+// until the unit is resumed. See SetPaused and ClearPaused.
+func (u *Unit) IsPaused() bool {
+	return u.doc.Paused
+}
+
+// SetPaused pauses the unit's hooks.
+// See ClearPaused and IsPaused.
+func (u *Unit) SetPaused() error {
+	return u.setPaused(true)
+}
+
+// ClearPaused resumes the unit's hooks.
+// See SetPaused and IsPaused.
+func (u *Unit) ClearPaused() error {
+	return u.setPaused(false)
+}
+
+func (u *Unit) setPaused(paused bool) error {
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: notDeadDoc,
+		Update: D{{"$set", D{{"paused", paused}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot set paused flag for unit %q to %v: %v", u, paused, onAbort(err, errDead))
+	}
+	u.doc.Paused = paused
+	return nil
+}
+
+// IsIdle returns whether the unit's agent has reported that it has no
+// hooks waiting to run. See SetIdle.
+func (u *Unit) IsIdle() bool {
+	return u.doc.Idle
+}
+
+// SetIdle records whether the unit's agent has no hooks waiting to run.
+func (u *Unit) SetIdle(idle bool) error {
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: notDeadDoc,
+		Update: D{{"$set", D{{"idle", idle}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot set idle flag for unit %q to %v: %v", u, idle, onAbort(err, errDead))
+	}
+	u.doc.Idle = idle
+	return nil
+}
+
 // ClearResolved removes any resolved setting on the unit.
 func (u *Unit) ClearResolved() error {
 	ops := []txn.Op{{
@@ -1277,7 +1542,7 @@ func (u *Unit) ClearResolved() error {
 	return nil
 }
 
-type portSlice []instance.Port
+type portSlice []instance.PortRange
 
 func (p portSlice) Len() int      { return len(p) }
 func (p portSlice) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
@@ -1287,11 +1552,14 @@ func (p portSlice) Less(i, j int) bool {
 	if p1.Protocol != p2.Protocol {
 		return p1.Protocol < p2.Protocol
 	}
-	return p1.Number < p2.Number
+	if p1.FromPort != p2.FromPort {
+		return p1.FromPort < p2.FromPort
+	}
+	return p1.ToPort < p2.ToPort
 }
 
-// SortPorts sorts the given ports, first by protocol,
-// then by number.
-func SortPorts(ports []instance.Port) {
+// SortPorts sorts the given port ranges, first by protocol,
+// then by their first and last port numbers.
+func SortPorts(ports []instance.PortRange) {
 	sort.Sort(portSlice(ports))
 }
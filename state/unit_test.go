@@ -270,6 +270,42 @@ func (s *UnitSuite) TestGetSetStatusWhileNotAlive(c *C) {
 	c.Assert(err, ErrorMatches, "status not found")
 }
 
+func (s *UnitSuite) TestGetSetWorkloadStatus(c *C) {
+	status, info, err := s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusUnknown)
+	c.Assert(info, Equals, "")
+
+	err = s.unit.SetWorkloadStatus(params.StatusBlocked, "need a database")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusBlocked)
+	c.Assert(info, Equals, "need a database")
+
+	err = s.unit.SetWorkloadStatus(params.StatusActive, "")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusActive)
+	c.Assert(info, Equals, "")
+
+	// The agent status is unaffected.
+	status, _, err = s.unit.Status()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusPending)
+
+	for _, status := range []params.Status{params.StatusUnknown, params.StatusStarted, "bogus"} {
+		err = s.unit.SetWorkloadStatus(status, "")
+		c.Assert(err, ErrorMatches, `cannot set workload status of unit "wordpress/0": invalid status "`+string(status)+`"`)
+	}
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.SetWorkloadStatus(params.StatusActive, "")
+	c.Assert(err, ErrorMatches, `cannot set workload status of unit "wordpress/0": not found or dead`)
+}
+
 func (s *UnitSuite) TestUnitCharm(c *C) {
 	preventUnitDestroyRemove(c, s.unit)
 	curl, ok := s.unit.CharmURL()
@@ -728,6 +764,52 @@ func (s *UnitSuite) TestGetSetClearResolved(c *C) {
 	c.Assert(err, ErrorMatches, `cannot set resolved mode for unit "wordpress/0": invalid error resolution mode: "foo"`)
 }
 
+func (s *UnitSuite) TestSetClearPaused(c *C) {
+	c.Assert(s.unit.IsPaused(), Equals, false)
+
+	err := s.unit.SetPaused()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsPaused(), Equals, true)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsPaused(), Equals, true)
+
+	err = s.unit.ClearPaused()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsPaused(), Equals, false)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsPaused(), Equals, false)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.SetPaused()
+	c.Assert(err, ErrorMatches, `cannot set paused flag for unit "wordpress/0" to true: not found or dead`)
+}
+
+func (s *UnitSuite) TestSetIdle(c *C) {
+	c.Assert(s.unit.IsIdle(), Equals, false)
+
+	err := s.unit.SetIdle(true)
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsIdle(), Equals, true)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsIdle(), Equals, true)
+
+	err = s.unit.SetIdle(false)
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsIdle(), Equals, false)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.IsIdle(), Equals, false)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.SetIdle(true)
+	c.Assert(err, ErrorMatches, `cannot set idle flag for unit "wordpress/0" to true: not found or dead`)
+}
+
 func (s *UnitSuite) TestOpenedPorts(c *C) {
 	// Verify no open ports before activity.
 	c.Assert(s.unit.OpenedPorts(), HasLen, 0)
@@ -736,53 +818,168 @@ func (s *UnitSuite) TestOpenedPorts(c *C) {
 	err := s.unit.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 	open := s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 80},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 80, 80},
 	})
 
 	err = s.unit.OpenPort("udp", 53)
 	c.Assert(err, IsNil)
 	open = s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 80},
-		{"udp", 53},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 80, 80},
+		{"udp", 53, 53},
 	})
 
 	err = s.unit.OpenPort("tcp", 53)
 	c.Assert(err, IsNil)
 	open = s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 53},
-		{"tcp", 80},
-		{"udp", 53},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 53, 53},
+		{"tcp", 80, 80},
+		{"udp", 53, 53},
 	})
 
 	err = s.unit.OpenPort("tcp", 443)
 	c.Assert(err, IsNil)
 	open = s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 53},
-		{"tcp", 80},
-		{"tcp", 443},
-		{"udp", 53},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 53, 53},
+		{"tcp", 80, 80},
+		{"tcp", 443, 443},
+		{"udp", 53, 53},
 	})
 
 	err = s.unit.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
 	open = s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 53},
-		{"tcp", 443},
-		{"udp", 53},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 53, 53},
+		{"tcp", 443, 443},
+		{"udp", 53, 53},
 	})
 
 	err = s.unit.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
 	open = s.unit.OpenedPorts()
-	c.Assert(open, DeepEquals, []instance.Port{
-		{"tcp", 53},
-		{"tcp", 443},
-		{"udp", 53},
+	c.Assert(open, DeepEquals, []instance.PortRange{
+		{"tcp", 53, 53},
+		{"tcp", 443, 443},
+		{"udp", 53, 53},
+	})
+}
+
+func (s *UnitSuite) TestOpenedPortRanges(c *C) {
+	err := s.unit.OpenPorts("udp", 10000, 20000)
+	c.Assert(err, IsNil)
+	err = s.unit.OpenPorts("icmp", -1, -1)
+	c.Assert(err, IsNil)
+	err = s.unit.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.OpenedPorts(), DeepEquals, []instance.PortRange{
+		{"icmp", -1, -1},
+		{"tcp", 80, 80},
+		{"udp", 10000, 20000},
+	})
+
+	// Opening the same range again does nothing.
+	err = s.unit.OpenPorts("udp", 10000, 20000)
+	c.Assert(err, IsNil)
+
+	// Overlapping ranges cannot be opened or closed.
+	err = s.unit.OpenPorts("udp", 15000, 25000)
+	c.Assert(err, ErrorMatches, `cannot open ports 15000-25000/udp for unit "wordpress/0": ports 10000-20000/udp are already open`)
+	err = s.unit.ClosePort("udp", 15000)
+	c.Assert(err, ErrorMatches, `cannot close ports 15000/udp for unit "wordpress/0": ports 10000-20000/udp are open`)
+
+	// Invalid ranges are rejected.
+	err = s.unit.OpenPorts("tcp", 90, 80)
+	c.Assert(err, ErrorMatches, `cannot open ports 90-80/tcp for unit "wordpress/0": invalid port range 90-80/tcp`)
+	err = s.unit.OpenPorts("icmp", 8, 8)
+	c.Assert(err, ErrorMatches, `cannot open ports icmp for unit "wordpress/0": icmp does not have ports`)
+
+	err = s.unit.ClosePorts("udp", 10000, 20000)
+	c.Assert(err, IsNil)
+	err = s.unit.ClosePorts("icmp", -1, -1)
+	c.Assert(err, IsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.OpenedPorts(), DeepEquals, []instance.PortRange{
+		{"tcp", 80, 80},
+	})
+}
+
+func (s *UnitSuite) TestOpenPortsConflictsOnMachine(c *C) {
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.unit.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+	other, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	err = other.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+	elsewhere, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+
+	err = s.unit.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, IsNil)
+	err = s.unit.OpenPorts("icmp", -1, -1)
+	c.Assert(err, IsNil)
+
+	// Another unit on the machine cannot open overlapping ports...
+	err = other.OpenPort("tcp", 8080)
+	c.Assert(err, ErrorMatches, `cannot open ports 8080/tcp for unit "wordpress/1": ports 8000-8080/tcp are already open for unit "wordpress/0"`)
+	err = other.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, ErrorMatches, `cannot open ports 8000-8080/tcp for unit "wordpress/1": ports 8000-8080/tcp are already open for unit "wordpress/0"`)
+
+	// ...but it can open others, and ICMP.
+	err = other.OpenPorts("tcp", 8081, 8090)
+	c.Assert(err, IsNil)
+	err = other.OpenPorts("udp", 8080, 8080)
+	c.Assert(err, IsNil)
+	err = other.OpenPorts("icmp", -1, -1)
+	c.Assert(err, IsNil)
+
+	// A unit on another machine is not affected.
+	err = elsewhere.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, IsNil)
+
+	// Once closed, the ports can be opened by another unit.
+	err = s.unit.ClosePorts("tcp", 8000, 8080)
+	c.Assert(err, IsNil)
+	err = other.OpenPort("tcp", 8080)
+	c.Assert(err, IsNil)
+}
+
+func (s *UnitSuite) TestOpenedPortsLegacyNumber(c *C) {
+	// Ports opened before port ranges were supported were stored
+	// with a single number.
+	err := s.units.Update(
+		D{{"_id", s.unit.Name()}},
+		D{{"$set", D{{"ports", []D{
+			{{"protocol", "tcp"}, {"number", 80}},
+			{{"protocol", "udp"}, {"number", 53}},
+		}}}}},
+	)
+	c.Assert(err, IsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.OpenedPorts(), DeepEquals, []instance.PortRange{
+		{"tcp", 80, 80},
+		{"udp", 53, 53},
+	})
+
+	// The legacy ports conflict with ranges as usual, and can be closed.
+	err = s.unit.OpenPorts("tcp", 70, 90)
+	c.Assert(err, ErrorMatches, `cannot open ports 70-90/tcp for unit "wordpress/0": ports 80/tcp are already open`)
+	err = s.unit.ClosePort("tcp", 80)
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.OpenedPorts(), DeepEquals, []instance.PortRange{
+		{"udp", 53, 53},
+	})
+	err = s.unit.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.unit.OpenedPorts(), DeepEquals, []instance.PortRange{
+		{"udp", 53, 53},
 	})
 }
 
@@ -16,16 +16,47 @@ import (
 
 var validUser = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9]*$")
 
-// AddUser adds a user to the state.
+// UserRole determines what a user is permitted to do in the
+// environment.
+type UserRole string
+
+const (
+	// AdminRole permits a user to make any change to the environment.
+	AdminRole UserRole = "admin"
+
+	// ReadOnlyRole permits a user to inspect the environment, but
+	// not to change it.
+	ReadOnlyRole UserRole = "read-only"
+)
+
+// IsValid returns whether r is a known user role.
+func (r UserRole) IsValid() bool {
+	return r == AdminRole || r == ReadOnlyRole
+}
+
+// AdminUser is the name of the user created when the environment is
+// bootstrapped. It cannot be removed.
+const AdminUser = "admin"
+
+// AddUser adds a user with the admin role to the state.
 func (st *State) AddUser(name, password string) (*User, error) {
+	return st.AddUserWithRole(name, password, AdminRole)
+}
+
+// AddUserWithRole adds a user with the given role to the state.
+func (st *State) AddUserWithRole(name, password string, role UserRole) (*User, error) {
 	if !validUser.MatchString(name) {
 		return nil, fmt.Errorf("invalid user name %q", name)
 	}
+	if !role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q for user %q", role, name)
+	}
 	u := &User{
 		st: st,
 		doc: userDoc{
 			Name:         name,
 			PasswordHash: utils.PasswordHash(password),
+			Role:         role,
 		},
 	}
 	ops := []txn.Op{{
@@ -72,6 +103,7 @@ type User struct {
 type userDoc struct {
 	Name         string `bson:"_id_"`
 	PasswordHash string
+	Role         UserRole
 }
 
 // Name returns the user name,
@@ -79,10 +111,45 @@ func (u *User) Name() string {
 	return u.doc.Name
 }
 
+// UserTag returns the tag for the
+// user with the given name.
+func UserTag(userName string) string {
+	return "user-" + userName
+}
+
 // Tag returns the Tag for
 // the user ("user-$username")
 func (u *User) Tag() string {
-	return "user-" + u.doc.Name
+	return UserTag(u.doc.Name)
+}
+
+// Role returns the user's role. Users created before roles were
+// introduced have the admin role.
+func (u *User) Role() UserRole {
+	if u.doc.Role == "" {
+		return AdminRole
+	}
+	return u.doc.Role
+}
+
+// Remove removes the user from the state. The user created when
+// the environment was bootstrapped cannot be removed.
+func (u *User) Remove() error {
+	if u.Name() == AdminUser {
+		return fmt.Errorf("cannot remove user %q: the %s user cannot be removed", u.Name(), AdminUser)
+	}
+	ops := []txn.Op{{
+		C:      u.st.users.Name,
+		Id:     u.Name(),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	if err := u.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("user %q", u.Name())
+	} else if err != nil {
+		return fmt.Errorf("cannot remove user %q: %v", u.Name(), err)
+	}
+	return nil
 }
 
 // SetPassword sets the password associated with the user.
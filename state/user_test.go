@@ -6,6 +6,7 @@ package state_test
 import (
 	. "launchpad.net/gocheck"
 
+	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/utils"
 )
@@ -44,6 +45,51 @@ func (s *UserSuite) TestAddUser(c *C) {
 	c.Assert(u1.PasswordValid("b"), Equals, true)
 }
 
+func (s *UserSuite) TestAddUserRole(c *C) {
+	u, err := s.State.AddUser("a", "b")
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.AdminRole)
+
+	u, err = s.State.AddUserWithRole("ro", "b", state.ReadOnlyRole)
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.ReadOnlyRole)
+
+	u, err = s.State.User("ro")
+	c.Assert(err, IsNil)
+	c.Assert(u.Role(), Equals, state.ReadOnlyRole)
+
+	u, err = s.State.AddUserWithRole("bad", "b", "superuser")
+	c.Assert(err, ErrorMatches, `invalid role "superuser" for user "bad"`)
+	c.Assert(u, IsNil)
+}
+
+func (s *UserSuite) TestAddUserDuplicate(c *C) {
+	_, err := s.State.AddUser("a", "b")
+	c.Assert(err, IsNil)
+	_, err = s.State.AddUserWithRole("a", "c", state.ReadOnlyRole)
+	c.Assert(err, ErrorMatches, "user already exists")
+}
+
+func (s *UserSuite) TestRemove(c *C) {
+	u, err := s.State.AddUser("someuser", "")
+	c.Assert(err, IsNil)
+	err = u.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.User("someuser")
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+
+	err = u.Remove()
+	c.Assert(err, ErrorMatches, `user "someuser" not found`)
+	c.Assert(errors.IsNotFoundError(err), Equals, true)
+}
+
+func (s *UserSuite) TestRemoveAdmin(c *C) {
+	u, err := s.State.AddUser(state.AdminUser, "")
+	c.Assert(err, IsNil)
+	err = u.Remove()
+	c.Assert(err, ErrorMatches, `cannot remove user "admin": the admin user cannot be removed`)
+}
+
 func (s *UserSuite) TestSetPassword(c *C) {
 	u, err := s.State.AddUser("someuser", "")
 	c.Assert(err, IsNil)
@@ -1281,3 +1281,211 @@ func (w *cleanupWatcher) loop() (err error) {
 	}
 	panic("unreachable")
 }
+
+// runCommandsWatcher notifies of run commands queued for a single unit
+// or machine. The first event emitted contains the ids of all pending
+// run commands for the receiver; subsequent events contain the ids of
+// newly enqueued run commands. Every id is reported at most once.
+type runCommandsWatcher struct {
+	commonWatcher
+	receiver string
+	known    map[string]bool
+	out      chan []string
+}
+
+// WatchRunCommands returns a StringsWatcher that notifies of commands
+// queued for execution by the unit or machine with the given tag.
+func (st *State) WatchRunCommands(receiver string) StringsWatcher {
+	return newRunCommandsWatcher(st, receiver)
+}
+
+func newRunCommandsWatcher(st *State, receiver string) StringsWatcher {
+	w := &runCommandsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		receiver:      receiver,
+		known:         make(map[string]bool),
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *runCommandsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+func (w *runCommandsWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	commands, err := w.st.PendingRunCommands(w.receiver)
+	if err != nil {
+		return nil, err
+	}
+	for _, rc := range commands {
+		w.known[rc.Id()] = true
+		ids.Add(rc.Id())
+	}
+	return ids, nil
+}
+
+func (w *runCommandsWatcher) merge(ids *set.Strings, change watcher.Change) error {
+	id := change.Id.(string)
+	if change.Revno == -1 {
+		delete(w.known, id)
+		ids.Remove(id)
+		return nil
+	}
+	if w.known[id] {
+		return nil
+	}
+	doc := runCommandDoc{}
+	if err := w.st.runCommands.FindId(id).One(&doc); err == mgo.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if doc.Receiver != w.receiver || doc.Status != RunCommandPending {
+		return nil
+	}
+	w.known[id] = true
+	ids.Add(id)
+	return nil
+}
+
+func (w *runCommandsWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.runCommands.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.runCommands.Name, ch)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return watcher.MustErr(w.st.watcher)
+		case change := <-ch:
+			if err = w.merge(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.SortedValues():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	panic("unreachable")
+}
+
+// actionsWatcher notifies of actions queued for a single unit. The
+// first event emitted contains the ids of all pending actions for the
+// unit; subsequent events contain the ids of newly enqueued actions.
+// Every id is reported at most once.
+type actionsWatcher struct {
+	commonWatcher
+	unitName string
+	known    map[string]bool
+	out      chan []string
+}
+
+// WatchActions returns a StringsWatcher that notifies of actions
+// queued for execution by the named unit.
+func (st *State) WatchActions(unitName string) StringsWatcher {
+	return newActionsWatcher(st, unitName)
+}
+
+func newActionsWatcher(st *State, unitName string) StringsWatcher {
+	w := &actionsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		unitName:      unitName,
+		known:         make(map[string]bool),
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *actionsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+func (w *actionsWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	actions, err := w.st.PendingActions(w.unitName)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range actions {
+		w.known[a.Id()] = true
+		ids.Add(a.Id())
+	}
+	return ids, nil
+}
+
+func (w *actionsWatcher) merge(ids *set.Strings, change watcher.Change) error {
+	id := change.Id.(string)
+	if change.Revno == -1 {
+		delete(w.known, id)
+		ids.Remove(id)
+		return nil
+	}
+	if w.known[id] {
+		return nil
+	}
+	doc := actionDoc{}
+	if err := w.st.actions.FindId(id).One(&doc); err == mgo.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if doc.Unit != w.unitName || doc.Status != ActionPending {
+		return nil
+	}
+	w.known[id] = true
+	ids.Add(id)
+	return nil
+}
+
+func (w *actionsWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.actions.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.actions.Name, ch)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return watcher.MustErr(w.st.watcher)
+		case change := <-ch:
+			if err = w.merge(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.SortedValues():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	panic("unreachable")
+}
@@ -87,6 +87,10 @@ func (d *FakeCharmDir) Config() *charm.Config {
 	return &charm.Config{make(map[string]charm.Option)}
 }
 
+func (d *FakeCharmDir) Actions() *charm.Actions {
+	return charm.NewActions()
+}
+
 func (d *FakeCharmDir) SetRevision(revision int) {
 	d.revision = revision
 }
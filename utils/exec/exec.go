@@ -0,0 +1,109 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The exec package runs shell commands on behalf of agents, capturing
+// their output and enforcing an optional deadline.
+package exec
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrTimedOut is returned by RunCommands when the commands did not
+// complete before the requested timeout expired.
+var ErrTimedOut = errors.New("command timed out")
+
+// RunParams holds the information for a RunCommands call.
+type RunParams struct {
+	// Commands holds the shell script to execute.
+	Commands string
+
+	// WorkingDir is the directory in which the commands are run.
+	WorkingDir string
+
+	// Environment holds os.Environ-style variables for the commands.
+	// If it is nil, the environment of the calling process is used.
+	Environment []string
+
+	// Timeout, if non-zero, is the maximum time the commands may
+	// run before their whole process group is killed.
+	Timeout time.Duration
+}
+
+// ExecResponse contains the return code and output generated by
+// executing a set of commands.
+type ExecResponse struct {
+	Code   int
+	Stdout []byte
+	Stderr []byte
+}
+
+// RunCommands executes the commands in a new process group using
+// "/bin/bash -s", passing the commands through as stdin, and collects
+// stdout and stderr. If a non-zero return code is returned, this is
+// collected as the code for the response and this does not classify
+// as an error. If the commands time out, their process group is
+// killed and ErrTimedOut is returned along with any output gathered
+// so far.
+func RunCommands(run RunParams) (*ExecResponse, error) {
+	ps := exec.Command("/bin/bash", "-s")
+	ps.Env = run.Environment
+	ps.Dir = run.WorkingDir
+	ps.Stdin = strings.NewReader(run.Commands)
+	ps.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	var stdout, stderr bytes.Buffer
+	ps.Stdout = &stdout
+	ps.Stderr = &stderr
+
+	if err := ps.Start(); err != nil {
+		return nil, err
+	}
+	err := Wait(ps, run.Timeout)
+	result := &ExecResponse{
+		Stdout: stdout.Bytes(),
+		Stderr: stderr.Bytes(),
+	}
+	if err == ErrTimedOut {
+		result.Code = -1
+		return result, err
+	}
+	if ee, ok := err.(*exec.ExitError); ok && err != nil {
+		status := ee.ProcessState.Sys().(syscall.WaitStatus)
+		if status.Exited() {
+			// A non-zero return code isn't considered an error here.
+			result.Code = status.ExitStatus()
+			err = nil
+		}
+	}
+	return result, err
+}
+
+// Wait waits for the already-started command to exit. If timeout is
+// non-zero and expires first, the command's process group is killed,
+// the command is reaped, and ErrTimedOut is returned. The command must
+// have been started with Setpgid set for the kill to reach any
+// children it spawned.
+func Wait(ps *exec.Cmd, timeout time.Duration) error {
+	if timeout <= 0 {
+		return ps.Wait()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+	}
+	// A negative pid signals the whole process group.
+	syscall.Kill(-ps.Process.Pid, syscall.SIGKILL)
+	<-done
+	return ErrTimedOut
+}
@@ -0,0 +1,93 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec_test
+
+import (
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type execSuite struct {
+	coretesting.LoggingSuite
+}
+
+var _ = Suite(&execSuite{})
+
+func (*execSuite) TestRunCommands(c *C) {
+	newDir := c.MkDir()
+
+	for i, test := range []struct {
+		message     string
+		commands    string
+		workingDir  string
+		environment []string
+		stdout      string
+		stderr      string
+		code        int
+	}{{
+		message:  "test stdout capture",
+		commands: "echo testing stdout",
+		stdout:   "testing stdout\n",
+	}, {
+		message:  "test stderr capture",
+		commands: "echo testing stderr >&2",
+		stderr:   "testing stderr\n",
+	}, {
+		message:  "test return code",
+		commands: "exit 42",
+		code:     42,
+	}, {
+		message:    "test working dir",
+		commands:   "pwd",
+		workingDir: newDir,
+		stdout:     newDir + "\n",
+	}, {
+		message:     "test environment",
+		commands:    "echo $OMG_IT_WORKS",
+		environment: []string{"OMG_IT_WORKS=like magic"},
+		stdout:      "like magic\n",
+	}} {
+		c.Logf("%v: %s", i, test.message)
+
+		result, err := exec.RunCommands(exec.RunParams{
+			Commands:    test.commands,
+			WorkingDir:  test.workingDir,
+			Environment: test.environment,
+		})
+		c.Assert(err, IsNil)
+		c.Assert(string(result.Stdout), Equals, test.stdout)
+		c.Assert(string(result.Stderr), Equals, test.stderr)
+		c.Assert(result.Code, Equals, test.code)
+	}
+}
+
+func (*execSuite) TestRunCommandsTimeout(c *C) {
+	// The marker file is only written if the backgrounded child
+	// survives the timeout.
+	marker := filepath.Join(c.MkDir(), "marker")
+	start := time.Now()
+	result, err := exec.RunCommands(exec.RunParams{
+		Commands: "echo started\n(sleep 1; touch " + marker + ") &\nsleep 10",
+		Timeout:  100 * time.Millisecond,
+	})
+	c.Assert(err, Equals, exec.ErrTimedOut)
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+	c.Assert(result.Code, Equals, -1)
+	c.Assert(string(result.Stdout), Equals, "started\n")
+
+	time.Sleep(1500 * time.Millisecond)
+	_, err = os.Stat(marker)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
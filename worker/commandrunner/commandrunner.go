@@ -0,0 +1,132 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commandrunner
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"launchpad.net/loggo"
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/watcher"
+	"launchpad.net/juju-core/utils/exec"
+	"launchpad.net/juju-core/utils/fslock"
+)
+
+var logger = loggo.GetLogger("juju.worker.commandrunner")
+
+// CommandRunner executes the commands sent to a machine by "juju run".
+type CommandRunner struct {
+	tomb     tomb.Tomb
+	st       *state.State
+	tag      string
+	hookLock *fslock.Lock
+}
+
+// NewCommandRunner returns a CommandRunner that runs the commands queued
+// for the machine with the given tag. Commands are run while holding the
+// machine's hook execution lock, so they never run concurrently with the
+// hooks of any unit deployed to the machine.
+func NewCommandRunner(st *state.State, machineTag, dataDir string) *CommandRunner {
+	cr := &CommandRunner{st: st, tag: machineTag}
+	go func() {
+		defer cr.tomb.Done()
+		cr.tomb.Kill(cr.loop(dataDir))
+	}()
+	return cr
+}
+
+func (cr *CommandRunner) String() string {
+	return fmt.Sprintf("commandrunner")
+}
+
+func (cr *CommandRunner) Kill() {
+	cr.tomb.Kill(nil)
+}
+
+func (cr *CommandRunner) Stop() error {
+	cr.tomb.Kill(nil)
+	return cr.tomb.Wait()
+}
+
+func (cr *CommandRunner) Wait() error {
+	return cr.tomb.Wait()
+}
+
+// checkTomb allows hook lock acquisition to be abandoned when the
+// worker is stopped.
+func (cr *CommandRunner) checkTomb() error {
+	select {
+	case <-cr.tomb.Dying():
+		return tomb.ErrDying
+	default:
+	}
+	return nil
+}
+
+// run executes a single set of commands and records their result.
+func (cr *CommandRunner) run(rc *state.RunCommand) error {
+	message := fmt.Sprintf("%s: running commands", cr.tag)
+	if err := cr.hookLock.LockWithFunc(message, cr.checkTomb); err != nil {
+		return err
+	}
+	logger.Infof("running %s", rc)
+	response, err := exec.RunCommands(exec.RunParams{
+		Commands: rc.Commands(),
+		Timeout:  rc.Timeout(),
+	})
+	if err := cr.hookLock.Unlock(); err != nil {
+		return err
+	}
+	var result state.RunResult
+	if response != nil {
+		result.Code = response.Code
+		result.Stdout = response.Stdout
+		result.Stderr = response.Stderr
+	}
+	if err == exec.ErrTimedOut {
+		result.Error = "timed out"
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+	if err := rc.Complete(result); errors.IsNotFoundError(err) {
+		// Nobody is waiting for the result any more.
+		logger.Debugf("%s was removed before completion", rc)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *CommandRunner) loop(dataDir string) (err error) {
+	cr.hookLock, err = fslock.NewLock(filepath.Join(dataDir, "locks"), "uniter-hook-execution")
+	if err != nil {
+		return err
+	}
+	w := cr.st.WatchRunCommands(cr.tag)
+	defer watcher.Stop(w, &cr.tomb)
+	for {
+		select {
+		case <-cr.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+			pending, err := cr.st.PendingRunCommands(cr.tag)
+			if err != nil {
+				return err
+			}
+			for _, rc := range pending {
+				if err := cr.run(rc); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,114 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commandrunner_test
+
+import (
+	"path/filepath"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	statetesting "launchpad.net/juju-core/state/testing"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/fslock"
+	"launchpad.net/juju-core/worker"
+	"launchpad.net/juju-core/worker/commandrunner"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type commandRunnerSuite struct {
+	testing.JujuConnSuite
+	machine *state.Machine
+	dataDir string
+}
+
+var _ = gc.Suite(&commandRunnerSuite{})
+
+var _ worker.Worker = (*commandrunner.CommandRunner)(nil)
+
+func (s *commandRunnerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	s.dataDir = c.MkDir()
+}
+
+func (s *commandRunnerSuite) waitResult(c *gc.C, rc *state.RunCommand) state.RunResult {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			err := rc.Refresh()
+			c.Assert(err, gc.IsNil)
+			if result, done := rc.Result(); done {
+				return result
+			}
+		case <-timeout:
+			c.Fatalf("commands never completed")
+		}
+	}
+	panic("unreachable")
+}
+
+func (s *commandRunnerSuite) TestRunsCommands(c *gc.C) {
+	// Commands queued before the worker starts are run too.
+	rc0, err := s.State.EnqueueRunCommand(s.machine.Tag(), "echo hello", 0)
+	c.Assert(err, gc.IsNil)
+
+	cr := commandrunner.NewCommandRunner(s.State, s.machine.Tag(), s.dataDir)
+	defer statetesting.AssertStop(c, cr)
+
+	result := s.waitResult(c, rc0)
+	c.Assert(result, gc.DeepEquals, state.RunResult{Stdout: []byte("hello\n")})
+
+	rc1, err := s.State.EnqueueRunCommand(s.machine.Tag(), "echo oops >&2; exit 2", 0)
+	c.Assert(err, gc.IsNil)
+	result = s.waitResult(c, rc1)
+	c.Assert(result, gc.DeepEquals, state.RunResult{Code: 2, Stderr: []byte("oops\n")})
+}
+
+func (s *commandRunnerSuite) TestTimeout(c *gc.C) {
+	cr := commandrunner.NewCommandRunner(s.State, s.machine.Tag(), s.dataDir)
+	defer statetesting.AssertStop(c, cr)
+
+	rc, err := s.State.EnqueueRunCommand(s.machine.Tag(), "echo before; sleep 10", 100*time.Millisecond)
+	c.Assert(err, gc.IsNil)
+	result := s.waitResult(c, rc)
+	c.Assert(result, gc.DeepEquals, state.RunResult{
+		Code:   -1,
+		Stdout: []byte("before\n"),
+		Error:  "timed out",
+	})
+}
+
+func (s *commandRunnerSuite) TestWaitsForHookLock(c *gc.C) {
+	lock, err := fslock.NewLock(filepath.Join(s.dataDir, "locks"), "uniter-hook-execution")
+	c.Assert(err, gc.IsNil)
+	err = lock.Lock("u/0: running hook")
+	c.Assert(err, gc.IsNil)
+
+	cr := commandrunner.NewCommandRunner(s.State, s.machine.Tag(), s.dataDir)
+	defer statetesting.AssertStop(c, cr)
+
+	rc, err := s.State.EnqueueRunCommand(s.machine.Tag(), "echo hello", 0)
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	time.Sleep(coretesting.ShortWait)
+	err = rc.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(rc.Status(), gc.Equals, state.RunCommandPending)
+
+	err = lock.Unlock()
+	c.Assert(err, gc.IsNil)
+	result := s.waitResult(c, rc)
+	c.Assert(result, gc.DeepEquals, state.RunResult{Stdout: []byte("hello\n")})
+}
@@ -31,7 +31,7 @@ type Firewaller struct {
 	serviceds       map[string]*serviceData
 	exposedChange   chan *exposedChange
 	globalMode      bool
-	globalPortRef   map[instance.Port]int
+	globalPortRef   map[instance.PortRange]int
 }
 
 // NewFirewaller returns a new Firewaller.
@@ -66,7 +66,7 @@ func (fw *Firewaller) loop() error {
 	}
 	if fw.environ.Config().FirewallMode() == config.FwGlobal {
 		fw.globalMode = true
-		fw.globalPortRef = make(map[instance.Port]int)
+		fw.globalPortRef = make(map[instance.PortRange]int)
 	}
 	for {
 		select {
@@ -135,7 +135,7 @@ func (fw *Firewaller) startMachine(id string) error {
 		fw:     fw,
 		id:     id,
 		unitds: make(map[string]*unitData),
-		ports:  make([]instance.Port, 0),
+		ports:  make([]instance.PortRange, 0),
 	}
 	m, err := machined.machine()
 	if errors.IsNotFoundError(err) {
@@ -194,7 +194,7 @@ func (fw *Firewaller) startUnit(unit *state.Unit, machineId string) error {
 	unitd.serviced = fw.serviceds[serviceName]
 	unitd.serviced.unitds[unitName] = unitd
 
-	ports := make([]instance.Port, len(unitd.ports))
+	ports := make([]instance.PortRange, len(unitd.ports))
 	copy(ports, unitd.ports)
 
 	go unitd.watchLoop(ports)
@@ -223,7 +223,7 @@ func (fw *Firewaller) reconcileGlobal() error {
 	if err != nil {
 		return err
 	}
-	collector := make(map[instance.Port]bool)
+	collector := make(map[instance.PortRange]bool)
 	for _, unitd := range fw.unitds {
 		if unitd.serviced.exposed {
 			for _, port := range unitd.ports {
@@ -231,7 +231,7 @@ func (fw *Firewaller) reconcileGlobal() error {
 			}
 		}
 	}
-	wantedPorts := []instance.Port{}
+	wantedPorts := []instance.PortRange{}
 	for port := range collector {
 		wantedPorts = append(wantedPorts, port)
 	}
@@ -364,7 +364,7 @@ func (fw *Firewaller) flushUnits(unitds []*unitData) error {
 // flushMachine opens and closes ports for the passed machine.
 func (fw *Firewaller) flushMachine(machined *machineData) error {
 	// Gather ports to open and close.
-	ports := map[instance.Port]bool{}
+	ports := map[instance.PortRange]bool{}
 	for _, unitd := range machined.unitds {
 		if unitd.serviced.exposed {
 			for _, port := range unitd.ports {
@@ -372,7 +372,7 @@ func (fw *Firewaller) flushMachine(machined *machineData) error {
 			}
 		}
 	}
-	want := []instance.Port{}
+	want := []instance.PortRange{}
 	for port := range ports {
 		want = append(want, port)
 	}
@@ -388,9 +388,9 @@ func (fw *Firewaller) flushMachine(machined *machineData) error {
 // flushGlobalPorts opens and closes global ports in the environment.
 // It keeps a reference count for ports so that only 0-to-1 and 1-to-0 events
 // modify the environment.
-func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []instance.Port) error {
+func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []instance.PortRange) error {
 	// Filter which ports are really to open or close.
-	var toOpen, toClose []instance.Port
+	var toOpen, toClose []instance.PortRange
 	for _, port := range rawOpen {
 		if fw.globalPortRef[port] == 0 {
 			toOpen = append(toOpen, port)
@@ -425,7 +425,7 @@ func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []instance.Port) error
 }
 
 // flushGlobalPorts opens and closes ports global on the machine.
-func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []instance.Port) error {
+func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []instance.PortRange) error {
 	// If there's nothing to do, do nothing.
 	// This is important because when a machine is first created,
 	// it will have no instance id but also no open ports -
@@ -582,7 +582,7 @@ type machineData struct {
 	fw     *Firewaller
 	id     string
 	unitds map[string]*unitData
-	ports  []instance.Port
+	ports  []instance.PortRange
 }
 
 func (md *machineData) machine() (*state.Machine, error) {
@@ -623,7 +623,7 @@ func (md *machineData) Stop() error {
 // portsChange contains the changed ports for one specific unit.
 type portsChange struct {
 	unitd *unitData
-	ports []instance.Port
+	ports []instance.PortRange
 }
 
 // unitData holds unit details and watches port changes.
@@ -633,11 +633,11 @@ type unitData struct {
 	unit     *state.Unit
 	serviced *serviceData
 	machined *machineData
-	ports    []instance.Port
+	ports    []instance.PortRange
 }
 
 // watchLoop watches the unit for port changes.
-func (ud *unitData) watchLoop(latestPorts []instance.Port) {
+func (ud *unitData) watchLoop(latestPorts []instance.PortRange) {
 	defer ud.tomb.Done()
 	w := ud.unit.Watch()
 	defer watcher.Stop(w, &ud.tomb)
@@ -672,7 +672,7 @@ func (ud *unitData) watchLoop(latestPorts []instance.Port) {
 
 // samePorts returns whether old and new contain the same set of ports.
 // Both old and new must be sorted.
-func samePorts(old, new []instance.Port) bool {
+func samePorts(old, new []instance.PortRange) bool {
 	if len(old) != len(new) {
 		return false
 	}
@@ -746,7 +746,7 @@ func (sd *serviceData) Stop() error {
 }
 
 // diff returns all the ports that exist in A but not B.
-func diff(A, B []instance.Port) (missing []instance.Port) {
+func diff(A, B []instance.PortRange) (missing []instance.PortRange) {
 next:
 	for _, a := range A {
 		for _, b := range B {
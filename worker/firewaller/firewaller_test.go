@@ -33,7 +33,7 @@ var _ worker.Worker = (*firewaller.Firewaller)(nil)
 
 // assertPorts retrieves the open ports of the instance and compares them
 // to the expected.
-func (s *FirewallerSuite) assertPorts(c *C, inst instance.Instance, machineId string, expected []instance.Port) {
+func (s *FirewallerSuite) assertPorts(c *C, inst instance.Instance, machineId string, expected []instance.PortRange) {
 	s.State.StartSync()
 	start := time.Now()
 	for {
@@ -59,7 +59,7 @@ func (s *FirewallerSuite) assertPorts(c *C, inst instance.Instance, machineId st
 
 // assertEnvironPorts retrieves the open ports of environment and compares them
 // to the expected.
-func (s *FirewallerSuite) assertEnvironPorts(c *C, expected []instance.Port) {
+func (s *FirewallerSuite) assertEnvironPorts(c *C, expected []instance.PortRange) {
 	s.State.StartSync()
 	start := time.Now()
 	for {
@@ -179,12 +179,39 @@ func (s *FirewallerSuite) TestExposedService(c *C) {
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	err = u.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 8080, 8080}})
+}
+
+func (s *FirewallerSuite) TestExposedServicePortRanges(c *C) {
+	fw := firewaller.NewFirewaller(s.State)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u, m := s.addUnit(c, svc)
+	inst := s.startInstance(c, m)
+
+	err = u.OpenPorts("udp", 10000, 20000)
+	c.Assert(err, IsNil)
+	err = u.OpenPorts("icmp", -1, -1)
+	c.Assert(err, IsNil)
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"icmp", -1, -1}, {"tcp", 80, 80}, {"udp", 10000, 20000}})
+
+	err = u.ClosePorts("udp", 10000, 20000)
+	c.Assert(err, IsNil)
+
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"icmp", -1, -1}, {"tcp", 80, 80}})
 }
 
 func (s *FirewallerSuite) TestMultipleExposedServices(c *C) {
@@ -213,15 +240,15 @@ func (s *FirewallerSuite) TestMultipleExposedServices(c *C) {
 	err = u2.OpenPort("tcp", 3306)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 80}, {"tcp", 8080}})
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 3306}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 3306, 3306}})
 
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
 	err = u2.ClosePort("tcp", 3306)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 8080, 8080}})
 	s.assertPorts(c, inst2, m2.Id(), nil)
 }
 
@@ -242,12 +269,12 @@ func (s *FirewallerSuite) TestMachineWithoutInstanceId(c *C) {
 	inst2 := s.startInstance(c, m2)
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	inst1 := s.startInstance(c, m1)
 	err = u1.OpenPort("tcp", 8080)
 	c.Assert(err, IsNil)
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 8080, 8080}})
 }
 
 func (s *FirewallerSuite) TestMultipleUnits(c *C) {
@@ -269,8 +296,8 @@ func (s *FirewallerSuite) TestMultipleUnits(c *C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 80, 80}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
@@ -301,7 +328,7 @@ func (s *FirewallerSuite) TestStartWithState(c *C) {
 	fw := firewaller.NewFirewaller(s.State)
 	defer func() { c.Assert(fw.Stop(), IsNil) }()
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	err = svc.SetExposed()
 	c.Assert(err, IsNil)
@@ -331,7 +358,7 @@ func (s *FirewallerSuite) TestStartWithPartialState(c *C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}})
 }
 
 func (s *FirewallerSuite) TestStartWithUnexposedService(c *C) {
@@ -357,7 +384,7 @@ func (s *FirewallerSuite) TestStartWithUnexposedService(c *C) {
 	// Expose service.
 	err = svc.SetExposed()
 	c.Assert(err, IsNil)
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}})
 }
 
 func (s *FirewallerSuite) TestSetClearExposedService(c *C) {
@@ -381,7 +408,7 @@ func (s *FirewallerSuite) TestSetClearExposedService(c *C) {
 	err = svc.SetExposed()
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// ClearExposed closes the ports again.
 	err = svc.ClearExposed()
@@ -409,8 +436,8 @@ func (s *FirewallerSuite) TestRemoveUnit(c *C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 80, 80}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	// Remove unit.
 	err = u1.EnsureDead()
@@ -419,7 +446,7 @@ func (s *FirewallerSuite) TestRemoveUnit(c *C) {
 	c.Assert(err, IsNil)
 
 	s.assertPorts(c, inst1, m1.Id(), nil)
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 80, 80}})
 }
 
 func (s *FirewallerSuite) TestRemoveService(c *C) {
@@ -436,7 +463,7 @@ func (s *FirewallerSuite) TestRemoveService(c *C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	// Remove service.
 	err = u.EnsureDead()
@@ -472,8 +499,8 @@ func (s *FirewallerSuite) TestRemoveMultipleServices(c *C) {
 	err = u2.OpenPort("tcp", 3306)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []instance.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []instance.Port{{"tcp", 3306}})
+	s.assertPorts(c, inst1, m1.Id(), []instance.PortRange{{"tcp", 80, 80}})
+	s.assertPorts(c, inst2, m2.Id(), []instance.PortRange{{"tcp", 3306, 3306}})
 
 	// Remove services.
 	err = u2.EnsureDead()
@@ -508,7 +535,7 @@ func (s *FirewallerSuite) TestDeadMachine(c *C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	// Remove unit and service, also tested without. Has no effect.
 	err = u.EnsureDead()
@@ -541,7 +568,7 @@ func (s *FirewallerSuite) TestRemoveMachine(c *C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []instance.PortRange{{"tcp", 80, 80}})
 
 	// Remove unit.
 	err = u.EnsureDead()
@@ -591,17 +618,17 @@ func (s *FirewallerSuite) TestGlobalMode(c *C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, IsNil)
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Closing a port opened by a different unit won't touch the environment.
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Closing a port used just once changes the environment.
 	err = u1.ClosePort("tcp", 8080)
 	c.Assert(err, IsNil)
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}})
 
 	// Closing the last port also modifies the environment.
 	err = u2.ClosePort("tcp", 80)
@@ -636,7 +663,7 @@ func (s *FirewallerSuite) TestGlobalModeStartWithUnexposedService(c *C) {
 	// Expose service.
 	err = svc.SetExposed()
 	c.Assert(err, IsNil)
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}})
 }
 
 func (s *FirewallerSuite) TestGlobalModeRestart(c *C) {
@@ -659,7 +686,7 @@ func (s *FirewallerSuite) TestGlobalModeRestart(c *C) {
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, IsNil)
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Stop firewall and close one and open a different port.
 	err = fw.Stop()
@@ -674,7 +701,7 @@ func (s *FirewallerSuite) TestGlobalModeRestart(c *C) {
 	fw = firewaller.NewFirewaller(s.State)
 	defer func() { c.Assert(fw.Stop(), IsNil) }()
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8888}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8888, 8888}})
 }
 
 func (s *FirewallerSuite) TestGlobalModeRestartUnexposedService(c *C) {
@@ -697,7 +724,7 @@ func (s *FirewallerSuite) TestGlobalModeRestartUnexposedService(c *C) {
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, IsNil)
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Stop firewall and clear exposed flag on service.
 	err = fw.Stop()
@@ -733,7 +760,7 @@ func (s *FirewallerSuite) TestGlobalModeRestartPortCount(c *C) {
 	err = u1.OpenPort("tcp", 8080)
 	c.Assert(err, IsNil)
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Stop firewall and add another service using the port.
 	err = fw.Stop()
@@ -753,17 +780,17 @@ func (s *FirewallerSuite) TestGlobalModeRestartPortCount(c *C) {
 	fw = firewaller.NewFirewaller(s.State)
 	defer func() { c.Assert(fw.Stop(), IsNil) }()
 
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Closing a port opened by a different unit won't touch the environment.
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, IsNil)
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}, {"tcp", 8080, 8080}})
 
 	// Closing a port used just once changes the environment.
 	err = u1.ClosePort("tcp", 8080)
 	c.Assert(err, IsNil)
-	s.assertEnvironPorts(c, []instance.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []instance.PortRange{{"tcp", 80, 80}})
 
 	// Closing the last port also modifies the environment.
 	err = u2.ClosePort("tcp", 80)
@@ -10,6 +10,9 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/log"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	utilexec "launchpad.net/juju-core/utils/exec"
+	"launchpad.net/juju-core/worker/uniter/debug"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"os"
 	"os/exec"
@@ -17,6 +20,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -49,6 +53,17 @@ type HookContext struct {
 
 	// apiAddrs contains the API server addresses.
 	apiAddrs []string
+
+	// actionName and actionParams identify the action being run, if
+	// the context is running an action; actionParams is nil otherwise.
+	actionName   string
+	actionParams map[string]interface{}
+
+	// actionResults holds the values set by the running action, and
+	// actionFailed and actionMessage record whether and why it failed.
+	actionResults map[string]interface{}
+	actionFailed  bool
+	actionMessage string
 }
 
 func NewHookContext(unit *state.Unit, id, uuid string, relationId int,
@@ -77,12 +92,44 @@ func (ctx *HookContext) PrivateAddress() (string, bool) {
 	return ctx.unit.PrivateAddress()
 }
 
-func (ctx *HookContext) OpenPort(protocol string, port int) error {
-	return ctx.unit.OpenPort(protocol, port)
+func (ctx *HookContext) OpenPorts(protocol string, fromPort, toPort int) error {
+	return ctx.unit.OpenPorts(protocol, fromPort, toPort)
+}
+
+func (ctx *HookContext) ClosePorts(protocol string, fromPort, toPort int) error {
+	return ctx.unit.ClosePorts(protocol, fromPort, toPort)
+}
+
+func (ctx *HookContext) WorkloadStatus() (params.Status, string, error) {
+	return ctx.unit.WorkloadStatus()
+}
+
+func (ctx *HookContext) SetWorkloadStatus(status params.Status, message string) error {
+	return ctx.unit.SetWorkloadStatus(status, message)
+}
+
+func (ctx *HookContext) IsLeader() (bool, error) {
+	svc, err := ctx.unit.Service()
+	if err != nil {
+		return false, err
+	}
+	leader, err := svc.Leader()
+	if err != nil {
+		return false, err
+	}
+	return leader == ctx.unit.Name(), nil
+}
+
+func (ctx *HookContext) LeaderSettings() (map[string]string, error) {
+	svc, err := ctx.unit.Service()
+	if err != nil {
+		return nil, err
+	}
+	return svc.LeaderSettings()
 }
 
-func (ctx *HookContext) ClosePort(protocol string, port int) error {
-	return ctx.unit.ClosePort(protocol, port)
+func (ctx *HookContext) SetLeaderSettings(settings map[string]string) error {
+	return ctx.unit.SetLeaderSettings(settings)
 }
 
 func (ctx *HookContext) ConfigSettings() (charm.Settings, error) {
@@ -121,6 +168,37 @@ func (ctx *HookContext) RelationIds() []int {
 	return ids
 }
 
+func (ctx *HookContext) ActionParams() (map[string]interface{}, error) {
+	if ctx.actionParams == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	result := make(map[string]interface{})
+	for name, value := range ctx.actionParams {
+		result[name] = value
+	}
+	return result, nil
+}
+
+func (ctx *HookContext) SetActionResult(key string, value interface{}) error {
+	if ctx.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	if ctx.actionResults == nil {
+		ctx.actionResults = make(map[string]interface{})
+	}
+	ctx.actionResults[key] = value
+	return nil
+}
+
+func (ctx *HookContext) SetActionFailed(message string) error {
+	if ctx.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	ctx.actionFailed = true
+	ctx.actionMessage = message
+	return nil
+}
+
 // hookVars returns an os.Environ-style list of strings necessary to run a hook
 // such that it can know what environment it's operating in, and can call back
 // into ctx.
@@ -142,15 +220,82 @@ func (ctx *HookContext) hookVars(charmDir, toolsDir, socketPath string) []string
 		name, _ := ctx.RemoteUnitName()
 		vars = append(vars, "JUJU_REMOTE_UNIT="+name)
 	}
+	if ctx.actionParams != nil {
+		vars = append(vars, "JUJU_ACTION_NAME="+ctx.actionName)
+	}
 	return vars
 }
 
+// RunCommands executes the commands in an environment which allows it to
+// call back into the hook context to execute jujuc tools, as "juju run"
+// requires. Relation settings changed by the commands are written only
+// if the commands complete successfully.
+func (ctx *HookContext) RunCommands(commands, charmDir, toolsDir, socketPath string, timeout time.Duration) (*utilexec.ExecResponse, error) {
+	result, err := utilexec.RunCommands(utilexec.RunParams{
+		Commands:    commands,
+		WorkingDir:  charmDir,
+		Environment: ctx.hookVars(charmDir, toolsDir, socketPath),
+		Timeout:     timeout,
+	})
+	write := err == nil && result.Code == 0
+	for id, rctx := range ctx.relations {
+		if write {
+			if e := rctx.WriteSettings(); e != nil {
+				e = fmt.Errorf("could not write settings from commands to relation %d: %v", id, e)
+				log.Errorf("worker/uniter: %v", e)
+				if err == nil {
+					err = e
+				}
+			}
+		}
+		rctx.ClearCache()
+	}
+	return result, err
+}
+
 // RunHook executes a hook in an environment which allows it to to call back
-// into ctx to execute jujuc tools.
-func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string) error {
-	ps := exec.Command(filepath.Join(charmDir, "hooks", hookName))
+// into ctx to execute jujuc tools. If the hook is being debugged with
+// "juju debug-hooks", the operator runs it in the debugging session instead.
+// Otherwise, if timeout is non-zero and the hook runs for longer, the
+// hook's whole process group is killed and utilexec.ErrTimedOut is
+// returned.
+func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string, timeout time.Duration) error {
+	session, err := debug.NewHooksContext(ctx.unit.Name()).FindSession()
+	if err != nil {
+		log.Warningf("worker/uniter: cannot look for debug-hooks session: %v", err)
+	}
+	if session != nil && session.MatchHook(hookName) {
+		log.Infof("worker/uniter: executing %q hook via debug-hooks", hookName)
+		err = session.RunHook(hookName, charmDir, ctx.hookVars(charmDir, toolsDir, socketPath))
+	} else {
+		err = ctx.runCharmProcess(filepath.Join(charmDir, "hooks", hookName), charmDir, toolsDir, socketPath, timeout)
+		if ee, ok := err.(*exec.Error); ok && err != nil {
+			if os.IsNotExist(ee.Err) {
+				// Missing hook is perfectly valid, but worth mentioning.
+				log.Infof("worker/uniter: skipped %q hook (not implemented)", hookName)
+				return nil
+			}
+		}
+	}
+	return ctx.finalizeContext(fmt.Sprintf("%q", hookName), err)
+}
+
+// RunAction executes the named action in an environment which allows
+// it to call back into ctx to execute jujuc tools. Unlike a missing
+// hook, a missing action is an error.
+func (ctx *HookContext) RunAction(name, charmDir, toolsDir, socketPath string) error {
+	err := ctx.runCharmProcess(filepath.Join(charmDir, "actions", name), charmDir, toolsDir, socketPath, 0)
+	return ctx.finalizeContext(fmt.Sprintf("action %q", name), err)
+}
+
+// runCharmProcess runs the executable at path, logging its output. The
+// process is run in a new process group, which is killed if the process
+// runs for longer than a non-zero timeout.
+func (ctx *HookContext) runCharmProcess(path, charmDir, toolsDir, socketPath string, timeout time.Duration) error {
+	ps := exec.Command(path)
 	ps.Env = ctx.hookVars(charmDir, toolsDir, socketPath)
 	ps.Dir = charmDir
+	ps.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	outReader, outWriter, err := os.Pipe()
 	if err != nil {
 		return fmt.Errorf("cannot make logging pipe: %v", err)
@@ -165,23 +310,23 @@ func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string)
 	err = ps.Start()
 	outWriter.Close()
 	if err == nil {
-		err = ps.Wait()
+		err = utilexec.Wait(ps, timeout)
 	}
 	logger.stop()
-	if ee, ok := err.(*exec.Error); ok && err != nil {
-		if os.IsNotExist(ee.Err) {
-			// Missing hook is perfectly valid, but worth mentioning.
-			log.Infof("worker/uniter: skipped %q hook (not implemented)", hookName)
-			return nil
-		}
-	}
+	return err
+}
+
+// finalizeContext writes the relation settings changed by the process
+// described by source, if it ran successfully, and clears the relation
+// caches.
+func (ctx *HookContext) finalizeContext(source string, err error) error {
 	write := err == nil
 	for id, rctx := range ctx.relations {
 		if write {
 			if e := rctx.WriteSettings(); e != nil {
 				e = fmt.Errorf(
-					"could not write settings from %q to relation %d: %v",
-					hookName, id, e,
+					"could not write settings from %s to relation %d: %v",
+					source, id, e,
 				)
 				log.Errorf("worker/uniter: %v", e)
 				if err == nil {
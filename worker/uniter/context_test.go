@@ -10,7 +10,9 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/utils"
+	utilexec "launchpad.net/juju-core/utils/exec"
 	"launchpad.net/juju-core/worker/uniter"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"os"
@@ -38,6 +40,9 @@ type hookSpec struct {
 	stderr string
 	// background holds a string to print in the background after 0.2s.
 	background string
+	// hang, if set, holds a path that a child of the hook touches
+	// after 0.5s, while the hook itself sleeps for a long time.
+	hang string
 }
 
 // makeCharm constructs a fake charm dir containing a single named hook
@@ -76,6 +81,10 @@ func makeCharm(c *C, spec hookSpec) (charmDir, outPath string) {
 		// expected.
 		printf("(sleep 0.2; echo %s; sleep 10) &", spec.background)
 	}
+	if spec.hang != "" {
+		printf("(sleep 0.5; touch %s) &", spec.hang)
+		printf("sleep 10")
+	}
 	printf("exit %d", spec.code)
 	return charmDir, outPath
 }
@@ -214,7 +223,7 @@ func (s *RunHookSuite) TestRunHook(c *C) {
 		}
 		toolsDir := c.MkDir()
 		t0 := time.Now()
-		err := ctx.RunHook("something-happened", charmDir, toolsDir, "/path/to/socket")
+		err := ctx.RunHook("something-happened", charmDir, toolsDir, "/path/to/socket", 0)
 		if t.err == "" {
 			c.Assert(err, IsNil)
 		} else {
@@ -246,6 +255,27 @@ func splitLine(s string) []string {
 	return ss
 }
 
+func (s *RunHookSuite) TestRunHookTimeout(c *C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, IsNil)
+	ctx := s.GetHookContext(c, uuid.String(), -1, "")
+	childPath := filepath.Join(c.MkDir(), "child")
+	charmDir, _ := makeCharm(c, hookSpec{
+		name: "something-happened",
+		perm: 0700,
+		hang: childPath,
+	})
+	t0 := time.Now()
+	err = ctx.RunHook("something-happened", charmDir, c.MkDir(), "/path/to/socket", 100*time.Millisecond)
+	c.Assert(err, Equals, utilexec.ErrTimedOut)
+	c.Assert(time.Since(t0) < 5*time.Second, Equals, true)
+
+	// The hook's child was killed along with it.
+	time.Sleep(time.Second)
+	_, err = os.Stat(childPath)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
 func (s *RunHookSuite) TestRunHookRelationFlushing(c *C) {
 	// Create a charm with a breaking hook.
 	uuid, err := utils.NewUUID()
@@ -264,7 +294,7 @@ func (s *RunHookSuite) TestRunHookRelationFlushing(c *C) {
 	node1.Set("bar", 2)
 
 	// Run the failing hook.
-	err = ctx.RunHook("something-happened", charmDir, c.MkDir(), "/path/to/socket")
+	err = ctx.RunHook("something-happened", charmDir, c.MkDir(), "/path/to/socket", 0)
 	c.Assert(err, ErrorMatches, "exit status 123")
 
 	// Check that the changes to the local settings nodes have been discarded.
@@ -292,7 +322,7 @@ func (s *RunHookSuite) TestRunHookRelationFlushing(c *C) {
 	node1.Set("qux", 4)
 
 	// Run the hook.
-	err = ctx.RunHook("something-happened", charmDir, c.MkDir(), "/path/to/socket")
+	err = ctx.RunHook("something-happened", charmDir, c.MkDir(), "/path/to/socket", 0)
 	c.Assert(err, IsNil)
 
 	// Check that the changes to the local settings nodes are still there.
@@ -588,6 +618,57 @@ func (s *InterfaceSuite) TestConfigCaching(c *C) {
 	c.Assert(settings, DeepEquals, charm.Settings{"blog-title": "My Title"})
 }
 
+func (s *InterfaceSuite) TestWorkloadStatus(c *C) {
+	ctx := s.GetContext(c, -1, "")
+	status, message, err := ctx.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusUnknown)
+	c.Assert(message, Equals, "")
+
+	err = ctx.SetWorkloadStatus(params.StatusBlocked, "need a database")
+	c.Assert(err, IsNil)
+	status, message, err = ctx.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusBlocked)
+	c.Assert(message, Equals, "need a database")
+
+	// The status is written straight to state.
+	u, err := s.State.Unit("u/0")
+	c.Assert(err, IsNil)
+	status, message, err = u.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.StatusBlocked)
+	c.Assert(message, Equals, "need a database")
+}
+
+func (s *InterfaceSuite) TestLeadership(c *C) {
+	ctx := s.GetContext(c, -1, "")
+	leader, err := ctx.IsLeader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, false)
+	err = ctx.SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, ErrorMatches, `cannot set leader settings for unit "u/0": unit is not the leader`)
+
+	u, err := s.State.Unit("u/0")
+	c.Assert(err, IsNil)
+	pinger, err := u.SetAgentAlive()
+	c.Assert(err, IsNil)
+	defer pinger.Stop()
+	s.State.Sync()
+	ok, err := u.ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	leader, err = ctx.IsLeader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, true)
+	err = ctx.SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, IsNil)
+	settings, err := ctx.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{"foo": "bar"})
+}
+
 type HookContextSuite struct {
 	testing.JujuConnSuite
 	service  *state.Service
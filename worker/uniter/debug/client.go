@@ -0,0 +1,92 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"launchpad.net/goyaml"
+)
+
+// ClientScript returns a bash script suitable for executing on the
+// unit's machine to start a debugging session for the given hooks, or
+// for all hooks if none are given. The script fails if the unit is
+// already being debugged.
+func ClientScript(c *HooksContext, hooks []string) string {
+	data, err := goyaml.Marshal(&debugArgs{Hooks: hooks})
+	if err != nil {
+		// Marshalling a list of strings cannot fail.
+		panic(err)
+	}
+	replacer := strings.NewReplacer(
+		"{unit_name}", c.Unit,
+		"{tmux_session}", c.tmuxSessionName(),
+		"{entry_flock}", c.ClientFileLock(),
+		"{exit_flock}", c.ClientExitFileLock(),
+		"{hook_args}", base64.StdEncoding.EncodeToString(data),
+	)
+	return replacer.Replace(clientScript)
+}
+
+const clientScript = `
+(
+# Lock the entry file, so that only one client debugs the unit at a time.
+flock -n 8 || { echo "Failed to acquire {entry_flock}: unit {unit_name} is already being debugged" >&2; exit 1; }
+(
+# Close the inherited lock FD, or tmux will keep it open.
+exec 8>&-
+
+# Write out the debug-hooks args.
+echo "{hook_args}" | base64 -d > {entry_flock}
+
+# Lock the exit file for as long as the tmux session runs.
+flock -n 9 || exit 1
+
+# Wait for tmux to be installed.
+while [ ! -f /usr/bin/tmux ]; do
+    sleep 1
+done
+
+if [ ! -f ~/.tmux.conf ]; then
+    cat > ~/.tmux.conf <<END
+# Status bar
+set-option -g status-bg black
+set-option -g status-fg white
+set-window-option -g window-status-current-bg red
+set-window-option -g window-status-current-attr bright
+
+# Panes
+set-option -g pane-border-fg white
+set-option -g pane-active-border-fg white
+
+# Monitor activity on windows
+set-window-option -g monitor-activity on
+
+# Screen bindings, since people are more familiar with that.
+set-option -g prefix C-a
+bind C-a last-window
+bind a send-key C-a
+
+bind | split-window -h
+bind - split-window -v
+
+# Fix CTRL-PGUP/PGDOWN for vim
+set-window-option -g xterm-keys on
+
+# Prevent ESC key from adding delay and breaking Vim's ESC > arrow key
+set-option -s escape-time 0
+END
+fi
+
+(
+    # Close the inherited lock FD, or tmux will keep it open.
+    exec 9>&-
+    # The session may already have been started by a hook.
+    tmux attach-session -t {tmux_session} 2>/dev/null || exec tmux new-session -s {tmux_session}
+)
+) 9>{exit_flock}
+) 8>{entry_flock}
+exit $?
+`
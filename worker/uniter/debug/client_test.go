@@ -0,0 +1,46 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug_test
+
+import (
+	"encoding/base64"
+	"regexp"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/worker/uniter/debug"
+)
+
+type DebugHooksClientSuite struct{}
+
+var _ = Suite(&DebugHooksClientSuite{})
+
+var argsRegexp = regexp.MustCompile(`echo "([^"]*)" \| base64 -d`)
+
+func (*DebugHooksClientSuite) TestClientScript(c *C) {
+	ctx := debug.NewHooksContext("foo/8")
+
+	script := debug.ClientScript(ctx, nil)
+	c.Assert(script, Matches, `(?s).*\) 8>/tmp/juju-foo-8-debug-hooks\n.*`)
+	c.Assert(script, Matches, `(?s).*\) 9>/tmp/juju-foo-8-debug-hooks-exit\n.*`)
+	c.Assert(script, Matches, `(?s).*tmux new-session -s foo-8\n.*`)
+	c.Assert(decodeArgs(c, script), HasLen, 0)
+
+	script = debug.ClientScript(ctx, []string{"install", "config-changed"})
+	c.Assert(decodeArgs(c, script), DeepEquals, []string{"install", "config-changed"})
+}
+
+func decodeArgs(c *C, script string) []string {
+	m := argsRegexp.FindStringSubmatch(script)
+	c.Assert(m, NotNil)
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	c.Assert(err, IsNil)
+	var args struct {
+		Hooks []string
+	}
+	err = goyaml.Unmarshal(data, &args)
+	c.Assert(err, IsNil)
+	return args.Hooks
+}
@@ -0,0 +1,56 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// debug implements the communication between "juju debug-hooks" and the
+// uniter: the client side records which hooks are to be debugged and
+// starts a tmux session on the unit's machine, and the server side runs
+// matching hooks in a new window of that session.
+package debug
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// HooksContext identifies the files and tmux session through which
+// hooks of a unit are debugged.
+type HooksContext struct {
+	// Unit holds the name of the unit being debugged.
+	Unit string
+
+	// FlockDir holds the directory in which the lock files used to
+	// coordinate debugging sessions are created.
+	FlockDir string
+}
+
+// NewHooksContext returns a HooksContext for debugging hooks of
+// the named unit.
+func NewHooksContext(unitName string) *HooksContext {
+	return &HooksContext{Unit: unitName, FlockDir: "/tmp"}
+}
+
+// ClientFileLock returns the path of the file that is locked by the
+// client for the duration of a debugging session. It also holds the
+// arguments of the session.
+func (c *HooksContext) ClientFileLock() string {
+	basename := fmt.Sprintf("juju-%s-debug-hooks", strings.Replace(c.Unit, "/", "-", -1))
+	return filepath.Join(c.FlockDir, basename)
+}
+
+// ClientExitFileLock returns the path of the file that is locked by
+// the client while its tmux session is running.
+func (c *HooksContext) ClientExitFileLock() string {
+	return c.ClientFileLock() + "-exit"
+}
+
+// tmuxSessionName returns the name of the tmux session in which hooks
+// of the unit are debugged.
+func (c *HooksContext) tmuxSessionName() string {
+	return strings.Replace(c.Unit, "/", "-", -1)
+}
+
+// debugArgs holds the arguments given to "juju debug-hooks".
+type debugArgs struct {
+	Hooks []string `yaml:"hooks,omitempty"`
+}
@@ -0,0 +1,29 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug_test
+
+import (
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/worker/uniter/debug"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type DebugHooksCommonSuite struct{}
+
+var _ = Suite(&DebugHooksCommonSuite{})
+
+func (*DebugHooksCommonSuite) TestHooksContext(c *C) {
+	ctx := debug.NewHooksContext("foo/8")
+	c.Assert(ctx.Unit, Equals, "foo/8")
+	c.Assert(ctx.FlockDir, Equals, "/tmp")
+	ctx.FlockDir = "/var/lib/juju"
+	c.Assert(ctx.ClientFileLock(), Equals, "/var/lib/juju/juju-foo-8-debug-hooks")
+	c.Assert(ctx.ClientExitFileLock(), Equals, "/var/lib/juju/juju-foo-8-debug-hooks-exit")
+}
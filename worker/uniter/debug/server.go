@@ -0,0 +1,141 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/utils/set"
+)
+
+// ServerSession represents a "juju debug-hooks" session to which
+// the uniter may hand over the execution of hooks.
+type ServerSession struct {
+	*HooksContext
+	hooks set.Strings
+}
+
+// FindSession returns the debugging session of the unit, or nil if no
+// client is currently debugging it.
+func (c *HooksContext) FindSession() (*ServerSession, error) {
+	f, err := os.Open(c.ClientExitFileLock())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// The client holds the exit lock for as long as it is attached.
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if err == nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return nil, nil
+	} else if err != syscall.EWOULDBLOCK {
+		return nil, fmt.Errorf("cannot check debug-hooks lock: %v", err)
+	}
+	data, err := ioutil.ReadFile(c.ClientFileLock())
+	if err != nil {
+		return nil, err
+	}
+	var args debugArgs
+	if err := goyaml.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("cannot read debug-hooks arguments: %v", err)
+	}
+	return &ServerSession{c, set.NewStrings(args.Hooks...)}, nil
+}
+
+// MatchHook reports whether the named hook should be debugged.
+func (s *ServerSession) MatchHook(hookName string) bool {
+	return s.hooks.IsEmpty() || s.hooks.Contains(hookName)
+}
+
+// RunHook opens a new window in the session's tmux session, with a
+// shell in which the environment of the named hook is set, and waits
+// for the operator to exit it. The hook fails if the shell exits with
+// a non-zero status, or if the client goes away first.
+func (s *ServerSession) RunHook(hookName, charmDir string, env []string) error {
+	debugDir, err := ioutil.TempDir("", "juju-debug-hooks-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(debugDir)
+	if err := ioutil.WriteFile(filepath.Join(debugDir, "env.sh"), envScript(env), 0600); err != nil {
+		return err
+	}
+	replacer := strings.NewReplacer(
+		"{unit_name}", s.Unit,
+		"{tmux_session}", s.tmuxSessionName(),
+		"{exit_flock}", s.ClientExitFileLock(),
+		"{hook_name}", hookName,
+	)
+	cmd := exec.Command("/bin/bash", "-s")
+	cmd.Env = append(env, "JUJU_DEBUG="+debugDir)
+	cmd.Dir = charmDir
+	cmd.Stdin = bytes.NewBufferString(replacer.Replace(serverScript))
+	return cmd.Run()
+}
+
+// envScript returns a bash script that exports the given environment.
+func envScript(env []string) []byte {
+	var buf bytes.Buffer
+	for _, kv := range env {
+		if i := strings.Index(kv, "="); i > 0 {
+			value := strings.Replace(kv[i+1:], "'", `'\''`, -1)
+			fmt.Fprintf(&buf, "export %s='%s'\n", kv[:i], value)
+		}
+	}
+	return buf.Bytes()
+}
+
+const serverScript = `
+# Create the script run in the hook window. It loads the hook environment
+# and records the exit status of the operator's shell.
+cat > $JUJU_DEBUG/hook.sh <<END
+#!/bin/bash
+. $JUJU_DEBUG/env.sh
+export PS1="{unit_name}:{hook_name} % "
+cd \$CHARM_DIR
+echo \$\$ > $JUJU_DEBUG/hook.pid
+/bin/bash --noprofile --norc
+echo \$? > $JUJU_DEBUG/hook.exit
+END
+chmod +x $JUJU_DEBUG/hook.sh
+
+# Start the session if the client has not yet done so.
+tmux new-session -d -s {tmux_session} 2>&1 | cat > /dev/null || true
+tmux new-window -t {tmux_session} -n {hook_name} "$JUJU_DEBUG/hook.sh"
+
+# If we exit for whatever reason, kill the hook shell.
+exit_handler() {
+    if [ -f $JUJU_DEBUG/hook.pid ]; then
+        pkill -9 -P $(cat $JUJU_DEBUG/hook.pid) 2>/dev/null || true
+        kill -9 $(cat $JUJU_DEBUG/hook.pid) 2>/dev/null || true
+    fi
+}
+trap exit_handler EXIT
+
+# Wait for the hook shell to start, and then for it to exit, giving up
+# if the client goes away.
+while [ ! -f $JUJU_DEBUG/hook.pid ]; do
+    sleep 1
+done
+HOOK_PID=$(cat $JUJU_DEBUG/hook.pid)
+while kill -0 $HOOK_PID 2>/dev/null; do
+    if flock -n {exit_flock} true; then
+        echo "debug-hooks client has gone away" >&2
+        exit 1
+    fi
+    sleep 1
+done
+exit $(cat $JUJU_DEBUG/hook.exit 2>/dev/null || echo 1)
+`
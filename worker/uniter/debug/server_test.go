@@ -0,0 +1,124 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/worker/uniter/debug"
+)
+
+type DebugHooksServerSuite struct {
+	ctx     *debug.HooksContext
+	fakebin string
+	tmuxIn  string
+	tmuxOut string
+}
+
+var _ = Suite(&DebugHooksServerSuite{})
+
+// fakeTmux runs the command given to "tmux new-window" in the background
+// with the contents of $FAKE_TMUX_INPUT as its input, as if an operator
+// had typed it.
+const fakeTmux = `#!/bin/bash
+echo "$@" >> $FAKE_TMUX_LOG
+if [ "$1" = new-window ]; then
+    eval "${@: -1}" < $FAKE_TMUX_INPUT > /dev/null 2>&1 &
+fi
+`
+
+func (s *DebugHooksServerSuite) SetUpTest(c *C) {
+	s.ctx = debug.NewHooksContext("foo/8")
+	s.ctx.FlockDir = c.MkDir()
+	s.fakebin = c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(s.fakebin, "tmux"), []byte(fakeTmux), 0755)
+	c.Assert(err, IsNil)
+	s.tmuxIn = filepath.Join(c.MkDir(), "input")
+	s.tmuxOut = filepath.Join(c.MkDir(), "log")
+}
+
+// lockClient writes the given arguments file and acquires the client's
+// exit lock, as ClientScript does. It returns a function that releases
+// the lock.
+func (s *DebugHooksServerSuite) lockClient(c *C, args string) func() {
+	err := ioutil.WriteFile(s.ctx.ClientFileLock(), []byte(args), 0644)
+	c.Assert(err, IsNil)
+	f, err := os.Create(s.ctx.ClientExitFileLock())
+	c.Assert(err, IsNil)
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	c.Assert(err, IsNil)
+	return func() { f.Close() }
+}
+
+func (s *DebugHooksServerSuite) TestFindSession(c *C) {
+	// No client has ever run.
+	session, err := s.ctx.FindSession()
+	c.Assert(err, IsNil)
+	c.Assert(session, IsNil)
+
+	// A client is attached, debugging all hooks.
+	release := s.lockClient(c, "{}\n")
+	session, err = s.ctx.FindSession()
+	c.Assert(err, IsNil)
+	c.Assert(session, NotNil)
+	c.Assert(session.MatchHook("install"), Equals, true)
+	c.Assert(session.MatchHook("config-changed"), Equals, true)
+
+	// The client has gone away.
+	release()
+	session, err = s.ctx.FindSession()
+	c.Assert(err, IsNil)
+	c.Assert(session, IsNil)
+
+	// A client is attached, debugging specific hooks.
+	release = s.lockClient(c, "hooks: [install, db-relation-joined]\n")
+	defer release()
+	session, err = s.ctx.FindSession()
+	c.Assert(err, IsNil)
+	c.Assert(session, NotNil)
+	c.Assert(session.MatchHook("install"), Equals, true)
+	c.Assert(session.MatchHook("db-relation-joined"), Equals, true)
+	c.Assert(session.MatchHook("config-changed"), Equals, false)
+}
+
+func (s *DebugHooksServerSuite) runHook(c *C, charmDir, input string) error {
+	defer s.lockClient(c, "{}\n")()
+	session, err := s.ctx.FindSession()
+	c.Assert(err, IsNil)
+	c.Assert(session, NotNil)
+	err = ioutil.WriteFile(s.tmuxIn, []byte(input), 0644)
+	c.Assert(err, IsNil)
+	env := []string{
+		"PATH=" + s.fakebin + ":" + os.Getenv("PATH"),
+		"CHARM_DIR=" + charmDir,
+		"JUJU_UNIT_NAME=foo/8",
+		"JUJU_QUOTED=it's",
+		"FAKE_TMUX_INPUT=" + s.tmuxIn,
+		"FAKE_TMUX_LOG=" + s.tmuxOut,
+	}
+	return session.RunHook("config-changed", charmDir, env)
+}
+
+func (s *DebugHooksServerSuite) TestRunHook(c *C) {
+	out := filepath.Join(c.MkDir(), "out")
+	charmDir := c.MkDir()
+	err := s.runHook(c, charmDir, `echo $JUJU_UNIT_NAME $JUJU_QUOTED $PWD > `+out+"\n")
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadFile(out)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "foo/8 it's "+charmDir+"\n")
+	data, err = ioutil.ReadFile(s.tmuxOut)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, "new-session -d -s foo-8\nnew-window -t foo-8 -n config-changed .*/hook.sh\n")
+}
+
+func (s *DebugHooksServerSuite) TestRunHookFails(c *C) {
+	err := s.runHook(c, c.MkDir(), "exit 3\n")
+	c.Assert(err, ErrorMatches, "exit status 3")
+}
@@ -13,6 +13,17 @@ import (
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/tomb"
 	"sort"
+	"time"
+)
+
+var (
+	// leaseDuration is how long the unit holds the leadership of its
+	// service, once claimed, unless the lease is renewed.
+	leaseDuration = 30 * time.Second
+
+	// leaseRenewal is how often the filter claims or renews the
+	// leadership lease.
+	leaseRenewal = 15 * time.Second
 )
 
 // filter collects unit, service, and service config information from separate
@@ -37,6 +48,17 @@ type filter struct {
 	outResolvedOn  chan state.ResolvedMode
 	outRelations   chan []int
 	outRelationsOn chan []int
+	outRun         chan struct{}
+	outRunOn       chan struct{}
+	outAction      chan struct{}
+	outActionOn    chan struct{}
+	outPaused      chan bool
+	outPausedOn    chan bool
+
+	outLeaderElected    chan struct{}
+	outLeaderElectedOn  chan struct{}
+	outLeaderSettings   chan struct{}
+	outLeaderSettingsOn chan struct{}
 
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
@@ -76,9 +98,12 @@ type filter struct {
 	unit             *state.Unit
 	life             state.Life
 	resolved         state.ResolvedMode
+	paused           bool
+	leader           bool
 	service          *state.Service
 	upgradeFrom      serviceCharm
 	upgradeAvailable serviceCharm
+	upgradeAllowed   bool
 	upgrade          *charm.URL
 	relations        []int
 }
@@ -87,23 +112,33 @@ type filter struct {
 // supplied unit.
 func newFilter(st *state.State, unitName string) (*filter, error) {
 	f := &filter{
-		st:                st,
-		outUnitDying:      make(chan struct{}),
-		outConfig:         make(chan struct{}),
-		outConfigOn:       make(chan struct{}),
-		outUpgrade:        make(chan *charm.URL),
-		outUpgradeOn:      make(chan *charm.URL),
-		outResolved:       make(chan state.ResolvedMode),
-		outResolvedOn:     make(chan state.ResolvedMode),
-		outRelations:      make(chan []int),
-		outRelationsOn:    make(chan []int),
-		wantForcedUpgrade: make(chan bool),
-		wantResolved:      make(chan struct{}),
-		discardConfig:     make(chan struct{}),
-		setCharm:          make(chan *charm.URL),
-		didSetCharm:       make(chan struct{}),
-		clearResolved:     make(chan struct{}),
-		didClearResolved:  make(chan struct{}),
+		st:                  st,
+		outUnitDying:        make(chan struct{}),
+		outConfig:           make(chan struct{}),
+		outConfigOn:         make(chan struct{}),
+		outUpgrade:          make(chan *charm.URL),
+		outUpgradeOn:        make(chan *charm.URL),
+		outResolved:         make(chan state.ResolvedMode),
+		outResolvedOn:       make(chan state.ResolvedMode),
+		outRelations:        make(chan []int),
+		outRelationsOn:      make(chan []int),
+		outRun:              make(chan struct{}),
+		outRunOn:            make(chan struct{}),
+		outAction:           make(chan struct{}),
+		outActionOn:         make(chan struct{}),
+		outPaused:           make(chan bool),
+		outPausedOn:         make(chan bool),
+		outLeaderElected:    make(chan struct{}),
+		outLeaderElectedOn:  make(chan struct{}),
+		outLeaderSettings:   make(chan struct{}),
+		outLeaderSettingsOn: make(chan struct{}),
+		wantForcedUpgrade:   make(chan bool),
+		wantResolved:        make(chan struct{}),
+		discardConfig:       make(chan struct{}),
+		setCharm:            make(chan *charm.URL),
+		didSetCharm:         make(chan struct{}),
+		clearResolved:       make(chan struct{}),
+		didClearResolved:    make(chan struct{}),
 	}
 	go func() {
 		defer f.tomb.Done()
@@ -159,6 +194,42 @@ func (f *filter) RelationsEvents() <-chan []int {
 	return f.outRelationsOn
 }
 
+// RunEvents returns a channel that will receive a signal whenever
+// commands are queued to be run in the unit's hook context by "juju run".
+func (f *filter) RunEvents() <-chan struct{} {
+	return f.outRunOn
+}
+
+// ActionEvents returns a channel that will receive a signal whenever
+// actions are queued to be run by the unit with "juju do".
+func (f *filter) ActionEvents() <-chan struct{} {
+	return f.outActionOn
+}
+
+// PausedEvents returns a channel that will receive whether the unit's
+// hooks are paused whenever that changes. While the unit is alive and
+// paused, no upgrade, config or relations events are sent; they are
+// held until the unit is resumed.
+func (f *filter) PausedEvents() <-chan bool {
+	return f.outPausedOn
+}
+
+// LeaderElectedEvents returns a channel that will receive a signal
+// whenever the unit becomes the leader of its service. The filter
+// claims and renews the service's leadership lease on the unit's
+// behalf.
+func (f *filter) LeaderElectedEvents() <-chan struct{} {
+	return f.outLeaderElectedOn
+}
+
+// LeaderSettingsEvents returns a channel that will receive a signal
+// whenever the leader of the unit's service changes the settings it
+// shares with the service's other units. No events are sent while the
+// unit is the leader.
+func (f *filter) LeaderSettingsEvents() <-chan struct{} {
+	return f.outLeaderSettingsOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -271,6 +342,17 @@ func (f *filter) loop(unitName string) (err error) {
 	}()
 	relationsw := f.service.WatchRelations()
 	defer func() { watcher.Stop(relationsw, &f.tomb) }()
+	runw := f.st.WatchRunCommands(f.unit.Tag())
+	defer watcher.Stop(runw, &f.tomb)
+	actionw := f.st.WatchActions(f.unit.Name())
+	defer watcher.Stop(actionw, &f.tomb)
+	leaderSettingsw := f.service.WatchLeaderSettings()
+	defer watcher.Stop(leaderSettingsw, &f.tomb)
+
+	// The initial leader settings event tells us nothing new, so it is
+	// not passed on; the leadership lease is claimed straight away.
+	seenLeaderSettings := false
+	claimLeadership := time.After(0)
 
 	// Config events cannot be meaningfully discarded until one is available;
 	// once we receive the initial change, we unblock discard requests by
@@ -278,6 +360,12 @@ func (f *filter) loop(unitName string) (err error) {
 	var discardConfig chan struct{}
 	for {
 		var ok bool
+		outUpgrade, outConfig, outRelations := f.outUpgrade, f.outConfig, f.outRelations
+		outLeaderElected, outLeaderSettings := f.outLeaderElected, f.outLeaderSettings
+		if f.paused && f.life == state.Alive {
+			outUpgrade, outConfig, outRelations = nil, nil, nil
+			outLeaderElected, outLeaderSettings = nil, nil
+		}
 		select {
 		case <-f.tomb.Dying():
 			return tomb.ErrDying
@@ -324,21 +412,69 @@ func (f *filter) loop(unitName string) (err error) {
 				}
 			}
 			f.relationsChanged(ids)
+		case ids, ok := <-runw.Changes():
+			log.Debugf("worker/uniter/filter: got run commands change")
+			if !ok {
+				return watcher.MustErr(runw)
+			}
+			if len(ids) != 0 {
+				log.Debugf("worker/uniter/filter: preparing new run event")
+				f.outRun = f.outRunOn
+			}
+		case ids, ok := <-actionw.Changes():
+			log.Debugf("worker/uniter/filter: got actions change")
+			if !ok {
+				return watcher.MustErr(actionw)
+			}
+			if len(ids) != 0 {
+				log.Debugf("worker/uniter/filter: preparing new action event")
+				f.outAction = f.outActionOn
+			}
+		case _, ok = <-leaderSettingsw.Changes():
+			log.Debugf("worker/uniter/filter: got leader settings change")
+			if !ok {
+				return watcher.MustErr(leaderSettingsw)
+			}
+			if seenLeaderSettings && !f.leader {
+				log.Debugf("worker/uniter/filter: preparing new leader settings event")
+				f.outLeaderSettings = f.outLeaderSettingsOn
+			}
+			seenLeaderSettings = true
+		case <-claimLeadership:
+			if err = f.claimLeadership(); err != nil {
+				return err
+			}
+			claimLeadership = time.After(leaseRenewal)
 
 		// Send events on active out chans.
-		case f.outUpgrade <- f.upgrade:
+		case outUpgrade <- f.upgrade:
 			log.Debugf("worker/uniter/filter: sent upgrade event")
 			f.outUpgrade = nil
 		case f.outResolved <- f.resolved:
 			log.Debugf("worker/uniter/filter: sent resolved event")
 			f.outResolved = nil
-		case f.outConfig <- nothing:
+		case outConfig <- nothing:
 			log.Debugf("worker/uniter/filter: sent config event")
 			f.outConfig = nil
-		case f.outRelations <- f.relations:
+		case outRelations <- f.relations:
 			log.Debugf("worker/uniter/filter: sent relations event")
 			f.outRelations = nil
 			f.relations = nil
+		case f.outRun <- nothing:
+			log.Debugf("worker/uniter/filter: sent run event")
+			f.outRun = nil
+		case f.outAction <- nothing:
+			log.Debugf("worker/uniter/filter: sent action event")
+			f.outAction = nil
+		case f.outPaused <- f.paused:
+			log.Debugf("worker/uniter/filter: sent paused event")
+			f.outPaused = nil
+		case outLeaderElected <- nothing:
+			log.Debugf("worker/uniter/filter: sent leader elected event")
+			f.outLeaderElected = nil
+		case outLeaderSettings <- nothing:
+			log.Debugf("worker/uniter/filter: sent leader settings event")
+			f.outLeaderSettings = nil
 
 		// Handle explicit requests.
 		case curl := <-f.setCharm:
@@ -428,6 +564,10 @@ func (f *filter) unitChanged() error {
 			return worker.ErrTerminateAgent
 		}
 	}
+	if paused := f.unit.IsPaused(); paused != f.paused {
+		f.paused = paused
+		f.outPaused = f.outPausedOn
+	}
 	if resolved := f.unit.Resolved(); resolved != f.resolved {
 		f.resolved = resolved
 		if f.resolved != state.ResolvedNone {
@@ -447,6 +587,7 @@ func (f *filter) serviceChanged() error {
 	}
 	url, force := f.service.CharmURL()
 	f.upgradeAvailable = serviceCharm{url, force}
+	f.upgradeAllowed = f.service.CharmUpgradeAllowed(f.unit.Name())
 	switch f.service.Life() {
 	case state.Dying:
 		if err := f.unit.Destroy(); err != nil {
@@ -472,6 +613,11 @@ func (f *filter) upgradeChanged() (err error) {
 		f.outUpgrade = nil
 		return nil
 	}
+	if !f.upgradeAllowed {
+		log.Debugf("worker/uniter/filter: charm check skipped, upgrade not yet allowed")
+		f.outUpgrade = nil
+		return nil
+	}
 	if *f.upgradeAvailable.url != *f.upgradeFrom.url {
 		if f.upgradeAvailable.force || !f.upgradeFrom.force {
 			log.Debugf("worker/uniter/filter: preparing new upgrade event")
@@ -487,6 +633,26 @@ func (f *filter) upgradeChanged() (err error) {
 	return nil
 }
 
+// claimLeadership claims the leadership of the unit's service, or
+// renews the unit's lease if it is already the leader, and prepares a
+// leader elected event when the unit becomes the leader.
+func (f *filter) claimLeadership() error {
+	leader, err := f.unit.ClaimLeadership(leaseDuration)
+	if err != nil {
+		return err
+	}
+	if leader && !f.leader {
+		log.Noticef("worker/uniter/filter: unit is the leader")
+		f.outLeaderElected = f.outLeaderElectedOn
+		f.outLeaderSettings = nil
+	} else if !leader && f.leader {
+		log.Noticef("worker/uniter/filter: unit is no longer the leader")
+		f.outLeaderElected = nil
+	}
+	f.leader = leader
+	return nil
+}
+
 // relationsChanged responds to service relation changes.
 func (f *filter) relationsChanged(ids []int) {
 outer:
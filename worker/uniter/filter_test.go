@@ -279,6 +279,67 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *C) {
 	assertNoChange()
 }
 
+func (s *FilterSuite) TestRestrictedCharmUpgradeEvents(c *C) {
+	oldCharm := s.AddTestingCharm(c, "upgrade1")
+	svc, err := s.State.AddService("upgradetest", oldCharm)
+	c.Assert(err, IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+
+	f, err := newFilter(s.State, unit.Name())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+	err = f.SetCharm(oldCharm.URL())
+	c.Assert(err, IsNil)
+	f.WantUpgradeEvent(false)
+
+	assertNoChange := func() {
+		s.State.StartSync()
+		select {
+		case sch := <-f.UpgradeEvents():
+			c.Fatalf("unexpected %#v", sch)
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertChange := func(url *charm.URL) {
+		s.State.Sync()
+		select {
+		case upgradeCharm := <-f.UpgradeEvents():
+			c.Assert(upgradeCharm, DeepEquals, url)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+
+	// Change the service's charm with a restriction; no events.
+	newCharm := s.AddTestingCharm(c, "upgrade2")
+	err = svc.SetCharmRestricted(newCharm, false)
+	c.Assert(err, IsNil)
+	assertNoChange()
+
+	// Allowing another unit to upgrade changes nothing.
+	err = svc.AllowCharmUpgrade("upgradetest/99")
+	c.Assert(err, IsNil)
+	assertNoChange()
+
+	// Allowing this unit to upgrade generates an event.
+	err = svc.AllowCharmUpgrade(unit.Name())
+	c.Assert(err, IsNil)
+	assertChange(newCharm.URL())
+	assertNoChange()
+
+	// Restricting again withdraws the event.
+	err = svc.SetCharmRestricted(newCharm, false)
+	c.Assert(err, IsNil)
+	assertNoChange()
+
+	// Lifting the restriction generates an event.
+	err = svc.SetCharm(newCharm, false)
+	c.Assert(err, IsNil)
+	assertChange(newCharm.URL())
+	assertNoChange()
+}
+
 func (s *FilterSuite) TestConfigEvents(c *C) {
 	f, err := newFilter(s.State, s.unit.Name())
 	c.Assert(err, IsNil)
@@ -440,6 +501,134 @@ func (s *FilterSuite) TestRelationsEvents(c *C) {
 	assertChange([]int{0, 2})
 }
 
+func (s *FilterSuite) TestPausedEvents(c *C) {
+	f, err := newFilter(s.State, s.unit.Name())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+	err = f.SetCharm(s.wpcharm.URL())
+	c.Assert(err, IsNil)
+
+	assertNoChange := func() {
+		s.State.StartSync()
+		select {
+		case paused := <-f.PausedEvents():
+			c.Fatalf("unexpected paused event %v", paused)
+		case <-f.ConfigEvents():
+			c.Fatalf("unexpected config event")
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertPaused := func(expect bool) {
+		s.State.StartSync()
+		select {
+		case paused := <-f.PausedEvents():
+			c.Assert(paused, Equals, expect)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+	assertConfig := func() {
+		s.State.StartSync()
+		select {
+		case _, ok := <-f.ConfigEvents():
+			c.Assert(ok, Equals, true)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+		assertNoChange()
+	}
+	assertConfig()
+
+	// Pause the unit; config events are held back.
+	err = s.unit.SetPaused()
+	c.Assert(err, IsNil)
+	assertPaused(true)
+	err = s.wordpress.UpdateConfigSettings(charm.Settings{"blog-title": "paused"})
+	c.Assert(err, IsNil)
+	assertNoChange()
+
+	// Resume it; the held event is delivered.
+	err = s.unit.ClearPaused()
+	c.Assert(err, IsNil)
+	assertPaused(false)
+	assertConfig()
+
+	// A new filter reports a unit that is already paused.
+	err = s.unit.SetPaused()
+	c.Assert(err, IsNil)
+	f, err = newFilter(s.State, s.unit.Name())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+	assertPaused(true)
+	assertNoChange()
+}
+
+func (s *FilterSuite) TestLeaderEvents(c *C) {
+	// Make another unit the leader before the filter starts.
+	other, err := s.wordpress.AddUnit()
+	c.Assert(err, IsNil)
+	pinger, err := other.SetAgentAlive()
+	c.Assert(err, IsNil)
+	defer pinger.Stop()
+	s.State.Sync()
+	ok, err := other.ClaimLeadership(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	defer func(d time.Duration) { leaseRenewal = d }(leaseRenewal)
+	leaseRenewal = 50 * time.Millisecond
+	f, err := newFilter(s.State, s.unit.Name())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+
+	assertNoChange := func() {
+		s.State.StartSync()
+		select {
+		case <-f.LeaderElectedEvents():
+			c.Fatalf("unexpected leader elected event")
+		case <-f.LeaderSettingsEvents():
+			c.Fatalf("unexpected leader settings event")
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertLeaderSettings := func() {
+		s.State.StartSync()
+		select {
+		case _, ok := <-f.LeaderSettingsEvents():
+			c.Assert(ok, Equals, true)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+		assertNoChange()
+	}
+	assertNoChange()
+
+	// The leader changes its settings.
+	err = other.SetLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, IsNil)
+	assertLeaderSettings()
+
+	// The leader's agent dies, and the unit takes over.
+	err = pinger.Kill()
+	c.Assert(err, IsNil)
+	s.State.StartSync()
+	select {
+	case _, ok := <-f.LeaderElectedEvents():
+		c.Assert(ok, Equals, true)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out")
+	}
+	assertNoChange()
+	leader, err := s.wordpress.Leader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, s.unit.Name())
+
+	// The unit's own settings changes are not reported to it.
+	err = s.unit.SetLeaderSettings(map[string]string{"foo": "baz"})
+	c.Assert(err, IsNil)
+	assertNoChange()
+}
+
 func (s *FilterSuite) addRelation(c *C) *state.Relation {
 	if s.mysqlcharm == nil {
 		s.mysqlcharm = s.AddTestingCharm(c, "mysql")
@@ -41,7 +41,8 @@ func (hi Info) Validate() error {
 			return fmt.Errorf("%q hook requires a remote unit", hi.Kind)
 		}
 		fallthrough
-	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken:
+	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken,
+		hooks.LeaderElected, hooks.LeaderSettingsChanged, hooks.UpdateStatus:
 		return nil
 	}
 	return fmt.Errorf("unknown hook kind %q", hi.Kind)
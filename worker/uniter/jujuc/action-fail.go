@@ -0,0 +1,45 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"launchpad.net/juju-core/cmd"
+)
+
+// ActionFailCommand implements the action-fail command.
+type ActionFailCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Message string
+}
+
+func NewActionFailCommand(ctx Context) cmd.Command {
+	return &ActionFailCommand{ctx: ctx}
+}
+
+func (c *ActionFailCommand) Info() *cmd.Info {
+	doc := `
+The running action is reported as failed, with the given message, even if
+it exits successfully. Results set with action-set are still reported.
+`
+	return &cmd.Info{
+		Name:    "action-fail",
+		Args:    `["<failure message>"]`,
+		Purpose: "set action fail status with message",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionFailCommand) Init(args []string) error {
+	c.Message = "action failed without reason given"
+	if len(args) > 0 {
+		c.Message = args[0]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ActionFailCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.SetActionFailed(c.Message)
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionFailSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionFailSuite{})
+
+var actionFailTests = []struct {
+	args    []string
+	message string
+}{
+	{nil, "action failed without reason given"},
+	{[]string{"disk full"}, "disk full"},
+}
+
+func (s *ActionFailSuite) TestFail(c *C) {
+	for i, t := range actionFailTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.actionParams = map[string]interface{}{}
+		com, err := jujuc.NewCommand(hctx, "action-fail")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(hctx.actionFailed, Equals, t.message)
+	}
+}
+
+func (s *ActionFailSuite) TestBadArgs(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-fail")
+	c.Assert(err, IsNil)
+	err = testing.InitCommand(com, []string{"disk", "full"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["full"\]`)
+}
+
+func (s *ActionFailSuite) TestNotRunningAction(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-fail")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
@@ -0,0 +1,68 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionGetSuite{})
+
+func (s *ActionGetSuite) GetActionContext(c *C) *Context {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.actionParams = map[string]interface{}{
+		"outfile": "foo.bz2",
+		"level":   int64(9),
+	}
+	return hctx
+}
+
+var actionGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "level: 9\noutfile: foo.bz2\n"},
+	{[]string{"--format", "json"}, `{"level":9,"outfile":"foo.bz2"}` + "\n"},
+	{[]string{"outfile"}, "foo.bz2\n"},
+	{[]string{"level"}, "9\n"},
+	{[]string{"missing"}, ""},
+	{[]string{"--format", "json", "missing"}, "null\n"},
+}
+
+func (s *ActionGetSuite) TestOutput(c *C) {
+	for i, t := range actionGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetActionContext(c), "action-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *ActionGetSuite) TestNotRunningAction(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-get")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
+
+func (s *ActionGetSuite) TestBadArgs(c *C) {
+	com, err := jujuc.NewCommand(s.GetActionContext(c), "action-get")
+	c.Assert(err, IsNil)
+	err = testing.InitCommand(com, []string{"outfile", "level"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["level"\]`)
+}
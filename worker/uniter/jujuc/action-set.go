@@ -0,0 +1,59 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// ActionSetCommand implements the action-set command.
+type ActionSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Results map[string]string
+}
+
+func NewActionSetCommand(ctx Context) cmd.Command {
+	return &ActionSetCommand{ctx: ctx, Results: map[string]string{}}
+}
+
+func (c *ActionSetCommand) Info() *cmd.Info {
+	doc := `
+The given values are reported as the results of the running action, and
+may be seen with "juju action-result". Setting a key more than once
+replaces its value.
+`
+	return &cmd.Info{
+		Name:    "action-set",
+		Args:    "key=value [key=value ...]",
+		Purpose: "set action results",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(`expected "key=value" parameters, got nothing`)
+	}
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Results[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *ActionSetCommand) Run(ctx *cmd.Context) error {
+	for k, v := range c.Results {
+		if err := c.ctx.SetActionResult(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
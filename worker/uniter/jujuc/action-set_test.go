@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionSetSuite{})
+
+func (s *ActionSetSuite) TestSet(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.actionParams = map[string]interface{}{}
+	com, err := jujuc.NewCommand(hctx, "action-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"size=42", "path=/tmp/x=y", "empty="})
+	c.Assert(code, Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), Equals, "")
+	c.Assert(hctx.actionResults, DeepEquals, map[string]interface{}{
+		"size":  "42",
+		"path":  "/tmp/x=y",
+		"empty": "",
+	})
+}
+
+var actionSetInitErrorTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, `expected "key=value" parameters, got nothing`},
+	{[]string{"size"}, `expected "key=value", got "size"`},
+	{[]string{"=42"}, `expected "key=value", got "=42"`},
+}
+
+func (s *ActionSetSuite) TestInitErrors(c *C) {
+	for i, t := range actionSetInitErrorTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-set")
+		c.Assert(err, IsNil)
+		err = testing.InitCommand(com, t.args)
+		c.Assert(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *ActionSetSuite) TestNotRunningAction(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"size=42"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
@@ -6,6 +6,7 @@ package jujuc
 import (
 	"fmt"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/state/api/params"
 	"strconv"
 	"strings"
 )
@@ -23,14 +24,14 @@ type Context interface {
 	// PrivateAddress returns the executing unit's private address.
 	PrivateAddress() (string, bool)
 
-	// OpenPort marks the supplied port for opening when the executing unit's
-	// service is exposed.
-	OpenPort(protocol string, port int) error
+	// OpenPorts marks the supplied port range for opening when the
+	// executing unit's service is exposed. The range must not overlap
+	// one opened by a co-located unit.
+	OpenPorts(protocol string, fromPort, toPort int) error
 
-	// ClosePort ensures the supplied port is closed even when the executing
-	// unit's service is exposed (unless it is opened separately by a co-
-	// located unit).
-	ClosePort(protocol string, port int) error
+	// ClosePorts ensures the supplied port range is closed even when the
+	// executing unit's service is exposed.
+	ClosePorts(protocol string, fromPort, toPort int) error
 
 	// Config returns the current service configuration of the executing unit.
 	ConfigSettings() (charm.Settings, error)
@@ -50,6 +51,41 @@ type Context interface {
 	// RelationIds returns the ids of all relations the executing unit is
 	// currently participating in.
 	RelationIds() []int
+
+	// ActionParams returns the parameters of the executing action, or an
+	// error if the context is not running an action.
+	ActionParams() (map[string]interface{}, error)
+
+	// SetActionResult records a value to be reported as a result of the
+	// executing action, or returns an error if the context is not running
+	// an action.
+	SetActionResult(key string, value interface{}) error
+
+	// SetActionFailed records that the executing action has failed, and
+	// why, or returns an error if the context is not running an action.
+	SetActionFailed(message string) error
+
+	// WorkloadStatus returns the status of the executing unit's workload,
+	// and the message set with it.
+	WorkloadStatus() (params.Status, string, error)
+
+	// SetWorkloadStatus sets the status of the executing unit's workload,
+	// with a message explaining it.
+	SetWorkloadStatus(status params.Status, message string) error
+
+	// IsLeader returns whether the executing unit is the leader of its
+	// service.
+	IsLeader() (bool, error)
+
+	// LeaderSettings returns the settings that the leader of the
+	// executing unit's service shares with the service's other units.
+	LeaderSettings() (map[string]string, error)
+
+	// SetLeaderSettings updates the settings that the leader of the
+	// executing unit's service shares with the service's other units;
+	// settings with empty values are removed. It returns an error if
+	// the executing unit is not the leader.
+	SetLeaderSettings(settings map[string]string) error
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
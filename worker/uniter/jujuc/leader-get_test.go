@@ -0,0 +1,51 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type LeaderGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&LeaderGetSuite{})
+
+var leaderGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "foo: bar\n"},
+	{[]string{"--format", "json"}, `{"foo":"bar"}` + "\n"},
+	{[]string{"foo"}, "bar\n"},
+	{[]string{"missing"}, ""},
+	{[]string{"--format", "json", "missing"}, "null\n"},
+}
+
+func (s *LeaderGetSuite) TestLeaderGet(c *C) {
+	for i, t := range leaderGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.leaderSettings = map[string]string{"foo": "bar"}
+		com, err := jujuc.NewCommand(hctx, "leader-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *LeaderGetSuite) TestBadArgs(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "leader-get")
+	c.Assert(err, IsNil)
+	err = testing.InitCommand(com, []string{"foo", "bar"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["bar"\]`)
+}
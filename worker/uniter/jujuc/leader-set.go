@@ -0,0 +1,55 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// LeaderSetCommand implements the leader-set command.
+type LeaderSetCommand struct {
+	cmd.CommandBase
+	ctx      Context
+	Settings map[string]string
+}
+
+func NewLeaderSetCommand(ctx Context) cmd.Command {
+	return &LeaderSetCommand{ctx: ctx, Settings: map[string]string{}}
+}
+
+func (c *LeaderSetCommand) Info() *cmd.Info {
+	doc := `
+Sets settings that the leader of the unit's service shares with the
+service's other units, which can read them with leader-get. Setting a
+key to an empty value removes it. Only the leader may set them; the
+other units run their leader-settings-changed hooks when they change.
+`
+	return &cmd.Info{
+		Name:    "leader-set",
+		Args:    "key=value [key=value ...]",
+		Purpose: "set service leader settings",
+		Doc:     doc,
+	}
+}
+
+func (c *LeaderSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(`expected "key=value" parameters, got nothing`)
+	}
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Settings[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *LeaderSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.SetLeaderSettings(c.Settings)
+}
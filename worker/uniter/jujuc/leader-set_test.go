@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type LeaderSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&LeaderSetSuite{})
+
+func (s *LeaderSetSuite) TestLeaderSet(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.leader = true
+	hctx.leaderSettings = map[string]string{"foo": "bar", "baz": "qux"}
+	com, err := jujuc.NewCommand(hctx, "leader-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"foo=", "baz=a=b", "new=value"})
+	c.Assert(code, Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), Equals, "")
+	c.Assert(bufferString(ctx.Stderr), Equals, "")
+	c.Assert(hctx.leaderSettings, DeepEquals, map[string]string{"baz": "a=b", "new": "value"})
+}
+
+func (s *LeaderSetSuite) TestNotLeader(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "leader-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"foo=bar"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: unit is not the leader\n")
+	c.Assert(hctx.leaderSettings, HasLen, 0)
+}
+
+var badLeaderSetTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, `expected "key=value" parameters, got nothing`},
+	{[]string{"foo"}, `expected "key=value", got "foo"`},
+	{[]string{"=bar"}, `expected "key=value", got "=bar"`},
+}
+
+func (s *LeaderSetSuite) TestBadArgs(c *C) {
+	for i, t := range badLeaderSetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "leader-set")
+		c.Assert(err, IsNil)
+		err = testing.InitCommand(com, t.args)
+		c.Assert(err, ErrorMatches, t.err)
+	}
+}
@@ -12,7 +12,7 @@ import (
 	"strings"
 )
 
-const portFormat = "<port>[/<protocol>]"
+const portFormat = "<port>[-<port>][/<protocol>] | icmp"
 
 // portCommand implements the open-port and close-port commands.
 type portCommand struct {
@@ -20,7 +20,8 @@ type portCommand struct {
 	info       *cmd.Info
 	action     func(*portCommand) error
 	Protocol   string
-	Port       int
+	FromPort   int
+	ToPort     int
 	formatFlag string // deprecated
 }
 
@@ -32,6 +33,17 @@ func badPort(value interface{}) error {
 	return fmt.Errorf(`port must be in the range [1, 65535]; got "%v"`, value)
 }
 
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badPort(value)
+	}
+	if port < 1 || port > 65535 {
+		return 0, badPort(port)
+	}
+	return port, nil
+}
+
 func (c *portCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.formatFlag, "format", "", "deprecated format flag")
 }
@@ -40,16 +52,31 @@ func (c *portCommand) Init(args []string) error {
 	if args == nil {
 		return errors.New("no port specified")
 	}
+	if strings.ToLower(args[0]) == "icmp" {
+		// ICMP has no ports.
+		c.Protocol, c.FromPort, c.ToPort = "icmp", -1, -1
+		return cmd.CheckEmpty(args[1:])
+	}
 	parts := strings.Split(args[0], "/")
 	if len(parts) > 2 {
 		return fmt.Errorf("expected %s; got %q", portFormat, args[0])
 	}
-	port, err := strconv.Atoi(parts[0])
+	ports := strings.Split(parts[0], "-")
+	if len(ports) > 2 {
+		return fmt.Errorf("expected %s; got %q", portFormat, args[0])
+	}
+	fromPort, err := parsePort(ports[0])
 	if err != nil {
-		return badPort(parts[0])
+		return err
 	}
-	if port < 1 || port > 65535 {
-		return badPort(port)
+	toPort := fromPort
+	if len(ports) == 2 {
+		if toPort, err = parsePort(ports[1]); err != nil {
+			return err
+		}
+		if toPort < fromPort {
+			return fmt.Errorf("invalid port range %q", parts[0])
+		}
 	}
 	protocol := "tcp"
 	if len(parts) == 2 {
@@ -58,7 +85,8 @@ func (c *portCommand) Init(args []string) error {
 			return fmt.Errorf(`protocol must be "tcp" or "udp"; got %q`, protocol)
 		}
 	}
-	c.Port = port
+	c.FromPort = fromPort
+	c.ToPort = toPort
 	c.Protocol = protocol
 	return cmd.CheckEmpty(args[1:])
 }
@@ -73,15 +101,18 @@ func (c *portCommand) Run(ctx *cmd.Context) error {
 var openPortInfo = &cmd.Info{
 	Name:    "open-port",
 	Args:    portFormat,
-	Purpose: "register a port to open",
-	Doc:     "The port will only be open while the service is exposed.",
+	Purpose: "register a port or range of ports to open",
+	Doc: `
+The ports will only be open while the service is exposed. A range of
+ports, such as 10000-20000/udp, is opened as a whole; pass icmp to allow
+ICMP traffic.`,
 }
 
 func NewOpenPortCommand(ctx Context) cmd.Command {
 	return &portCommand{
 		info: openPortInfo,
 		action: func(c *portCommand) error {
-			return ctx.OpenPort(c.Protocol, c.Port)
+			return ctx.OpenPorts(c.Protocol, c.FromPort, c.ToPort)
 		},
 	}
 }
@@ -89,14 +120,14 @@ func NewOpenPortCommand(ctx Context) cmd.Command {
 var closePortInfo = &cmd.Info{
 	Name:    "close-port",
 	Args:    portFormat,
-	Purpose: "ensure a port is always closed",
+	Purpose: "ensure a port or range of ports is always closed",
 }
 
 func NewClosePortCommand(ctx Context) cmd.Command {
 	return &portCommand{
 		info: closePortInfo,
 		action: func(c *portCommand) error {
-			return ctx.ClosePort(c.Protocol, c.Port)
+			return ctx.ClosePorts(c.Protocol, c.FromPort, c.ToPort)
 		},
 	}
 }
@@ -26,6 +26,10 @@ var portsTests = []struct {
 	{[]string{"close-port", "80/TCP"}, set.NewStrings("99/tcp")},
 	{[]string{"open-port", "123/udp"}, set.NewStrings("99/tcp", "123/udp")},
 	{[]string{"close-port", "9999/UDP"}, set.NewStrings("99/tcp", "123/udp")},
+	{[]string{"open-port", "10000-20000/udp"}, set.NewStrings("99/tcp", "123/udp", "10000-20000/udp")},
+	{[]string{"open-port", "ICMP"}, set.NewStrings("99/tcp", "123/udp", "10000-20000/udp", "icmp")},
+	{[]string{"close-port", "10000-20000/udp"}, set.NewStrings("99/tcp", "123/udp", "icmp")},
+	{[]string{"close-port", "icmp"}, set.NewStrings("99/tcp", "123/udp")},
 }
 
 func (s *PortsSuite) TestOpenClose(c *C) {
@@ -51,7 +55,11 @@ var badPortsTests = []struct {
 	{[]string{"65536"}, `port must be in the range \[1, 65535\]; got "65536"`},
 	{[]string{"two"}, `port must be in the range \[1, 65535\]; got "two"`},
 	{[]string{"80/http"}, `protocol must be "tcp" or "udp"; got "http"`},
-	{[]string{"blah/blah/blah"}, `expected <port>\[/<protocol>\]; got "blah/blah/blah"`},
+	{[]string{"blah/blah/blah"}, `expected <port>\[-<port>\]\[/<protocol>\] \| icmp; got "blah/blah/blah"`},
+	{[]string{"1-2-3"}, `expected <port>\[-<port>\]\[/<protocol>\] \| icmp; got "1-2-3"`},
+	{[]string{"10-70000"}, `port must be in the range \[1, 65535\]; got "70000"`},
+	{[]string{"20-10/udp"}, `invalid port range "20-10"`},
+	{[]string{"icmp", "haha"}, `unrecognized args: \["haha"\]`},
 	{[]string{"123", "haha"}, `unrecognized args: \["haha"\]`},
 }
 
@@ -73,17 +81,19 @@ func (s *PortsSuite) TestHelp(c *C) {
 	c.Assert(err, IsNil)
 	flags := testing.NewFlagSet()
 	c.Assert(string(open.Info().Help(flags)), Equals, `
-usage: open-port <port>[/<protocol>]
-purpose: register a port to open
+usage: open-port <port>[-<port>][/<protocol>] | icmp
+purpose: register a port or range of ports to open
 
-The port will only be open while the service is exposed.
+The ports will only be open while the service is exposed. A range of
+ports, such as 10000-20000/udp, is opened as a whole; pass icmp to allow
+ICMP traffic.
 `[1:])
 
 	close, err := jujuc.NewCommand(hctx, "close-port")
 	c.Assert(err, IsNil)
 	c.Assert(string(close.Info().Help(flags)), Equals, `
-usage: close-port <port>[/<protocol>]
-purpose: ensure a port is always closed
+usage: close-port <port>[-<port>][/<protocol>] | icmp
+purpose: ensure a port or range of ports is always closed
 `[1:])
 }
 
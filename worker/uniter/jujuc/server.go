@@ -21,14 +21,22 @@ import (
 
 // newCommands maps Command names to initializers.
 var newCommands = map[string]func(Context) cmd.Command{
+	"action-fail":   NewActionFailCommand,
+	"action-get":    NewActionGetCommand,
+	"action-set":    NewActionSetCommand,
 	"close-port":    NewClosePortCommand,
 	"config-get":    NewConfigGetCommand,
+	"is-leader":     NewIsLeaderCommand,
 	"juju-log":      NewJujuLogCommand,
+	"leader-get":    NewLeaderGetCommand,
+	"leader-set":    NewLeaderSetCommand,
 	"open-port":     NewOpenPortCommand,
 	"relation-get":  NewRelationGetCommand,
 	"relation-ids":  NewRelationIdsCommand,
 	"relation-list": NewRelationListCommand,
 	"relation-set":  NewRelationSetCommand,
+	"status-get":    NewStatusGetCommand,
+	"status-set":    NewStatusSetCommand,
 	"unit-get":      NewUnitGetCommand,
 }
 
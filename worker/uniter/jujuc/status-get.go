@@ -0,0 +1,58 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// StatusGetCommand implements the status-get command.
+type StatusGetCommand struct {
+	cmd.CommandBase
+	ctx         Context
+	IncludeData bool
+	out         cmd.Output
+}
+
+func NewStatusGetCommand(ctx Context) cmd.Command {
+	return &StatusGetCommand{ctx: ctx}
+}
+
+func (c *StatusGetCommand) Info() *cmd.Info {
+	doc := `
+Prints the status of the unit's workload, as last set with status-set.
+If --include-data is set, the message set with the status is printed
+too.
+`
+	return &cmd.Info{
+		Name:    "status-get",
+		Purpose: "print workload status",
+		Doc:     doc,
+	}
+}
+
+func (c *StatusGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.BoolVar(&c.IncludeData, "include-data", false, "print the status message too")
+}
+
+func (c *StatusGetCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *StatusGetCommand) Run(ctx *cmd.Context) error {
+	status, message, err := c.ctx.WorkloadStatus()
+	if err != nil {
+		return err
+	}
+	if !c.IncludeData {
+		return c.out.Write(ctx, string(status))
+	}
+	return c.out.Write(ctx, map[string]interface{}{
+		"status":  string(status),
+		"message": message,
+	})
+}
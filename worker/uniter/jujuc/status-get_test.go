@@ -0,0 +1,61 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type StatusGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&StatusGetSuite{})
+
+var statusGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "blocked\n"},
+	{[]string{"--format", "json"}, `"blocked"` + "\n"},
+	{[]string{"--include-data"}, "message: need a database\nstatus: blocked\n"},
+	{[]string{"--include-data", "--format", "json"}, `{"message":"need a database","status":"blocked"}` + "\n"},
+}
+
+func (s *StatusGetSuite) TestStatusGet(c *C) {
+	for i, t := range statusGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		err := hctx.SetWorkloadStatus(params.StatusBlocked, "need a database")
+		c.Assert(err, IsNil)
+		com, err := jujuc.NewCommand(hctx, "status-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *StatusGetSuite) TestStatusGetUnknown(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "status-get")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Assert(code, Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), Equals, "unknown\n")
+}
+
+func (s *StatusGetSuite) TestUnknownArg(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "status-get")
+	c.Assert(err, IsNil)
+	err = testing.InitCommand(com, []string{"blah"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["blah"\]`)
+}
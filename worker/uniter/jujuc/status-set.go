@@ -0,0 +1,66 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"errors"
+	"fmt"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// StatusSetCommand implements the status-set command.
+type StatusSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Status  params.Status
+	Message string
+}
+
+func NewStatusSetCommand(ctx Context) cmd.Command {
+	return &StatusSetCommand{ctx: ctx}
+}
+
+func (c *StatusSetCommand) Info() *cmd.Info {
+	doc := `
+Sets the status of the unit's workload, which is shown by juju status
+alongside the status of the unit's agent. The status must be one of:
+
+    maintenance  the workload is being installed, configured or changed
+    blocked      the workload needs an operator to act, e.g. to add a relation
+    waiting      the workload is waiting for something else, e.g. a related service
+    active       the workload is ready and providing its service
+
+The message, if given, explains the status to the operator.
+`
+	return &cmd.Info{
+		Name:    "status-set",
+		Args:    "<maintenance|blocked|waiting|active> [<message>]",
+		Purpose: "set workload status",
+		Doc:     doc,
+	}
+}
+
+func (c *StatusSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no status specified")
+	}
+	switch status := params.Status(args[0]); status {
+	case params.StatusMaintenance, params.StatusBlocked, params.StatusWaiting, params.StatusActive:
+		c.Status = status
+	default:
+		return fmt.Errorf("invalid status %q, expected one of maintenance, blocked, waiting or active", args[0])
+	}
+	args = args[1:]
+	if len(args) > 0 {
+		c.Message = args[0]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *StatusSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.SetWorkloadStatus(c.Status, c.Message)
+}
@@ -0,0 +1,66 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type StatusSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&StatusSetSuite{})
+
+var statusSetTests = []struct {
+	args    []string
+	status  params.Status
+	message string
+}{
+	{[]string{"maintenance"}, params.StatusMaintenance, ""},
+	{[]string{"blocked", "need a database"}, params.StatusBlocked, "need a database"},
+	{[]string{"waiting", "waiting for the database"}, params.StatusWaiting, "waiting for the database"},
+	{[]string{"active", "ready"}, params.StatusActive, "ready"},
+}
+
+func (s *StatusSetSuite) TestStatusSet(c *C) {
+	for i, t := range statusSetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "status-set")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stdout), Equals, "")
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(hctx.workloadStatus, Equals, t.status)
+		c.Assert(hctx.workloadMessage, Equals, t.message)
+	}
+}
+
+var badStatusSetTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, "no status specified"},
+	{[]string{"started"}, `invalid status "started", expected one of maintenance, blocked, waiting or active`},
+	{[]string{"unknown"}, `invalid status "unknown", expected one of maintenance, blocked, waiting or active`},
+	{[]string{"blocked", "need", "a database"}, `unrecognized args: \["a database"\]`},
+}
+
+func (s *StatusSetSuite) TestBadArgs(c *C) {
+	for i, t := range badStatusSetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "status-set")
+		c.Assert(err, IsNil)
+		err = testing.InitCommand(com, t.args)
+		c.Assert(err, ErrorMatches, t.err)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"io"
 	. "launchpad.net/gocheck"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/utils/set"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"sort"
@@ -73,10 +75,19 @@ func setSettings(c *C, ru *state.RelationUnit, settings map[string]interface{})
 }
 
 type Context struct {
-	ports  set.Strings
-	relid  int
-	remote string
-	rels   map[int]*ContextRelation
+	ports         set.Strings
+	relid         int
+	remote        string
+	rels          map[int]*ContextRelation
+	actionParams  map[string]interface{}
+	actionResults map[string]interface{}
+	actionFailed  string
+
+	workloadStatus  params.Status
+	workloadMessage string
+
+	leader         bool
+	leaderSettings map[string]string
 }
 
 func (c *Context) UnitName() string {
@@ -91,13 +102,15 @@ func (c *Context) PrivateAddress() (string, bool) {
 	return "192.168.0.99", true
 }
 
-func (c *Context) OpenPort(protocol string, port int) error {
-	c.ports.Add(fmt.Sprintf("%d/%s", port, protocol))
+func (c *Context) OpenPorts(protocol string, fromPort, toPort int) error {
+	ports := instance.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	c.ports.Add(ports.String())
 	return nil
 }
 
-func (c *Context) ClosePort(protocol string, port int) error {
-	c.ports.Remove(fmt.Sprintf("%d/%s", port, protocol))
+func (c *Context) ClosePorts(protocol string, fromPort, toPort int) error {
+	ports := instance.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	c.ports.Remove(ports.String())
 	return nil
 }
 
@@ -132,6 +145,74 @@ func (c *Context) RelationIds() []int {
 	return ids
 }
 
+func (c *Context) ActionParams() (map[string]interface{}, error) {
+	if c.actionParams == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	return c.actionParams, nil
+}
+
+func (c *Context) SetActionResult(key string, value interface{}) error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	if c.actionResults == nil {
+		c.actionResults = make(map[string]interface{})
+	}
+	c.actionResults[key] = value
+	return nil
+}
+
+func (c *Context) SetActionFailed(message string) error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	c.actionFailed = message
+	return nil
+}
+
+func (c *Context) WorkloadStatus() (params.Status, string, error) {
+	if c.workloadStatus == "" {
+		return params.StatusUnknown, "", nil
+	}
+	return c.workloadStatus, c.workloadMessage, nil
+}
+
+func (c *Context) SetWorkloadStatus(status params.Status, message string) error {
+	c.workloadStatus = status
+	c.workloadMessage = message
+	return nil
+}
+
+func (c *Context) IsLeader() (bool, error) {
+	return c.leader, nil
+}
+
+func (c *Context) LeaderSettings() (map[string]string, error) {
+	settings := map[string]string{}
+	for k, v := range c.leaderSettings {
+		settings[k] = v
+	}
+	return settings, nil
+}
+
+func (c *Context) SetLeaderSettings(settings map[string]string) error {
+	if !c.leader {
+		return fmt.Errorf("unit is not the leader")
+	}
+	if c.leaderSettings == nil {
+		c.leaderSettings = map[string]string{}
+	}
+	for k, v := range settings {
+		if v == "" {
+			delete(c.leaderSettings, k)
+		} else {
+			c.leaderSettings[k] = v
+		}
+	}
+	return nil
+}
+
 type ContextRelation struct {
 	id    int
 	name  string
@@ -6,6 +6,8 @@ package uniter
 import (
 	stderrors "errors"
 	"fmt"
+	"strings"
+
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/charm/hooks"
 	"launchpad.net/juju-core/environs"
@@ -221,7 +223,10 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 // * service configuration changes
 // * charm upgrade requests
 // * relation changes
+// * commands sent by "juju run"
+// * actions sent by "juju do"
 // * unit death
+// It also runs the "update-status" hook periodically.
 func ModeAbide(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeAbide", &err)()
 	if u.s.Op != Continue {
@@ -249,19 +254,80 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 	return modeAbideAliveLoop(u)
 }
 
+// ready is a closed channel, always ready to receive from.
+var ready = make(chan struct{})
+
+func init() {
+	close(ready)
+}
+
 // modeAbideAliveLoop handles all state changes for ModeAbide when the unit
 // is in an Alive state.
 func modeAbideAliveLoop(u *Uniter) (Mode, error) {
+	updateStatus, err := u.updateStatusTimer()
+	if err != nil {
+		return nil, err
+	}
 	for {
 		hi := hook.Info{}
+		// Relation hooks stay queued while the unit is paused; the
+		// filter holds back the other hook events itself.
+		relationHooks := u.relationHooks
+		if u.paused {
+			relationHooks = nil
+		}
+		// Once nothing else is ready, the unit reports that it is idle.
+		var idle <-chan struct{}
+		if !u.paused && !u.unit.IsIdle() {
+			idle = ready
+		}
 		select {
 		case <-u.tomb.Dying():
 			return nil, tomb.ErrDying
 		case <-u.f.UnitDying():
 			return modeAbideDyingLoop(u)
+		case u.paused = <-u.f.PausedEvents():
+			if u.paused {
+				log.Infof("worker/uniter: hooks paused")
+				// Hooks may be held back while paused, so the unit
+				// cannot claim to be idle.
+				if u.unit.IsIdle() {
+					if err := u.unit.SetIdle(false); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				log.Infof("worker/uniter: hooks resumed")
+			}
+			continue
 		case <-u.f.ConfigEvents():
 			hi = hook.Info{Kind: hooks.ConfigChanged}
-		case hi = <-u.relationHooks:
+		case <-u.f.LeaderElectedEvents():
+			hi = hook.Info{Kind: hooks.LeaderElected}
+		case <-u.f.LeaderSettingsEvents():
+			hi = hook.Info{Kind: hooks.LeaderSettingsChanged}
+		case hi = <-relationHooks:
+		case <-updateStatus:
+			if updateStatus, err = u.updateStatusTimer(); err != nil {
+				return nil, err
+			}
+			if u.paused {
+				continue
+			}
+			// The update-status hook gives way to any other hook that
+			// is waiting to run.
+			var pending bool
+			if hi, pending = u.pendingHook(relationHooks); !pending {
+				hi = hook.Info{Kind: hooks.UpdateStatus}
+			}
+		case <-idle:
+			var pending bool
+			if hi, pending = u.pendingHook(relationHooks); !pending {
+				if err := u.unit.SetIdle(true); err != nil {
+					return nil, err
+				}
+				continue
+			}
 		case ids := <-u.f.RelationsEvents():
 			added, err := u.updateRelations(ids)
 			if err != nil {
@@ -271,6 +337,16 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 				r.StartHooks()
 			}
 			continue
+		case <-u.f.RunEvents():
+			if err := u.runCommands(); err != nil {
+				return nil, err
+			}
+			continue
+		case <-u.f.ActionEvents():
+			if err := u.runActions(); err != nil {
+				return nil, err
+			}
+			continue
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
 		}
@@ -283,6 +359,23 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 	panic("unreachable")
 }
 
+// pendingHook returns a hook that is already waiting to run, if there is
+// one, without blocking.
+func (u *Uniter) pendingHook(relationHooks <-chan hook.Info) (hook.Info, bool) {
+	select {
+	case <-u.f.ConfigEvents():
+		return hook.Info{Kind: hooks.ConfigChanged}, true
+	case <-u.f.LeaderElectedEvents():
+		return hook.Info{Kind: hooks.LeaderElected}, true
+	case <-u.f.LeaderSettingsEvents():
+		return hook.Info{Kind: hooks.LeaderSettingsChanged}, true
+	case hi := <-relationHooks:
+		return hi, true
+	default:
+	}
+	return hook.Info{}, false
+}
+
 // modeAbideDyingLoop handles the proper termination of all relations in
 // response to a Dying unit.
 func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
@@ -316,6 +409,16 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 		case <-u.f.ConfigEvents():
 			hi = hook.Info{Kind: hooks.ConfigChanged}
 		case hi = <-u.relationHooks:
+		case <-u.f.RunEvents():
+			if err := u.runCommands(); err != nil {
+				return nil, err
+			}
+			continue
+		case <-u.f.ActionEvents():
+			if err := u.runActions(); err != nil {
+				return nil, err
+			}
+			continue
 		}
 		if err = u.runHook(hi); err == errHookFailed {
 			return ModeHookError, nil
@@ -329,15 +432,32 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 // ModeHookError is responsible for watching and responding to:
 // * user resolution of hook errors
 // * charm upgrade requests
+// * commands sent by "juju run"
 func ModeHookError(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeHookError", &err)()
 	if u.s.Op != RunHook || u.s.OpStep != Pending {
 		return nil, fmt.Errorf("insane uniter state: %#v", u.s)
 	}
-	msg := fmt.Sprintf("hook failed: %q", u.s.Hook.Kind)
-	if err = u.unit.SetStatus(params.StatusError, msg); err != nil {
+	failed := fmt.Sprintf("hook failed: %q", u.s.Hook.Kind)
+	setStatus := func() error {
+		msg := failed
+		if u.hookTimedOut > 0 {
+			msg += fmt.Sprintf(" (timed out after %v)", u.hookTimedOut)
+		}
+		return u.unit.SetStatus(params.StatusError, msg)
+	}
+	// If the uniter was restarted, the status already reports the
+	// failure, along with details such as a timeout that are no
+	// longer known; it is left alone.
+	status, info, err := u.unit.Status()
+	if err != nil {
 		return nil, err
 	}
+	if status != params.StatusError || !strings.HasPrefix(info, failed) {
+		if err = setStatus(); err != nil {
+			return nil, err
+		}
+	}
 	u.f.WantResolvedEvent()
 	u.f.WantUpgradeEvent(true)
 	for {
@@ -357,11 +477,18 @@ func ModeHookError(u *Uniter) (next Mode, err error) {
 				return nil, e
 			}
 			if err == errHookFailed {
+				if err = setStatus(); err != nil {
+					return nil, err
+				}
 				continue
 			} else if err != nil {
 				return nil, err
 			}
 			return ModeContinue, nil
+		case <-u.f.RunEvents():
+			if err := u.runCommands(); err != nil {
+				return nil, err
+			}
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
 		}
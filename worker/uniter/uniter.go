@@ -23,6 +23,7 @@ import (
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/utils"
+	utilexec "launchpad.net/juju-core/utils/exec"
 	"launchpad.net/juju-core/utils/fslock"
 	"launchpad.net/juju-core/worker/uniter/charm"
 	"launchpad.net/juju-core/worker/uniter/hook"
@@ -57,6 +58,14 @@ type Uniter struct {
 	hookLock     *fslock.Lock
 
 	ranConfigChanged bool
+
+	// paused holds whether the unit's hooks are paused, as last
+	// reported by the filter.
+	paused bool
+
+	// hookTimedOut holds the timeout after which the last hook run
+	// was killed, or zero if it was not.
+	hookTimedOut time.Duration
 }
 
 // NewUniter creates a new Uniter which will install, run, and upgrade a
@@ -140,6 +149,12 @@ func (u *Uniter) init(name string) (err error) {
 	if err = u.setupLocks(); err != nil {
 		return err
 	}
+	// The unit is not idle until it has caught up with its hooks.
+	if u.unit.IsIdle() {
+		if err = u.unit.SetIdle(false); err != nil {
+			return err
+		}
+	}
 	ename := u.unit.Tag()
 	u.toolsDir = tools.ToolsDir(u.dataDir, ename)
 	if err := EnsureJujucSymlinks(u.toolsDir); err != nil {
@@ -277,6 +292,196 @@ func (u *Uniter) deploy(curl *corecharm.URL, reason Op) error {
 	return u.writeState(RunHook, status, hi, nil)
 }
 
+// acquireHookLock acquires the machine-wide lock that serialises hook
+// execution, giving up only if the uniter is stopped while waiting.
+func (u *Uniter) acquireHookLock(message string) error {
+	// We want to make sure we don't block forever when locking, but take the
+	// tomb into account.
+	checkTomb := func() error {
+		select {
+		case <-u.tomb.Dying():
+			return tomb.ErrDying
+		default:
+			// no-op to fall through to return.
+		}
+		return nil
+	}
+	return u.hookLock.LockWithFunc(message, checkTomb)
+}
+
+// hookContext returns a new HookContext for running the named hook, or
+// any other commands that need access to the jujuc tools.
+func (u *Uniter) hookContext(hookName string, relationId int, remoteUnit string) (*HookContext, error) {
+	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
+	ctxRelations := map[int]*ContextRelation{}
+	for id, r := range u.relationers {
+		ctxRelations[id] = r.Context()
+	}
+	apiAddrs, err := u.st.APIAddresses()
+	if err != nil {
+		return nil, err
+	}
+	return NewHookContext(u.unit, hctxId, u.uuid, relationId, remoteUnit,
+		ctxRelations, apiAddrs), nil
+}
+
+// startJujucServer starts a jujuc server serving the supplied context,
+// and returns it along with the path of its socket. The caller is
+// responsible for closing the server.
+func (u *Uniter) startJujucServer(hctx *HookContext) (*jujuc.Server, string, error) {
+	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
+		// TODO: switch to long-running server with single context;
+		// use nonce in place of context id.
+		if ctxId != hctx.id {
+			return nil, fmt.Errorf("expected context id %q, got %q", hctx.id, ctxId)
+		}
+		return jujuc.NewCommand(hctx, cmdName)
+	}
+	socketPath := filepath.Join(u.baseDir, "agent.socket")
+	srv, err := jujuc.NewServer(getCmd, socketPath)
+	if err != nil {
+		return nil, "", err
+	}
+	go srv.Run()
+	return srv, socketPath, nil
+}
+
+// runCommands executes, one at a time and in the order they were
+// queued, any commands sent to the unit by "juju run". Each set of
+// commands is run in a hook context while holding the hook lock, so
+// that it never runs concurrently with a hook, and its result is
+// recorded in state for the client to collect.
+func (u *Uniter) runCommands() error {
+	pending, err := u.st.PendingRunCommands(u.unit.Tag())
+	if err != nil {
+		return err
+	}
+	for _, rc := range pending {
+		// The client may have given up waiting while earlier
+		// commands ran, in which case the commands are not run.
+		if err := rc.Refresh(); errors.IsNotFoundError(err) {
+			log.Debugf("worker/uniter: %s was removed before it ran", rc)
+			continue
+		} else if err != nil {
+			return err
+		}
+		result, err := u.runCommand(rc)
+		if err != nil {
+			return err
+		}
+		if err := rc.Complete(result); errors.IsNotFoundError(err) {
+			// Nobody is waiting for the result any more.
+			log.Debugf("worker/uniter: %s was removed before completion", rc)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand executes a single set of commands sent by "juju run". A
+// failure to execute the commands is reported in the result rather
+// than returned, so that it does not stop the uniter.
+func (u *Uniter) runCommand(rc *state.RunCommand) (state.RunResult, error) {
+	lockMessage := fmt.Sprintf("%s: running commands", u.unit.Name())
+	if err := u.acquireHookLock(lockMessage); err != nil {
+		return state.RunResult{}, err
+	}
+	defer u.hookLock.Unlock()
+
+	hctx, err := u.hookContext("run-commands", -1, "")
+	if err != nil {
+		return state.RunResult{}, err
+	}
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return state.RunResult{}, err
+	}
+	defer srv.Close()
+
+	log.Infof("worker/uniter: running %s", rc)
+	response, err := hctx.RunCommands(rc.Commands(), u.charm.Path(), u.toolsDir, socketPath, rc.Timeout())
+	var result state.RunResult
+	if response != nil {
+		result.Code = response.Code
+		result.Stdout = response.Stdout
+		result.Stderr = response.Stderr
+	}
+	if err == utilexec.ErrTimedOut {
+		result.Error = "timed out"
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+	log.Infof("worker/uniter: ran %s", rc)
+	return result, nil
+}
+
+// runActions runs, one at a time and in the order they were queued,
+// any actions sent to the unit by "juju do". Each action is run in a
+// hook context while holding the hook lock, so that it never runs
+// concurrently with a hook, and its results are recorded in state for
+// the client to collect.
+func (u *Uniter) runActions() error {
+	pending, err := u.st.PendingActions(u.unit.Name())
+	if err != nil {
+		return err
+	}
+	for _, a := range pending {
+		if err := u.runAction(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAction runs a single action sent by "juju do", and records its
+// results. An action that fails to run, or that exits with an error,
+// is recorded as failed; this does not stop the uniter.
+func (u *Uniter) runAction(a *state.Action) error {
+	lockMessage := fmt.Sprintf("%s: running action %q", u.unit.Name(), a.Name())
+	if err := u.acquireHookLock(lockMessage); err != nil {
+		return err
+	}
+	defer u.hookLock.Unlock()
+
+	hctx, err := u.hookContext("action-"+a.Name(), -1, "")
+	if err != nil {
+		return err
+	}
+	hctx.actionName = a.Name()
+	hctx.actionParams = a.Params()
+	if hctx.actionParams == nil {
+		hctx.actionParams = map[string]interface{}{}
+	}
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	log.Infof("worker/uniter: running %s", a)
+	err = hctx.RunAction(a.Name(), u.charm.Path(), u.toolsDir, socketPath)
+	if err != nil {
+		log.Errorf("worker/uniter: %s failed: %v", a, err)
+		if !hctx.actionFailed {
+			hctx.actionFailed = true
+			hctx.actionMessage = err.Error()
+		}
+	}
+	if hctx.actionFailed {
+		err = a.Fail(hctx.actionResults, hctx.actionMessage)
+	} else {
+		err = a.Complete(hctx.actionResults)
+	}
+	if errors.IsNotFoundError(err) {
+		log.Debugf("worker/uniter: %s was removed before completion", a)
+	} else if err != nil {
+		return err
+	}
+	log.Infof("worker/uniter: ran %s", a)
+	return nil
+}
+
 // errHookFailed indicates that a hook failed to execute, but that the Uniter's
 // operation is not affected by the error.
 var errHookFailed = stderrors.New("hook execution failed")
@@ -289,6 +494,12 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 		return err
 	}
 
+	if u.unit.IsIdle() {
+		if err = u.unit.SetIdle(false); err != nil {
+			return err
+		}
+	}
+
 	hookName := string(hi.Kind)
 	relationId := -1
 	if hi.Kind.IsRelation() {
@@ -297,50 +508,24 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 			return err
 		}
 	}
-	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
-	// We want to make sure we don't block forever when locking, but take the
-	// tomb into account.
-	checkTomb := func() error {
-		select {
-		case <-u.tomb.Dying():
-			return tomb.ErrDying
-		default:
-			// no-op to fall through to return.
-		}
-		return nil
-	}
 	lockMessage := fmt.Sprintf("%s: running hook %q", u.unit.Name(), hookName)
-	if err = u.hookLock.LockWithFunc(lockMessage, checkTomb); err != nil {
+	if err = u.acquireHookLock(lockMessage); err != nil {
 		return err
 	}
 	defer u.hookLock.Unlock()
 
-	ctxRelations := map[int]*ContextRelation{}
-	for id, r := range u.relationers {
-		ctxRelations[id] = r.Context()
-	}
-	apiAddrs, err := u.st.APIAddresses()
+	hctx, err := u.hookContext(hookName, relationId, hi.RemoteUnit)
 	if err != nil {
 		return err
 	}
-	hctx := NewHookContext(u.unit, hctxId, u.uuid, relationId, hi.RemoteUnit,
-		ctxRelations, apiAddrs)
-
-	// Prepare server.
-	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
-		// TODO: switch to long-running server with single context;
-		// use nonce in place of context id.
-		if ctxId != hctxId {
-			return nil, fmt.Errorf("expected context id %q, got %q", hctxId, ctxId)
-		}
-		return jujuc.NewCommand(hctx, cmdName)
+	timeout, err := u.hookTimeout()
+	if err != nil {
+		return err
 	}
-	socketPath := filepath.Join(u.baseDir, "agent.socket")
-	srv, err := jujuc.NewServer(getCmd, socketPath)
+	srv, socketPath, err := u.startJujucServer(hctx)
 	if err != nil {
 		return err
 	}
-	go srv.Run()
 	defer srv.Close()
 
 	// Run the hook.
@@ -348,7 +533,12 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 		return err
 	}
 	log.Infof("worker/uniter: running %q hook", hookName)
-	if err := hctx.RunHook(hookName, u.charm.Path(), u.toolsDir, socketPath); err != nil {
+	u.hookTimedOut = 0
+	if err := hctx.RunHook(hookName, u.charm.Path(), u.toolsDir, socketPath, timeout); err != nil {
+		if err == utilexec.ErrTimedOut {
+			u.hookTimedOut = timeout
+			err = fmt.Errorf("timed out after %v", timeout)
+		}
 		log.Errorf("worker/uniter: hook failed: %s", err)
 		return errHookFailed
 	}
@@ -359,6 +549,40 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	return u.commitHook(hi)
 }
 
+// hookTimeout returns the time a hook may run before it is killed: the
+// hook timeout in the charm's metadata if there is one, or else the
+// environment's. Zero means that hooks may run forever.
+func (u *Uniter) hookTimeout() (time.Duration, error) {
+	ch, err := corecharm.ReadDir(u.charm.Path())
+	if err != nil {
+		return 0, err
+	}
+	if timeout := ch.Meta().HookTimeout; timeout > 0 {
+		return timeout, nil
+	}
+	cfg, err := u.st.EnvironConfig()
+	if err != nil {
+		return 0, err
+	}
+	return cfg.HookTimeout(), nil
+}
+
+// updateStatusTimer returns a channel that receives a value when the
+// update-status hook is next due, as set by the environment's
+// update-status interval. If the interval is zero, the hook is never
+// due and the returned channel is nil.
+func (u *Uniter) updateStatusTimer() (<-chan time.Time, error) {
+	cfg, err := u.st.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	interval := cfg.UpdateStatusInterval()
+	if interval == 0 {
+		return nil, nil
+	}
+	return time.After(interval), nil
+}
+
 // commitHook ensures that state is consistent with the supplied hook, and
 // that the fact of the hook's completion is persisted.
 func (u *Uniter) commitHook(hi hook.Info) error {
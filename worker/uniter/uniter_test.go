@@ -467,6 +467,278 @@ func (s *UniterSuite) TestUniterHookSynchronisation(c *C) {
 	s.runUniterTests(c, hookSynchronizationTests)
 }
 
+var pauseTests = []uniterTest{
+	ut(
+		"config change queued while paused",
+		quickStart{},
+		pauseUnit{},
+		changeConfig{"blog-title": "Goodness Gracious Me"},
+		waitHooks{},
+		resumeUnit{},
+		waitHooks{"config-changed"},
+		verifyRunning{},
+	), ut(
+		"relation hooks queued while paused",
+		quickStartRelation{},
+		pauseUnit{},
+		addRelationUnit{},
+		waitHooks{},
+		resumeUnit{},
+		waitHooks{"db-relation-joined mysql/1 db:0", "db-relation-changed mysql/1 db:0"},
+		verifyRunning{},
+	), ut(
+		"unit dies while paused",
+		quickStartRelation{},
+		pauseUnit{},
+		unitDying,
+		waitHooks{"db-relation-departed mysql/0 db:0", "db-relation-broken db:0", "stop"},
+		waitUniterDead{},
+	),
+}
+
+func (s *UniterSuite) TestUniterPause(c *C) {
+	s.runUniterTests(c, pauseTests)
+}
+
+var idleTests = []uniterTest{
+	ut(
+		"unit reports idle once its hooks have run",
+		quickStart{},
+		waitIdle{true},
+		changeConfig{"blog-title": "Goodness Gracious Me"},
+		waitHooks{"config-changed"},
+		waitIdle{true},
+	), ut(
+		"paused unit is not idle",
+		quickStart{},
+		waitIdle{true},
+		pauseUnit{},
+		waitIdle{false},
+		resumeUnit{},
+		waitIdle{true},
+	), ut(
+		"unit in error state is not idle",
+		startupError{"config-changed"},
+		waitIdle{false},
+		fixHook{"config-changed"},
+		resolveError{state.ResolvedNoHooks},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"start", "config-changed"},
+		waitIdle{true},
+	),
+}
+
+func (s *UniterSuite) TestUniterIdle(c *C) {
+	s.runUniterTests(c, idleTests)
+}
+
+var hookTimeoutTests = []uniterTest{
+	ut(
+		"hook killed after the charm's hook timeout",
+		createCharm{
+			customize: func(c *C, ctx *context, path string) {
+				appendHook(c, path, "start", "sleep 10")
+				appendMeta(c, path, "hook-timeout: 1s\n")
+			},
+		},
+		serveCharm{},
+		createUniter{},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start" (timed out after 1s)`,
+		},
+		// The timeout is still reported after a restart.
+		stopUniter{},
+		startUniter{},
+		waitHooks{},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start" (timed out after 1s)`,
+		},
+		resolveError{state.ResolvedNoHooks},
+		waitUnit{
+			status: params.StatusStarted,
+		},
+	), ut(
+		"hook killed after the environment's hook timeout",
+		createCharm{
+			customize: func(c *C, ctx *context, path string) {
+				appendHook(c, path, "start", "sleep 10")
+			},
+		},
+		serveCharm{},
+		createServiceAndUnit{},
+		setHookTimeout{"1s"},
+		startUniter{},
+		waitAddresses{},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start" (timed out after 1s)`,
+		},
+	), ut(
+		"charm's hook timeout overrides the environment's",
+		createCharm{
+			customize: func(c *C, ctx *context, path string) {
+				appendHook(c, path, "start", "sleep 2")
+				appendMeta(c, path, "hook-timeout: 1m\n")
+			},
+		},
+		serveCharm{},
+		createServiceAndUnit{},
+		setHookTimeout{"1s"},
+		startUniter{},
+		waitAddresses{},
+		waitUnit{
+			status: params.StatusStarted,
+		},
+	),
+}
+
+func (s *UniterSuite) TestUniterHookTimeout(c *C) {
+	s.runUniterTests(c, hookTimeoutTests)
+}
+
+var updateStatusTests = []uniterTest{
+	ut(
+		"update-status hook runs periodically",
+		createCharm{
+			customize: func(c *C, ctx *context, path string) {
+				ctx.writeHook(c, filepath.Join(path, "hooks", "update-status"), true)
+			},
+		},
+		serveCharm{},
+		createServiceAndUnit{},
+		setUpdateStatusInterval{"500ms"},
+		startUniter{},
+		waitAddresses{},
+		waitUnit{
+			status: params.StatusStarted,
+		},
+		waitHooks{"install", "config-changed", "start", "update-status"},
+		waitHooks{"update-status"},
+	),
+}
+
+func (s *UniterSuite) TestUniterUpdateStatus(c *C) {
+	s.runUniterTests(c, updateStatusTests)
+}
+
+var runCommandsTests = []uniterTest{
+	ut(
+		"commands run in a hook context while started",
+		quickStart{},
+		runCommands{
+			commands: "echo $JUJU_UNIT_NAME; unit-get private-address; echo oops >&2; exit 3",
+			result: state.RunResult{
+				Code:   3,
+				Stdout: []byte("u/0\nprivate.dummy.address.example.com\n"),
+				Stderr: []byte("oops\n"),
+			},
+		},
+		waitHooks{},
+	),
+	ut(
+		"commands run while in an error state",
+		startupError{"start"},
+		runCommands{
+			commands: "echo hello",
+			result:   state.RunResult{Stdout: []byte("hello\n")},
+		},
+		verifyWaiting{},
+	),
+	ut(
+		"commands are killed when they time out",
+		quickStart{},
+		runCommands{
+			commands: "echo before; sleep 10",
+			timeout:  100 * time.Millisecond,
+			result: state.RunResult{
+				Code:   -1,
+				Stdout: []byte("before\n"),
+				Error:  "timed out",
+			},
+		},
+	),
+	ut(
+		"commands do not run while the hook lock is held",
+		quickStart{},
+		acquireHookSyncLock{},
+		enqueueCommands{"echo hello"},
+		verifyCommandsPending,
+		releaseHookSyncLock,
+		runCommands{
+			commands: "echo goodbye",
+			result:   state.RunResult{Stdout: []byte("goodbye\n")},
+		},
+	),
+	ut(
+		"commands removed while waiting for the hook lock are not run",
+		quickStart{},
+		acquireHookSyncLock{},
+		enqueueCommands{"echo hello"},
+		enqueueRemovedCommands,
+		releaseHookSyncLock,
+		runCommands{
+			commands: "echo goodbye",
+			result:   state.RunResult{Stdout: []byte("goodbye\n")},
+		},
+		verifyRemovedCommandsNotRun,
+	),
+}
+
+func (s *UniterSuite) TestUniterRunCommands(c *C) {
+	s.runUniterTests(c, runCommandsTests)
+}
+
+var runActionsTests = []uniterTest{
+	ut(
+		"action runs in a hook context with its parameters",
+		actionsStart{},
+		runAction{
+			name:    "snapshot",
+			params:  map[string]interface{}{"outfile": "bar.gz"},
+			status:  state.ActionCompleted,
+			results: map[string]interface{}{"outfile": "bar.gz", "name": "snapshot", "unit": "u/0"},
+		},
+		runAction{
+			name:    "snapshot",
+			status:  state.ActionCompleted,
+			results: map[string]interface{}{"outfile": "foo.bz2", "name": "snapshot", "unit": "u/0"},
+		},
+		waitHooks{},
+	),
+	ut(
+		"action reports failure with action-fail",
+		actionsStart{},
+		runAction{
+			name:    "fail",
+			status:  state.ActionFailed,
+			results: map[string]interface{}{"partial": "yes"},
+			message: "disk full",
+		},
+	),
+	ut(
+		"action fails when it exits with an error",
+		actionsStart{},
+		runAction{
+			name:    "crash",
+			status:  state.ActionFailed,
+			message: "exit status 3",
+		},
+		runAction{
+			name:    "missing",
+			status:  state.ActionFailed,
+			message: ".*/actions/missing: no such file or directory",
+		},
+		waitHooks{},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterRunActions(c *C) {
+	s.runUniterTests(c, runActionsTests)
+}
+
 var dyingReactionTests = []uniterTest{
 	// Reaction to entity deaths.
 	ut(
@@ -1039,6 +1311,32 @@ func (serveCharm) step(c *C, ctx *context) {
 	coretesting.Server.ResponseMap(1, ctx.charms)
 }
 
+type setHookTimeout struct {
+	timeout string
+}
+
+func (s setHookTimeout) step(c *C, ctx *context) {
+	cfg, err := ctx.st.EnvironConfig()
+	c.Assert(err, IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"hook-timeout": s.timeout})
+	c.Assert(err, IsNil)
+	err = ctx.st.SetEnvironConfig(cfg)
+	c.Assert(err, IsNil)
+}
+
+type setUpdateStatusInterval struct {
+	interval string
+}
+
+func (s setUpdateStatusInterval) step(c *C, ctx *context) {
+	cfg, err := ctx.st.EnvironConfig()
+	c.Assert(err, IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"update-status-interval": s.interval})
+	c.Assert(err, IsNil)
+	err = ctx.st.SetEnvironConfig(cfg)
+	c.Assert(err, IsNil)
+}
+
 type createServiceAndUnit struct{}
 
 func (createServiceAndUnit) step(c *C, ctx *context) {
@@ -1261,6 +1559,45 @@ func (s resolveError) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+type pauseUnit struct{}
+
+func (s pauseUnit) step(c *C, ctx *context) {
+	err := ctx.unit.SetPaused()
+	c.Assert(err, IsNil)
+}
+
+type resumeUnit struct{}
+
+func (s resumeUnit) step(c *C, ctx *context) {
+	err := ctx.unit.ClearPaused()
+	c.Assert(err, IsNil)
+}
+
+type waitIdle struct {
+	idle bool
+}
+
+func (s waitIdle) step(c *C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		ctx.st.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			err := ctx.unit.Refresh()
+			if err != nil {
+				c.Fatalf("cannot refresh unit: %v", err)
+			}
+			if idle := ctx.unit.IsIdle(); idle != s.idle {
+				c.Logf("want unit idle %v, got %v; still waiting", s.idle, idle)
+				continue
+			}
+			return
+		case <-timeout:
+			c.Fatalf("never reached desired idle state")
+		}
+	}
+}
+
 type waitUnit struct {
 	status   params.Status
 	info     string
@@ -1640,6 +1977,145 @@ func (s chmod) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+type enqueueCommands struct {
+	commands string
+}
+
+func (s enqueueCommands) step(c *C, ctx *context) {
+	_, err := ctx.st.EnqueueRunCommand(ctx.unit.Tag(), s.commands, 0)
+	c.Assert(err, IsNil)
+}
+
+var verifyCommandsPending = custom{func(c *C, ctx *context) {
+	ctx.st.StartSync()
+	time.Sleep(coretesting.ShortWait)
+	pending, err := ctx.st.PendingRunCommands(ctx.unit.Tag())
+	c.Assert(err, IsNil)
+	c.Assert(pending, Not(HasLen), 0)
+}}
+
+// enqueueRemovedCommands enqueues commands that create a file, waits
+// for the uniter to see them, and removes them, as a client that timed
+// out would.
+var enqueueRemovedCommands = custom{func(c *C, ctx *context) {
+	rc, err := ctx.st.EnqueueRunCommand(ctx.unit.Tag(), "touch "+filepath.Join(ctx.path, "removed-commands-ran"), 0)
+	c.Assert(err, IsNil)
+	step(c, ctx, verifyCommandsPending)
+	err = rc.Remove()
+	c.Assert(err, IsNil)
+}}
+
+var verifyRemovedCommandsNotRun = custom{func(c *C, ctx *context) {
+	_, err := os.Stat(filepath.Join(ctx.path, "removed-commands-ran"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}}
+
+type runCommands struct {
+	commands string
+	timeout  time.Duration
+	result   state.RunResult
+}
+
+func (s runCommands) step(c *C, ctx *context) {
+	rc, err := ctx.st.EnqueueRunCommand(ctx.unit.Tag(), s.commands, s.timeout)
+	c.Assert(err, IsNil)
+	timeout := time.After(worstCase)
+	for {
+		ctx.st.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			err := rc.Refresh()
+			c.Assert(err, IsNil)
+			result, done := rc.Result()
+			if !done {
+				c.Logf("commands not yet completed; still waiting")
+				continue
+			}
+			c.Assert(result, DeepEquals, s.result)
+			return
+		case <-timeout:
+			c.Fatalf("commands never completed")
+		}
+	}
+}
+
+var actionsYaml = `
+actions:
+  snapshot:
+    params:
+      outfile:
+        type: string
+        default: foo.bz2
+  fail: {}
+  crash: {}
+  missing: {}
+`
+
+var actionScripts = map[string]string{
+	"snapshot": "action-set outfile=\"$(action-get outfile)\" name=$JUJU_ACTION_NAME unit=$JUJU_UNIT_NAME",
+	"fail":     "action-set partial=yes; action-fail \"disk full\"",
+	"crash":    "exit 3",
+}
+
+type actionsStart struct{}
+
+func (s actionsStart) step(c *C, ctx *context) {
+	step(c, ctx, createCharm{
+		customize: func(c *C, ctx *context, path string) {
+			err := ioutil.WriteFile(filepath.Join(path, "actions.yaml"), []byte(actionsYaml), 0644)
+			c.Assert(err, IsNil)
+			err = os.Mkdir(filepath.Join(path, "actions"), 0755)
+			c.Assert(err, IsNil)
+			for name, script := range actionScripts {
+				content := "#!/bin/bash\n" + script + "\n"
+				err := ioutil.WriteFile(filepath.Join(path, "actions", name), []byte(content), 0755)
+				c.Assert(err, IsNil)
+			}
+		},
+	})
+	step(c, ctx, serveCharm{})
+	step(c, ctx, createUniter{})
+	step(c, ctx, waitUnit{status: params.StatusStarted})
+	step(c, ctx, waitHooks{"install", "config-changed", "start"})
+}
+
+type runAction struct {
+	name    string
+	params  map[string]interface{}
+	status  state.ActionStatus
+	results map[string]interface{}
+	message string
+}
+
+func (s runAction) step(c *C, ctx *context) {
+	a, err := ctx.st.EnqueueAction(ctx.unit.Name(), s.name, s.params)
+	c.Assert(err, IsNil)
+	timeout := time.After(worstCase)
+	for {
+		ctx.st.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			err := a.Refresh()
+			c.Assert(err, IsNil)
+			if a.Status() == state.ActionPending {
+				c.Logf("action not yet finished; still waiting")
+				continue
+			}
+			c.Assert(a.Status(), Equals, s.status)
+			results, message := a.Results()
+			if s.results == nil {
+				c.Assert(results, HasLen, 0)
+			} else {
+				c.Assert(results, DeepEquals, s.results)
+			}
+			c.Assert(message, Matches, s.message)
+			return
+		case <-timeout:
+			c.Fatalf("action never finished")
+		}
+	}
+}
+
 type custom struct {
 	f func(*C, *context)
 }
@@ -1681,6 +2157,15 @@ func appendHook(c *C, charm, name, data string) {
 	c.Assert(err, IsNil)
 }
 
+func appendMeta(c *C, charm, data string) {
+	path := filepath.Join(charm, "metadata.yaml")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = f.Write([]byte(data))
+	c.Assert(err, IsNil)
+}
+
 func renameRelation(c *C, charmPath, oldName, newName string) {
 	path := filepath.Join(charmPath, "metadata.yaml")
 	f, err := os.Open(path)